@@ -0,0 +1,200 @@
+package flat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNonNumericAggregate is returned when a column aggregated using WithAggregates holds a non-numeric value.
+var ErrNonNumericAggregate = errors.New("non-numeric value in aggregated column")
+
+// AggFunc defines the aggregation computed over the values of a column for the footer row.
+type AggFunc int
+
+const (
+	// AggSum sums the numeric values of the column.
+	AggSum AggFunc = iota
+	// AggCount counts the non-empty values of the column, numeric or not.
+	AggCount
+	// AggAvg averages the numeric values of the column.
+	AggAvg
+	// AggMin returns the lowest numeric value of the column.
+	AggMin
+	// AggMax returns the highest numeric value of the column.
+	AggMax
+)
+
+// WithAggregates writes a footer row after the data rows with the aggregation of each listed column,
+// identified by its original name. The remaining cells of the footer are left empty.
+// Aggregations are computed over the formatted values of the rows written to each writer, so every split
+// gets its own footer, and are not included in the RowsWritten stats.
+// Null, missing and empty values are ignored. A non-numeric value in a column aggregated with a function
+// other than AggCount makes the row fail with ErrNonNumericAggregate, unless SkipNonNumericAggregates is used.
+func WithAggregates(aggregates map[string]AggFunc) ExportOption {
+	return func(o *exportOptions) {
+		o.aggregates = aggregates
+	}
+}
+
+// SkipNonNumericAggregates ignores the non-numeric values of the columns aggregated using WithAggregates
+// instead of failing the row.
+func SkipNonNumericAggregates() ExportOption {
+	return func(o *exportOptions) {
+		o.skipNonNumericAggregates = true
+	}
+}
+
+// aggregateInput holds the value of an aggregated column in a row.
+type aggregateInput struct {
+	value   decimal.Decimal
+	numeric bool
+}
+
+// aggregator accumulates the values of a column written to a single writer.
+type aggregator struct {
+	count    int
+	numerics int
+	sum      decimal.Decimal
+	min      decimal.Decimal
+	max      decimal.Decimal
+}
+
+// aggregateInputs returns the values of the aggregated columns of the row, keyed by column name.
+// Columns without a value are not included.
+// It returns an error if a value fails or a non-numeric value cannot be skipped.
+func (t *CSV) aggregateInputs(r *row) (map[string]aggregateInput, error) {
+	if len(t.options.aggregates) == 0 {
+		return nil, nil
+	}
+
+	inputs := make(map[string]aggregateInput, len(t.options.aggregates))
+	for name, fn := range t.options.aggregates {
+		column, exists := r.columns[name]
+		if !exists {
+			continue
+		}
+
+		data := column.data
+		if data.Error() != nil {
+			return nil, withColumn(name, fmt.Errorf("failed to get value: %w", data.Error()))
+		}
+		if data.IsNull() || (data.dataType == DataTypeString && data.value == "") {
+			continue
+		}
+
+		value, numeric := decimalValue(data)
+		if !numeric && fn != AggCount {
+			if t.options.skipNonNumericAggregates {
+				continue
+			}
+			return nil, withColumn(name, ErrNonNumericAggregate)
+		}
+
+		inputs[name] = aggregateInput{value: value, numeric: numeric}
+	}
+
+	return inputs, nil
+}
+
+// decimalValue converts a numeric DynamicValue into a decimal, so sums keep the exact literal values.
+func decimalValue(dv *DynamicValue) (decimal.Decimal, bool) {
+	switch dv.dataType {
+	case DataTypeFloat:
+		return decimal.NewFromFloat(dv.value.(float64)), true
+	case DataTypeInt:
+		return decimal.NewFromInt(int64(dv.value.(int))), true
+	case DataTypeNumber:
+		d, err := decimal.NewFromString(string(dv.value.(json.Number)))
+		return d, err == nil
+	default:
+		return decimal.Decimal{}, false
+	}
+}
+
+// newAggregators creates the aggregators of each writer, keyed by column name.
+// It returns nil if no aggregates are set.
+func (t *CSV) newAggregators(writers int) []map[string]*aggregator {
+	if len(t.options.aggregates) == 0 {
+		return nil
+	}
+
+	aggregators := make([]map[string]*aggregator, writers)
+	for i := range aggregators {
+		aggregators[i] = make(map[string]*aggregator, len(t.options.aggregates))
+		for name := range t.options.aggregates {
+			aggregators[i][name] = &aggregator{}
+		}
+	}
+
+	return aggregators
+}
+
+// add accumulates a column value.
+func (a *aggregator) add(input aggregateInput) {
+	a.count++
+	if !input.numeric {
+		return
+	}
+
+	if a.numerics == 0 || input.value.LessThan(a.min) {
+		a.min = input.value
+	}
+	if a.numerics == 0 || input.value.GreaterThan(a.max) {
+		a.max = input.value
+	}
+	a.sum = a.sum.Add(input.value)
+	a.numerics++
+}
+
+// result returns the aggregation as a cell value. Averages, minimums and maximums of columns
+// without numeric values are empty.
+func (a *aggregator) result(fn AggFunc) string {
+	switch fn {
+	case AggSum:
+		return a.sum.String()
+	case AggCount:
+		return strconv.Itoa(a.count)
+	case AggAvg:
+		if a.numerics == 0 {
+			return ""
+		}
+		return a.sum.Div(decimal.NewFromInt(int64(a.numerics))).String()
+	case AggMin:
+		if a.numerics == 0 {
+			return ""
+		}
+		return a.min.String()
+	case AggMax:
+		if a.numerics == 0 {
+			return ""
+		}
+		return a.max.String()
+	default:
+		return ""
+	}
+}
+
+// writeFooters writes the footer row of each encoder with the aggregations of the output columns.
+func (t *CSV) writeFooters(encoders []RowEncoder, aggregators []map[string]*aggregator, columns []string) error {
+	for i, encoder := range encoders {
+		footer := make([]Cell, len(columns))
+		for j, name := range columns {
+			footer[j].Data = newDynamicValue(nil)
+			if fn, ok := t.options.aggregates[name]; ok {
+				if result := aggregators[i][name].result(fn); result != "" {
+					footer[j] = Cell{Value: result, Data: newDynamicValue(json.Number(result))}
+				}
+			}
+		}
+
+		if err := encoder.WriteRow(footer); err != nil {
+			return fmt.Errorf("failed to write CSV footer: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package flat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestAggregatesSplit tests that each split writer gets a footer with its own totals
+func TestAggregatesSplit(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"name": "John", "age": 30, "balance": json.Number("10.10")},
+		{"name": "Jane", "age": 25, "balance": json.Number("0.20")},
+		{"name": "Bob", "age": 35, "balance": json.Number("-3.05")},
+		{"name": "Alice", "age": 22},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+		d.Col("balance", s.Key("balance"))
+	}
+
+	tests := []struct {
+		name       string
+		aggregates map[string]AggFunc
+		wantYoung  string
+		wantOld    string
+	}{
+		{
+			name:       "sum and count",
+			aggregates: map[string]AggFunc{"name": AggCount, "balance": AggSum},
+			wantYoung:  "name,age,balance\nJane,25,0.2\nAlice,22,\n2,,0.2\n",
+			wantOld:    "name,age,balance\nJohn,30,10.1\nBob,35,-3.05\n2,,7.05\n",
+		},
+		{
+			name:       "avg",
+			aggregates: map[string]AggFunc{"age": AggAvg, "balance": AggAvg},
+			wantYoung:  "name,age,balance\nJane,25,0.2\nAlice,22,\n,23.5,0.2\n",
+			wantOld:    "name,age,balance\nJohn,30,10.1\nBob,35,-3.05\n,32.5,3.525\n",
+		},
+		{
+			name:       "min and max",
+			aggregates: map[string]AggFunc{"age": AggMin, "balance": AggMax},
+			wantYoung:  "name,age,balance\nJane,25,0.2\nAlice,22,\n,22,0.2\n",
+			wantOld:    "name,age,balance\nJohn,30,10.1\nBob,35,-3.05\n,30,10.1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var young, old bytes.Buffer
+			stats, err := data.GetCSV(flattener, WithAggregates(tt.aggregates)).ExportSplitWithStats(
+				Split(&young, "age", func(v int) bool { return v < 30 }),
+				Split(&old, "age", func(v int) bool { return v >= 30 }),
+			)
+			if err != nil {
+				t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+			}
+
+			if young.String() != tt.wantYoung {
+				t.Errorf("CSV.ExportSplit() split 0 = %q, want %q", young.String(), tt.wantYoung)
+			}
+			if old.String() != tt.wantOld {
+				t.Errorf("CSV.ExportSplit() split 1 = %q, want %q", old.String(), tt.wantOld)
+			}
+			if stats.Writers[0].RowsWritten != 2 || stats.Writers[1].RowsWritten != 2 {
+				t.Errorf("RowsWritten = %d, %d, want footer excluded (2, 2)", stats.Writers[0].RowsWritten, stats.Writers[1].RowsWritten)
+			}
+		})
+	}
+}
+
+func TestAggregatesNonNumeric(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"id": 1, "price": 1.5},
+		{"id": 2, "price": "n/a"},
+		{"id": 3, "price": 2.25},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("price", s.Key("price"))
+	}
+
+	t.Run("fails by default", func(t *testing.T) {
+		err := data.GetCSV(flattener, WithAggregates(map[string]AggFunc{"price": AggSum})).Export(&bytes.Buffer{})
+		if !errors.Is(err, ErrNonNumericAggregate) {
+			t.Fatalf("CSV.Export() error = %v, want %v", err, ErrNonNumericAggregate)
+		}
+
+		var rowErr *RowError
+		if !errors.As(err, &rowErr) || rowErr.Row != 1 {
+			t.Errorf("CSV.Export() error = %v, want RowError for row 1", err)
+		}
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := data.GetCSV(flattener, WithAggregates(map[string]AggFunc{"price": AggSum, "id": AggCount}), SkipNonNumericAggregates()).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+		if want := "id,price\n1,1.5\n2,n/a\n3,2.25\n3,3.75\n"; buf.String() != want {
+			t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("formatted values", func(t *testing.T) {
+		cents := NewFormatter(func(v float64) (int, error) { return int(v * 100), nil })
+
+		var buf bytes.Buffer
+		err := data.GetCSV(func(s Source, d Dest) {
+			d.Col("id", s.Key("id"))
+			d.ColFormatted("price", s.Key("price"), Default(cents, 0))
+		}, WithAggregates(map[string]AggFunc{"price": AggSum})).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+		if want := "id,price\n1,150\n2,0\n3,225\n,375\n"; buf.String() != want {
+			t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("unknown function", func(t *testing.T) {
+		err := data.GetCSV(flattener, WithAggregates(map[string]AggFunc{"price": AggFunc(42)})).Export(&bytes.Buffer{})
+		if err == nil {
+			t.Error("CSV.Export() expected error for unknown aggregate function, got nil")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package flat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrConcatColumns is returned by the export of concatenated values whose rows do not have the same columns.
+var ErrConcatColumns = errors.New("concatenated values have different columns")
+
+// concatDataTypes defines the types of data that can be concatenated.
+var concatDataTypes = []DataType{
+	DataTypeArray,
+	DataTypeArrayOfObjects,
+	DataTypeStreamOfObjects,
+}
+
+// concatSource holds several values whose rows are streamed one after the other.
+type concatSource struct {
+	values []*DynamicValue
+}
+
+// Concat creates a new DynamicValue instance that streams the rows of each value, in order, as a single
+// stream of objects. It is useful to export data split across several shards or files in a single export,
+// writing one header line followed by the rows of every value.
+// The values must be arrays, arrays of objects or streams of objects, and the streams can only be exported once.
+// If any value holds an error or has a different data type, it returns a DynamicValue holding the error.
+// The first row of every value must have the same columns, in any order, or the export fails with ErrConcatColumns
+// instead of dropping the columns missing from the first value.
+func Concat(dvs ...*DynamicValue) *DynamicValue {
+	values := make([]*DynamicValue, 0, len(dvs))
+	for i, dv := range dvs {
+		if dv.Error() != nil {
+			return errorDynamicValue(fmt.Errorf("cannot concatenate value %d: %w", i, dv.Error()))
+		}
+		if !slices.Contains(concatDataTypes, dv.DataType()) {
+			return errorDynamicValue(fmt.Errorf("cannot concatenate value %d: only arrays and streams of objects can be concatenated", i))
+		}
+
+		values = append(values, dv)
+	}
+
+	return newDynamicValue(&concatSource{values: values})
+}
+
+// sameColumns returns whether the headers hold the same columns, in any order.
+func sameColumns(headers, other []string) bool {
+	if len(headers) != len(other) {
+		return false
+	}
+
+	for _, header := range other {
+		if !slices.Contains(headers, header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// streamConcatRows streams the rows of each concatenated value in order.
+// Every value starts with a row with headers, only the first one is written by the export and the others are
+// checked against it.
+func (t *CSV) streamConcatRows(ctx context.Context, rows chan *row, source *concatSource) error {
+	for i, value := range source.values {
+		if err := t.streamValueRows(ctx, rows, value); err != nil {
+			return fmt.Errorf("concatenated value %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package flat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+	}
+
+	tests := []struct {
+		name    string
+		data    func() *DynamicValue
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "array and JSON stream",
+			data: func() *DynamicValue {
+				return Concat(
+					ReadJSONFromReader(strings.NewReader(`[{"name": "John", "age": 30}, {"name": "Jane", "age": 25}]`)),
+					StreamJSONFromReader(strings.NewReader("{\"name\": \"Bob\", \"age\": 35}\n{\"name\": \"Alice\"}\n")),
+				)
+			},
+			want: "name,age\nJohn,30\nJane,25\nBob,35\nAlice,\n",
+		},
+		{
+			name: "empty source first",
+			data: func() *DynamicValue {
+				return Concat(
+					StreamJSONArrayFromReader(strings.NewReader(`[]`)),
+					newDynamicValue([]map[string]any{{"name": "John", "age": 30}}),
+					ReadCSVFromReader(strings.NewReader("name,age\nJane,25\n")),
+				)
+			},
+			want: "name,age\nJohn,30\nJane,25\n",
+		},
+		{
+			name: "nested concat",
+			data: func() *DynamicValue {
+				return Concat(
+					Concat(newDynamicValue([]any{map[string]any{"name": "John"}})),
+					newDynamicValue([]any{map[string]any{"name": "Jane"}}),
+				)
+			},
+			want: "name,age\nJohn,\nJane,\n",
+		},
+		{
+			name: "no sources",
+			data: func() *DynamicValue { return Concat() },
+			want: "",
+		},
+		{
+			name: "object and array",
+			data: func() *DynamicValue {
+				return Concat(newDynamicValue(map[string]any{"name": "John"}), newDynamicValue([]any{}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "source with error",
+			data: func() *DynamicValue {
+				return Concat(newDynamicValue([]any{}), ReadJSONFromReader(strings.NewReader(`[{`)))
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed stream",
+			data: func() *DynamicValue {
+				return Concat(
+					newDynamicValue([]any{map[string]any{"name": "John"}}),
+					StreamJSONArrayFromReader(strings.NewReader(`[{"name": "Jane"}`)),
+				)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tt.data().GetCSV(flattener).Export(&buf)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CSV.Export() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConcatColumns(t *testing.T) {
+	flattener := func(s Source, d Dest) {
+		for _, key := range s.Keys() {
+			d.Col(key, s.Key(key))
+		}
+	}
+
+	tests := []struct {
+		name    string
+		data    func() *DynamicValue
+		want    string
+		wantErr error
+	}{
+		{
+			name: "same keys",
+			data: func() *DynamicValue {
+				return Concat(
+					ReadJSONFromReader(strings.NewReader(`[{"name": "John", "age": 30}]`)),
+					StreamJSONFromReader(strings.NewReader(`{"age": 25, "name": "Jane"}`)),
+				)
+			},
+			want: "age,name\n30,John\n25,Jane\n",
+		},
+		{
+			name: "different keys",
+			data: func() *DynamicValue {
+				return Concat(
+					ReadJSONFromReader(strings.NewReader(`[{"name": "John", "age": 30}]`)),
+					StreamJSONFromReader(strings.NewReader(`{"name": "Jane", "age": 25, "city": "Austin"}`)),
+				)
+			},
+			wantErr: ErrConcatColumns,
+		},
+		{
+			name: "missing keys",
+			data: func() *DynamicValue {
+				return Concat(
+					newDynamicValue([]map[string]any{{"name": "John", "age": 30}}),
+					newDynamicValue([]map[string]any{{"name": "Jane"}}),
+				)
+			},
+			wantErr: ErrConcatColumns,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s/%d workers", tt.name, workers), func(t *testing.T) {
+				var buf bytes.Buffer
+				err := tt.data().GetCSV(flattener, WithWorkers(workers)).Export(&buf)
+
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CSV.Export() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantErr == nil && buf.String() != tt.want {
+					t.Errorf("CSV.Export() = %q, want %q", buf.String(), tt.want)
+				}
+			})
+		}
+	}
+}
@@ -1,21 +1,44 @@
 package flat
 
 import (
-	"encoding/csv"
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"slices"
+	"sync"
 )
 
-const bufferSize = 100
-
 // rootDataTypes defines the types of data that can be used as root data for CSV generation.
+// Null root data produces an empty export.
 var rootDataTypes = []DataType{
 	DataTypeObject,
 	DataTypeArray,
 	DataTypeArrayOfObjects,
 	DataTypeStreamOfObjects,
+	DataTypeNull,
+}
+
+// ErrUnsupportedRootType is returned when the root data type cannot be exported, see RootTypeError.
+var ErrUnsupportedRootType = errors.New("data type is not supported for CSV generation")
+
+// RootTypeError is returned when the root data type cannot be exported, i.e. a string or a number.
+// It matches ErrUnsupportedRootType using errors.Is.
+type RootTypeError struct {
+	// DataType is the type of the root data.
+	DataType DataType
+}
+
+// Error returns the error message including the root data type.
+func (e *RootTypeError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrUnsupportedRootType, e.DataType)
+}
+
+// Is reports whether target is ErrUnsupportedRootType.
+func (e *RootTypeError) Is(target error) bool {
+	return target == ErrUnsupportedRootType
 }
 
 // CSV represets data that can be exported to CSV format.
@@ -23,23 +46,30 @@ type CSV struct {
 	rootData  *DynamicValue
 	err       error
 	flattener flattener
+	options   *exportOptions
 }
 
 // newCsv creates a new CSV instance from the provided rootDynamicValue and flattener function.
 // It checks if the rootDynamicValue contains an error or if its data type is supported for CSV generation.
-// If the data type is not supported, it returns an error CSV instance.
-func newCsv(rootDynamicValue *DynamicValue, f flattener) *CSV {
+// If the data type is not supported, it returns an error CSV instance holding a RootTypeError.
+func newCsv(rootDynamicValue *DynamicValue, f flattener, opts ...ExportOption) *CSV {
 	if rootDynamicValue.Error() != nil {
 		return newErrorCsv(rootDynamicValue.Error())
 	}
 
 	if !slices.Contains(rootDataTypes, rootDynamicValue.dataType) {
-		return newErrorCsv(fmt.Errorf("data type is not supported for CSV generation"))
+		return newErrorCsv(&RootTypeError{DataType: rootDynamicValue.dataType})
+	}
+
+	options, err := newExportOptions(opts...)
+	if err != nil {
+		return newErrorCsv(fmt.Errorf("invalid export options: %w", err))
 	}
 
 	return &CSV{
 		rootData:  rootDynamicValue,
 		flattener: f,
+		options:   options,
 	}
 }
 
@@ -54,75 +84,228 @@ func newErrorCsv(err error) *CSV {
 // It writes the headers first, then the data rows.
 // If an error has occurred during the process, it returns an error.
 func (t *CSV) Export(w io.Writer) error {
-	return t.ExportSplit(NoSplit(w))
+	return t.ExportSplitContext(context.Background(), NoSplit(w))
+}
+
+// ExportContext writes the CSV data to the provided writer like Export.
+// If ctx is cancelled the export stops consuming the source data and returns the context error.
+func (t *CSV) ExportContext(ctx context.Context, w io.Writer) error {
+	return t.ExportSplitContext(ctx, NoSplit(w))
 }
 
 // ExportSplit writes the CSV data to multiple writers based on the provided Splits.
 // A Split contains a writer and an optional split function.
 // The split function is used to determine whether a row should be written to that writer.
 func (t *CSV) ExportSplit(splitters ...splitWriter) error {
+	return t.ExportSplitContext(context.Background(), splitters...)
+}
+
+// ExportSplitContext writes the CSV data to multiple writers like ExportSplit.
+// If ctx is cancelled the export stops consuming the source data and returns the context error.
+func (t *CSV) ExportSplitContext(ctx context.Context, splitters ...splitWriter) error {
+	_, err := t.exportSplit(ctx, splitters)
+	return err
+}
+
+// ExportSplitWithStats writes the CSV data to multiple writers like ExportSplit
+// and returns the number of rows processed, and the rows and bytes written to each writer.
+// The stats are returned even if the export fails, reporting the progress made before the error.
+func (t *CSV) ExportSplitWithStats(splitters ...splitWriter) (*ExportStats, error) {
+	return t.exportSplit(context.Background(), splitters)
+}
+
+// ExportSplitContextWithStats writes the CSV data to multiple writers like ExportSplitWithStats.
+// If ctx is cancelled the export stops consuming the source data and returns the context error.
+func (t *CSV) ExportSplitContextWithStats(ctx context.Context, splitters ...splitWriter) (*ExportStats, error) {
+	return t.exportSplit(ctx, splitters)
+}
+
+// ExportRecords returns the CSV records, headers first, instead of writing them to a writer.
+// The records are produced exactly as Export writes them, so it is useful to assert the output
+// structurally in tests or to build responses that are not written to an io.Writer.
+func (t *CSV) ExportRecords() ([][]string, error) {
+	return t.ExportRecordsContext(context.Background())
+}
+
+// ExportRecordsContext returns the CSV records like ExportRecords.
+// If ctx is cancelled the export stops consuming the source data and returns the context error.
+func (t *CSV) ExportRecordsContext(ctx context.Context) ([][]string, error) {
 	if t.err != nil {
-		return fmt.Errorf("cannot export CSV due to previous error: %w", t.err)
+		return nil, fmt.Errorf("cannot export CSV due to previous error: %w", t.err)
 	}
 
-	csvWriters := make([]*csv.Writer, len(splitters))
-	for i, s := range splitters {
-		csvWriters[i] = csv.NewWriter(s)
+	collector := &recordCollector{}
+	stats := newExportStats(1)
+	log := t.options.newExportLogger(ctx)
+	err := t.writeRecords(ctx, log, []splitWriter{NoSplit(io.Discard)}, []RowEncoder{collector}, stats)
+	log.done(stats, err)
+	if err != nil {
+		return nil, err
 	}
 
-	rows := make(chan *row, bufferSize)
-	go t.streamRows(rows)
+	return collector.records, nil
+}
 
-	var headers []string
-	for row := range rows {
-		if row.hasHeaders() {
-			headers = row.getHeaders()
+// exportSplit writes the CSV data to the splitters collecting the export stats.
+func (t *CSV) exportSplit(ctx context.Context, splitters []splitWriter) (*ExportStats, error) {
+	stats := newExportStats(len(splitters))
 
-			for _, csvWriter := range csvWriters {
-				if err := csvWriter.Write(headers); err != nil {
-					return fmt.Errorf("failed to write CSV headers: %w", err)
-				}
+	if t.err != nil {
+		return stats, fmt.Errorf("cannot export CSV due to previous error: %w", t.err)
+	}
+
+	var valueEncoders []*valueEncoder
+	encoders := make([]RowEncoder, len(splitters))
+	for i, s := range splitters {
+		if split, ok := s.(*valueSplitWriter); ok {
+			if len(t.options.aggregates) > 0 {
+				return stats, fmt.Errorf("cannot export CSV: aggregates are not supported with SplitByValue")
 			}
+
+			encoder := newValueEncoder(split, t.options.newEncoder, &stats.Writers[i].BytesWritten)
+			valueEncoders = append(valueEncoders, encoder)
+			encoders[i] = encoder
+			continue
 		}
 
-		for i, csvWriter := range csvWriters {
-			columnValues := make([]string, len(headers))
-			includeLine := true
-			for j, header := range headers {
-				if column, exists := row.columns[header]; exists {
-					// Check if the split function should include this line
-					shouldInclude, err := splitters[i].shouldInclude(header, column.data)
-					if err != nil {
-						return fmt.Errorf("error checking split condition for header %s: %w", header, err)
-					}
+		encoders[i] = t.options.newEncoder(countingWriter{w: s, count: &stats.Writers[i].BytesWritten})
+	}
 
-					if !shouldInclude {
-						includeLine = false
-						break // Skip writing this line for this writer
-					}
+	log := t.options.newExportLogger(ctx)
+	err := t.writeRecords(ctx, log, splitters, encoders, stats)
 
-					val, err := column.strVal()
-					if err != nil {
-						return fmt.Errorf("failed to get value for header %s: %w", header, err)
-					}
+	// The writers created by SplitByValue are closed even if the export fails
+	for _, encoder := range valueEncoders {
+		if closeErr := encoder.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
 
-					columnValues[j] = val
+	// The final entry is logged once the writers are closed, so it reports the close errors
+	log.done(stats, err)
+
+	return stats, err
+}
+
+// writeRecords writes the records of each splitter to the encoder at the same index,
+// collecting the export stats. Every export is produced by this function, regardless of the output.
+// It logs the start and the progress of the export to log, the caller logs the final entry once the
+// output is complete.
+func (t *CSV) writeRecords(ctx context.Context, log *exportLogger, splitters []splitWriter, encoders []RowEncoder, stats *ExportStats) error {
+	log.start(t.rootData, len(splitters))
+
+	// Cancelling on return stops the row producer when the export ends early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	source := make(chan *row, t.options.rowBuffer)
+	streamDone := make(chan struct{})
+	var streamErr error
+	go func() {
+		defer close(streamDone)
+		defer close(source)
+		streamErr = t.streamRows(ctx, source)
+	}()
+
+	var rows <-chan *row = source
+	if t.options.workers > 1 {
+		rows = t.flattenConcurrently(ctx, source)
+	}
+
+	aggregators := t.newAggregators(len(splitters))
+
+	var headers, columns []string
+	var dedupers []*deduper
+	headersWritten := false
+	buffers := newRowBuffers(len(splitters))
+	for row := range rows {
+		// Concatenated sources produce a row with headers for each source, only the first one is written
+		if row.hasHeaders() && headersWritten && !sameColumns(headers, row.getHeaders()) {
+			err := fmt.Errorf("%w: %q, want %q", ErrConcatColumns, row.getHeaders(), headers)
+			releaseRow(row)
+			return err
+		}
+		if row.hasHeaders() && !headersWritten {
+			headersWritten = true
+			headers = row.getHeaders()
+			columns = t.options.outputColumns(headers)
+			dedupers = t.newDedupers(len(splitters), columns)
+
+			// Null data only has headers if the flattener declares columns
+			if !row.headerOnly || len(columns) > 0 {
+				headerLine := t.options.headerLine(columns)
+				for _, encoder := range encoders {
+					if err := encoder.WriteHeader(headerLine); err != nil {
+						return fmt.Errorf("failed to write CSV headers: %w", err)
+					}
 				}
 			}
+		}
+		if row.headerOnly {
+			releaseRow(row)
+			continue
+		}
+
+		rowIndex := stats.RowsProcessed
+		stats.RowsProcessed++
+		log.progress(stats.RowsProcessed)
+
+		lines, err := t.rowLines(row, headers, columns, splitters, buffers)
+		var inputs map[string]aggregateInput
+		if err == nil {
+			inputs, err = t.aggregateInputs(row)
+		}
+		if err == nil {
+			err = t.routeRow(row, encoders)
+		}
+		if err != nil {
+			if err := t.handleRowError(stats, log, rowIndex, err); err != nil {
+				return err
+			}
+			releaseRow(row)
+			continue // Skip the failed row for all writers
+		}
 
-			if !includeLine {
+		for i, encoder := range encoders {
+			if lines[i] == nil {
+				stats.Writers[i].RowsSkipped++
 				continue // Skip writing this line for this writer
 			}
+			if dedupers != nil && dedupers[i].duplicate(encoderOutput(encoder), lines[i]) {
+				stats.Writers[i].RowsDeduped++
+				continue
+			}
+
+			if err := encoder.WriteRow(lines[i]); err != nil {
+				return &ExportError{Row: rowIndex, Writer: i, Err: fmt.Errorf("failed to write CSV data: %w", err)}
+			}
+			stats.Writers[i].RowsWritten++
 
-			if err := csvWriter.Write(columnValues); err != nil {
-				return fmt.Errorf("failed to write CSV data: %w", err)
+			for name, input := range inputs {
+				aggregators[i][name].add(input)
 			}
 		}
+
+		releaseRow(row)
+	}
+
+	// The concurrent workers stop on cancellation without draining the producer, wait for it to return
+	<-streamDone
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if streamErr != nil {
+		return fmt.Errorf("failed to read CSV rows: %w", streamErr)
+	}
+
+	if aggregators != nil && headers != nil {
+		if err := t.writeFooters(encoders, aggregators, columns); err != nil {
+			return err
+		}
 	}
 
-	for _, csvWriter := range csvWriters {
-		csvWriter.Flush()
-		if err := csvWriter.Error(); err != nil {
+	for _, encoder := range encoders {
+		if err := encoder.Flush(); err != nil {
 			return fmt.Errorf("failed to flush CSV writer: %w", err)
 		}
 	}
@@ -130,6 +313,193 @@ func (t *CSV) ExportSplit(splitters ...splitWriter) error {
 	return nil
 }
 
+// routeRow sets the writer of each SplitByValue the row is written to.
+func (t *CSV) routeRow(r *row, encoders []RowEncoder) error {
+	for _, encoder := range encoders {
+		if valueEncoder, ok := encoder.(*valueEncoder); ok {
+			if err := valueEncoder.route(r, t.options); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rowLines returns the line of cells for each splitter, or nil if the splitter excludes the row.
+// Split conditions are checked on the headers produced by the flattener, while the line contains the
+// cells of the output columns. Values are converted once per row and shared across splitters.
+// It returns an error if the row cannot be read or a value or split condition fails.
+// The returned lines are only valid until the next row.
+func (t *CSV) rowLines(r *row, headers, columns []string, splitters []splitWriter, buffers *rowBuffers) ([][]Cell, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var values []Cell
+	lines := buffers.lines
+	clear(lines)
+	splitValues := t.options.rowSplitValues(r, headers, buffers.splitValues)
+
+	for i, splitter := range splitters {
+		include, err := splitter.shouldIncludeRow(splitValues)
+		if err != nil {
+			return nil, withWriter(i, err)
+		}
+
+		if !include {
+			continue // Skip writing this line for this writer
+		}
+
+		if values == nil {
+			if values, err = t.rowValues(r, columns, buffers); err != nil {
+				return nil, err
+			}
+		}
+
+		lines[i] = values
+	}
+
+	return lines, nil
+}
+
+// rowBuffers holds the buffers reused across the rows of an export to reduce allocations.
+type rowBuffers struct {
+	lines       [][]Cell
+	values      []string
+	splitValues map[string]*DynamicValue
+}
+
+// newRowBuffers creates the buffers of an export to the given number of splitters.
+func newRowBuffers(splitters int) *rowBuffers {
+	return &rowBuffers{
+		lines:       make([][]Cell, splitters),
+		splitValues: make(map[string]*DynamicValue),
+	}
+}
+
+// stringValues returns the values buffer resized to n.
+func (b *rowBuffers) stringValues(n int) []string {
+	if cap(b.values) < n {
+		b.values = make([]string, n)
+	}
+	b.values = b.values[:n]
+	return b.values
+}
+
+// rowSplitValues fills values with the values of the row columns that split conditions are checked on,
+// by header, and returns it. Booleans are replaced by their labels with WithBoolStrings.
+func (o *exportOptions) rowSplitValues(r *row, headers []string, values map[string]*DynamicValue) map[string]*DynamicValue {
+	clear(values)
+	for _, header := range headers {
+		if column, exists := r.columns[header]; exists {
+			values[header] = o.splitValue(column.data)
+		}
+	}
+	return values
+}
+
+// rowValues returns the cells of the row for the given columns.
+// Columns not produced by the flattener are left empty unless they have a default value or a placeholder.
+// It returns an error if a required column is empty.
+func (t *CSV) rowValues(r *row, columns []string, buffers *rowBuffers) ([]Cell, error) {
+	values := buffers.stringValues(len(columns))
+	for j, name := range columns {
+		val, err := t.cellValue(r, name)
+		if err != nil {
+			return nil, err
+		}
+		values[j] = val
+	}
+
+	for _, name := range t.options.requiredColumns {
+		val := ""
+		if j := slices.Index(columns, name); j >= 0 {
+			val = values[j]
+		} else {
+			var err error
+			if val, err = t.cellValue(r, name); err != nil {
+				return nil, err
+			}
+		}
+
+		if val == "" {
+			return nil, withColumn(name, ErrMissingRequiredValue)
+		}
+	}
+
+	cells := make([]Cell, len(columns))
+	for j, name := range columns {
+		cells[j] = Cell{Value: t.renderCell(r, name, values[j]), Data: dynamicValueMissing}
+		if column, exists := r.columns[name]; exists {
+			cells[j].Data = column.data
+		}
+	}
+
+	return cells, nil
+}
+
+// cellValue returns the string value of a column of the row, using the column default value when it is empty.
+func (t *CSV) cellValue(r *row, name string) (string, error) {
+	val := ""
+	if column, exists := r.columns[name]; exists {
+		var err error
+		if val, err = column.strValWithOptions(t.options); err != nil {
+			return "", withColumn(name, fmt.Errorf("failed to get value: %w", err))
+		}
+	}
+
+	if val == "" {
+		val = t.options.defaults[name]
+	}
+
+	return val, nil
+}
+
+// renderCell returns the cell to write for the value of a column of the row.
+// Empty null or missing values are replaced by their placeholder, which is written as is.
+// Newline replacement and control character stripping are applied to other values, followed by
+// formula escaping unless the value comes from a numeric value.
+func (t *CSV) renderCell(r *row, name, val string) string {
+	column, exists := r.columns[name]
+
+	if val == "" {
+		missing := !exists || column.data.missing
+		if missing && t.options.missingPlaceholder != nil {
+			return *t.options.missingPlaceholder
+		}
+		if missing || column.data.IsNull() {
+			return t.options.nullPlaceholder
+		}
+		return val
+	}
+
+	val = t.options.sanitizeCell(val)
+
+	if t.options.escapeFormulas && !(exists && column.data.isNumeric()) {
+		val = escapeFormula(val)
+	}
+
+	return val
+}
+
+// handleRowError records a failed row in the stats.
+// It returns an ExportError to abort the export when the CSV is fail-fast or the maximum of row errors is exceeded.
+func (t *CSV) handleRowError(stats *ExportStats, log *exportLogger, rowIndex int, err error) error {
+	rowErr := newExportError(rowIndex, err)
+	if !t.options.continueOnRowError {
+		return rowErr
+	}
+
+	stats.RowErrors = append(stats.RowErrors, rowErr)
+	log.skipped(rowErr)
+
+	if t.options.maxRowErrors >= 0 && len(stats.RowErrors) > t.options.maxRowErrors {
+		return fmt.Errorf("too many row errors (%d): %w", len(stats.RowErrors), stats.RowErrors[len(stats.RowErrors)-1])
+	}
+
+	return nil
+}
+
 // Dest is an interface for writing data to a CSV.
 // Add more detailed documentation for interfaces
 type Dest interface {
@@ -145,6 +515,14 @@ type Dest interface {
 	//   value: The source value to add
 	//   formatter: A function to format the value before adding
 	ColFormatted(name string, value Source, formatter Formatter)
+
+	// ColIf adds a column to the CSV only when cond is true.
+	// When cond is false the header is still registered but the cell is left empty for this row.
+	// Parameters:
+	//   name: The column header name
+	//   cond: Whether the value should be added
+	//   value: The source value to add
+	ColIf(name string, cond bool, value Source)
 }
 
 // Source represents a source of data for CSV generation.
@@ -162,6 +540,7 @@ func FixValue[T any](value T) Source {
 }
 
 // Idx retrieves an element from the Source instance that holds an array or an array of objects.
+// Negative indexes count from the end of the array, so -1 returns the last element.
 // If the index is out of bounds or the data type is not an array, it returns a new Source with NullData.
 // If the data is not an array, it returns a new Source with NullData.
 // If the index is valid, it returns a new Source with the data at that index.
@@ -171,6 +550,26 @@ func (s Source) Idx(index int) Source {
 	}
 }
 
+// IdxStrict retrieves an element like Idx, but returns a Source holding an error
+// when the data is not an array or the index is out of bounds.
+func (s Source) IdxStrict(index int) Source {
+	return Source{
+		data: s.data.IdxStrict(index),
+	}
+}
+
+// Len returns the number of elements of an array or the number of keys of an object.
+// It returns -1 for any other data type.
+func (s Source) Len() int {
+	return s.data.Len()
+}
+
+// Keys returns the sorted keys of an object.
+// It returns nil if the data is not an object.
+func (s Source) Keys() []string {
+	return s.data.Keys()
+}
+
 // Key retrieves a value from the Source instance using a sequence of keys.
 // If no keys are provided, it returns a new Source with NullData.
 // If the data is not an object or the key does not exist, it returns a new Source with NullData.
@@ -181,6 +580,66 @@ func (s Source) Key(keys ...string) Source {
 	}
 }
 
+// Path retrieves a value from the Source instance traversing objects and arrays in a single call.
+// String parts are used as object keys and int parts as array indexes.
+// If any step fails, it returns a new Source with NullData.
+func (s Source) Path(parts ...any) Source {
+	return Source{
+		data: s.data.Path(parts...),
+	}
+}
+
+// PathStrict retrieves a value like Path, but returns a Source holding an error when any step fails.
+func (s Source) PathStrict(parts ...any) Source {
+	return Source{
+		data: s.data.PathStrict(parts...),
+	}
+}
+
+// PathString retrieves a value from the Source instance using a dot separated path like "orders.0.total".
+// Dots that are part of a key must be escaped with a backslash.
+func (s Source) PathString(path string) Source {
+	return Source{
+		data: s.data.PathString(path),
+	}
+}
+
+// Set returns a new Source with the key of the object set to value, the original Source is not modified.
+// If the data is not an object, it returns a new Source holding an error.
+func (s Source) Set(key string, value any) Source {
+	return Source{
+		data: s.data.Set(key, value),
+	}
+}
+
+// Delete returns a new Source with the key removed from the object, the original Source is not modified.
+// If the data is not an object, it returns a new Source holding an error.
+func (s Source) Delete(key string) Source {
+	return Source{
+		data: s.data.Delete(key),
+	}
+}
+
+// ParseTime returns a new Source with the value converted into a time.
+// Strings are parsed using the provided layouts (time.RFC3339 by default) and numbers are read as Unix epochs
+// in seconds or milliseconds. If the value cannot be converted, it returns a new Source holding an error.
+func (s Source) ParseTime(layouts ...string) Source {
+	return Source{
+		data: s.data.ParseTime(layouts...),
+	}
+}
+
+// Exists reports whether the value was found in the data.
+// It returns false for missing keys and out of range indexes, and true for explicit JSON nulls.
+func (s Source) Exists() bool {
+	return s.data.Exists()
+}
+
+// IsNull reports whether the value is null, either because it is an explicit JSON null or because it is missing.
+func (s Source) IsNull() bool {
+	return s.data.IsNull()
+}
+
 // format applies a formatting function to the data in the Source instance.
 // The formatter function is used to transform the data before it is written to the CSV.
 // If multiple formatters are applied, the last one will take precedence.
@@ -206,6 +665,12 @@ func (s Source) strVal() (string, error) {
 	return s.data.strVal()
 }
 
+// strValWithOptions retrieves the string representation of the data in the Source instance
+// using the provided export options.
+func (s Source) strValWithOptions(opts *exportOptions) (string, error) {
+	return s.data.strValWithOptions(opts)
+}
+
 // flattener is a function type that takes a Source and a Dest as arguments.
 // It is used to map data from the Source to the Dest during CSV generation.
 type flattener func(s Source, b Dest)
@@ -216,16 +681,29 @@ type flattener func(s Source, b Dest)
 type row struct {
 	columns     map[string]Source
 	headers     []string
+	headerSet   map[string]struct{} // lookup of the headers once they exceed headerSetThreshold
 	withHeaders bool
+	err         error
+	item        *DynamicValue
+	seq         int
+	headerOnly  bool // the row only declares the headers and is not written
+}
+
+// headerSetThreshold is the number of headers above which a row looks them up in a map instead of the slice.
+const headerSetThreshold = 16
+
+// rowPool reuses the rows, and their columns map, once they are written.
+var rowPool = sync.Pool{
+	New: func() any {
+		return &row{columns: make(map[string]Source)}
+	},
 }
 
 // newRow creates a new row instance.
 // If withHeaders is true, it initializes the headers slice to track column names.
 func newRow(withHeaders bool) *row {
-	r := &row{
-		columns:     make(map[string]Source),
-		withHeaders: withHeaders,
-	}
+	r := rowPool.Get().(*row)
+	r.withHeaders = withHeaders
 
 	if withHeaders {
 		r.headers = make([]string, 0)
@@ -234,6 +712,18 @@ func newRow(withHeaders bool) *row {
 	return r
 }
 
+// releaseRow resets the row and puts it back in the pool, the row must not be used afterwards.
+// The headers are not reused as the export keeps the headers of the first row.
+func releaseRow(r *row) {
+	if r.columns == nil {
+		return // Error rows are not created by newRow
+	}
+
+	clear(r.columns)
+	*r = row{columns: r.columns}
+	rowPool.Put(r)
+}
+
 // Col adds a column to the row with the specified name and value.
 func (r *row) Col(name string, value Source) {
 	r.ColFormatted(name, value, nil)
@@ -241,11 +731,7 @@ func (r *row) Col(name string, value Source) {
 
 // Col adds a column to the row with the specified name and value.
 func (r *row) ColFormatted(name string, value Source, formatter Formatter) {
-	if r.withHeaders {
-		if !slices.Contains(r.headers, name) {
-			r.headers = append(r.headers, name)
-		}
-	}
+	r.addHeader(name)
 
 	if formatter != nil {
 		value = value.format(formatter)
@@ -254,6 +740,53 @@ func (r *row) ColFormatted(name string, value Source, formatter Formatter) {
 	r.columns[name] = value
 }
 
+// ColIf adds a column to the row only when cond is true.
+// The header is always registered so that rows omitting the value do not change the CSV layout.
+func (r *row) ColIf(name string, cond bool, value Source) {
+	if cond {
+		r.Col(name, value)
+		return
+	}
+
+	r.addHeader(name)
+}
+
+// addHeader registers the column name in the headers if the row tracks them.
+func (r *row) addHeader(name string) {
+	if !r.withHeaders {
+		return
+	}
+
+	if r.headerSet != nil {
+		if _, exists := r.headerSet[name]; exists {
+			return
+		}
+		r.headerSet[name] = struct{}{}
+	} else if slices.Contains(r.headers, name) {
+		return
+	}
+
+	r.headers = append(r.headers, name)
+
+	if r.headerSet == nil && len(r.headers) > headerSetThreshold {
+		r.headerSet = make(map[string]struct{}, len(r.headers)*2)
+		for _, header := range r.headers {
+			r.headerSet[header] = struct{}{}
+		}
+	}
+}
+
+// flattenRow applies the flattener to the item of the row and normalizes the values of its columns.
+func (t *CSV) flattenRow(r *row) {
+	if r.item == nil {
+		return
+	}
+
+	t.flattener(Source{data: r.item}, r)
+	r.item = nil
+	t.normalizeRow(r)
+}
+
 // hasHeaders checks if the row has headers.
 func (r *row) hasHeaders() bool {
 	return r.withHeaders
@@ -268,50 +801,185 @@ func (r *row) getHeaders() []string {
 }
 
 // streamRows streams the rows from the rootData based on its data type.
-func (t *CSV) streamRows(rows chan *row) {
-	switch t.rootData.DataType() {
+// It stops and returns the context error when ctx is cancelled.
+func (t *CSV) streamRows(ctx context.Context, rows chan *row) error {
+	return t.streamValueRows(ctx, rows, t.rootData)
+}
+
+// streamValueRows streams the rows of a root data value based on its data type.
+func (t *CSV) streamValueRows(ctx context.Context, rows chan *row, value *DynamicValue) error {
+	switch value.DataType() {
 	case DataTypeObject:
-		s := Source{data: t.rootData}
-		d := newRow(true)
-		t.flattener(s, d)
-		rows <- d
+		return t.sendRow(ctx, rows, value, true)
+	case DataTypeNull:
+		return t.sendHeaderRow(ctx, rows, value)
 	case DataTypeArray:
-		arr := t.rootData.value.([]any)
+		arr := value.value.([]any)
 		for i, item := range arr {
-			s := Source{data: newDynamicValue(item)}
-			d := newRow(i == 0) // Only write headers for the first item
-			t.flattener(s, d)
-			rows <- d
+			// Only write headers for the first item
+			if err := t.sendRow(ctx, rows, newDynamicValue(item), i == 0); err != nil {
+				return err
+			}
 		}
 	case DataTypeArrayOfObjects:
-		arr := t.rootData.value.([]map[string]any)
+		if arr, ok := value.value.(*structSlice); ok {
+			for i := 0; i < arr.len(); i++ {
+				// Only write headers for the first item
+				if err := t.sendRow(ctx, rows, value.Idx(i), i == 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		arr := value.value.([]map[string]any)
 		for i, item := range arr {
-			s := Source{data: newDynamicValue(item)}
-			d := newRow(i == 0) // Only write headers for the first item
-			t.flattener(s, d)
-			rows <- d
+			// Only write headers for the first item
+			if err := t.sendRow(ctx, rows, newDynamicValue(item), i == 0); err != nil {
+				return err
+			}
 		}
 	case DataTypeStreamOfObjects:
-		reader := t.rootData.value.(io.Reader)
-		decoder := json.NewDecoder(reader)
-
-		withHeaders := true
-		for {
-			var item map[string]any
-			if err := decoder.Decode(&item); err == io.EOF {
-				break // End of stream
-			} else if err != nil {
-				t.err = fmt.Errorf("error decoding JSON stream: %w", err)
-				return
+		switch stream := value.value.(type) {
+		case <-chan map[string]any:
+			return t.streamChannelRows(ctx, rows, stream)
+		case *csvSource:
+			return t.streamCSVRows(ctx, rows, stream)
+		case *jsonArraySource:
+			return t.streamJSONArrayRows(ctx, rows, stream)
+		case *concatSource:
+			return t.streamConcatRows(ctx, rows, stream)
+		case io.Reader:
+			return t.streamReaderRows(ctx, rows, stream)
+		}
+	}
+
+	return nil
+}
+
+// streamReaderRows streams the rows from a reader containing a stream of JSON objects.
+// When the CSV continues on row errors, objects that cannot be decoded are sent as failed rows
+// and malformed JSON is skipped up to the next line.
+func (t *CSV) streamReaderRows(ctx context.Context, rows chan *row, reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	withHeaders := true
+	for {
+		var item map[string]any
+		if err := decoder.Decode(&item); err == io.EOF {
+			return nil // End of stream
+		} else if err != nil {
+			err = fmt.Errorf("error decoding JSON stream: %w", err)
+			if !t.options.continueOnRowError {
+				return err
+			}
+
+			if err := t.sendErrorRow(ctx, rows, err); err != nil {
+				return err
+			}
+
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				// The decoder cannot recover from a syntax error, continue after the malformed line
+				reader = skipLine(io.MultiReader(decoder.Buffered(), reader))
+				decoder = json.NewDecoder(reader)
+				decoder.UseNumber()
+			} else if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil // Truncated last object
+			}
+			continue
+		}
+
+		if err := t.sendRow(ctx, rows, newDynamicValue(item), withHeaders); err != nil {
+			return err
+		}
+
+		withHeaders = false // Only write headers for the first item
+	}
+}
+
+// skipLine returns a reader positioned after the line of the next non whitespace character of r.
+// Reaching the end of the stream is not an error here, it is detected by the next decode.
+func skipLine(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return br
+		}
+
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			_, _ = br.ReadBytes('\n')
+			return br
+		}
+	}
+}
+
+// streamChannelRows streams the rows from a channel of objects until it is closed or ctx is cancelled.
+func (t *CSV) streamChannelRows(ctx context.Context, rows chan *row, ch <-chan map[string]any) error {
+	withHeaders := true
+	for {
+		// Check cancellation first so a ready channel never wins over a cancelled context
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item, ok := <-ch:
+			if !ok {
+				return nil // Channel closed
+			}
+
+			if err := t.sendRow(ctx, rows, newDynamicValue(item), withHeaders); err != nil {
+				return err
 			}
-			s := Source{data: newDynamicValue(item)}
-			d := newRow(withHeaders)
-			t.flattener(s, d)
-			rows <- d
 
 			withHeaders = false // Only write headers for the first item
 		}
 	}
+}
+
+// sendErrorRow sends a row that failed to be read to the rows channel.
+func (t *CSV) sendErrorRow(ctx context.Context, rows chan *row, err error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case rows <- &row{err: err}:
+		return nil
+	}
+}
+
+// sendRow flattens the item into a new row and sends it to the rows channel.
+// When the export uses workers the row is sent without flattening, the workers flatten it.
+// It returns the context error if ctx is cancelled before the row is sent.
+func (t *CSV) sendRow(ctx context.Context, rows chan *row, item *DynamicValue, withHeaders bool) error {
+	d := newRow(withHeaders)
+	d.item = item
+	return t.send(ctx, rows, d)
+}
+
+// sendHeaderRow sends a row only declaring the headers the flattener sets for the item.
+// It is used for null root data, which has no rows.
+func (t *CSV) sendHeaderRow(ctx context.Context, rows chan *row, item *DynamicValue) error {
+	d := newRow(true)
+	d.item = item
+	d.headerOnly = true
+	return t.send(ctx, rows, d)
+}
+
+// send flattens the row unless the export uses workers and sends it to the rows channel.
+func (t *CSV) send(ctx context.Context, rows chan *row, d *row) error {
+	if t.options.workers <= 1 {
+		t.flattenRow(d)
+	}
 
-	close(rows)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case rows <- d:
+		return nil
+	}
 }
@@ -0,0 +1,157 @@
+package flat
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVReadOption is a function that customizes how a CSV input is read.
+type CSVReadOption func(*csvReadOptions)
+
+// csvReadOptions holds the settings applied while reading a CSV input.
+type csvReadOptions struct {
+	delimiter       rune
+	inferTypes      bool
+	allowRaggedRows bool
+}
+
+// WithCSVDelimiter sets the field delimiter of the CSV input. The default delimiter is a comma.
+func WithCSVDelimiter(delimiter rune) CSVReadOption {
+	return func(o *csvReadOptions) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithCSVTypeInference converts "true" and "false" values to booleans and numeric values to numbers.
+// Without this option all values are read as strings.
+func WithCSVTypeInference() CSVReadOption {
+	return func(o *csvReadOptions) {
+		o.inferTypes = true
+	}
+}
+
+// WithCSVRaggedRows allows records with a different number of fields than the header.
+// Missing fields are read as empty strings and extra fields are dropped.
+// Without this option a ragged record makes the export fail.
+func WithCSVRaggedRows() CSVReadOption {
+	return func(o *csvReadOptions) {
+		o.allowRaggedRows = true
+	}
+}
+
+// csvSource holds a CSV input whose records are streamed as objects keyed by the header.
+type csvSource struct {
+	reader  *csv.Reader
+	headers []string
+	options *csvReadOptions
+}
+
+// ReadCSVFromReader creates a new DynamicValue instance from a io.Reader containing CSV data.
+// The first record is used as header and the following records are streamed as objects keyed by header,
+// so the DynamicValue is a stream of objects that is read while exporting.
+// If the header cannot be read, it returns a DynamicValue holding the error.
+func ReadCSVFromReader(r io.Reader, opts ...CSVReadOption) *DynamicValue {
+	options := &csvReadOptions{delimiter: ','}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = options.delimiter
+	reader.ReuseRecord = true
+	if options.allowRaggedRows {
+		reader.FieldsPerRecord = -1
+	}
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return errorDynamicValue(fmt.Errorf("failed to read CSV header: empty input"))
+	} else if err != nil {
+		return errorDynamicValue(fmt.Errorf("failed to read CSV header: %w", err))
+	}
+
+	return newDynamicValue(&csvSource{
+		reader:  reader,
+		headers: append([]string{}, headers...),
+		options: options,
+	})
+}
+
+// next reads the next record as an object. It returns io.EOF at the end of the input.
+func (c *csvSource) next() (map[string]any, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	item := make(map[string]any, len(c.headers))
+	for i, header := range c.headers {
+		value := ""
+		if i < len(record) {
+			value = record[i]
+		}
+
+		if c.options.inferTypes {
+			item[header] = inferCSVValue(value)
+		} else {
+			item[header] = value
+		}
+	}
+
+	return item, nil
+}
+
+// inferCSVValue converts boolean and numeric CSV values to their typed representation.
+func inferCSVValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "":
+		return value
+	}
+
+	first := value[0]
+	isNumberStart := first == '-' || (first >= '0' && first <= '9')
+	if isNumberStart && strings.TrimSpace(value) == value && json.Valid([]byte(value)) {
+		return json.Number(value)
+	}
+
+	return value
+}
+
+// streamCSVRows streams the records of a CSV input as rows.
+// When the CSV continues on row errors, records that cannot be parsed are sent as failed rows.
+func (t *CSV) streamCSVRows(ctx context.Context, rows chan *row, source *csvSource) error {
+	withHeaders := true
+	for {
+		item, err := source.next()
+		if err == io.EOF {
+			return nil // End of input
+		} else if err != nil {
+			err = fmt.Errorf("error reading CSV input: %w", err)
+			if !t.options.continueOnRowError {
+				return err
+			}
+
+			// The CSV reader continues with the next record after a parse error
+			if err := t.sendErrorRow(ctx, rows, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := t.sendRow(ctx, rows, newDynamicValue(item), withHeaders); err != nil {
+			return err
+		}
+
+		withHeaders = false // Only write headers for the first item
+	}
+}
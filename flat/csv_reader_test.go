@@ -0,0 +1,147 @@
+package flat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestReadCSVRoundTrip tests that a CSV read into a DynamicValue can be exported back unchanged
+func TestReadCSVRoundTrip(t *testing.T) {
+	input := "id,name,notes,price\n" +
+		"1,John,\"likes \"\"quotes\"\", commas\",0.0000001\n" +
+		"2,Jane,\"multi\nline\",12345678.9\n" +
+		"3,Bob,,007\n"
+
+	for _, opts := range [][]CSVReadOption{nil, {WithCSVTypeInference()}} {
+		data := ReadCSVFromReader(strings.NewReader(input), opts...)
+		if data.DataType() != DataTypeStreamOfObjects {
+			t.Fatalf("ReadCSVFromReader() type = %v, want %v", data.DataType(), DataTypeStreamOfObjects)
+		}
+
+		var buf bytes.Buffer
+		err := data.GetCSV(func(s Source, d Dest) {
+			for _, header := range []string{"id", "name", "notes", "price"} {
+				d.Col(header, s.Key(header))
+			}
+		}).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+
+		if got := buf.String(); got != input {
+			t.Errorf("CSV.Export() = %q, want %q", got, input)
+		}
+	}
+}
+
+func TestReadCSVFromReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    []CSVReadOption
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "re-project columns",
+			input: "a,b,c\n1,2,3\n4,5,6\n",
+			want:  "c,first\n3,1\n6,4\n",
+		},
+		{
+			name:  "custom delimiter",
+			input: "a;b;c\n1;\"2;x\";3\n",
+			opts:  []CSVReadOption{WithCSVDelimiter(';')},
+			want:  "c,first\n3,1\n",
+		},
+		{
+			name:    "ragged rows fail by default",
+			input:   "a,b,c\n1,2\n",
+			wantErr: true,
+		},
+		{
+			name:  "ragged rows padded",
+			input: "a,b,c\n1,2\n4,5,6,7\n",
+			opts:  []CSVReadOption{WithCSVRaggedRows()},
+			want:  "c,first\n,1\n6,4\n",
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:    "malformed quotes",
+			input:   "a,b,c\n\"1,2,3\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := ReadCSVFromReader(strings.NewReader(tt.input), tt.opts...).GetCSV(func(s Source, d Dest) {
+				d.Col("c", s.Key("c"))
+				d.Col("first", s.Key("a"))
+			}).Export(&buf)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CSV.Export() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVTypeInference(t *testing.T) {
+	input := "flag,count,price,code,text\ntrue,42,-1.5e3,007,hello\n"
+
+	tests := []struct {
+		name   string
+		opts   []CSVReadOption
+		header string
+		want   DataType
+	}{
+		{name: "strings by default", header: "count", want: DataTypeString},
+		{name: "boolean", opts: []CSVReadOption{WithCSVTypeInference()}, header: "flag", want: DataTypeBoolean},
+		{name: "integer", opts: []CSVReadOption{WithCSVTypeInference()}, header: "count", want: DataTypeNumber},
+		{name: "float", opts: []CSVReadOption{WithCSVTypeInference()}, header: "price", want: DataTypeNumber},
+		{name: "leading zeros stay string", opts: []CSVReadOption{WithCSVTypeInference()}, header: "code", want: DataTypeString},
+		{name: "text", opts: []CSVReadOption{WithCSVTypeInference()}, header: "text", want: DataTypeString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DataType
+			err := ReadCSVFromReader(strings.NewReader(input), tt.opts...).GetCSV(func(s Source, d Dest) {
+				got = s.Key(tt.header).data.DataType()
+				d.Col(tt.header, s.Key(tt.header))
+			}).Export(&bytes.Buffer{})
+			if err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("inferred type = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("inferred numbers work with splitters", func(t *testing.T) {
+		var high, low bytes.Buffer
+		err := ReadCSVFromReader(strings.NewReader("id,age\n1,30\n2,25\n"), WithCSVTypeInference()).GetCSV(func(s Source, d Dest) {
+			d.Col("id", s.Key("id"))
+			d.Col("age", s.Key("age"))
+		}).ExportSplit(
+			Split(&high, "age", func(v int) bool { return v >= 30 }),
+			Split(&low, "age", func(v int) bool { return v < 30 }),
+		)
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+		if high.String() != "id,age\n1,30\n" || low.String() != "id,age\n2,25\n" {
+			t.Errorf("CSV.ExportSplit() = %q, %q", high.String(), low.String())
+		}
+	})
+}
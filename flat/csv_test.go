@@ -2,9 +2,18 @@ package flat
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/flat/flattest"
 )
 
 // TestCSVExport tests the CSV export functionality
@@ -280,3 +289,631 @@ func TestCSVRow(t *testing.T) {
 		}
 	}
 }
+
+// TestCSVExportLargeIntegers tests that integers above 2^53 keep their exact digits
+func TestCSVExportLargeIntegers(t *testing.T) {
+	input := `[{"id": 9007199254740993, "price": 0.1}, {"id": 12345678901234567890, "price": 2}]`
+
+	tests := []struct {
+		name string
+		data func() *DynamicValue
+	}{
+		{
+			name: "json array",
+			data: func() *DynamicValue {
+				return ReadJSONFromReader(strings.NewReader(input))
+			},
+		},
+		{
+			name: "json stream",
+			data: func() *DynamicValue {
+				return StreamJSONFromReader(strings.NewReader(
+					`{"id": 9007199254740993, "price": 0.1}` + "\n" + `{"id": 12345678901234567890, "price": 2}`,
+				))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tt.data().GetCSV(func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+				d.Col("price", s.Key("price"))
+			}).Export(&buf)
+			if err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+
+			want := "id,price\n9007199254740993,0.1\n12345678901234567890,2\n"
+			if got := buf.String(); got != want {
+				t.Errorf("CSV.Export() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestCSVColIf tests conditional columns and missing vs null introspection in a flattener
+func TestCSVColIf(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"name": "John", "email": ""},
+		{"name": "Jane", "email": null},
+		{"name": "Bob"}
+	]`))
+
+	var buf bytes.Buffer
+	err := data.GetCSV(func(s Source, d Dest) {
+		email := s.Key("email")
+		d.Col("name", s.Key("name"))
+		d.ColIf("email", !email.IsNull(), email)
+		d.Col("email_status", FixValue(emailStatus(email)))
+	}).Export(&buf)
+	if err != nil {
+		t.Fatalf("CSV.Export() unexpected error = %v", err)
+	}
+
+	want := "name,email,email_status\nJohn,,empty\nJane,,null\nBob,,missing\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV.Export() = %q, want %q", got, want)
+	}
+
+	t.Run("header kept when first row omits the value", func(t *testing.T) {
+		var buf bytes.Buffer
+		data := newDynamicValue([]map[string]any{{"name": "John"}, {"name": "Jane", "age": float64(25)}})
+		err := data.GetCSV(func(s Source, d Dest) {
+			d.Col("name", s.Key("name"))
+			d.ColIf("age", s.Key("age").Exists(), s.Key("age"))
+		}).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+
+		want := "name,age\nJohn,\nJane,25\n"
+		if got := buf.String(); got != want {
+			t.Errorf("CSV.Export() = %q, want %q", got, want)
+		}
+	})
+}
+
+// emailStatus describes how a value is present in the source data
+func emailStatus(s Source) string {
+	switch {
+	case !s.Exists():
+		return "missing"
+	case s.IsNull():
+		return "null"
+	default:
+		return "empty"
+	}
+}
+
+// TestCSVLenKeys tests last element access and key iteration in a flattener
+func TestCSVLenKeys(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"name": "John", "orders": [10, 20, 30], "attrs": {"vip": true, "city": "NYC"}},
+		{"name": "Jane", "orders": [], "attrs": {}}
+	]`))
+
+	var buf bytes.Buffer
+	err := data.GetCSV(func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("order_count", FixValue(s.Key("orders").Len()))
+		d.Col("last_order", s.Key("orders").Idx(-1))
+
+		attrs := s.Key("attrs")
+		for _, key := range []string{"city", "vip"} {
+			d.Col(key, attrs.Key(key))
+		}
+		d.Col("attr_keys", FixValue(strings.Join(attrs.Keys(), "|")))
+	}).Export(&buf)
+	if err != nil {
+		t.Fatalf("CSV.Export() unexpected error = %v", err)
+	}
+
+	want := "name,order_count,last_order,city,vip,attr_keys\nJohn,3,30,NYC,true,city|vip\nJane,0,,,,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV.Export() = %q, want %q", got, want)
+	}
+
+	t.Run("strict index surfaces out of range errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := data.GetCSV(func(s Source, d Dest) {
+			d.Col("last_order", s.Key("orders").IdxStrict(-1))
+		}).Export(&buf)
+		if err == nil {
+			t.Error("CSV.Export() expected error for out of range strict index, got nil")
+		}
+	})
+}
+
+// TestCSVStreamFromChannel tests exporting objects produced through a channel
+func TestCSVStreamFromChannel(t *testing.T) {
+	const total = 1000
+
+	ch := make(chan map[string]any)
+	go func() {
+		defer close(ch)
+		for i := 0; i < total; i++ {
+			ch <- map[string]any{"id": i, "name": fmt.Sprintf("row-%d", i)}
+		}
+	}()
+
+	var buf bytes.Buffer
+	err := StreamFromChannel(ch).GetCSV(func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("name", s.Key("name"))
+	}).Export(&buf)
+	if err != nil {
+		t.Fatalf("CSV.Export() unexpected error = %v", err)
+	}
+
+	var want strings.Builder
+	want.WriteString("id,name\n")
+	for i := 0; i < total; i++ {
+		fmt.Fprintf(&want, "%d,row-%d\n", i, i)
+	}
+
+	if got := buf.String(); got != want.String() {
+		t.Errorf("CSV.Export() produced %d bytes, want %d bytes of ordered rows", len(got), want.Len())
+	}
+}
+
+// TestCSVExportContextCancel tests that cancelling the export stops consuming the source
+func TestCSVExportContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan map[string]any)
+	consumed := make(chan int, 1)
+	go func() {
+		sent := 0
+		defer func() { consumed <- sent }()
+		for i := 0; ; i++ {
+			if i == 10 {
+				cancel()
+			}
+			select {
+			case ch <- map[string]any{"id": i}:
+				sent++
+			case <-time.After(100 * time.Millisecond):
+				return // Export stopped consuming the channel
+			}
+		}
+	}()
+
+	var buf bytes.Buffer
+	err := StreamFromChannel(ch).GetCSV(func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+	}).ExportContext(ctx, &buf)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CSV.ExportContext() error = %v, want %v", err, context.Canceled)
+	}
+
+	if sent := <-consumed; sent > 11 {
+		t.Errorf("channel consumed %d rows after cancellation, want at most 11", sent)
+	}
+}
+
+// TestCSVStreamDecodeError tests that errors decoding a JSON stream are returned by the export
+func TestCSVStreamDecodeError(t *testing.T) {
+	var buf bytes.Buffer
+	err := StreamJSONFromReader(strings.NewReader(`{"id": 1}` + "\n" + `{invalid}`)).GetCSV(func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+	}).Export(&buf)
+	if err == nil {
+		t.Error("CSV.Export() expected error for invalid JSON stream, got nil")
+	}
+}
+
+// TestCSVUnsupportedRoot tests that scalar roots and roots holding an error fail with a typed error
+func TestCSVUnsupportedRoot(t *testing.T) {
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+	}
+
+	t.Run("scalar", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			data     *DynamicValue
+			wantType DataType
+		}{
+			{name: "string", data: newDynamicValue("text"), wantType: DataTypeString},
+			{name: "int", data: newDynamicValue(42), wantType: DataTypeInt},
+			{name: "boolean", data: newDynamicValue(true), wantType: DataTypeBoolean},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.data.GetCSV(flattener).Export(&bytes.Buffer{})
+				if !errors.Is(err, ErrUnsupportedRootType) {
+					t.Fatalf("CSV.Export() error = %v, want %v", err, ErrUnsupportedRootType)
+				}
+
+				var rootErr *RootTypeError
+				if !errors.As(err, &rootErr) {
+					t.Fatalf("CSV.Export() error = %v, want RootTypeError", err)
+				}
+				if rootErr.DataType != tt.wantType {
+					t.Errorf("RootTypeError.DataType = %v, want %v", rootErr.DataType, tt.wantType)
+				}
+				if !strings.Contains(err.Error(), tt.wantType.String()) {
+					t.Errorf("CSV.Export() error = %q, want it to name the type %q", err, tt.wantType)
+				}
+			})
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		err := ReadJSONFromReader(strings.NewReader(`{invalid}`)).GetCSV(flattener).Export(&bytes.Buffer{})
+
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("CSV.Export() error = %v, want wrapped json.SyntaxError", err)
+		}
+		if errors.Is(err, ErrUnsupportedRootType) {
+			t.Errorf("CSV.Export() error = %v, want decode error only", err)
+		}
+	})
+}
+
+// TestCSVNullRoot tests that null data produces an empty export
+func TestCSVNullRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      *DynamicValue
+		flattener flattener
+		want      string
+	}{
+		{
+			name: "declared columns",
+			data: newDynamicValue(nil),
+			flattener: func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+				d.Col("name", s.Key("name"))
+			},
+			want: "id,name\n",
+		},
+		{
+			name:      "no columns",
+			data:      newDynamicValue(nil),
+			flattener: func(s Source, d Dest) {},
+			want:      "",
+		},
+		{
+			name: "missing key",
+			data: newDynamicValue(map[string]any{}).Key("orders"),
+			flattener: func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+			},
+			want: "id\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			stats, err := tt.data.GetCSV(tt.flattener).ExportSplitWithStats(NoSplit(&buf))
+			if err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", buf.String(), tt.want)
+			}
+			if stats.RowsProcessed != 0 {
+				t.Errorf("RowsProcessed = %d, want 0", stats.RowsProcessed)
+			}
+		})
+	}
+}
+
+// TestCSVSourceSetDelete tests enriching objects in a flattener
+func TestCSVSourceSetDelete(t *testing.T) {
+	data := StreamJSONFromReader(strings.NewReader(`{"id": 1, "noise": "x"}` + "\n" + `{"id": 2, "noise": "y"}`))
+
+	var buf bytes.Buffer
+	err := data.GetCSV(func(s Source, d Dest) {
+		enriched := s.Delete("noise").Set("source", "stream")
+		d.Col("id", enriched.Key("id"))
+		d.Col("source", enriched.Key("source"))
+		d.Col("object", enriched)
+	}).Export(&buf)
+	if err != nil {
+		t.Fatalf("CSV.Export() unexpected error = %v", err)
+	}
+
+	want := "id,source,object\n1,stream,\"{\"\"id\"\":1,\"\"source\"\":\"\"stream\"\"}\"\n2,stream,\"{\"\"id\"\":2,\"\"source\"\":\"\"stream\"\"}\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV.Export() = %q, want %q", got, want)
+	}
+}
+
+// TestCSVTimeColumns tests time-typed columns formatting and splitting
+func TestCSVTimeColumns(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"id": 1, "created": "2024-03-15T10:30:00Z"},
+		{"id": 2, "created": 1718447400},
+		{"id": 3, "created": 1735734600000}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("created", s.Key("created").ParseTime())
+	}
+
+	t.Run("default layout", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := data.GetCSV(flattener).Export(&buf); err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+		want := "id,created\n1,2024-03-15T10:30:00Z\n2,2024-06-15T10:30:00Z\n3,2025-01-01T12:30:00Z\n"
+		if got := buf.String(); got != want {
+			t.Errorf("CSV.Export() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("custom layout and timezone", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skipf("time zone database not available: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := data.GetCSV(flattener, WithTimeFormat("2006-01-02 15:04 MST", newYork)).Export(&buf); err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+		want := "id,created\n1,2024-03-15 06:30 EDT\n2,2024-06-15 06:30 EDT\n3,2025-01-01 07:30 EST\n"
+		if got := buf.String(); got != want {
+			t.Errorf("CSV.Export() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("time based split", func(t *testing.T) {
+		cutoff := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		var before, after bytes.Buffer
+		err := data.GetCSV(flattener).ExportSplit(
+			Split(&before, "created", func(v time.Time) bool { return v.Before(cutoff) }),
+			Split(&after, "created", func(v time.Time) bool { return !v.Before(cutoff) }),
+		)
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+		if want := "id,created\n1,2024-03-15T10:30:00Z\n"; before.String() != want {
+			t.Errorf("CSV.ExportSplit() split 0 = %q, want %q", before.String(), want)
+		}
+		if want := "id,created\n2,2024-06-15T10:30:00Z\n3,2025-01-01T12:30:00Z\n"; after.String() != want {
+			t.Errorf("CSV.ExportSplit() split 1 = %q, want %q", after.String(), want)
+		}
+	})
+}
+
+// TestExportRecordsMatchesExport tests that ExportRecords produces the same records Export writes
+func TestExportRecordsMatchesExport(t *testing.T) {
+	input := `[
+		{"name": "John", "age": 30, "notes": "likes \"quotes\", commas", "price": 0.0000001},
+		{"name": "Jane", "age": 25, "notes": "multi\nline", "tags": ["a", "b"]},
+		{"name": "Bob", "notes": "=cmd", "price": 12.5}
+	]`
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+		d.Col("notes", s.Key("notes"))
+		d.ColIf("price", s.Key("price").Exists(), s.Key("price"))
+		d.Col("tags", s.Key("tags"))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want [][]string
+	}{
+		{
+			name: "default",
+			want: [][]string{
+				{"name", "age", "notes", "price", "tags"},
+				{"John", "30", `likes "quotes", commas`, "0.0000001", ""},
+				{"Jane", "25", "multi\nline", "", `["a","b"]`},
+				{"Bob", "", "=cmd", "12.5", ""},
+			},
+		},
+		{
+			name: "options",
+			opts: []ExportOption{
+				WithColumns("price", "name", "notes"),
+				WithHeaderRename(map[string]string{"price": "Price"}),
+				WithDefault("price", "0"),
+				WithFormulaEscaping(true),
+				WithAggregates(map[string]AggFunc{"price": AggSum}),
+			},
+			want: [][]string{
+				{"Price", "name", "notes"},
+				{"0.0000001", "John", `likes "quotes", commas`},
+				{"0", "Jane", "multi\nline"},
+				{"12.5", "Bob", "'=cmd"},
+				{"12.5000001", "", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadJSONFromReader(strings.NewReader(input)).GetCSV(flattener, tt.opts...).ExportRecords()
+			if err != nil {
+				t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+			}
+			flattest.AssertCSVEqual(t, tt.want, records)
+
+			var exported, encoded bytes.Buffer
+			if err := ReadJSONFromReader(strings.NewReader(input)).GetCSV(flattener, tt.opts...).Export(&exported); err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			writer := csv.NewWriter(&encoded)
+			if err := writer.WriteAll(records); err != nil {
+				t.Fatalf("csv.Writer.WriteAll() unexpected error = %v", err)
+			}
+			if exported.String() != encoded.String() {
+				t.Errorf("CSV.Export() = %q, encoded records = %q", exported.String(), encoded.String())
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		records, err := newDynamicValue("not a root").GetCSV(flattener).ExportRecords()
+		if err == nil || records != nil {
+			t.Errorf("CSV.ExportRecords() = %v, %v, want nil records and error", records, err)
+		}
+	})
+}
+
+const (
+	benchRows    = 2000
+	benchColumns = 40
+)
+
+// benchWideRows returns n rows of benchColumns columns alternating strings and ints.
+func benchWideRows(n int) []map[string]any {
+	rows := make([]map[string]any, n)
+	for i := range rows {
+		rows[i] = make(map[string]any, benchColumns)
+		for j := 0; j < benchColumns; j++ {
+			if j%2 == 0 {
+				rows[i][benchColumnName(j)] = fmt.Sprintf("value %d-%d", i, j)
+			} else {
+				rows[i][benchColumnName(j)] = i * j
+			}
+		}
+	}
+	return rows
+}
+
+// benchColumnNames are computed once so the flattener does not add allocations to the benchmarks.
+var benchColumnNames = func() []string {
+	names := make([]string, benchColumns)
+	for j := range names {
+		names[j] = fmt.Sprintf("col_%02d", j)
+	}
+	return names
+}()
+
+func benchColumnName(j int) string {
+	return benchColumnNames[j]
+}
+
+func benchWideFlattener(s Source, d Dest) {
+	for _, name := range benchColumnNames {
+		d.Col(name, s.Key(name))
+	}
+}
+
+// benchWideGolden returns the CSV of the rows, written without the flat package.
+func benchWideGolden(b *testing.B, rows []map[string]any) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	record := make([]string, benchColumns)
+	for j := range record {
+		record[j] = benchColumnName(j)
+	}
+	if err := w.Write(record); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, row := range rows {
+		for j := range record {
+			record[j] = fmt.Sprint(row[benchColumnName(j)])
+		}
+		if err := w.Write(record); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+func BenchmarkExportWide(b *testing.B) {
+	rows := benchWideRows(benchRows)
+	data := newDynamicValue(rows)
+
+	var got bytes.Buffer
+	if err := data.GetCSV(benchWideFlattener).Export(&got); err != nil {
+		b.Fatal(err)
+	}
+	if want := benchWideGolden(b, rows); got.String() != want {
+		b.Fatal("CSV.Export() output differs from golden output")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := data.GetCSV(benchWideFlattener).Export(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExportStream(b *testing.B) {
+	rows := benchWideRows(benchRows)
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var got bytes.Buffer
+	if err := StreamJSONFromReader(bytes.NewReader(input.Bytes())).GetCSV(benchWideFlattener).Export(&got); err != nil {
+		b.Fatal(err)
+	}
+	if want := benchWideGolden(b, rows); got.String() != want {
+		b.Fatal("CSV.Export() output differs from golden output")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := StreamJSONFromReader(bytes.NewReader(input.Bytes())).GetCSV(benchWideFlattener).Export(io.Discard)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExportSplit3Writers(b *testing.B) {
+	rows := benchWideRows(benchRows)
+	data := newDynamicValue(rows)
+
+	// Column col_01 holds the row index, each writer gets the rows of one remainder
+	splits := func(writers []io.Writer) []splitWriter {
+		splits := make([]splitWriter, len(writers))
+		for k := range writers {
+			splits[k] = Split(writers[k], "col_01", func(v int) bool { return v%3 == k })
+		}
+		return splits
+	}
+
+	var got [3]bytes.Buffer
+	if err := data.GetCSV(benchWideFlattener).ExportSplit(splits([]io.Writer{&got[0], &got[1], &got[2]})...); err != nil {
+		b.Fatal(err)
+	}
+	for k := range got {
+		var splitRows []map[string]any
+		for i, row := range rows {
+			if i%3 == k {
+				splitRows = append(splitRows, row)
+			}
+		}
+		if want := benchWideGolden(b, splitRows); got[k].String() != want {
+			b.Fatalf("CSV.ExportSplit() output of writer %d differs from golden output", k)
+		}
+	}
+
+	discard := splits([]io.Writer{io.Discard, io.Discard, io.Discard})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := data.GetCSV(benchWideFlattener).ExportSplit(discard...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
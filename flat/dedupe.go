@@ -0,0 +1,86 @@
+package flat
+
+import "slices"
+
+// WithDedupeConsecutive skips the rows whose values of the listed columns, identified by their original name,
+// match the previous row written to the same writer, or all the output columns when none are listed.
+// Values are compared once formatted, as written to the output, and each writer dedupes independently,
+// as does each value of SplitByValue. Listed columns that are not part of the output are ignored,
+// so no row is skipped if none of them is.
+// Skipped rows are counted in the RowsDeduped stats of the writer.
+func WithDedupeConsecutive(keys ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.dedupe = true
+		o.dedupeKeys = keys
+	}
+}
+
+// deduper remembers the key values of the last row written to each output of a writer.
+type deduper struct {
+	indexes  []int
+	previous map[string][]string
+}
+
+// newDedupers creates the dedupers of each writer comparing the given output columns.
+// It returns nil if WithDedupeConsecutive is not used.
+func (t *CSV) newDedupers(writers int, columns []string) []*deduper {
+	if !t.options.dedupe {
+		return nil
+	}
+
+	var indexes []int
+	for j, name := range columns {
+		if len(t.options.dedupeKeys) == 0 || slices.Contains(t.options.dedupeKeys, name) {
+			indexes = append(indexes, j)
+		}
+	}
+
+	dedupers := make([]*deduper, writers)
+	for i := range dedupers {
+		dedupers[i] = &deduper{indexes: indexes, previous: make(map[string][]string)}
+	}
+
+	return dedupers
+}
+
+// duplicate reports whether the line has the same key values as the previous line written to the output,
+// and otherwise remembers its key values. The output is the value routed by SplitByValue, empty for
+// the other writers.
+func (d *deduper) duplicate(output string, line []Cell) bool {
+	if len(d.indexes) == 0 {
+		return false
+	}
+
+	previous, exists := d.previous[output]
+	if exists {
+		same := true
+		for k, j := range d.indexes {
+			if line[j].Value != previous[k] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return true
+		}
+	}
+
+	if !exists {
+		previous = make([]string, len(d.indexes))
+	}
+	for k, j := range d.indexes {
+		previous[k] = line[j].Value
+	}
+	d.previous[output] = previous
+
+	return false
+}
+
+// encoderOutput returns the output of the encoder the row is written to: the routed value for SplitByValue,
+// empty for the other encoders.
+func encoderOutput(encoder RowEncoder) string {
+	if valueEncoder, ok := encoder.(*valueEncoder); ok {
+		return valueEncoder.nextValue
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package flat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDedupeConsecutiveSplit tests that each writer dedupes the rows it writes, even when the duplicates
+// are separated by rows excluded by its split condition
+func TestDedupeConsecutiveSplit(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"id": 1, "region": "us"},
+		{"id": 1, "region": "us"},
+		{"id": 2, "region": "eu"},
+		{"id": 1, "region": "us"},
+		{"id": 2, "region": "eu"}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("region", s.Key("region"))
+	}
+
+	var us, eu, all bytes.Buffer
+	stats, err := data.GetCSV(flattener, WithDedupeConsecutive()).ExportSplitWithStats(
+		Split(&us, "region", func(v string) bool { return v == "us" }),
+		Split(&eu, "region", func(v string) bool { return v == "eu" }),
+		NoSplit(&all),
+	)
+	if err != nil {
+		t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		buf   *bytes.Buffer
+		stats WriterStats
+		want  string
+	}{
+		{name: "us", buf: &us, stats: WriterStats{RowsWritten: 1, RowsSkipped: 2, RowsDeduped: 2}, want: "id,region\n1,us\n"},
+		{name: "eu", buf: &eu, stats: WriterStats{RowsWritten: 1, RowsSkipped: 3, RowsDeduped: 1}, want: "id,region\n2,eu\n"},
+		{name: "all", buf: &all, stats: WriterStats{RowsWritten: 4, RowsDeduped: 1}, want: "id,region\n1,us\n2,eu\n1,us\n2,eu\n"},
+	}
+	for i, tt := range tests {
+		tt.stats.BytesWritten = int64(tt.buf.Len())
+		if stats.Writers[i] != tt.stats {
+			t.Errorf("%s stats = %+v, want %+v", tt.name, stats.Writers[i], tt.stats)
+		}
+		if got := tt.buf.String(); got != tt.want {
+			t.Errorf("%s output = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if stats.RowsProcessed != 5 {
+		t.Errorf("RowsProcessed = %d, want 5", stats.RowsProcessed)
+	}
+}
+
+func TestDedupeConsecutiveKeys(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"id": 1, "status": "open", "amount": 1.5, "received_at": "10:00"},
+		{"id": 1, "status": "open", "amount": 1.50, "received_at": "10:01"},
+		{"id": 1, "status": "closed", "amount": 1.5, "received_at": "10:02"},
+		{"id": 1, "status": "closed", "amount": 2, "received_at": "10:03"},
+		{"id": 2, "status": "closed", "amount": 2, "received_at": "10:04"}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("status", s.Key("status"))
+		d.ColFormatted("amount", s.Key("amount"), PriceColumn("USD", PricePrecision(2)))
+		d.Col("received_at", s.Key("received_at"))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want []string
+	}{
+		{
+			name: "all columns",
+			opts: []ExportOption{WithDedupeConsecutive()},
+			want: []string{"10:00", "10:01", "10:02", "10:03", "10:04"},
+		},
+		{
+			name: "key subset",
+			opts: []ExportOption{WithDedupeConsecutive("id", "status")},
+			want: []string{"10:00", "10:02", "10:04"},
+		},
+		{
+			name: "formatted values",
+			opts: []ExportOption{WithDedupeConsecutive("id", "amount")},
+			want: []string{"10:00", "10:03", "10:04"},
+		},
+		{
+			name: "renamed headers",
+			opts: []ExportOption{WithDedupeConsecutive("status"), WithHeaderRename(map[string]string{"status": "state"})},
+			want: []string{"10:00", "10:02"},
+		},
+		{
+			name: "columns not in the output",
+			opts: []ExportOption{WithDedupeConsecutive("status"), WithColumns("id", "received_at")},
+			want: []string{"10:00", "10:01", "10:02", "10:03", "10:04"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := data.GetCSV(flattener, tt.opts...).ExportRecords()
+			if err != nil {
+				t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+			}
+
+			var got []string
+			for _, record := range records[1:] {
+				got = append(got, record[len(record)-1])
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("received_at of the written rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeConsecutiveSplitByValue(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"name": "John", "city": "Boston"},
+		{"name": "John", "city": "Chicago"},
+		{"name": "John", "city": "Boston"},
+		{"name": "Jane", "city": "Boston"},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("city", s.Key("city"))
+	}
+
+	tw := newTestWriters()
+	stats, err := data.GetCSV(flattener, WithDedupeConsecutive("name")).ExportSplitWithStats(SplitByValue("city", tw.newWriter))
+	if err != nil {
+		t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+	}
+
+	want := map[string]string{
+		"Boston":  "name,city\nJohn,Boston\nJane,Boston\n",
+		"Chicago": "name,city\nJohn,Chicago\n",
+	}
+	for value, output := range want {
+		if got := tw.writers[value].String(); got != output {
+			t.Errorf("%s output = %q, want %q", value, got, output)
+		}
+	}
+	if stats.Writers[0].RowsWritten != 3 || stats.Writers[0].RowsDeduped != 1 {
+		t.Errorf("stats = %+v, want 3 rows written and 1 deduped", stats.Writers[0])
+	}
+}
@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type DataType int
@@ -18,25 +23,55 @@ const (
 	DataTypeInt
 	DataTypeBoolean
 	DataTypeNull
+	DataTypeNumber
+	DataTypeTime
 )
 
+// dataTypeNames holds the name of each DataType.
+var dataTypeNames = map[DataType]string{
+	DataTypeObject:          "object",
+	DataTypeArray:           "array",
+	DataTypeArrayOfObjects:  "array of objects",
+	DataTypeStreamOfObjects: "stream of objects",
+	DataTypeString:          "string",
+	DataTypeFloat:           "float",
+	DataTypeInt:             "int",
+	DataTypeBoolean:         "boolean",
+	DataTypeNull:            "null",
+	DataTypeNumber:          "number",
+	DataTypeTime:            "time",
+}
+
+// String returns the name of the data type.
+func (t DataType) String() string {
+	if name, ok := dataTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("DataType(%d)", int(t))
+}
+
 const errorStrValue = "<ERROR>"
 
 type DynamicValue struct {
 	dataType DataType
 	value    any
 	err      error
+	missing  bool
 }
 
 var DynamicValueNull = &DynamicValue{dataType: DataTypeNull, value: nil}
 
+// dynamicValueMissing is returned when a key or index does not exist.
+// It behaves as a null value but allows to distinguish a missing value from an explicit JSON null.
+var dynamicValueMissing = &DynamicValue{dataType: DataTypeNull, value: nil, missing: true}
+
 func getDataTypeFromValue(v any) DataType {
 	switch v.(type) {
 	case map[string]any:
 		return DataTypeObject
 	case []any:
 		return DataTypeArray
-	case []map[string]any:
+	case []map[string]any, *structSlice:
 		return DataTypeArrayOfObjects
 	case string:
 		return DataTypeString
@@ -46,7 +81,11 @@ func getDataTypeFromValue(v any) DataType {
 		return DataTypeInt
 	case bool:
 		return DataTypeBoolean
-	case io.Reader:
+	case json.Number:
+		return DataTypeNumber
+	case time.Time:
+		return DataTypeTime
+	case io.Reader, <-chan map[string]any, *csvSource, *jsonArraySource, *concatSource:
 		return DataTypeStreamOfObjects
 	default:
 		return DataTypeNull
@@ -54,7 +93,12 @@ func getDataTypeFromValue(v any) DataType {
 }
 
 func newDynamicValue(d any) *DynamicValue {
-	return &DynamicValue{dataType: getDataTypeFromValue(d), value: d}
+	dataType := getDataTypeFromValue(d)
+	if dataType == DataTypeNull && d != nil {
+		// Structs, typed slices and maps are exposed like their JSON decoded equivalent
+		return newDynamicValueFromReflection(d)
+	}
+	return &DynamicValue{dataType: dataType, value: d}
 }
 
 func isValidDataType[T any]() bool {
@@ -65,6 +109,41 @@ func getDataTypeFromType[T any]() DataType {
 	return getDataTypeFromValue(*new(T))
 }
 
+// valueAs returns the value held by dv as T.
+// Besides an exact type match it supports the numeric coercions shared by splitters and formatters:
+// whole floats to int, and json.Number literals to float64 or int.
+// The boolean result is false when the value cannot be represented as T.
+func valueAs[T any](dv *DynamicValue) (T, bool) {
+	expectedType := getDataTypeFromType[T]()
+
+	if dv.dataType == expectedType {
+		v, ok := dv.value.(T)
+		return v, ok
+	}
+
+	switch expectedType {
+	case DataTypeInt:
+		if fval, ok := dv.value.(float64); ok {
+			if ival := int(fval); float64(ival) == fval { // Only convert if no precision is lost
+				return any(ival).(T), true
+			}
+		}
+		if num, ok := dv.value.(json.Number); ok {
+			if ival, err := strconv.ParseInt(string(num), 10, 0); err == nil {
+				return any(int(ival)).(T), true
+			}
+		}
+	case DataTypeFloat:
+		if num, ok := dv.value.(json.Number); ok {
+			if fval, err := num.Float64(); err == nil {
+				return any(fval).(T), true
+			}
+		}
+	}
+
+	return *new(T), false
+}
+
 func errorDynamicValue(err error) *DynamicValue {
 	return &DynamicValue{
 		dataType: DataTypeNull,
@@ -75,9 +154,11 @@ func errorDynamicValue(err error) *DynamicValue {
 
 // ReadJSONFromReader creates a new DynamicValue instance from a io.Reader containing JSON data.
 // It expect the that IO.Reader contains a single JSON object, array or array of objects.
+// Numbers are kept as json.Number so large integers are exported with their original digits.
 func ReadJSONFromReader(r io.Reader) *DynamicValue {
 	var data any
 	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
 	if err := decoder.Decode(&data); err != nil {
 		return errorDynamicValue(fmt.Errorf("failed to decode JSON: %w", err))
 	}
@@ -91,6 +172,29 @@ func StreamJSONFromReader(r io.Reader) *DynamicValue {
 	return newDynamicValue(r)
 }
 
+// Exists reports whether the value was found in the data.
+// It returns false for missing keys, out of range indexes and errors, and true for explicit JSON nulls.
+func (d *DynamicValue) Exists() bool {
+	return !d.missing && d.err == nil
+}
+
+// IsNull reports whether the value is null, either because it is an explicit JSON null or because it is missing.
+func (d *DynamicValue) IsNull() bool {
+	return d.dataType == DataTypeNull
+}
+
+// isNumeric reports whether the value holds a float, an int or a json.Number.
+func (d *DynamicValue) isNumeric() bool {
+	return d.dataType == DataTypeFloat || d.dataType == DataTypeInt || d.dataType == DataTypeNumber
+}
+
+// StreamFromChannel creates a new DynamicValue instance from a channel of objects.
+// This is useful for producers that generate rows programmatically, i.e. database cursors,
+// without encoding them to JSON. The export ends when the channel is closed.
+func StreamFromChannel(ch <-chan map[string]any) *DynamicValue {
+	return newDynamicValue(ch)
+}
+
 // DataType returns the type of data contained in the Data instance.
 func (d *DynamicValue) DataType() DataType {
 	return d.dataType
@@ -101,32 +205,54 @@ func (d *DynamicValue) Error() error {
 	return d.err
 }
 
-// strVal returns the string representation of the data based on its type.
+// strVal returns the string representation of the data based on its type using the default export options.
 // If the data type is not supported or an error occurs, it returns an error.
 func (d *DynamicValue) strVal() (string, error) {
+	return d.strValWithOptions(defaultExportOptions())
+}
+
+// strValWithOptions returns the string representation of the data based on its type.
+// Floats are rendered with the number formatter configured in opts, and objects and arrays as JSON
+// with sorted keys and without HTML escaping, indented as configured in opts.
+// If the data type is not supported or an error occurs, it returns an error.
+func (d *DynamicValue) strValWithOptions(opts *exportOptions) (string, error) {
 	if d.err != nil {
 		return errorStrValue, fmt.Errorf("data contains error: %w", d.err)
 	}
 
 	switch d.dataType {
 	case DataTypeObject, DataTypeArray, DataTypeArrayOfObjects:
-		strObj, err := json.Marshal(d.value)
-		if err != nil {
+		var buf strings.Builder
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false) // Cells are not embedded in HTML, keep & < > readable
+		encoder.SetIndent("", opts.jsonIndent)
+		if err := encoder.Encode(d.value); err != nil {
 			return errorStrValue, fmt.Errorf("failed to marshal data: %w", err)
 		}
-		return string(strObj), nil
+		return strings.TrimSuffix(buf.String(), "\n"), nil
 	case DataTypeString:
 		str := d.value.(string)
 		return str, nil
 	case DataTypeFloat:
 		num := d.value.(float64)
-		return fmt.Sprintf("%g", num), nil
+		return opts.floatFormatter(num), nil
 	case DataTypeInt:
 		num := d.value.(int)
-		return fmt.Sprintf("%d", num), nil
+		return strconv.Itoa(num), nil
 	case DataTypeBoolean:
-		boolean := d.value.(bool)
-		return fmt.Sprintf("%t", boolean), nil
+		if d.value.(bool) {
+			return opts.trueLabel, nil
+		}
+		return opts.falseLabel, nil
+	case DataTypeNumber:
+		num := d.value.(json.Number)
+		return opts.formatNumber(num), nil
+	case DataTypeTime:
+		t := d.value.(time.Time)
+		if opts.timeLocation != nil {
+			t = t.In(opts.timeLocation)
+		}
+		return t.Format(opts.timeLayout), nil
 	case DataTypeStreamOfObjects:
 		return errorStrValue, fmt.Errorf("data is a stream of objects, cannot convert to string")
 	case DataTypeNull:
@@ -137,11 +263,11 @@ func (d *DynamicValue) strVal() (string, error) {
 }
 
 // rootKey retrieves a value from a Data instances holding a object.
-// If the data is not an object or the key does not exist, it returns NullData.
+// If the data is not an object or the key does not exist, it returns a missing null value.
 func (d *DynamicValue) rootKey(key string) *DynamicValue {
 	// Return null if data is not an object
 	if d.dataType != DataTypeObject {
-		return DynamicValueNull
+		return dynamicValueMissing
 	}
 
 	if obj, ok := d.value.(map[string]any); ok {
@@ -151,15 +277,15 @@ func (d *DynamicValue) rootKey(key string) *DynamicValue {
 	}
 
 	// Return null if key does not exist
-	return DynamicValueNull
+	return dynamicValueMissing
 }
 
 // Key retrieves a value from a Data instance using a sequence of keys.
-// If no keys are provided, it returns NullData.
+// If no keys are provided or any key does not exist, it returns a missing null value.
 func (d *DynamicValue) Key(keys ...string) *DynamicValue {
 	// Return null if no keys provided
 	if len(keys) == 0 {
-		return DynamicValueNull
+		return dynamicValueMissing
 	}
 
 	// If only one key, return the value for that key
@@ -171,6 +297,222 @@ func (d *DynamicValue) Key(keys ...string) *DynamicValue {
 	return d.rootKey(keys[0]).Key(keys[1:]...)
 }
 
+// Path retrieves a value traversing objects and arrays in a single call.
+// String parts are used as object keys and int parts as array indexes (negative indexes count from the end).
+// If any step fails or a part has an unsupported type, it returns a missing null value.
+// If no parts are provided, it returns the value itself.
+func (d *DynamicValue) Path(parts ...any) *DynamicValue {
+	return d.path(false, parts)
+}
+
+// PathStrict retrieves a value like Path, but returns an error value describing the failing step
+// when a key does not exist, an index is out of bounds or a part has an unsupported type.
+func (d *DynamicValue) PathStrict(parts ...any) *DynamicValue {
+	return d.path(true, parts)
+}
+
+// path walks the parts over the value, returning an error value on failure if strict is true.
+func (d *DynamicValue) path(strict bool, parts []any) *DynamicValue {
+	current := d
+	for i, part := range parts {
+		if current.err != nil {
+			return current
+		}
+
+		switch p := part.(type) {
+		case string:
+			next := current.rootKey(p)
+			if strict && !next.Exists() {
+				return errorDynamicValue(fmt.Errorf("path step %d: key %q not found", i, p))
+			}
+			current = next
+		case int:
+			if strict {
+				next := current.IdxStrict(p)
+				if next.err != nil {
+					return errorDynamicValue(fmt.Errorf("path step %d: %w", i, next.err))
+				}
+				current = next
+			} else {
+				current = current.Idx(p)
+			}
+		default:
+			if strict {
+				return errorDynamicValue(fmt.Errorf("path step %d: unsupported path part type %T", i, part))
+			}
+			return dynamicValueMissing
+		}
+	}
+
+	return current
+}
+
+// PathString retrieves a value using a dot separated path like "orders.0.total".
+// Numeric segments are used as indexes when the current value is an array and as keys otherwise.
+// Dots and backslashes that are part of a key must be escaped with a backslash, i.e. `prices.BRK\.A`.
+// If any step fails, it returns a missing null value.
+func (d *DynamicValue) PathString(path string) *DynamicValue {
+	current := d
+	for _, segment := range splitPath(path) {
+		if current.dataType == DataTypeArray || current.dataType == DataTypeArrayOfObjects {
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return dynamicValueMissing
+			}
+			current = current.Idx(index)
+			continue
+		}
+
+		current = current.rootKey(segment)
+	}
+
+	return current
+}
+
+// splitPath splits a dot separated path into its segments, unescaping `\.` and `\\`.
+func splitPath(path string) []string {
+	segments := []string{}
+	var sb strings.Builder
+	escaped := false
+
+	for _, r := range path {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			segments = append(segments, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return append(segments, sb.String())
+}
+
+// Set returns a new object DynamicValue with the key set to value, the original value is not modified.
+// The value can be any value accepted by the package, including another DynamicValue.
+// If the data is not an object, it returns an error value.
+func (d *DynamicValue) Set(key string, value any) *DynamicValue {
+	obj, err := d.objectCopy("set")
+	if err != nil {
+		return errorDynamicValue(err)
+	}
+
+	if dv, ok := value.(*DynamicValue); ok {
+		if dv.err != nil {
+			return errorDynamicValue(fmt.Errorf("cannot set key %q: %w", key, dv.err))
+		}
+		value = dv.value
+	}
+
+	obj[key] = value
+	return newDynamicValue(obj)
+}
+
+// Delete returns a new object DynamicValue without the key, the original value is not modified.
+// Deleting a key that does not exist returns an unchanged copy.
+// If the data is not an object, it returns an error value.
+func (d *DynamicValue) Delete(key string) *DynamicValue {
+	obj, err := d.objectCopy("delete")
+	if err != nil {
+		return errorDynamicValue(err)
+	}
+
+	delete(obj, key)
+	return newDynamicValue(obj)
+}
+
+// objectCopy returns a shallow copy of the object held by the DynamicValue.
+func (d *DynamicValue) objectCopy(operation string) (map[string]any, error) {
+	if d.err != nil {
+		return nil, fmt.Errorf("cannot %s key: %w", operation, d.err)
+	}
+
+	obj, ok := d.value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot %s key: data is not an object", operation)
+	}
+
+	newObj := make(map[string]any, len(obj)+1)
+	for k, v := range obj {
+		newObj[k] = v
+	}
+
+	return newObj, nil
+}
+
+// MarshalJSON encodes the DynamicValue as JSON.
+// Null and missing values are encoded as null. Values holding an error and streams cannot be encoded.
+func (d *DynamicValue) MarshalJSON() ([]byte, error) {
+	if d.err != nil {
+		return nil, fmt.Errorf("data contains error: %w", d.err)
+	}
+
+	if d.dataType == DataTypeStreamOfObjects {
+		return nil, fmt.Errorf("data is a stream of objects, cannot be encoded as JSON")
+	}
+
+	return json.Marshal(d.value)
+}
+
+// ParseTime converts the value into a time-typed DynamicValue.
+// Strings are parsed using the first matching layout, time.RFC3339 is used when no layouts are provided.
+// Numbers are read as Unix epochs: values with an absolute value of at least 1e11 are read as milliseconds
+// and smaller values as seconds, fractions included.
+// Time values are returned unchanged and null values stay null.
+// Values that cannot be converted return an error value.
+func (d *DynamicValue) ParseTime(layouts ...string) *DynamicValue {
+	if d.err != nil || d.dataType == DataTypeNull || d.dataType == DataTypeTime {
+		return d
+	}
+
+	var epoch float64
+	switch v := d.value.(type) {
+	case string:
+		if len(layouts) == 0 {
+			layouts = []string{time.RFC3339}
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return newDynamicValue(t)
+			}
+		}
+		return errorDynamicValue(fmt.Errorf("cannot parse time %q with layouts %v", v, layouts))
+	case float64:
+		epoch = v
+	case int:
+		epoch = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return errorDynamicValue(fmt.Errorf("cannot parse time from number %s: %w", v, err))
+		}
+		epoch = f
+	default:
+		return errorDynamicValue(fmt.Errorf("cannot parse time from data type %v", d.dataType))
+	}
+
+	return newDynamicValue(epochToTime(epoch))
+}
+
+// epochMillisThreshold is the absolute epoch value from which numbers are read as milliseconds.
+// 1e11 seconds is in the year 5138, while 1e11 milliseconds is in 1973.
+const epochMillisThreshold = 1e11
+
+// epochToTime converts a Unix epoch in seconds or milliseconds into a UTC time.
+func epochToTime(epoch float64) time.Time {
+	if math.Abs(epoch) >= epochMillisThreshold {
+		return time.UnixMilli(int64(epoch)).UTC()
+	}
+
+	sec, frac := math.Modf(epoch)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC()
+}
+
 // Format applies a transformation function to the Data instance.
 // If the function is nil, it returns the original Data instance.
 func (d *DynamicValue) Format(formatterFunc Formatter) *DynamicValue {
@@ -187,31 +529,105 @@ func (d *DynamicValue) Format(formatterFunc Formatter) *DynamicValue {
 }
 
 // Idx retrieves an element from a Data instance that holds an array or an array of objects.
-// If the index is out of bounds or the data type is not an array, it returns NullData.
+// Negative indexes count from the end of the array, so -1 returns the last element.
+// If the index is out of bounds or the data type is not an array, it returns a missing null value.
 func (d *DynamicValue) Idx(index int) *DynamicValue {
 	if d.dataType != DataTypeArray && d.dataType != DataTypeArrayOfObjects {
-		return DynamicValueNull // Return null if not an array
+		return dynamicValueMissing // Return null if not an array
 	}
 
 	if arr, ok := d.value.([]any); ok {
-		if index >= 0 && index < len(arr) {
-			return newDynamicValue(arr[index])
+		if i, ok := resolveIndex(index, len(arr)); ok {
+			return newDynamicValue(arr[i])
 		}
 	}
 
 	if arr, ok := d.value.([]map[string]any); ok {
-		if index >= 0 && index < len(arr) {
-			return newDynamicValue(arr[index])
+		if i, ok := resolveIndex(index, len(arr)); ok {
+			return newDynamicValue(arr[i])
 		}
 	}
 
-	return DynamicValueNull
+	if arr, ok := d.value.(*structSlice); ok {
+		if i, ok := resolveIndex(index, arr.len()); ok {
+			obj, err := arr.object(i)
+			if err != nil {
+				return errorDynamicValue(fmt.Errorf("failed to read index %d: %w", index, err))
+			}
+			return newDynamicValue(obj)
+		}
+	}
+
+	return dynamicValueMissing
+}
+
+// IdxStrict retrieves an element like Idx, but returns an error value when the data is not an array
+// or the index is out of bounds.
+func (d *DynamicValue) IdxStrict(index int) *DynamicValue {
+	if d.err != nil {
+		return d
+	}
+
+	if d.dataType != DataTypeArray && d.dataType != DataTypeArrayOfObjects {
+		return errorDynamicValue(fmt.Errorf("cannot get index %d: data is not an array", index))
+	}
+
+	length := d.Len()
+	if _, ok := resolveIndex(index, length); !ok {
+		return errorDynamicValue(fmt.Errorf("index %d out of range for array of length %d", index, length))
+	}
+
+	return d.Idx(index)
+}
+
+// resolveIndex converts a possibly negative index into a position in a collection of the given length.
+// It returns false if the index is out of bounds.
+func resolveIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
+
+// Len returns the number of elements of an array or the number of keys of an object.
+// It returns -1 for any other data type.
+func (d *DynamicValue) Len() int {
+	switch v := d.value.(type) {
+	case []any:
+		return len(v)
+	case []map[string]any:
+		return len(v)
+	case *structSlice:
+		return v.len()
+	case map[string]any:
+		return len(v)
+	default:
+		return -1
+	}
+}
+
+// Keys returns the sorted keys of an object.
+// It returns nil if the data is not an object.
+func (d *DynamicValue) Keys() []string {
+	obj, ok := d.value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
 
 // GetCSV generates a CSV representation of the DynamicValue instance.
 // It applies the provided mapper function to each item in the DynamicValue instance.
 // The mapper function takes a Source and a Dest as arguments, allowing it to write data to the CSV.
 // If the Data instance contains an error or is not an array or array of objects, it returns a CSV with an error.
-func (d *DynamicValue) GetCSV(f flattener) *CSV {
-	return newCsv(d, f)
+// ExportOptions can be provided to customize how the values are rendered.
+func (d *DynamicValue) GetCSV(f flattener, opts ...ExportOption) *CSV {
+	return newCsv(d, f, opts...)
 }
@@ -1,9 +1,12 @@
 package flat
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewData(t *testing.T) {
@@ -52,6 +55,11 @@ func TestNewData(t *testing.T) {
 			input:    nil,
 			wantType: DataTypeNull,
 		},
+		{
+			name:     "json number",
+			input:    json.Number("42"),
+			wantType: DataTypeNumber,
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,6 +91,12 @@ func TestNewFromJSONReader(t *testing.T) {
 			wantType: DataTypeArray,
 			wantErr:  false,
 		},
+		{
+			name:     "number",
+			input:    `9007199254740993`,
+			wantType: DataTypeNumber,
+			wantErr:  false,
+		},
 		{
 			name:     "invalid JSON",
 			input:    `{invalid}`,
@@ -128,6 +142,12 @@ func TestDataVal(t *testing.T) {
 			want:    "123.45",
 			wantErr: false,
 		},
+		{
+			name:    "json number value",
+			data:    newDynamicValue(json.Number("9007199254740993")),
+			want:    "9007199254740993",
+			wantErr: false,
+		},
 		{
 			name:    "object value",
 			data:    newDynamicValue(map[string]any{"key": "value"}),
@@ -155,3 +175,271 @@ func TestDataVal(t *testing.T) {
 		})
 	}
 }
+
+func TestDataExistsIsNull(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`{"empty": "", "null": null, "items": [null]}`))
+
+	tests := []struct {
+		name       string
+		data       *DynamicValue
+		wantExists bool
+		wantIsNull bool
+	}{
+		{name: "missing key", data: data.Key("missing"), wantExists: false, wantIsNull: true},
+		{name: "missing nested key", data: data.Key("empty", "child"), wantExists: false, wantIsNull: true},
+		{name: "explicit null", data: data.Key("null"), wantExists: true, wantIsNull: true},
+		{name: "empty string", data: data.Key("empty"), wantExists: true, wantIsNull: false},
+		{name: "null array element", data: data.Key("items").Idx(0), wantExists: true, wantIsNull: true},
+		{name: "out of range index", data: data.Key("items").Idx(1), wantExists: false, wantIsNull: true},
+		{name: "error value", data: errorDynamicValue(fmt.Errorf("test error")), wantExists: false, wantIsNull: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.Exists(); got != tt.wantExists {
+				t.Errorf("Exists() = %v, want %v", got, tt.wantExists)
+			}
+			if got := tt.data.IsNull(); got != tt.wantIsNull {
+				t.Errorf("IsNull() = %v, want %v", got, tt.wantIsNull)
+			}
+		})
+	}
+}
+
+func TestDataIdx(t *testing.T) {
+	arr := newDynamicValue([]any{"a", "b", "c"})
+	objs := newDynamicValue([]map[string]any{{"k": "first"}, {"k": "last"}})
+
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		want    any
+		wantErr bool
+	}{
+		{name: "first", data: arr.Idx(0), want: "a"},
+		{name: "last", data: arr.Idx(-1), want: "c"},
+		{name: "negative from end", data: arr.Idx(-3), want: "a"},
+		{name: "out of range", data: arr.Idx(3), want: nil},
+		{name: "negative out of range", data: arr.Idx(-4), want: nil},
+		{name: "last object", data: objs.Idx(-1).Key("k"), want: "last"},
+		{name: "strict in range", data: arr.IdxStrict(-1), want: "c"},
+		{name: "strict out of range", data: arr.IdxStrict(3), wantErr: true},
+		{name: "strict negative out of range", data: arr.IdxStrict(-4), wantErr: true},
+		{name: "strict not an array", data: newDynamicValue("a").IdxStrict(0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.data.Error() != nil) != tt.wantErr {
+				t.Fatalf("Idx() error = %v, wantErr %v", tt.data.Error(), tt.wantErr)
+			}
+			if tt.data.value != tt.want {
+				t.Errorf("Idx() = %v, want %v", tt.data.value, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataLenKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     *DynamicValue
+		wantLen  int
+		wantKeys []string
+	}{
+		{name: "object", data: newDynamicValue(map[string]any{"b": 1, "a": 2, "c": 3}), wantLen: 3, wantKeys: []string{"a", "b", "c"}},
+		{name: "empty object", data: newDynamicValue(map[string]any{}), wantLen: 0, wantKeys: []string{}},
+		{name: "array", data: newDynamicValue([]any{1, 2}), wantLen: 2, wantKeys: nil},
+		{name: "array of objects", data: newDynamicValue([]map[string]any{{}}), wantLen: 1, wantKeys: nil},
+		{name: "string", data: newDynamicValue("abc"), wantLen: -1, wantKeys: nil},
+		{name: "null", data: DynamicValueNull, wantLen: -1, wantKeys: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+			got := tt.data.Keys()
+			if (got == nil) != (tt.wantKeys == nil) || !slices.Equal(got, tt.wantKeys) {
+				t.Errorf("Keys() = %#v, want %#v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestDataPath(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`{
+		"orders": [{"total": 10.5, "items": ["a", "b"]}, {"total": 20}],
+		"prices": {"BRK.A": 600000, "back\\slash": 1, "0": "zero key"}
+	}`))
+
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		want    string
+		wantErr bool
+	}{
+		{name: "mixed traversal", data: data.Path("orders", 0, "total"), want: "10.5"},
+		{name: "negative index", data: data.Path("orders", -1, "total"), want: "20"},
+		{name: "nested array", data: data.Path("orders", 0, "items", 1), want: "b"},
+		{name: "no parts", data: data.Path("orders", 1).Path(), want: `{"total":20}`},
+		{name: "wrong type step", data: data.Path("orders", "total"), want: ""},
+		{name: "index on object", data: data.Path("prices", 0), want: ""},
+		{name: "unsupported part", data: data.Path("orders", 1.5), want: ""},
+		{name: "strict found", data: data.PathStrict("orders", 1, "total"), want: "20"},
+		{name: "strict missing key", data: data.PathStrict("orders", 0, "missing"), wantErr: true},
+		{name: "strict wrong type step", data: data.PathStrict("orders", "total"), wantErr: true},
+		{name: "strict out of range", data: data.PathStrict("orders", 5), wantErr: true},
+		{name: "strict unsupported part", data: data.PathStrict(true), wantErr: true},
+		{name: "string form", data: data.PathString("orders.0.total"), want: "10.5"},
+		{name: "string form negative index", data: data.PathString("orders.-1.total"), want: "20"},
+		{name: "string form escaped dot", data: data.PathString(`prices.BRK\.A`), want: "600000"},
+		{name: "string form escaped backslash", data: data.PathString(`prices.back\\slash`), want: "1"},
+		{name: "string form numeric key on object", data: data.PathString("prices.0"), want: "zero key"},
+		{name: "string form unescaped dot", data: data.PathString("prices.BRK.A"), want: ""},
+		{name: "string form non numeric index", data: data.PathString("orders.first"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.data.strVal()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Path() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSetDelete(t *testing.T) {
+	original := ReadJSONFromReader(strings.NewReader(`{"id": 1, "name": "John", "noise": "x"}`))
+
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		want    string
+		wantErr bool
+	}{
+		{name: "set new key", data: original.Set("vip", true), want: `{"id":1,"name":"John","noise":"x","vip":true}`},
+		{name: "overwrite key", data: original.Set("name", "Jane"), want: `{"id":1,"name":"Jane","noise":"x"}`},
+		{name: "set dynamic value", data: original.Set("copy", original.Key("name")), want: `{"copy":"John","id":1,"name":"John","noise":"x"}`},
+		{name: "set nested object", data: original.Set("meta", map[string]any{"a": 1}), want: `{"id":1,"meta":{"a":1},"name":"John","noise":"x"}`},
+		{name: "delete key", data: original.Delete("noise"), want: `{"id":1,"name":"John"}`},
+		{name: "delete missing key", data: original.Delete("missing"), want: `{"id":1,"name":"John","noise":"x"}`},
+		{name: "chained", data: original.Delete("noise").Set("total", 9.5), want: `{"id":1,"name":"John","total":9.5}`},
+		{name: "set on non object", data: original.Key("name").Set("k", 1), wantErr: true},
+		{name: "delete on non object", data: newDynamicValue([]any{1}).Delete("k"), wantErr: true},
+		{name: "set on missing", data: original.Key("missing").Set("k", 1), wantErr: true},
+		{name: "set error value", data: original.Set("k", errorDynamicValue(fmt.Errorf("test error"))), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.data)
+			if (err != nil) != tt.wantErr || (tt.data.Error() != nil) != tt.wantErr {
+				t.Fatalf("json.Marshal() error = %v, value error = %v, wantErr %v", err, tt.data.Error(), tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("original is not modified", func(t *testing.T) {
+		got, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("json.Marshal() unexpected error = %v", err)
+		}
+		if want := `{"id":1,"name":"John","noise":"x"}`; string(got) != want {
+			t.Errorf("json.Marshal() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestDataMarshalJSON(t *testing.T) {
+	input := `{"id":9007199254740993,"items":[1,"a",null,true],"nested":{"price":0.0000001}}`
+	data := ReadJSONFromReader(strings.NewReader(input))
+
+	got, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error = %v", err)
+	}
+	if string(got) != input {
+		t.Errorf("json.Marshal() = %s, want %s", got, input)
+	}
+
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		want    string
+		wantErr bool
+	}{
+		{name: "missing", data: data.Key("missing"), want: "null"},
+		{name: "scalar", data: data.Path("items", 1), want: `"a"`},
+		{name: "error", data: errorDynamicValue(fmt.Errorf("test error")), wantErr: true},
+		{name: "stream", data: StreamJSONFromReader(strings.NewReader(input)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataParseTime(t *testing.T) {
+	want := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		layouts []string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "rfc3339", data: newDynamicValue("2024-03-15T10:30:00Z"), want: want},
+		{name: "rfc3339 with offset", data: newDynamicValue("2024-03-15T05:30:00-05:00"), want: want},
+		{name: "custom layout", data: newDynamicValue("15/03/2024 10:30"), layouts: []string{time.RFC3339, "02/01/2006 15:04"}, want: want},
+		{name: "epoch seconds", data: newDynamicValue(float64(want.Unix())), want: want},
+		{name: "epoch seconds int", data: newDynamicValue(int(want.Unix())), want: want},
+		{name: "epoch seconds fraction", data: newDynamicValue(json.Number(fmt.Sprintf("%d.5", want.Unix()))), want: want.Add(500 * time.Millisecond)},
+		{name: "epoch millis", data: newDynamicValue(json.Number(fmt.Sprint(want.UnixMilli()))), want: want},
+		{name: "time value", data: newDynamicValue(want), want: want},
+		{name: "malformed string", data: newDynamicValue("yesterday"), wantErr: true},
+		{name: "boolean", data: newDynamicValue(true), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.data.ParseTime(tt.layouts...)
+			if (got.Error() != nil) != tt.wantErr {
+				t.Fatalf("ParseTime() error = %v, wantErr %v", got.Error(), tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.DataType() != DataTypeTime {
+				t.Fatalf("ParseTime() type = %v, want %v", got.DataType(), DataTypeTime)
+			}
+			if !got.value.(time.Time).Equal(tt.want) {
+				t.Errorf("ParseTime() = %v, want %v", got.value, tt.want)
+			}
+		})
+	}
+
+	t.Run("null stays null", func(t *testing.T) {
+		got := newDynamicValue(map[string]any{}).Key("missing").ParseTime()
+		if got.Error() != nil || got.Exists() || !got.IsNull() {
+			t.Errorf("ParseTime() = %+v, want missing null value", got)
+		}
+	})
+}
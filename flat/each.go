@@ -0,0 +1,120 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotArray is returned when iterating over data that is not an array, see ArrayTypeError.
+var ErrNotArray = errors.New("data is not an array")
+
+// ArrayTypeError is returned by Each, Map and JoinKey when the data is not an array, i.e. an object or a string.
+// It matches ErrNotArray using errors.Is.
+type ArrayTypeError struct {
+	// DataType is the type of the data.
+	DataType DataType
+}
+
+// Error returns the error message including the data type.
+func (e *ArrayTypeError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrNotArray, e.DataType)
+}
+
+// Is reports whether target is ErrNotArray.
+func (e *ArrayTypeError) Is(target error) bool {
+	return target == ErrNotArray
+}
+
+// Each calls fn with the index and the value of each element of an array or an array of objects, in order.
+// Null and missing data have no elements, so fn is never called. Data that is not an array returns
+// an ArrayTypeError and data holding an error returns that error.
+// Iteration stops at the first error returned by fn, which is returned wrapped with the element index.
+func (s Source) Each(fn func(i int, elem Source) error) error {
+	if err := s.data.Error(); err != nil {
+		return err
+	}
+
+	switch s.data.dataType {
+	case DataTypeArray, DataTypeArrayOfObjects:
+	case DataTypeNull:
+		return nil
+	default:
+		return &ArrayTypeError{DataType: s.data.dataType}
+	}
+
+	for i := 0; i < s.Len(); i++ {
+		elem := s.Idx(i)
+		if err := elem.data.Error(); err != nil {
+			return err
+		}
+
+		if err := fn(i, elem); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Map returns the string returned by fn for each element of an array or an array of objects, in order.
+// It fails like Each.
+func (s Source) Map(fn func(elem Source) (string, error)) ([]string, error) {
+	values := make([]string, 0, max(s.Len(), 0))
+	err := s.Each(func(_ int, elem Source) error {
+		value, err := fn(elem)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// JoinKey returns a Source holding the values of the nested key of each element of an array or an array
+// of objects joined with sep, i.e. s.Key("tags").JoinKey(", ", "name") to list the tag names in a single column.
+// Without keys the elements themselves are joined. Null and missing values are left out.
+// If the data is not an array or a value cannot be converted into a string, it returns a Source holding an error.
+func (s Source) JoinKey(sep string, keys ...string) Source {
+	var values []string
+	err := s.Each(func(_ int, elem Source) error {
+		if len(keys) > 0 {
+			elem = elem.Key(keys...)
+		}
+		if elem.IsNull() {
+			return nil
+		}
+
+		value, err := elem.strVal()
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		return Source{data: errorDynamicValue(fmt.Errorf("failed to join values: %w", err))}
+	}
+
+	return Source{data: newDynamicValue(strings.Join(values, sep))}
+}
+
+// SourceAs returns the value of s as T, applying the same conversions as ColumnAs.
+// It returns an error if the value holds an error or is not a T.
+// It is meant to read the elements passed by Each, i.e. to sum a numeric field.
+func SourceAs[T any](s Source) (T, error) {
+	if err := s.data.Error(); err != nil {
+		return *new(T), err
+	}
+
+	value, ok := valueAs[T](s.data)
+	if !ok {
+		return *new(T), fmt.Errorf("value type %v mismatch with %v", s.data.dataType, getDataTypeFromType[T]())
+	}
+	return value, nil
+}
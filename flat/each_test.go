@@ -0,0 +1,177 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// errNotFound is returned by the functions of the tests.
+var errNotFound = errors.New("not found")
+
+// TestEachInFlattener tests summing and joining the elements of arrays inside a flattener
+func TestEachInFlattener(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"id": 1, "items": [{"total": 10.5}, {"total": 2.25}], "tags": [{"name": "new"}, {"name": "sale"}]},
+		{"id": 2, "items": [], "tags": [{"name": "gift"}, {"label": "no name"}]},
+		{"id": 3, "tags": null}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+
+		var total float64
+		err := s.Key("items").Each(func(_ int, item Source) error {
+			value, err := SourceAs[float64](item.Key("total"))
+			total += value
+			return err
+		})
+		if err != nil {
+			d.Col("total", Source{data: errorDynamicValue(err)})
+		} else {
+			d.Col("total", FixValue(total))
+		}
+
+		d.Col("tags", s.Key("tags").JoinKey(", ", "name"))
+	}
+
+	records, err := data.GetCSV(flattener).ExportRecords()
+	if err != nil {
+		t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+	}
+
+	want := [][]string{
+		{"id", "total", "tags"},
+		{"1", "12.75", "new, sale"},
+		{"2", "0", "gift"},
+		{"3", "0", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV.ExportRecords() = %q, want %q", records, want)
+	}
+}
+
+func TestEach(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		want    []string
+		wantErr error
+	}{
+		{name: "array", data: newDynamicValue([]any{"a", 1, nil}), want: []string{"0:a", "1:1", "2:"}},
+		{name: "array of objects", data: newDynamicValue([]map[string]any{{"k": "x"}, {"k": "y"}}), want: []string{`0:{"k":"x"}`, `1:{"k":"y"}`}},
+		{name: "empty", data: newDynamicValue([]any{}), want: nil},
+		{name: "null", data: DynamicValueNull, want: nil},
+		{name: "missing", data: dynamicValueMissing, want: nil},
+		{name: "object", data: newDynamicValue(map[string]any{"k": "v"}), wantErr: ErrNotArray},
+		{name: "string", data: newDynamicValue("a,b"), wantErr: ErrNotArray},
+		{name: "error", data: errorDynamicValue(errNotFound), wantErr: errNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := Source{data: tt.data}.Each(func(i int, elem Source) error {
+				value, err := elem.strVal()
+				got = append(got, fmt.Sprintf("%d:%s", i, value))
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Source.Each() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Source.Each() elements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var typeErr *ArrayTypeError
+	err := Source{data: newDynamicValue(42)}.Each(func(int, Source) error { return nil })
+	if !errors.As(err, &typeErr) || typeErr.DataType != DataTypeInt {
+		t.Errorf("Source.Each() error = %v, want an ArrayTypeError for int", err)
+	}
+}
+
+func TestEachStopsOnError(t *testing.T) {
+	var calls int
+	err := Source{data: newDynamicValue([]any{1, 2, 3})}.Each(func(i int, _ Source) error {
+		calls++
+		if i == 1 {
+			return errNotFound
+		}
+		return nil
+	})
+	if !errors.Is(err, errNotFound) || err.Error() != "element 1: not found" {
+		t.Errorf("Source.Each() error = %v, want the error of element 1", err)
+	}
+	if calls != 2 {
+		t.Errorf("Source.Each() called fn %d times, want 2", calls)
+	}
+}
+
+func TestMap(t *testing.T) {
+	s := Source{data: newDynamicValue([]any{
+		map[string]any{"qty": 2},
+		map[string]any{"qty": 5},
+	})}
+
+	got, err := s.Map(func(elem Source) (string, error) {
+		qty, err := SourceAs[int](elem.Key("qty"))
+		return strconv.Itoa(qty * 10), err
+	})
+	if err != nil {
+		t.Fatalf("Source.Map() unexpected error = %v", err)
+	}
+	if want := []string{"20", "50"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Source.Map() = %q, want %q", got, want)
+	}
+
+	got, err = s.Map(func(elem Source) (string, error) {
+		_, err := SourceAs[string](elem.Key("qty"))
+		return "", err
+	})
+	if err == nil || got != nil {
+		t.Errorf("Source.Map() = %q, %v, want the type mismatch error", got, err)
+	}
+
+	if _, err := FixValue("not an array").Map(func(Source) (string, error) { return "", nil }); !errors.Is(err, ErrNotArray) {
+		t.Errorf("Source.Map() error = %v, want ErrNotArray", err)
+	}
+}
+
+func TestJoinKey(t *testing.T) {
+	users := newDynamicValue([]any{
+		map[string]any{"name": "John", "address": map[string]any{"city": "Paris"}},
+		map[string]any{"name": "Jane", "address": map[string]any{"city": nil}},
+		map[string]any{"name": "Bob", "address": map[string]any{"city": "Rome"}},
+	})
+
+	tests := []struct {
+		name    string
+		data    *DynamicValue
+		sep     string
+		keys    []string
+		want    string
+		wantErr error
+	}{
+		{name: "key", data: users, sep: ", ", keys: []string{"name"}, want: "John, Jane, Bob"},
+		{name: "nested key", data: users, sep: "|", keys: []string{"address", "city"}, want: "Paris|Rome"},
+		{name: "elements", data: newDynamicValue([]any{"a", 1, nil, true}), sep: "-", want: "a-1-true"},
+		{name: "null", data: DynamicValueNull, sep: ",", keys: []string{"name"}, want: ""},
+		{name: "object", data: newDynamicValue(map[string]any{"name": "John"}), sep: ",", keys: []string{"name"}, wantErr: ErrNotArray},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source{data: tt.data}.JoinKey(tt.sep, tt.keys...).strVal()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Source.JoinKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("Source.JoinKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package flat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Cell is a value of a row passed to a RowEncoder.
+type Cell struct {
+	// Value is the cell as written to a CSV, after applying formatters, defaults, placeholders and
+	// the sanitization options.
+	Value string
+	// Data is the typed value of the column after applying formatters, it is null if the column is missing.
+	Data *DynamicValue
+}
+
+// RowEncoder encodes the rows of an export into an output format.
+// The export calls WriteHeader once before the first row, WriteRow for every row, and Flush once at the end.
+// Headers and rows are not written if the data has no rows.
+type RowEncoder interface {
+	// WriteHeader writes the header line with the names of the output columns.
+	WriteHeader(headers []string) error
+	// WriteRow writes a row with a cell for each output column, in the same order as the headers.
+	WriteRow(cells []Cell) error
+	// Flush writes any buffered data and ends the output.
+	Flush() error
+}
+
+// WithEncoder encodes the output of each writer using the RowEncoder created by newEncoder,
+// instead of encoding it as CSV. Splitters are checked before encoding, so they work with any encoder.
+// If newEncoder is nil, the rows are encoded as CSV.
+func WithEncoder(newEncoder func(w io.Writer) RowEncoder) ExportOption {
+	return func(o *exportOptions) {
+		if newEncoder == nil {
+			newEncoder = NewCSVEncoder
+		}
+		o.newEncoder = newEncoder
+	}
+}
+
+// csvEncoder implements the RowEncoder interface writing the rows as CSV records.
+type csvEncoder struct {
+	writer *csv.Writer
+	record []string // reused across rows, the csv.Writer does not keep it
+}
+
+// NewCSVEncoder creates a RowEncoder that writes the header and the cell values as CSV records to w.
+// It is the encoder used by default.
+func NewCSVEncoder(w io.Writer) RowEncoder {
+	return &csvEncoder{writer: csv.NewWriter(w)}
+}
+
+// WriteHeader writes the headers as a CSV record.
+func (e *csvEncoder) WriteHeader(headers []string) error {
+	return e.writer.Write(headers)
+}
+
+// WriteRow writes the cell values as a CSV record.
+func (e *csvEncoder) WriteRow(cells []Cell) error {
+	e.record = e.record[:0]
+	for _, cell := range cells {
+		e.record = append(e.record, cell.Value)
+	}
+	return e.writer.Write(e.record)
+}
+
+// Flush writes the buffered records to the writer.
+func (e *csvEncoder) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonArrayEncoder implements the RowEncoder interface writing the rows as a JSON array of objects.
+type jsonArrayEncoder struct {
+	w       io.Writer
+	headers []string
+	rows    int
+}
+
+// NewJSONArrayEncoder creates a RowEncoder that writes the rows to w as a JSON array of objects keyed
+// by header, one object per line. Cells keep the JSON type of their data, so numbers and booleans are not
+// quoted, while cells without data, i.e. filled with a default value, are written as strings.
+// Cells without data or value are written as null. An export without rows writes an empty array.
+// Time values are written as strings with the layout of WithTimeFormat, and & < > are not escaped.
+func NewJSONArrayEncoder(w io.Writer) RowEncoder {
+	return &jsonArrayEncoder{w: w}
+}
+
+// WriteHeader keeps the headers used as keys of the objects.
+func (e *jsonArrayEncoder) WriteHeader(headers []string) error {
+	e.headers = slices.Clone(headers)
+	return nil
+}
+
+// WriteRow writes the cells as an object of the array.
+func (e *jsonArrayEncoder) WriteRow(cells []Cell) error {
+	buf := []byte(",\n{")
+	if e.rows == 0 {
+		buf = []byte("[\n{")
+	}
+
+	for i, cell := range cells {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		key, err := marshalJSONUnescaped(e.headers[i])
+		if err != nil {
+			return fmt.Errorf("failed to encode header %s: %w", e.headers[i], err)
+		}
+
+		value, err := cellJSON(cell)
+		if err != nil {
+			return fmt.Errorf("failed to encode column %s: %w", e.headers[i], err)
+		}
+
+		buf = append(append(append(buf, key...), ':'), value...)
+	}
+	buf = append(buf, '}')
+
+	e.rows++
+	_, err := e.w.Write(buf)
+	return err
+}
+
+// cellJSON returns the JSON encoding of a cell, using its data when available.
+// Like the JSON cells of the CSV output, & < > are not escaped. Time values are written as rendered,
+// with the layout and location of WithTimeFormat.
+func cellJSON(cell Cell) ([]byte, error) {
+	if data := cell.Data; data != nil && !data.IsNull() {
+		switch {
+		case data.err != nil:
+			return nil, fmt.Errorf("data contains error: %w", data.err)
+		case data.dataType == DataTypeStreamOfObjects:
+			return nil, fmt.Errorf("data is a stream of objects, cannot be encoded as JSON")
+		case data.dataType != DataTypeTime:
+			return marshalJSONUnescaped(data.value)
+		}
+	}
+	if cell.Value == "" {
+		return []byte("null"), nil
+	}
+	return marshalJSONUnescaped(cell.Value)
+}
+
+// marshalJSONUnescaped returns the JSON encoding of v without escaping & < >.
+func marshalJSONUnescaped(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Flush ends the array.
+func (e *jsonArrayEncoder) Flush() error {
+	end := "\n]\n"
+	if e.rows == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// recordCollector implements the RowEncoder interface keeping the records in memory.
+type recordCollector struct {
+	records [][]string
+}
+
+// WriteHeader appends a copy of the headers to the collected records.
+func (c *recordCollector) WriteHeader(headers []string) error {
+	c.records = append(c.records, slices.Clone(headers))
+	return nil
+}
+
+// WriteRow appends the cell values to the collected records.
+func (c *recordCollector) WriteRow(cells []Cell) error {
+	c.records = append(c.records, cellValues(cells))
+	return nil
+}
+
+// Flush does nothing, records are collected as they are written.
+func (c *recordCollector) Flush() error {
+	return nil
+}
+
+// cellValues returns the values of the cells.
+func cellValues(cells []Cell) []string {
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		values[i] = cell.Value
+	}
+	return values
+}
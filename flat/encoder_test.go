@@ -0,0 +1,202 @@
+package flat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEncoder records the headers and cells it receives.
+type testEncoder struct {
+	headers [][]string
+	rows    [][]Cell
+	flushed int
+}
+
+func (e *testEncoder) WriteHeader(headers []string) error {
+	e.headers = append(e.headers, headers)
+	return nil
+}
+
+func (e *testEncoder) WriteRow(cells []Cell) error {
+	e.rows = append(e.rows, cells)
+	return nil
+}
+
+func (e *testEncoder) Flush() error {
+	e.flushed++
+	return nil
+}
+
+func TestCustomEncoder(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"name": "John", "age": 30, "active": true, "tags": ["a"]},
+		{"name": "Jane", "age": 25, "active": false}
+	]`))
+
+	// Encoders are created in the order of the writers
+	var encoders []*testEncoder
+	newEncoder := func(io.Writer) RowEncoder {
+		e := &testEncoder{}
+		encoders = append(encoders, e)
+		return e
+	}
+
+	var adults, young bytes.Buffer
+	err := data.GetCSV(func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+		d.Col("active", s.Key("active"))
+		d.Col("tags", s.Key("tags"))
+	}, WithEncoder(newEncoder), WithDefault("tags", "none")).ExportSplit(
+		Split(&adults, "age", func(v int) bool { return v >= 30 }),
+		SplitAnd(&young, NewSplitter("age", func(v int) bool { return v < 30 })),
+	)
+	if err != nil {
+		t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+	}
+
+	if len(encoders) != 2 {
+		t.Fatalf("created %d encoders, want one per writer", len(encoders))
+	}
+	if adults.Len() != 0 || young.Len() != 0 {
+		t.Errorf("custom encoder output written as CSV: %q, %q", adults.String(), young.String())
+	}
+
+	tests := []struct {
+		name      string
+		encoder   *testEncoder
+		wantTypes []DataType
+		want      []string
+	}{
+		{
+			name:      "adults",
+			encoder:   encoders[0],
+			wantTypes: []DataType{DataTypeString, DataTypeNumber, DataTypeBoolean, DataTypeArray},
+			want:      []string{"John", "30", "true", `["a"]`},
+		},
+		{
+			name:      "young",
+			encoder:   encoders[1],
+			wantTypes: []DataType{DataTypeString, DataTypeNumber, DataTypeBoolean, DataTypeNull},
+			want:      []string{"Jane", "25", "false", "none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.encoder.headers) != 1 || strings.Join(tt.encoder.headers[0], ",") != "name,age,active,tags" {
+				t.Errorf("WriteHeader() calls = %q, want one call with the headers", tt.encoder.headers)
+			}
+			if tt.encoder.flushed != 1 {
+				t.Errorf("Flush() calls = %d, want 1", tt.encoder.flushed)
+			}
+			if len(tt.encoder.rows) != 1 {
+				t.Fatalf("WriteRow() calls = %d, want 1", len(tt.encoder.rows))
+			}
+
+			for i, cell := range tt.encoder.rows[0] {
+				if cell.Value != tt.want[i] {
+					t.Errorf("cell %d value = %q, want %q", i, cell.Value, tt.want[i])
+				}
+				if cell.Data.DataType() != tt.wantTypes[i] {
+					t.Errorf("cell %d type = %v, want %v", i, cell.Data.DataType(), tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestJSONArrayEncoder(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"name": "John", "age": 30, "price": 0.10, "meta": {"x": 1}},
+		{"name": "Jane \"J\"", "age": null}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+		d.Col("price", s.Key("price"))
+		d.Col("meta", s.Key("meta"))
+	}
+
+	t.Run("typed values", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := data.GetCSV(flattener, WithEncoder(NewJSONArrayEncoder), WithDefault("price", "n/a")).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+
+		want := "[\n" +
+			`{"name":"John","age":30,"price":0.10,"meta":{"x":1}},` + "\n" +
+			`{"name":"Jane \"J\"","age":null,"price":"n/a","meta":null}` + "\n]\n"
+		if buf.String() != want {
+			t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+		}
+
+		var decoded []map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Errorf("JSON array encoder output is not valid JSON: %v", err)
+		}
+	})
+
+	t.Run("html characters and time layout", func(t *testing.T) {
+		created := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+		data := newDynamicValue([]map[string]any{
+			{"name": "AT&T <T>", "meta": map[string]any{"q": "a&b"}, "created": created},
+		})
+
+		var buf bytes.Buffer
+		err := data.GetCSV(func(s Source, d Dest) {
+			d.Col("name & <id>", s.Key("name"))
+			d.Col("meta", s.Key("meta"))
+			d.Col("created", s.Key("created"))
+		}, WithEncoder(NewJSONArrayEncoder), WithTimeFormat("2006-01-02 15:04", time.FixedZone("EST", -5*3600))).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+
+		want := "[\n" + `{"name & <id>":"AT&T <T>","meta":{"q":"a&b"},"created":"2024-03-15 05:30"}` + "\n]\n"
+		if buf.String() != want {
+			t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := newDynamicValue([]any{}).GetCSV(flattener, WithEncoder(NewJSONArrayEncoder)).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+		if buf.String() != "[]\n" {
+			t.Errorf("CSV.Export() = %q, want empty array", buf.String())
+		}
+	})
+}
+
+// failingEncoder fails to write rows.
+type failingEncoder struct {
+	testEncoder
+	err error
+}
+
+func (e *failingEncoder) WriteRow(_ []Cell) error {
+	return e.err
+}
+
+func TestEncoderError(t *testing.T) {
+	encodeErr := errors.New("encode failed")
+	data := newDynamicValue([]map[string]any{{"name": "John"}})
+
+	err := data.GetCSV(func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+	}, WithEncoder(func(io.Writer) RowEncoder { return &failingEncoder{err: encodeErr} })).Export(&bytes.Buffer{})
+
+	var exportErr *ExportError
+	if !errors.Is(err, encodeErr) || !errors.As(err, &exportErr) || exportErr.Writer != 0 {
+		t.Errorf("CSV.Export() error = %v, want ExportError for writer 0 wrapping %v", err, encodeErr)
+	}
+}
@@ -0,0 +1,81 @@
+// Package flattest provides helpers to test the CSV output of the flat package.
+package flattest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// AssertCSVEqual checks that got holds the same CSV records as want, headers first,
+// as returned by CSV.ExportRecords. On mismatch it reports every differing cell with its
+// row and column header, and the records missing or unexpected at the end of got.
+// It returns true if the records are equal.
+func AssertCSVEqual(t testing.TB, want, got [][]string) bool {
+	t.Helper()
+
+	diffs := diffRecords(want, got)
+	if len(diffs) == 0 {
+		return true
+	}
+
+	t.Errorf("CSV records mismatch (-want +got):\n%s", strings.Join(diffs, "\n"))
+	return false
+}
+
+// diffRecords returns a description of each difference between the want and got records.
+func diffRecords(want, got [][]string) []string {
+	var headers []string
+	if len(want) > 0 {
+		headers = want[0]
+	}
+
+	var diffs []string
+	for i := 0; i < len(want) || i < len(got); i++ {
+		switch {
+		case i >= len(got):
+			diffs = append(diffs, fmt.Sprintf("%s: - %q", recordName(i), want[i]))
+		case i >= len(want):
+			diffs = append(diffs, fmt.Sprintf("%s: + %q", recordName(i), got[i]))
+		default:
+			diffs = append(diffs, diffRecord(i, headers, want[i], got[i])...)
+		}
+	}
+
+	return diffs
+}
+
+// diffRecord returns a description of each differing cell of a record.
+func diffRecord(index int, headers, want, got []string) []string {
+	var diffs []string
+	for j := 0; j < len(want) || j < len(got); j++ {
+		wantCell, gotCell := cell(want, j), cell(got, j)
+		if wantCell == gotCell {
+			continue
+		}
+
+		column := fmt.Sprintf("column %d", j)
+		if j < len(headers) && index > 0 {
+			column = fmt.Sprintf("column %d (%s)", j, headers[j])
+		}
+		diffs = append(diffs, fmt.Sprintf("%s, %s:\n\t- %s\n\t+ %s", recordName(index), column, wantCell, gotCell))
+	}
+
+	return diffs
+}
+
+// cell returns the quoted value of the cell at index j, or <missing> if the record is shorter.
+func cell(record []string, j int) string {
+	if j >= len(record) {
+		return "<missing>"
+	}
+	return fmt.Sprintf("%q", record[j])
+}
+
+// recordName names a record by its index, the first record being the header.
+func recordName(index int) string {
+	if index == 0 {
+		return "header"
+	}
+	return fmt.Sprintf("row %d", index)
+}
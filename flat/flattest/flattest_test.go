@@ -0,0 +1,86 @@
+package flattest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeTB records the errors reported through testing.TB.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertCSVEqual(t *testing.T) {
+	want := [][]string{{"name", "age"}, {"John", "30"}, {"Jane", "25"}}
+
+	tests := []struct {
+		name      string
+		got       [][]string
+		wantOK    bool
+		wantDiffs []string
+	}{
+		{
+			name:   "equal",
+			got:    [][]string{{"name", "age"}, {"John", "30"}, {"Jane", "25"}},
+			wantOK: true,
+		},
+		{
+			name:      "different cell",
+			got:       [][]string{{"name", "age"}, {"John", "31"}, {"Jane", "25"}},
+			wantDiffs: []string{"row 1, column 1 (age):\n\t- \"30\"\n\t+ \"31\""},
+		},
+		{
+			name:      "different header",
+			got:       [][]string{{"age", "name"}, {"John", "30"}, {"Jane", "25"}},
+			wantDiffs: []string{"header, column 0:", "header, column 1:"},
+		},
+		{
+			name:      "missing cell",
+			got:       [][]string{{"name", "age"}, {"John"}, {"Jane", "25"}},
+			wantDiffs: []string{"row 1, column 1 (age):\n\t- \"30\"\n\t+ <missing>"},
+		},
+		{
+			name:      "missing row",
+			got:       [][]string{{"name", "age"}, {"John", "30"}},
+			wantDiffs: []string{"row 2: - [\"Jane\" \"25\"]"},
+		},
+		{
+			name:      "unexpected row",
+			got:       [][]string{{"name", "age"}, {"John", "30"}, {"Jane", "25"}, {"Bob", "35"}},
+			wantDiffs: []string{"row 3: + [\"Bob\" \"35\"]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{}
+			if ok := AssertCSVEqual(tb, want, tt.got); ok != tt.wantOK {
+				t.Errorf("AssertCSVEqual() = %v, want %v", ok, tt.wantOK)
+			}
+
+			if tt.wantOK {
+				if len(tb.errors) > 0 {
+					t.Errorf("AssertCSVEqual() reported %q, want no errors", tb.errors)
+				}
+				return
+			}
+
+			if len(tb.errors) != 1 {
+				t.Fatalf("AssertCSVEqual() reported %d errors, want 1", len(tb.errors))
+			}
+			for _, diff := range tt.wantDiffs {
+				if !strings.Contains(tb.errors[0], diff) {
+					t.Errorf("AssertCSVEqual() reported %q, want it to contain %q", tb.errors[0], diff)
+				}
+			}
+		})
+	}
+}
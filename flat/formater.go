@@ -1,7 +1,14 @@
 package flat
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stocktwits/go-infrastructure/v2/pricefmt"
 )
 
 // Formatter is a function type that formats a DynamicValue.
@@ -19,11 +26,12 @@ func NewFormatter[T, S any](f func(T) (S, error)) Formatter {
 			return nil, fmt.Errorf("invalid data types: %T to %T", *new(T), *new(S))
 		}
 
-		if getDataTypeFromType[T]() != dv.DataType() {
+		rawValue, ok := valueAs[T](dv)
+		if !ok {
 			return nil, fmt.Errorf("formatter function type mismatch with data type")
 		}
 
-		newVal, err := f(dv.value.(T))
+		newVal, err := f(rawValue)
 		if err != nil {
 			return nil, fmt.Errorf("error formatting data: %w", err)
 		}
@@ -39,3 +47,264 @@ func NewSafeFormatter[T, S any](f func(T) S) Formatter {
 		return f(d), nil
 	})
 }
+
+// Chain creates a Formatter that applies the provided formatters from left to right,
+// passing the result of each formatter to the next one.
+// Null values are not skipped, every formatter receives them so a Default can substitute them at any step.
+// If a formatter returns an error the chain stops and the error is returned.
+func Chain(fs ...Formatter) Formatter {
+	return func(dv *DynamicValue) (*DynamicValue, error) {
+		for i, f := range fs {
+			if f == nil {
+				continue
+			}
+
+			newDv, err := f(dv)
+			if err != nil {
+				return nil, fmt.Errorf("error in chained formatter %d: %w", i, err)
+			}
+
+			if newDv == nil {
+				newDv = DynamicValueNull
+			}
+			dv = newDv
+		}
+
+		return dv, nil
+	}
+}
+
+// Default creates a Formatter that applies f and substitutes the fallback value when f returns an error
+// or when the input value is null. If f is nil, only null values are substituted.
+// Values holding an error from a previous step are returned untouched.
+func Default(f Formatter, fallback any) Formatter {
+	fallbackValue := newDynamicValue(fallback)
+
+	return func(dv *DynamicValue) (*DynamicValue, error) {
+		if dv == nil || (dv.DataType() == DataTypeNull && dv.Error() == nil) {
+			return fallbackValue, nil
+		}
+
+		if f == nil || dv.Error() != nil {
+			return dv, nil
+		}
+
+		newDv, err := f(dv)
+		if err != nil {
+			return fallbackValue, nil
+		}
+
+		return newDv, nil
+	}
+}
+
+// DateFormatter creates a Formatter that parses string dates using the first matching layout in inLayouts
+// and renders them using outLayout. If inLayouts is empty, time.RFC3339 is used.
+// Values that do not match any layout produce an error.
+func DateFormatter(inLayouts []string, outLayout string) Formatter {
+	if len(inLayouts) == 0 {
+		inLayouts = []string{time.RFC3339}
+	}
+
+	return NewFormatter(func(v string) (string, error) {
+		for _, layout := range inLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.Format(outLayout), nil
+			}
+		}
+		return "", fmt.Errorf("cannot parse date %q with layouts %v", v, inLayouts)
+	})
+}
+
+// PriceFormatter creates a Formatter that renders prices as display strings using pricefmt.
+// It accepts float, int, numeric string and JSON number values.
+// Small decimals that qualify for subscript formatting are rendered with subscript zeros (i.e. $0.0₅1234).
+// Values that cannot be parsed as prices produce an error.
+func PriceFormatter(currencyCode string) Formatter {
+	return func(dv *DynamicValue) (*DynamicValue, error) {
+		if dv == nil || dv.value == nil {
+			return dv, nil
+		}
+
+		price, err := priceDecimal(dv)
+		if err != nil {
+			return nil, err
+		}
+
+		formatted, err := pricefmt.FormatWithCurrency(price, currencyCode)
+		if err != nil {
+			return nil, fmt.Errorf("error formatting price: %w", err)
+		}
+
+		return newDynamicValue(priceDisplayString(formatted)), nil
+	}
+}
+
+// PriceOption configures a Formatter created by PriceColumn.
+type PriceOption func(*priceOptions)
+
+// priceOptions holds the configuration of a PriceColumn formatter.
+type priceOptions struct {
+	precision       int
+	subscript       bool
+	subscriptLength int
+	valueLength     int
+}
+
+// PricePrecision renders prices with exactly decimals digits after the decimal point, rounding half away from zero.
+func PricePrecision(decimals int) PriceOption {
+	return func(o *priceOptions) {
+		o.precision = decimals
+	}
+}
+
+// PriceSubscript renders small decimals with at least subscriptLength zeros after the decimal point using
+// subscript zeros followed by up to valueLength digits, like PriceFormatter, instead of a plain decimal.
+func PriceSubscript(subscriptLength, valueLength int) PriceOption {
+	return func(o *priceOptions) {
+		o.subscript = true
+		o.subscriptLength = subscriptLength
+		o.valueLength = valueLength
+	}
+}
+
+// PriceColumn creates a Formatter that renders prices as the display strings of a CSV column using pricefmt:
+// the currency symbol followed by the plain decimal value, i.e. -$0.0000012.
+// It accepts float, int, numeric string and JSON number values.
+// Use PricePrecision to render a fixed number of decimals and PriceSubscript to keep the subscript notation.
+// Values that cannot be parsed as prices produce an error, reported by the export with its row and column.
+func PriceColumn(currencyCode string, opts ...PriceOption) Formatter {
+	o := priceOptions{precision: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(dv *DynamicValue) (*DynamicValue, error) {
+		if dv == nil || dv.value == nil {
+			return dv, nil
+		}
+
+		price, err := priceDecimal(dv)
+		if err != nil {
+			return nil, err
+		}
+
+		var formatted *pricefmt.PriceFormatted
+		if o.subscript {
+			formatted, err = pricefmt.FormatWithOptions(price, currencyCode, o.subscriptLength, o.valueLength)
+		} else {
+			formatted, err = pricefmt.FormatWithCurrency(price, currencyCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error formatting price: %w", err)
+		}
+
+		if o.subscript && formatted.UseSubscript {
+			return newDynamicValue(priceDisplayString(formatted)), nil
+		}
+
+		value := price.Abs().String()
+		if o.precision >= 0 {
+			price = price.Round(int32(o.precision))
+			value = price.Abs().StringFixed(int32(o.precision))
+		}
+
+		// prices rounded to zero are not negative
+		sign := ""
+		if price.IsNegative() {
+			sign = "-"
+		}
+
+		return newDynamicValue(sign + formatted.CurrencyString + value), nil
+	}
+}
+
+// priceDecimal returns the price held by a float, int, numeric string or JSON number value.
+func priceDecimal(dv *DynamicValue) (decimal.Decimal, error) {
+	var (
+		price decimal.Decimal
+		err   error
+	)
+
+	switch v := dv.value.(type) {
+	case float64:
+		price = decimal.NewFromFloat(v)
+	case int:
+		price = decimal.NewFromInt(int64(v))
+	case string:
+		price, err = decimal.NewFromString(v)
+	case json.Number:
+		price, err = decimal.NewFromString(v.String())
+	default:
+		return decimal.Decimal{}, fmt.Errorf("price formatter does not support data type %v", dv.DataType())
+	}
+
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("error formatting price: %w", err)
+	}
+
+	return price, nil
+}
+
+// priceDisplayString renders a formatted price as it is shown to the user.
+func priceDisplayString(p *pricefmt.PriceFormatted) string {
+	sign := ""
+	if p.IsNegative {
+		sign = "-"
+	}
+
+	if p.UseSubscript && p.ZerosAfterDecimal != nil && p.AfterZerosValue != nil {
+		return fmt.Sprintf("%s%s0.0%s%d", sign, p.CurrencyString, subscriptDigits(*p.ZerosAfterDecimal), *p.AfterZerosValue)
+	}
+
+	return sign + p.CurrencyString + strings.TrimPrefix(p.RawValue, "-")
+}
+
+// subscriptDigits returns the unicode subscript representation of n.
+func subscriptDigits(n int) string {
+	var sb strings.Builder
+	for _, r := range strconv.Itoa(n) {
+		sb.WriteRune('₀' + (r - '0'))
+	}
+	return sb.String()
+}
+
+// BoolFormatter creates a Formatter that renders boolean values using the provided labels.
+// Non boolean values produce an error.
+func BoolFormatter(trueLabel, falseLabel string) Formatter {
+	return NewSafeFormatter(func(v bool) string {
+		if v {
+			return trueLabel
+		}
+		return falseLabel
+	})
+}
+
+// MapFormatter creates a Formatter that replaces values with their label in labels.
+// String, numeric and boolean values are looked up by their string representation,
+// values without a label are rendered as defaultLabel.
+// Objects and arrays produce an error.
+func MapFormatter(labels map[string]string, defaultLabel string) Formatter {
+	return func(dv *DynamicValue) (*DynamicValue, error) {
+		if dv == nil || dv.value == nil {
+			return dv, nil
+		}
+
+		switch dv.DataType() {
+		case DataTypeString, DataTypeFloat, DataTypeInt, DataTypeBoolean, DataTypeNumber:
+		default:
+			return nil, fmt.Errorf("map formatter does not support data type %v", dv.DataType())
+		}
+
+		key, err := dv.strVal()
+		if err != nil {
+			return nil, fmt.Errorf("error getting map key: %w", err)
+		}
+
+		if label, ok := labels[key]; ok {
+			return newDynamicValue(label), nil
+		}
+
+		return newDynamicValue(defaultLabel), nil
+	}
+}
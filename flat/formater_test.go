@@ -0,0 +1,257 @@
+package flat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// formatterTestCase holds a single input/output expectation for a Formatter.
+type formatterTestCase struct {
+	name    string
+	input   any
+	want    any
+	wantErr bool
+}
+
+// runFormatterTests applies the formatter to each test case input and checks the result.
+func runFormatterTests(t *testing.T, f Formatter, tests []formatterTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f(newDynamicValue(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Formatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.value != tt.want {
+				t.Errorf("Formatter() = %#v, want %#v", got.value, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateFormatter(t *testing.T) {
+	runFormatterTests(t, DateFormatter(nil, "2006-01-02"), []formatterTestCase{
+		{name: "rfc3339", input: "2024-03-15T10:30:00Z", want: "2024-03-15"},
+		{name: "rfc3339 with offset", input: "2024-03-15T23:30:00-05:00", want: "2024-03-15"},
+		{name: "null passthrough", input: nil, want: nil},
+		{name: "malformed date", input: "15/03/2024", wantErr: true},
+		{name: "non string", input: float64(20240315), wantErr: true},
+	})
+
+	t.Run("multiple layouts", func(t *testing.T) {
+		runFormatterTests(t, DateFormatter([]string{"2006-01-02", "02/01/2006"}, "Jan 2, 2006"), []formatterTestCase{
+			{name: "first layout", input: "2024-03-15", want: "Mar 15, 2024"},
+			{name: "second layout", input: "15/03/2024", want: "Mar 15, 2024"},
+			{name: "no layout matches", input: "2024-03-15T10:30:00Z", wantErr: true},
+		})
+	})
+}
+
+func TestPriceFormatter(t *testing.T) {
+	runFormatterTests(t, PriceFormatter("USD"), []formatterTestCase{
+		{name: "float", input: 123.45, want: "$123.45"},
+		{name: "int", input: 42, want: "$42"},
+		{name: "string", input: "0.5", want: "$0.5"},
+		{name: "json number", input: json.Number("1234.5678"), want: "$1234.5678"},
+		{name: "negative", input: -19.99, want: "-$19.99"},
+		{name: "small decimal without subscript", input: 0.0012, want: "$0.0012"},
+		{name: "small decimal with subscript", input: "0.00000123456", want: "$0.0₅1234"},
+		{name: "negative subscript", input: "-0.000000000012", want: "-$0.0₁₀12"},
+		{name: "null passthrough", input: nil, want: nil},
+		{name: "malformed string", input: "12,34", wantErr: true},
+		{name: "boolean", input: true, wantErr: true},
+	})
+
+	t.Run("other currency", func(t *testing.T) {
+		runFormatterTests(t, PriceFormatter("EUR"), []formatterTestCase{
+			{name: "float", input: 9.5, want: "€9.5"},
+		})
+	})
+}
+
+func TestPriceColumn(t *testing.T) {
+	runFormatterTests(t, PriceColumn("USD"), []formatterTestCase{
+		{name: "float", input: 123.45, want: "$123.45"},
+		{name: "int", input: 42, want: "$42"},
+		{name: "json number", input: json.Number("1234.5678"), want: "$1234.5678"},
+		{name: "negative", input: -19.99, want: "-$19.99"},
+		{name: "small decimal collapsed", input: "0.00000123456", want: "$0.00000123456"},
+		{name: "null passthrough", input: nil, want: nil},
+		{name: "malformed string", input: "12,34", wantErr: true},
+		{name: "boolean", input: true, wantErr: true},
+	})
+
+	t.Run("precision", func(t *testing.T) {
+		runFormatterTests(t, PriceColumn("GBP", PricePrecision(2)), []formatterTestCase{
+			{name: "padded", input: 9.5, want: "£9.50"},
+			{name: "rounded", input: "1.005", want: "£1.01"},
+			{name: "int", input: 3, want: "£3.00"},
+			{name: "negative rounded to zero", input: -0.001, want: "£0.00"},
+		})
+	})
+
+	t.Run("subscript", func(t *testing.T) {
+		runFormatterTests(t, PriceColumn("USD", PriceSubscript(3, 2), PricePrecision(2)), []formatterTestCase{
+			{name: "subscript", input: "-0.000123", want: "-$0.0₃12"},
+			{name: "below threshold", input: 0.0123, want: "$0.01"},
+		})
+	})
+}
+
+func TestPriceColumnExport(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"symbol": "AAPL", "price": 185.1},
+		{"symbol": "BRK.A", "price": 621000},
+		{"symbol": "SHIB", "price": json.Number("0.00000912")},
+		{"symbol": "TSLA", "price": "-3.14159"},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("symbol", s.Key("symbol"))
+		d.ColFormatted("price", s.Key("price"), PriceColumn("USD", PricePrecision(4)))
+	}
+
+	records, err := data.GetCSV(flattener).ExportRecords()
+	if err != nil {
+		t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+	}
+	want := [][]string{
+		{"symbol", "price"},
+		{"AAPL", "$185.1000"},
+		{"BRK.A", "$621000.0000"},
+		{"SHIB", "$0.0000"},
+		{"TSLA", "-$3.1416"},
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("CSV.ExportRecords() = %q, want %q", records, want)
+	}
+
+	invalid := newDynamicValue([]map[string]any{
+		{"symbol": "AAPL", "price": 185.1},
+		{"symbol": "???", "price": "n/a"},
+	})
+	_, err = invalid.GetCSV(flattener).ExportRecords()
+
+	var exportErr *ExportError
+	if !errors.As(err, &exportErr) || exportErr.Row != 1 || exportErr.Column != "price" {
+		t.Errorf("CSV.ExportRecords() error = %v, want an ExportError for row 1, column price", err)
+	}
+}
+
+func TestBoolFormatter(t *testing.T) {
+	runFormatterTests(t, BoolFormatter("Yes", "No"), []formatterTestCase{
+		{name: "true", input: true, want: "Yes"},
+		{name: "false", input: false, want: "No"},
+		{name: "null passthrough", input: nil, want: nil},
+		{name: "string", input: "true", wantErr: true},
+	})
+}
+
+func TestMapFormatter(t *testing.T) {
+	labels := map[string]string{
+		"A":    "Active",
+		"1":    "One",
+		"true": "Enabled",
+	}
+
+	runFormatterTests(t, MapFormatter(labels, "Unknown"), []formatterTestCase{
+		{name: "string code", input: "A", want: "Active"},
+		{name: "int code", input: 1, want: "One"},
+		{name: "float code", input: float64(1), want: "One"},
+		{name: "json number code", input: json.Number("1"), want: "One"},
+		{name: "boolean code", input: true, want: "Enabled"},
+		{name: "unknown code", input: "Z", want: "Unknown"},
+		{name: "null passthrough", input: nil, want: nil},
+		{name: "object", input: map[string]any{"A": 1}, wantErr: true},
+	})
+}
+
+func TestChain(t *testing.T) {
+	parsePrice := NewFormatter(func(v string) (float64, error) {
+		return strconv.ParseFloat(v, 64)
+	})
+	bucket := NewSafeFormatter(func(v float64) string {
+		if v >= 100 {
+			return "high"
+		}
+		return "low"
+	})
+	label := MapFormatter(map[string]string{"high": "High price", "low": "Low price"}, "")
+
+	runFormatterTests(t, Chain(parsePrice, bucket, label), []formatterTestCase{
+		{name: "high", input: "150.5", want: "High price"},
+		{name: "low", input: "3", want: "Low price"},
+		{name: "null reaches every formatter", input: nil, want: nil},
+		{name: "error short circuits", input: "abc", wantErr: true},
+		{name: "type mismatch short circuits", input: true, wantErr: true},
+	})
+
+	t.Run("no formatters", func(t *testing.T) {
+		runFormatterTests(t, Chain(), []formatterTestCase{
+			{name: "identity", input: "value", want: "value"},
+		})
+	})
+
+	t.Run("nil formatters are skipped", func(t *testing.T) {
+		runFormatterTests(t, Chain(nil, BoolFormatter("Y", "N"), nil), []formatterTestCase{
+			{name: "bool", input: true, want: "Y"},
+		})
+	})
+
+	t.Run("default in chain substitutes null", func(t *testing.T) {
+		runFormatterTests(t, Chain(parsePrice, Default(bucket, "N/A")), []formatterTestCase{
+			{name: "value", input: "150", want: "high"},
+			{name: "null", input: nil, want: "N/A"},
+		})
+	})
+}
+
+func TestDefault(t *testing.T) {
+	runFormatterTests(t, Default(BoolFormatter("Yes", "No"), "N/A"), []formatterTestCase{
+		{name: "formatted", input: true, want: "Yes"},
+		{name: "null", input: nil, want: "N/A"},
+		{name: "formatter error", input: "not a bool", want: "N/A"},
+	})
+
+	t.Run("nil formatter", func(t *testing.T) {
+		runFormatterTests(t, Default(nil, 0), []formatterTestCase{
+			{name: "value untouched", input: "value", want: "value"},
+			{name: "null", input: nil, want: 0},
+		})
+	})
+
+	t.Run("error values are not masked", func(t *testing.T) {
+		dv := errorDynamicValue(fmt.Errorf("upstream error"))
+		got, err := Default(BoolFormatter("Yes", "No"), "N/A")(dv)
+		if err != nil {
+			t.Fatalf("Default() unexpected error = %v", err)
+		}
+		if got.Error() == nil {
+			t.Error("Default() masked an upstream error value")
+		}
+	})
+
+	t.Run("missing key renders fallback", func(t *testing.T) {
+		var buf bytes.Buffer
+		data := newDynamicValue([]map[string]any{{"name": "John", "city": "NYC"}, {"name": "Jane"}})
+		err := data.GetCSV(func(s Source, d Dest) {
+			d.Col("name", s.Key("name"))
+			d.ColFormatted("city", s.Key("city"), Default(nil, "N/A"))
+		}).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+
+		want := "name,city\nJohn,NYC\nJane,N/A\n"
+		if got := buf.String(); got != want {
+			t.Errorf("CSV.Export() = %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package flat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonArraySource holds a reader containing a single JSON array whose elements are streamed as rows.
+type jsonArraySource struct {
+	reader io.Reader
+}
+
+// StreamJSONArrayFromReader creates a new DynamicValue instance from a io.Reader containing a single JSON array.
+// Unlike ReadJSONFromReader, the array is not decoded into memory: its elements are decoded one at a time
+// while exporting, so the memory used stays constant regardless of the size of the array.
+// The DynamicValue is a stream of objects that can only be exported once.
+// A malformed array, i.e. a missing bracket or data after the array, makes the export fail.
+func StreamJSONArrayFromReader(r io.Reader) *DynamicValue {
+	return newDynamicValue(&jsonArraySource{reader: r})
+}
+
+// streamJSONArrayRows streams the elements of a JSON array as rows.
+func (t *CSV) streamJSONArrayRows(ctx context.Context, rows chan *row, source *jsonArraySource) error {
+	decoder := json.NewDecoder(source.reader)
+	decoder.UseNumber()
+
+	if err := expectDelim(decoder, '['); err != nil {
+		return fmt.Errorf("error decoding JSON array: %w", err)
+	}
+
+	withHeaders := true
+	for decoder.More() {
+		var item any
+		if err := decoder.Decode(&item); err != nil {
+			// The decoder cannot recover inside an array, so the error fails the export
+			return fmt.Errorf("error decoding JSON array element: %w", err)
+		}
+
+		if err := t.sendRow(ctx, rows, newDynamicValue(item), withHeaders); err != nil {
+			return err
+		}
+
+		withHeaders = false // Only write headers for the first item
+	}
+
+	if err := expectDelim(decoder, ']'); err != nil {
+		return fmt.Errorf("error decoding JSON array: %w", err)
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("error decoding JSON array: unexpected data after the array")
+	}
+
+	return nil
+}
+
+// expectDelim reads the next token of the decoder and checks that it is the delim delimiter.
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return fmt.Errorf("expected %v, got end of input", delim)
+	} else if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
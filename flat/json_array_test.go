@@ -0,0 +1,183 @@
+package flat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSyntheticArray writes a JSON array of n objects to w.
+func writeSyntheticArray(w io.Writer, n int) error {
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		sep := ","
+		if i == n-1 {
+			sep = ""
+		}
+		_, err := fmt.Fprintf(w, `{"id": %d, "name": "user %d", "price": %d.%02d, "tags": ["a", "b"], "meta": {"active": %t}}%s`+"\n",
+			i, i, i, i%100, i%2 == 0, sep)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// syntheticArrayReader returns a reader producing a JSON array of n objects without holding it in memory.
+func syntheticArrayReader(n int) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeSyntheticArray(pw, n))
+	}()
+	return pr
+}
+
+func syntheticArrayFlattener(s Source, d Dest) {
+	d.Col("id", s.Key("id"))
+	d.Col("name", s.Key("name"))
+	d.Col("price", s.Key("price"))
+	d.Col("first_tag", s.Key("tags").Idx(0))
+	d.Col("active", s.Key("meta", "active"))
+}
+
+// TestStreamJSONArrayMatchesInMemory tests that streaming a JSON array exports the same CSV as decoding it
+func TestStreamJSONArrayMatchesInMemory(t *testing.T) {
+	const n = 20000
+
+	var input bytes.Buffer
+	if err := writeSyntheticArray(&input, n); err != nil {
+		t.Fatalf("writeSyntheticArray() unexpected error = %v", err)
+	}
+
+	var inMemory, streamed bytes.Buffer
+	if err := ReadJSONFromReader(bytes.NewReader(input.Bytes())).GetCSV(syntheticArrayFlattener).Export(&inMemory); err != nil {
+		t.Fatalf("in-memory CSV.Export() unexpected error = %v", err)
+	}
+
+	data := StreamJSONArrayFromReader(syntheticArrayReader(n))
+	if data.DataType() != DataTypeStreamOfObjects {
+		t.Fatalf("StreamJSONArrayFromReader() type = %v, want %v", data.DataType(), DataTypeStreamOfObjects)
+	}
+	stats, err := data.GetCSV(syntheticArrayFlattener).ExportSplitWithStats(NoSplit(&streamed))
+	if err != nil {
+		t.Fatalf("streamed CSV.Export() unexpected error = %v", err)
+	}
+
+	if stats.RowsProcessed != n {
+		t.Errorf("RowsProcessed = %d, want %d", stats.RowsProcessed, n)
+	}
+	if streamed.String() != inMemory.String() {
+		t.Error("streamed CSV differs from the in-memory CSV")
+	}
+}
+
+func TestStreamJSONArrayFromReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "array of objects", input: `[{"id": 1}, {"id": 2}]`, want: "id\n1\n2\n"},
+		{name: "surrounding whitespace", input: "\n [ {\"id\": 1} ] \n", want: "id\n1\n"},
+		{name: "empty array", input: `[]`, want: ""},
+		{name: "empty input", input: ``, wantErr: true},
+		{name: "not an array", input: `{"id": 1}`, wantErr: true},
+		{name: "missing opening bracket", input: `{"id": 1}, {"id": 2}]`, wantErr: true},
+		{name: "missing closing bracket", input: `[{"id": 1}, {"id": 2}`, wantErr: true},
+		{name: "trailing garbage", input: `[{"id": 1}] {"id": 2}`, wantErr: true},
+		{name: "malformed element", input: `[{"id": 1}, {"id": }]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := StreamJSONArrayFromReader(strings.NewReader(tt.input)).GetCSV(func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+			}).Export(&buf)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CSV.Export() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// reportPeakHeap runs fn sampling the heap in use and reports the peak as the peak-heap-B metric.
+func reportPeakHeap(b *testing.B, fn func() error) {
+	b.Helper()
+
+	done := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		var max uint64
+		var stats runtime.MemStats
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapInuse > max {
+				max = stats.HeapInuse
+			}
+			select {
+			case <-done:
+				peak <- max
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	runtime.GC()
+	err := fn()
+	close(done)
+	b.ReportMetric(float64(<-peak), "peak-heap-B")
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkJSONArray(b *testing.B) {
+	const n = 10000
+
+	var input bytes.Buffer
+	if err := writeSyntheticArray(&input, n); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("in memory", func(b *testing.B) {
+		b.ReportAllocs()
+		reportPeakHeap(b, func() error {
+			for i := 0; i < b.N; i++ {
+				err := ReadJSONFromReader(bytes.NewReader(input.Bytes())).GetCSV(syntheticArrayFlattener).Export(io.Discard)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	b.Run("streamed", func(b *testing.B) {
+		b.ReportAllocs()
+		reportPeakHeap(b, func() error {
+			for i := 0; i < b.N; i++ {
+				err := StreamJSONArrayFromReader(bytes.NewReader(input.Bytes())).GetCSV(syntheticArrayFlattener).Export(io.Discard)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
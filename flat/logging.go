@@ -0,0 +1,112 @@
+package flat
+
+import (
+	"context"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// defaultProgressLogRows is the default number of rows processed between progress entries of WithLogger.
+const defaultProgressLogRows = 10000
+
+// WithLogger logs the progress of the export through l: a debug entry when it starts with the root data type
+// and the number of writers, an info entry every 10000 rows processed, a warning for each row skipped by
+// ContinueOnRowError and a final entry with the export stats, at error level if the export fails.
+// The entries are created using l.NewWithContext with the export context, so they carry its txId.
+// Nothing is logged without this option.
+func WithLogger(l stlogs.Logger) ExportOption {
+	return func(o *exportOptions) {
+		o.logger = l
+	}
+}
+
+// WithProgressLogInterval sets the number of rows processed between the progress entries logged by WithLogger.
+// Values lower than 1 disable the progress entries.
+func WithProgressLogInterval(rows int) ExportOption {
+	return func(o *exportOptions) {
+		o.progressLogRows = rows
+	}
+}
+
+// exportLogger logs the progress of a single export. A nil exportLogger logs nothing.
+type exportLogger struct {
+	logger      stlogs.Logger
+	progressLog int
+}
+
+// newExportLogger creates the logger of an export using the context, or nil if no logger is set.
+func (o *exportOptions) newExportLogger(ctx context.Context) *exportLogger {
+	if o.logger == nil {
+		return nil
+	}
+
+	logger, _ := o.logger.NewWithContext(ctx)
+	return &exportLogger{logger: logger, progressLog: o.progressLogRows}
+}
+
+// start logs the start of the export.
+func (l *exportLogger) start(root *DynamicValue, writers int) {
+	if l == nil {
+		return
+	}
+
+	l.logger.NewEntry().
+		AddData("root_data_type", root.DataType().String()).
+		AddData("writers", writers).
+		Debug("flat export started")
+}
+
+// progress logs the rows processed every progress interval.
+func (l *exportLogger) progress(rowsProcessed int) {
+	if l == nil || l.progressLog < 1 || rowsProcessed%l.progressLog != 0 {
+		return
+	}
+
+	l.logger.WithData("rows_processed", rowsProcessed).Info("flat export progress")
+}
+
+// skipped logs a row skipped because of an error.
+func (l *exportLogger) skipped(rowErr *ExportError) {
+	if l == nil {
+		return
+	}
+
+	entry := l.logger.NewEntry().AddData("row", rowErr.Row)
+	if rowErr.Column != "" {
+		entry = entry.AddData("column", rowErr.Column)
+	}
+	if rowErr.Writer >= 0 {
+		entry = entry.AddData("writer", rowErr.Writer)
+	}
+	entry.WithError(rowErr.Err).Warn("flat export skipped row")
+}
+
+// done logs the stats of the export, at error level if err is not nil.
+func (l *exportLogger) done(stats *ExportStats, err error) {
+	if l == nil {
+		return
+	}
+
+	var rowsWritten, rowsSkipped, rowsDeduped int
+	var bytesWritten int64
+	for _, writer := range stats.Writers {
+		rowsWritten += writer.RowsWritten
+		rowsSkipped += writer.RowsSkipped
+		rowsDeduped += writer.RowsDeduped
+		bytesWritten += writer.BytesWritten
+	}
+
+	entry := l.logger.NewEntry().
+		AddData("rows_processed", stats.RowsProcessed).
+		AddData("rows_written", rowsWritten).
+		AddData("rows_skipped", rowsSkipped).
+		AddData("rows_deduped", rowsDeduped).
+		AddData("bytes_written", bytesWritten).
+		AddData("row_errors", len(stats.RowErrors))
+
+	if err != nil {
+		entry.WithError(err).Error("flat export failed")
+		return
+	}
+	entry.Info("flat export finished")
+}
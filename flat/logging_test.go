@@ -0,0 +1,161 @@
+package flat_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/flat"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// levelNames names the levels of the logged entries.
+var levelNames = map[stlogs.Level]string{
+	stlogs.DEBUG: "DEBUG",
+	stlogs.INFO:  "INFO",
+	stlogs.WARN:  "WARN",
+	stlogs.ERROR: "ERROR",
+}
+
+func TestWithLogger(t *testing.T) {
+	data := flat.ReadJSONFromReader(strings.NewReader(`[
+		{"name": "John", "age": 30},
+		{"name": "Jane", "age": "n/a"},
+		{"name": "Bob", "age": 35},
+		{"name": "Alice", "age": 22},
+		{"name": "Eve", "age": 41}
+	]`))
+	flattener := func(s flat.Source, d flat.Dest) {
+		d.Col("name", s.Key("name"))
+		d.ColFormatted("age", s.Key("age"), flat.NewSafeFormatter(func(v float64) float64 { return v }))
+	}
+
+	logger := stmocks.NewLogger()
+	parent, ctx := logger.NewWithContext(context.Background())
+	parent.AddData("request", "export-1")
+
+	var buf bytes.Buffer
+	err := data.GetCSV(flattener,
+		flat.WithLogger(logger),
+		flat.WithProgressLogInterval(2),
+		flat.ContinueOnRowError(-1),
+	).ExportContext(ctx, &buf)
+	if err != nil {
+		t.Fatalf("CSV.ExportContext() unexpected error = %v", err)
+	}
+
+	entries := logger.Entries()
+	var got []string
+	for _, entry := range entries {
+		got = append(got, levelNames[entry.Level]+" "+entry.Msg)
+		if entry.Data["txId"] != "tx-1" || entry.Data["request"] != "export-1" {
+			t.Errorf("entry %q data = %v, want the txId and data of the context", entry.Msg, entry.Data)
+		}
+	}
+	want := []string{
+		"DEBUG flat export started",
+		"INFO flat export progress",
+		"WARN flat export skipped row",
+		"INFO flat export progress",
+		"INFO flat export finished",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("logged entries = %q, want %q", got, want)
+	}
+
+	fields := []struct {
+		entry int
+		key   string
+		want  any
+	}{
+		{entry: 0, key: "root_data_type", want: "array"},
+		{entry: 0, key: "writers", want: 1},
+		{entry: 1, key: "rows_processed", want: 2},
+		{entry: 2, key: "row", want: 1},
+		{entry: 2, key: "column", want: "age"},
+		{entry: 3, key: "rows_processed", want: 4},
+		{entry: 4, key: "rows_processed", want: 5},
+		{entry: 4, key: "rows_written", want: 4},
+		{entry: 4, key: "rows_skipped", want: 0},
+		{entry: 4, key: "bytes_written", want: int64(buf.Len())},
+		{entry: 4, key: "row_errors", want: 1},
+	}
+	for _, f := range fields {
+		if got := entries[f.entry].Data[f.key]; got != f.want {
+			t.Errorf("entry %q %s = %#v, want %#v", entries[f.entry].Msg, f.key, got, f.want)
+		}
+	}
+	if _, ok := entries[2].Data["error"]; !ok {
+		t.Errorf("skipped row entry data = %v, want the row error", entries[2].Data)
+	}
+}
+
+func TestWithLoggerFailure(t *testing.T) {
+	data := flat.ReadJSONFromReader(strings.NewReader(`[{"price": "n/a"}]`))
+
+	logger := stmocks.NewLogger()
+	_, err := data.GetCSV(func(s flat.Source, d flat.Dest) {
+		d.ColFormatted("price", s.Key("price"), flat.PriceColumn("USD"))
+	}, flat.WithLogger(logger)).ExportRecords()
+	if err == nil {
+		t.Fatal("CSV.ExportRecords() expected error, got nil")
+	}
+
+	failed := logger.EntriesAt(stlogs.ERROR)
+	if len(failed) != 1 || failed[0].Msg != "flat export failed" || failed[0].Data["error"] != err.Error() {
+		t.Errorf("error entries = %v, want a single flat export failed entry with %q", failed, err)
+	}
+	if skipped := logger.EntriesAt(stlogs.WARN); len(skipped) != 0 {
+		t.Errorf("warning entries = %v, want none without ContinueOnRowError", skipped)
+	}
+}
+
+// failingCloser is a writer of SplitByValue failing to close.
+type failingCloser struct {
+	bytes.Buffer
+}
+
+func (w *failingCloser) Close() error {
+	return errors.New("disk full")
+}
+
+func TestWithLoggerSplitByValueClose(t *testing.T) {
+	data := flat.ReadJSONFromReader(strings.NewReader(`[{"name": "John", "city": "NYC"}, {"name": "Jane", "city": "LA"}]`))
+
+	var writers []*failingCloser
+	newWriter := func(string) (io.WriteCloser, error) {
+		w := &failingCloser{}
+		writers = append(writers, w)
+		return w, nil
+	}
+
+	logger := stmocks.NewLogger()
+	stats, err := data.GetCSV(func(s flat.Source, d flat.Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("city", s.Key("city"))
+	}, flat.WithLogger(logger)).ExportSplitWithStats(flat.SplitByValue("city", newWriter))
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("CSV.ExportSplitWithStats() error = %v, want the close error", err)
+	}
+
+	failed := logger.EntriesAt(stlogs.ERROR)
+	if len(failed) != 1 || failed[0].Msg != "flat export failed" || failed[0].Data["error"] != err.Error() {
+		t.Fatalf("error entries = %v, want a single flat export failed entry with %q", failed, err)
+	}
+	if finished := logger.EntriesAt(stlogs.INFO); len(finished) != 0 {
+		t.Errorf("info entries = %v, want no flat export finished entry", finished)
+	}
+
+	var written int64
+	for _, w := range writers {
+		written += int64(w.Len())
+	}
+	if got := failed[0].Data["bytes_written"]; got != written || got != stats.Writers[0].BytesWritten {
+		t.Errorf("bytes_written = %#v, want %d written to the value writers", got, written)
+	}
+}
@@ -0,0 +1,91 @@
+package flat
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithTrimSpace removes the leading and trailing white space of the string values of every column,
+// i.e. "  AAPL " is written as "AAPL". Values are trimmed once formatted, before the split conditions
+// are checked, so splitters see the trimmed values. Trimming is disabled by default.
+func WithTrimSpace(enabled bool) ExportOption {
+	return func(o *exportOptions) {
+		o.trimSpace = enabled
+	}
+}
+
+// WithUpper upper-cases the string values of the listed columns, identified by their original name.
+// Like WithTrimSpace, values are normalized once formatted and before the split conditions are checked.
+func WithUpper(columns ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.upperColumns = append(o.upperColumns, columns...)
+	}
+}
+
+// WithLower lower-cases the string values of the listed columns, identified by their original name.
+// Like WithTrimSpace, values are normalized once formatted and before the split conditions are checked.
+func WithLower(columns ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.lowerColumns = append(o.lowerColumns, columns...)
+	}
+}
+
+// WithBoolStrings renders the boolean values of every column as trueLabel and falseLabel instead of
+// "true" and "false", i.e. "Y" and "N". Use BoolFormatter to change the labels of a single column.
+// Like WithTrimSpace, the labels are applied before the split conditions are checked: the conditions of Split
+// get the labels as strings, and SplitByValue groups the rows by the labels. The JSON array encoder still
+// writes the values as JSON booleans.
+func WithBoolStrings(trueLabel, falseLabel string) ExportOption {
+	return func(o *exportOptions) {
+		o.trueLabel = trueLabel
+		o.falseLabel = falseLabel
+		o.boolSplitValues = [2]*DynamicValue{newDynamicValue(falseLabel), newDynamicValue(trueLabel)}
+	}
+}
+
+// splitValue returns the value the split conditions get for data, the label of a boolean value with
+// WithBoolStrings, data otherwise.
+func (o *exportOptions) splitValue(data *DynamicValue) *DynamicValue {
+	if o.boolSplitValues[0] == nil || data == nil || data.err != nil || data.dataType != DataTypeBoolean {
+		return data
+	}
+
+	if data.value.(bool) {
+		return o.boolSplitValues[1]
+	}
+	return o.boolSplitValues[0]
+}
+
+// normalizes reports whether the options change the string values of the rows.
+func (o *exportOptions) normalizes() bool {
+	return o.trimSpace || len(o.upperColumns) > 0 || len(o.lowerColumns) > 0
+}
+
+// normalizeRow applies the trimming and case options to the string values of the columns of the row.
+func (t *CSV) normalizeRow(r *row) {
+	if !t.options.normalizes() {
+		return
+	}
+
+	for name, column := range r.columns {
+		if column.data.err != nil || column.data.dataType != DataTypeString {
+			continue
+		}
+
+		original := column.data.value.(string)
+		value := original
+		if t.options.trimSpace {
+			value = strings.TrimSpace(value)
+		}
+		if slices.Contains(t.options.upperColumns, name) {
+			value = strings.ToUpper(value)
+		}
+		if slices.Contains(t.options.lowerColumns, name) {
+			value = strings.ToLower(value)
+		}
+
+		if value != original {
+			r.columns[name] = Source{data: newDynamicValue(value)}
+		}
+	}
+}
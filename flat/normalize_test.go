@@ -0,0 +1,140 @@
+package flat
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeOptions(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"symbol": "  aapl ", "exchange": " Nasdaq", "note": " Buy\t", "active": true, "count": 3},
+		{"symbol": "Msft", "exchange": "NYSE ", "note": "   ", "active": false, "count": 4}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("symbol", s.Key("symbol"))
+		d.Col("exchange", s.Key("exchange"))
+		d.Col("note", s.Key("note"))
+		d.Col("active", s.Key("active"))
+		d.ColFormatted("label", s.Key("count"), NewSafeFormatter(func(v float64) string { return " x" + strings.Repeat("i", int(v)) + " " }))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want [][]string
+	}{
+		{
+			name: "no normalization",
+			want: [][]string{
+				{"  aapl ", " Nasdaq", " Buy\t", "true", " xiii "},
+				{"Msft", "NYSE ", "   ", "false", " xiiii "},
+			},
+		},
+		{
+			name: "trim space",
+			opts: []ExportOption{WithTrimSpace(true)},
+			want: [][]string{
+				{"aapl", "Nasdaq", "Buy", "true", "xiii"},
+				{"Msft", "NYSE", "", "false", "xiiii"},
+			},
+		},
+		{
+			name: "upper and lower",
+			opts: []ExportOption{WithUpper("symbol", "label"), WithLower("exchange")},
+			want: [][]string{
+				{"  AAPL ", " nasdaq", " Buy\t", "true", " XIII "},
+				{"MSFT", "nyse ", "   ", "false", " XIIII "},
+			},
+		},
+		{
+			name: "bool strings",
+			opts: []ExportOption{WithBoolStrings("Y", "N")},
+			want: [][]string{
+				{"  aapl ", " Nasdaq", " Buy\t", "Y", " xiii "},
+				{"Msft", "NYSE ", "   ", "N", " xiiii "},
+			},
+		},
+		{
+			name: "combined",
+			opts: []ExportOption{WithTrimSpace(true), WithUpper("symbol"), WithDefault("note", "none"), WithBoolStrings("yes", "no")},
+			want: [][]string{
+				{"AAPL", "Nasdaq", "Buy", "yes", "xiii"},
+				{"MSFT", "NYSE", "none", "no", "xiiii"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, workers := range []int{1, 4} {
+				records, err := data.GetCSV(flattener, append(tt.opts, WithWorkers(workers))...).ExportRecords()
+				if err != nil {
+					t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+				}
+				if !reflect.DeepEqual(records[1:], tt.want) {
+					t.Errorf("CSV.ExportRecords() with %d workers = %q, want %q", workers, records[1:], tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestNormalizeBeforeSplit tests that the split conditions and SplitByValue see the normalized values
+func TestNormalizeBeforeSplit(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"symbol": " aapl", "exchange": "nasdaq ", "active": true},
+		{"symbol": "ibm ", "exchange": " NYSE", "active": false},
+		{"symbol": "MSFT", "exchange": "Nasdaq", "active": true}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("symbol", s.Key("symbol"))
+		d.Col("exchange", s.Key("exchange"))
+		d.Col("active", s.Key("active"))
+	}
+
+	var nasdaq, active bytes.Buffer
+	byActive := newTestWriters()
+	err := data.GetCSV(flattener, WithTrimSpace(true), WithUpper("symbol", "exchange"), WithBoolStrings("Y", "N")).ExportSplit(
+		Split(&nasdaq, "exchange", func(v string) bool { return v == "NASDAQ" }),
+		Split(&active, "active", func(v string) bool { return v == "Y" }),
+		SplitByValue("active", byActive.newWriter),
+	)
+	if err != nil {
+		t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+	}
+
+	outputs := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "nasdaq", got: nasdaq.String(), want: "symbol,exchange,active\nAAPL,NASDAQ,Y\nMSFT,NASDAQ,Y\n"},
+		{name: "active", got: active.String(), want: "symbol,exchange,active\nAAPL,NASDAQ,Y\nMSFT,NASDAQ,Y\n"},
+		{name: "value Y", got: byActive.writers["Y"].String(), want: "symbol,exchange,active\nAAPL,NASDAQ,Y\nMSFT,NASDAQ,Y\n"},
+		{name: "value N", got: byActive.writers["N"].String(), want: "symbol,exchange,active\nIBM,NYSE,N\n"},
+	}
+	for _, o := range outputs {
+		if o.got != o.want {
+			t.Errorf("%s output = %q, want %q", o.name, o.got, o.want)
+		}
+	}
+}
+
+func TestBoolStringsJSONArray(t *testing.T) {
+	data := newDynamicValue([]map[string]any{{"name": " John ", "active": true}})
+
+	var buf bytes.Buffer
+	err := data.GetCSV(func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("active", s.Key("active"))
+	}, WithTrimSpace(true), WithBoolStrings("Y", "N"), WithEncoder(NewJSONArrayEncoder)).Export(&buf)
+	if err != nil {
+		t.Fatalf("CSV.Export() unexpected error = %v", err)
+	}
+
+	if want := "[\n{\"name\":\"John\",\"active\":true}\n]\n"; buf.String() != want {
+		t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,371 @@
+package flat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// exponentUpperBound and exponentLowerBound delimit the absolute float values rendered without exponent.
+// Values outside this range would need dozens of padding zeros, so they fall back to scientific notation.
+const (
+	exponentUpperBound = 1e21
+	exponentLowerBound = 1e-15
+)
+
+// ErrMissingRequiredValue is returned when a column set as required using WithRequiredColumns is empty.
+var ErrMissingRequiredValue = errors.New("missing required value")
+
+// defaultRowBuffer is the default number of rows buffered between the reader of the source data and the writer.
+const defaultRowBuffer = 100
+
+// ExportOption is a function that customizes how a CSV is exported.
+type ExportOption func(*exportOptions)
+
+// exportOptions holds the settings applied while exporting a CSV.
+type exportOptions struct {
+	floatFormatter           func(float64) string
+	timeLayout               string
+	timeLocation             *time.Location
+	continueOnRowError       bool
+	maxRowErrors             int
+	columnOrder              []string
+	onlyListedColumns        bool
+	headerRenames            map[string]string
+	defaults                 map[string]string
+	requiredColumns          []string
+	workers                  int
+	rowBuffer                int
+	escapeFormulas           bool
+	newlineReplacer          *strings.Replacer
+	stripControlChars        bool
+	aggregates               map[string]AggFunc
+	skipNonNumericAggregates bool
+	nullPlaceholder          string
+	missingPlaceholder       *string
+	jsonIndent               string
+	newEncoder               func(w io.Writer) RowEncoder
+	logger                   stlogs.Logger
+	progressLogRows          int
+	dedupe                   bool
+	dedupeKeys               []string
+	trimSpace                bool
+	upperColumns             []string
+	lowerColumns             []string
+	trueLabel                string
+	falseLabel               string
+	boolSplitValues          [2]*DynamicValue
+}
+
+// defaultExportOptions returns the options used when no ExportOption is provided.
+func defaultExportOptions() *exportOptions {
+	return &exportOptions{
+		floatFormatter:  formatFloat,
+		timeLayout:      time.RFC3339,
+		rowBuffer:       defaultRowBuffer,
+		newEncoder:      NewCSVEncoder,
+		progressLogRows: defaultProgressLogRows,
+		trueLabel:       "true",
+		falseLabel:      "false",
+	}
+}
+
+// newExportOptions creates the export options applying the provided ExportOptions over the defaults.
+// It returns an error if any option has an invalid value.
+func newExportOptions(opts ...ExportOption) (*exportOptions, error) {
+	options := defaultExportOptions()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+
+	if options.rowBuffer < 0 {
+		return nil, fmt.Errorf("row buffer must be greater than or equal to 0, got %d", options.rowBuffer)
+	}
+
+	for name, fn := range options.aggregates {
+		if fn < AggSum || fn > AggMax {
+			return nil, fmt.Errorf("unknown aggregate function %d for column %s", fn, name)
+		}
+	}
+
+	return options, nil
+}
+
+// formatFloat is the default float formatter.
+// It renders floats without exponent (0.0000001 instead of 1e-07) using the minimum number of digits
+// needed to represent the value. Values at or above 1e21 or below 1e-15 use scientific notation.
+func formatFloat(f float64) string {
+	abs := math.Abs(f)
+	if abs != 0 && (abs >= exponentUpperBound || abs < exponentLowerBound) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatNumber renders a JSON number literal. Integers keep their original digits, so large ids are not rounded,
+// while literals with a fraction or an exponent are rendered by the float formatter like float values.
+func (o *exportOptions) formatNumber(num json.Number) string {
+	literal := num.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		return literal
+	}
+
+	f, err := num.Float64()
+	if err != nil {
+		return literal
+	}
+	return o.floatFormatter(f)
+}
+
+// WithFloatPrecision renders float values, and JSON numbers with a fraction or an exponent, with a fixed number
+// of decimal places.
+// A negative precision uses the minimum number of digits needed to represent the value.
+func WithFloatPrecision(precision int) ExportOption {
+	return func(o *exportOptions) {
+		o.floatFormatter = func(f float64) string {
+			return strconv.FormatFloat(f, 'f', precision, 64)
+		}
+	}
+}
+
+// WithFloatFormatter renders float values, and JSON numbers with a fraction or an exponent, using the provided function.
+// If the function is nil, the default float formatting is used.
+func WithFloatFormatter(formatter func(float64) string) ExportOption {
+	return func(o *exportOptions) {
+		if formatter == nil {
+			formatter = formatFloat
+		}
+		o.floatFormatter = formatter
+	}
+}
+
+// WithTimeFormat renders time values using the layout in the given location.
+// An empty layout keeps the default time.RFC3339 layout and a nil location keeps the location of each value.
+func WithTimeFormat(layout string, location *time.Location) ExportOption {
+	return func(o *exportOptions) {
+		if layout != "" {
+			o.timeLayout = layout
+		}
+		o.timeLocation = location
+	}
+}
+
+// ContinueOnRowError skips the rows that fail instead of aborting the export.
+// A row fails when it cannot be decoded from a JSON stream, a formatter fails or a value cannot be converted.
+// The failed rows are reported in ExportStats.RowErrors and the export is aborted only when more than
+// max rows fail. A negative max never aborts the export.
+// By default the export fails on the first row error.
+func ContinueOnRowError(max int) ExportOption {
+	return func(o *exportOptions) {
+		o.continueOnRowError = true
+		o.maxRowErrors = max
+	}
+}
+
+// WithColumnOrder writes the listed columns first, in the given order, followed by the remaining
+// columns in the order the flattener produced them.
+// Listed columns that are never produced are written as empty columns.
+func WithColumnOrder(names ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.columnOrder = names
+		o.onlyListedColumns = false
+	}
+}
+
+// WithColumns writes only the listed columns, in the given order.
+// Listed columns that are never produced are written as empty columns.
+// Splitters are still checked on all the columns produced by the flattener.
+func WithColumns(names ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.columnOrder = names
+		o.onlyListedColumns = true
+	}
+}
+
+// WithHeaderRename renames the columns in the header line using the provided map of original to new names.
+// The rename only applies to the written header, splitters and column options keep using the original names.
+func WithHeaderRename(renames map[string]string) ExportOption {
+	return func(o *exportOptions) {
+		o.headerRenames = renames
+	}
+}
+
+// WithDefault writes value in the column cells that are empty, either because the value is missing,
+// null or an empty string. The column is identified by its original name.
+func WithDefault(column, value string) ExportOption {
+	return func(o *exportOptions) {
+		if o.defaults == nil {
+			o.defaults = make(map[string]string)
+		}
+		o.defaults[column] = value
+	}
+}
+
+// WithRequiredColumns makes the export fail on the first row where any of the columns is empty.
+// The error is an ExportError naming the row and the column and wrapping ErrMissingRequiredValue.
+// Columns with a default value set using WithDefault are never empty.
+func WithRequiredColumns(names ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.requiredColumns = append(o.requiredColumns, names...)
+	}
+}
+
+// outputColumns returns the original names of the columns to write, applying the column order options
+// to the headers produced by the flattener.
+func (o *exportOptions) outputColumns(headers []string) []string {
+	if o.columnOrder == nil {
+		return headers
+	}
+
+	columns := make([]string, 0, len(o.columnOrder)+len(headers))
+	for _, name := range o.columnOrder {
+		if !slices.Contains(columns, name) {
+			columns = append(columns, name)
+		}
+	}
+
+	if !o.onlyListedColumns {
+		for _, header := range headers {
+			if !slices.Contains(columns, header) {
+				columns = append(columns, header)
+			}
+		}
+	}
+
+	return columns
+}
+
+// headerLine returns the header line to write for the columns, applying the header renames.
+func (o *exportOptions) headerLine(columns []string) []string {
+	if len(o.headerRenames) == 0 {
+		return columns
+	}
+
+	line := make([]string, len(columns))
+	for i, name := range columns {
+		if renamed, ok := o.headerRenames[name]; ok {
+			line[i] = renamed
+		} else {
+			line[i] = name
+		}
+	}
+
+	return line
+}
+
+// WithWorkers flattens the rows using n concurrent workers, which speeds up exports with expensive
+// flatteners or formatters. The output keeps the order of the source data and is written by a single writer.
+// The flattener must be safe to call concurrently. Values lower than 2 flatten the rows sequentially.
+func WithWorkers(n int) ExportOption {
+	return func(o *exportOptions) {
+		o.workers = n
+	}
+}
+
+// WithRowBuffer sets the number of rows buffered between the reader of the source data and the CSV writer.
+// The reader stops consuming the source when the buffer is full, so a slow writer applies backpressure
+// to the producer instead of accumulating rows in memory. A bigger buffer absorbs bursts of a fast
+// producer or a slow writer at the cost of holding more rows in memory, while 0 makes the reader wait
+// for the writer on every row. The default buffer is 100 rows. Negative values make the export fail.
+func WithRowBuffer(n int) ExportOption {
+	return func(o *exportOptions) {
+		o.rowBuffer = n
+	}
+}
+
+// WithFormulaEscaping prefixes the cells starting with '=', '+', '-', '@', a tab or a carriage return with a
+// single quote, so spreadsheet applications don't interpret user provided content as a formula (CSV injection).
+// The escaping is applied to the final cell value, after formatters and default values. Cells holding numeric
+// values are never escaped, so negative numbers are written unchanged. Escaping is disabled by default.
+func WithFormulaEscaping(enabled bool) ExportOption {
+	return func(o *exportOptions) {
+		o.escapeFormulas = enabled
+	}
+}
+
+// formulaTriggers are the leading characters that make spreadsheet applications evaluate a cell as a formula.
+const formulaTriggers = "=+-@\t\r"
+
+// escapeFormula prefixes value with a single quote if it starts with a formula trigger character.
+func escapeFormula(value string) string {
+	if value != "" && strings.IndexByte(formulaTriggers, value[0]) >= 0 {
+		return "'" + value
+	}
+	return value
+}
+
+// WithCellNewlineReplacement replaces the "\r\n", "\n" and "\r" line breaks inside the cell values with
+// replacement, so every record is written in a single line for consumers that read the CSV line by line.
+// The replacement applies to the final cell value, including values produced by formatters and default values.
+// By default line breaks are kept and the cell is quoted, as allowed by the CSV format.
+func WithCellNewlineReplacement(replacement string) ExportOption {
+	return func(o *exportOptions) {
+		o.newlineReplacer = strings.NewReplacer("\r\n", replacement, "\n", replacement, "\r", replacement)
+	}
+}
+
+// WithControlCharacterStripping removes the control characters other than tabs and line breaks from the cell
+// values. Combine it with WithCellNewlineReplacement to also remove line breaks.
+// Stripping is disabled by default.
+func WithControlCharacterStripping(enabled bool) ExportOption {
+	return func(o *exportOptions) {
+		o.stripControlChars = enabled
+	}
+}
+
+// sanitizeCell applies the newline replacement and the control character stripping to a cell value.
+func (o *exportOptions) sanitizeCell(value string) string {
+	if o.newlineReplacer != nil {
+		value = o.newlineReplacer.Replace(value)
+	}
+
+	if o.stripControlChars {
+		value = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				return -1
+			}
+			return r
+		}, value)
+	}
+
+	return value
+}
+
+// WithNullPlaceholder writes placeholder in the cells of null values, i.e. "\\N" for loaders that
+// distinguish NULL from an empty string. Explicit JSON nulls, formatters returning null and missing
+// values use the placeholder, while empty strings are written empty. Columns with a default value
+// set using WithDefault use the default instead. By default null values are written empty.
+func WithNullPlaceholder(placeholder string) ExportOption {
+	return func(o *exportOptions) {
+		o.nullPlaceholder = placeholder
+	}
+}
+
+// WithMissingPlaceholder writes placeholder in the cells of missing values, either because the key or
+// index does not exist or because the flattener did not produce the column for the row, so they can be
+// distinguished from explicit JSON nulls. Without this option missing values are written like null values.
+func WithMissingPlaceholder(placeholder string) ExportOption {
+	return func(o *exportOptions) {
+		o.missingPlaceholder = &placeholder
+	}
+}
+
+// WithIndentedJSONCells renders object and array values as JSON indented with indent, one element per line.
+// By default they are rendered as compact JSON. Combine it with WithCellNewlineReplacement to keep
+// each record in a single line.
+func WithIndentedJSONCells(indent string) ExportOption {
+	return func(o *exportOptions) {
+		o.jsonIndent = indent
+	}
+}
@@ -0,0 +1,613 @@
+package flat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		name  string
+		input float64
+		want  string
+	}{
+		{name: "zero", input: 0, want: "0"},
+		{name: "typical price", input: 123.45, want: "123.45"},
+		{name: "small price", input: 0.0001234, want: "0.0001234"},
+		{name: "negative price", input: -19.99, want: "-19.99"},
+		{name: "large value", input: 12345678.9, want: "12345678.9"},
+		{name: "tiny value", input: 1e-7, want: "0.0000001"},
+		{name: "whole value", input: 30, want: "30"},
+		{name: "upper bound", input: 1e21, want: "1e+21"},
+		{name: "below upper bound", input: 1e20, want: "100000000000000000000"},
+		{name: "below lower bound", input: 1e-16, want: "1e-16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFloat(tt.input); got != tt.want {
+				t.Errorf("formatFloat(%v) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportOptionsFloat(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"price": 0.1},
+		{"price": 1e-7},
+		{"price": float64(2)},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("price", s.Key("price"))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{
+			name: "default",
+			opts: nil,
+			want: "price\n0.1\n0.0000001\n2\n",
+		},
+		{
+			name: "fixed precision",
+			opts: []ExportOption{WithFloatPrecision(2)},
+			want: "price\n0.10\n0.00\n2.00\n",
+		},
+		{
+			name: "custom formatter",
+			opts: []ExportOption{WithFloatFormatter(func(f float64) string { return fmt.Sprintf("%g", f) })},
+			want: "price\n0.1\n1e-07\n2\n",
+		},
+		{
+			name: "nil formatter uses default",
+			opts: []ExportOption{WithFloatFormatter(nil)},
+			want: "price\n0.1\n0.0000001\n2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := data.GetCSV(flattener, tt.opts...).Export(&buf); err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExportOptionsJSONNumbers tests that the float options apply to the numbers decoded from JSON input,
+// while integers keep their original digits
+func TestExportOptionsJSONNumbers(t *testing.T) {
+	input := `[
+		{"id": 12345678901234567890, "p": 0.123456789, "q": 1e-7},
+		{"id": 2, "p": 19.99, "q": 1e21},
+		{"id": 3, "p": 12345678.9, "q": 1E2}
+	]`
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("p", s.Key("p"))
+		d.Col("q", s.Key("q"))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{
+			name: "default",
+			want: "id,p,q\n12345678901234567890,0.123456789,0.0000001\n2,19.99,1e+21\n3,12345678.9,100\n",
+		},
+		{
+			name: "fixed precision",
+			opts: []ExportOption{WithFloatPrecision(2)},
+			want: "id,p,q\n12345678901234567890,0.12,0.00\n2,19.99,1000000000000000000000.00\n3,12345678.90,100.00\n",
+		},
+		{
+			name: "custom formatter",
+			opts: []ExportOption{WithFloatFormatter(func(f float64) string { return fmt.Sprintf("%g", f) })},
+			want: "id,p,q\n12345678901234567890,0.123456789,1e-07\n2,19.99,1e+21\n3,1.23456789e+07,100\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ReadJSONFromReader(strings.NewReader(input)).GetCSV(flattener, tt.opts...).Export(&buf); err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportOptionsColumns(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"name": "John", "age": float64(30), "city": "NYC"},
+		{"name": "Jane", "age": float64(25), "city": "LA"},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+		d.Col("city", s.Key("city"))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{
+			name: "reorder",
+			opts: []ExportOption{WithColumnOrder("city", "name")},
+			want: "city,name,age\nNYC,John,30\nLA,Jane,25\n",
+		},
+		{
+			name: "subset",
+			opts: []ExportOption{WithColumns("city", "name")},
+			want: "city,name\nNYC,John\nLA,Jane\n",
+		},
+		{
+			name: "missing listed column",
+			opts: []ExportOption{WithColumns("name", "email")},
+			want: "name,email\nJohn,\nJane,\n",
+		},
+		{
+			name: "rename",
+			opts: []ExportOption{WithHeaderRename(map[string]string{"name": "Full Name", "age": "Age"})},
+			want: "Full Name,Age,city\nJohn,30,NYC\nJane,25,LA\n",
+		},
+		{
+			name: "subset and rename",
+			opts: []ExportOption{WithColumns("city", "name"), WithHeaderRename(map[string]string{"city": "City"})},
+			want: "City,name\nNYC,John\nLA,Jane\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := data.GetCSV(flattener, tt.opts...).Export(&buf); err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("split on original name after rename", func(t *testing.T) {
+		var old, young bytes.Buffer
+		err := data.GetCSV(flattener,
+			WithColumns("name", "city"),
+			WithHeaderRename(map[string]string{"name": "Name"}),
+		).ExportSplit(
+			Split(&old, "age", func(v float64) bool { return v >= 30 }),
+			Split(&young, "name", func(v string) bool { return v == "Jane" }),
+		)
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+		if want := "Name,city\nJohn,NYC\n"; old.String() != want {
+			t.Errorf("CSV.ExportSplit() split 0 = %q, want %q", old.String(), want)
+		}
+		if want := "Name,city\nJane,LA\n"; young.String() != want {
+			t.Errorf("CSV.ExportSplit() split 1 = %q, want %q", young.String(), want)
+		}
+	})
+}
+
+func TestExportOptionsDefaultsRequired(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"name": "John", "email": "john@example.com", "city": "NYC"},
+		{"name": "Jane", "email": null, "city": ""},
+		{"name": "Bob"}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("email", s.Key("email"))
+		d.Col("city", s.Key("city"))
+	}
+
+	tests := []struct {
+		name    string
+		opts    []ExportOption
+		want    string
+		wantRow int
+		wantCol string
+	}{
+		{
+			name: "defaults fill missing, null and empty values",
+			opts: []ExportOption{WithDefault("email", "N/A"), WithDefault("city", "unknown")},
+			want: "name,email,city\nJohn,john@example.com,NYC\nJane,N/A,unknown\nBob,N/A,unknown\n",
+		},
+		{
+			name: "default for a column never produced",
+			opts: []ExportOption{WithColumns("name", "country"), WithDefault("country", "US")},
+			want: "name,country\nJohn,US\nJane,US\nBob,US\n",
+		},
+		{
+			name:    "required column missing",
+			opts:    []ExportOption{WithRequiredColumns("name", "email")},
+			wantRow: 1,
+			wantCol: "email",
+		},
+		{
+			name:    "first failing required column is reported",
+			opts:    []ExportOption{WithRequiredColumns("city", "email")},
+			wantRow: 1,
+			wantCol: "city",
+		},
+		{
+			name: "default satisfies the requirement",
+			opts: []ExportOption{WithRequiredColumns("email"), WithDefault("email", "N/A")},
+			want: "name,email,city\nJohn,john@example.com,NYC\nJane,N/A,\nBob,N/A,\n",
+		},
+		{
+			name:    "required column not written",
+			opts:    []ExportOption{WithColumns("name"), WithRequiredColumns("city")},
+			wantRow: 1,
+			wantCol: "city",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := data.GetCSV(flattener, tt.opts...).Export(&buf)
+
+			if tt.wantCol != "" {
+				var exportErr *ExportError
+				if !errors.As(err, &exportErr) || !errors.Is(err, ErrMissingRequiredValue) {
+					t.Fatalf("CSV.Export() error = %v, want missing required value export error", err)
+				}
+				if exportErr.Row != tt.wantRow || exportErr.Column != tt.wantCol {
+					t.Errorf("CSV.Export() error = %v, want row %d column %s", err, tt.wantRow, tt.wantCol)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportOptionsRowBuffer(t *testing.T) {
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("name", s.Key("name"))
+	}
+
+	tests := []struct {
+		name string
+		data func() *DynamicValue
+		want string
+	}{
+		{
+			name: "object",
+			data: func() *DynamicValue { return newDynamicValue(map[string]any{"id": 1, "name": "John"}) },
+			want: "id,name\n1,John\n",
+		},
+		{
+			name: "array of objects",
+			data: func() *DynamicValue {
+				return newDynamicValue([]map[string]any{{"id": 1, "name": "John"}, {"id": 2, "name": "Jane"}})
+			},
+			want: "id,name\n1,John\n2,Jane\n",
+		},
+		{
+			name: "json stream",
+			data: func() *DynamicValue {
+				return StreamJSONFromReader(strings.NewReader(`{"id": 1, "name": "John"}` + "\n" + `{"id": 2, "name": "Jane"}`))
+			},
+			want: "id,name\n1,John\n2,Jane\n",
+		},
+		{
+			name: "channel stream",
+			data: func() *DynamicValue {
+				ch := make(chan map[string]any)
+				go func() {
+					defer close(ch)
+					ch <- map[string]any{"id": 1, "name": "John"}
+					ch <- map[string]any{"id": 2, "name": "Jane"}
+				}()
+				return StreamFromChannel(ch)
+			},
+			want: "id,name\n1,John\n2,Jane\n",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, opts := range [][]ExportOption{
+			{WithRowBuffer(0)},
+			{WithRowBuffer(0), WithWorkers(4)},
+			{WithRowBuffer(1)},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				done := make(chan error, 1)
+				var buf bytes.Buffer
+				go func() {
+					done <- tt.data().GetCSV(flattener, opts...).Export(&buf)
+				}()
+
+				select {
+				case err := <-done:
+					if err != nil {
+						t.Fatalf("CSV.Export() unexpected error = %v", err)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("CSV.Export() deadlocked")
+				}
+
+				if got := buf.String(); got != tt.want {
+					t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+
+	t.Run("negative buffer", func(t *testing.T) {
+		err := newDynamicValue(map[string]any{"id": 1}).GetCSV(flattener, WithRowBuffer(-1)).Export(&bytes.Buffer{})
+		if err == nil {
+			t.Error("CSV.Export() expected error for negative row buffer, got nil")
+		}
+	})
+}
+
+func TestExportOptionsFormulaEscaping(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		opts  []ExportOption
+		want  string
+	}{
+		{name: "disabled by default", value: "=SUM(A1:A2)", want: "=SUM(A1:A2)"},
+		{name: "equals", value: "=SUM(A1:A2)", opts: []ExportOption{WithFormulaEscaping(true)}, want: "'=SUM(A1:A2)"},
+		{name: "plus", value: "+1+2", opts: []ExportOption{WithFormulaEscaping(true)}, want: "'+1+2"},
+		{name: "minus", value: "-1+2", opts: []ExportOption{WithFormulaEscaping(true)}, want: "'-1+2"},
+		{name: "at", value: "@cmd", opts: []ExportOption{WithFormulaEscaping(true)}, want: "'@cmd"},
+		{name: "tab", value: "\t=1", opts: []ExportOption{WithFormulaEscaping(true)}, want: "'\t=1"},
+		{name: "carriage return", value: "\r=1", opts: []ExportOption{WithFormulaEscaping(true)}, want: "\"'\r=1\""},
+		{name: "explicitly disabled", value: "=1", opts: []ExportOption{WithFormulaEscaping(false)}, want: "=1"},
+		{name: "safe string", value: "a=b", opts: []ExportOption{WithFormulaEscaping(true)}, want: "a=b"},
+		{name: "negative float", value: -1.5, opts: []ExportOption{WithFormulaEscaping(true)}, want: "-1.5"},
+		{name: "negative int", value: -7, opts: []ExportOption{WithFormulaEscaping(true)}, want: "-7"},
+		{name: "negative json number", value: json.Number("-10"), opts: []ExportOption{WithFormulaEscaping(true)}, want: "-10"},
+		{name: "numeric string", value: "-10", opts: []ExportOption{WithFormulaEscaping(true)}, want: "'-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := newDynamicValue([]map[string]any{{"value": tt.value}})
+
+			var buf bytes.Buffer
+			err := data.GetCSV(func(s Source, d Dest) {
+				d.Col("value", s.Key("value"))
+			}, tt.opts...).Export(&buf)
+			if err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+
+			if want := "value\n" + tt.want + "\n"; buf.String() != want {
+				t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+			}
+		})
+	}
+
+	t.Run("applies to formatted and default values", func(t *testing.T) {
+		data := newDynamicValue([]map[string]any{{"name": "John"}})
+
+		var buf bytes.Buffer
+		err := data.GetCSV(func(s Source, d Dest) {
+			d.ColFormatted("name", s.Key("name"), NewFormatter(func(v string) (string, error) {
+				return "=HYPERLINK(\"" + v + "\")", nil
+			}))
+			d.Col("note", s.Key("note"))
+		}, WithFormulaEscaping(true), WithDefault("note", "-")).Export(&buf)
+		if err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+
+		if want := "name,note\n\"'=HYPERLINK(\"\"John\"\")\",'-\n"; buf.String() != want {
+			t.Errorf("CSV.Export() = %q, want %q", buf.String(), want)
+		}
+	})
+}
+
+func TestExportOptionsCellSanitization(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"id": 1, "notes": "first line\nsecond line", "meta": map[string]any{"tags": []any{"a", "b"}}},
+		{"id": 2, "notes": "windows\r\nline\rbreaks", "meta": map[string]any{"tags": []any{}}},
+		{"id": 3, "notes": "bell\a and\x00 nul\ttab", "meta": nil},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("notes", s.Key("notes"))
+		d.ColFormatted("meta", s.Key("meta"), func(v *DynamicValue) (*DynamicValue, error) {
+			if v.IsNull() {
+				return v, nil
+			}
+			indented, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return newDynamicValue(string(indented)), nil
+		})
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{
+			name: "space replacement",
+			opts: []ExportOption{WithCellNewlineReplacement(" ")},
+			want: "id,notes,meta\n" +
+				"1,first line second line,\"{   \"\"tags\"\": [     \"\"a\"\",     \"\"b\"\"   ] }\"\n" +
+				"2,windows line breaks,\"{   \"\"tags\"\": [] }\"\n" +
+				"3,bell\a and\x00 nul\ttab,\n",
+		},
+		{
+			name: "escaped replacement with control characters stripped",
+			opts: []ExportOption{WithCellNewlineReplacement(`\n`), WithControlCharacterStripping(true)},
+			want: "id,notes,meta\n" +
+				"1,first line\\nsecond line,\"{\\n  \"\"tags\"\": [\\n    \"\"a\"\",\\n    \"\"b\"\"\\n  ]\\n}\"\n" +
+				"2,windows\\nline\\nbreaks,\"{\\n  \"\"tags\"\": []\\n}\"\n" +
+				"3,bell and nul\ttab,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := data.GetCSV(flattener, tt.opts...).Export(&buf); err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+			}
+			if lines := strings.Count(buf.String(), "\n"); lines != 4 {
+				t.Errorf("CSV.Export() wrote %d lines, want one per record (4)", lines)
+			}
+		})
+	}
+}
+
+func TestExportOptionsNullPlaceholder(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"id": 1, "note": null},
+		{"id": 2},
+		{"id": 3, "note": ""},
+		{"id": 4, "note": "text"}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("note", s.Key("note"))
+		d.ColIf("extra", s.Key("id").data.value == json.Number("4"), s.Key("note"))
+		d.ColFormatted("upper", s.Key("note"), func(v *DynamicValue) (*DynamicValue, error) {
+			if str, ok := valueAs[string](v); ok && str != "" {
+				return newDynamicValue(strings.ToUpper(str)), nil
+			}
+			return newDynamicValue(nil), nil
+		})
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{
+			name: "default",
+			want: "id,note,extra,upper\n1,,,\n2,,,\n3,,,\n4,text,text,TEXT\n",
+		},
+		{
+			name: "null placeholder",
+			opts: []ExportOption{WithNullPlaceholder(`\N`)},
+			want: "id,note,extra,upper\n1,\\N,\\N,\\N\n2,\\N,\\N,\\N\n3,,\\N,\\N\n4,text,text,TEXT\n",
+		},
+		{
+			name: "null and missing placeholders",
+			opts: []ExportOption{WithNullPlaceholder("NULL"), WithMissingPlaceholder("MISSING")},
+			want: "id,note,extra,upper\n1,NULL,MISSING,NULL\n2,MISSING,MISSING,NULL\n3,,MISSING,NULL\n4,text,text,TEXT\n",
+		},
+		{
+			name: "default value takes precedence",
+			opts: []ExportOption{WithNullPlaceholder("NULL"), WithDefault("note", "none")},
+			want: "id,note,extra,upper\n1,none,NULL,NULL\n2,none,NULL,NULL\n3,none,NULL,NULL\n4,text,text,TEXT\n",
+		},
+		{
+			name: "placeholder is not escaped",
+			opts: []ExportOption{WithNullPlaceholder("-"), WithFormulaEscaping(true)},
+			want: "id,note,extra,upper\n1,-,-,-\n2,-,-,-\n3,,-,-\n4,text,text,TEXT\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := data.GetCSV(flattener, tt.opts...).Export(&buf); err != nil {
+				t.Fatalf("CSV.Export() unexpected error = %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV.Export() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("placeholder does not satisfy required columns", func(t *testing.T) {
+		err := data.GetCSV(flattener, WithNullPlaceholder(`\N`), WithRequiredColumns("note")).Export(&bytes.Buffer{})
+		if !errors.Is(err, ErrMissingRequiredValue) {
+			t.Errorf("CSV.Export() error = %v, want %v", err, ErrMissingRequiredValue)
+		}
+	})
+}
+
+func TestExportOptionsJSONCells(t *testing.T) {
+	data := ReadJSONFromReader(strings.NewReader(`[
+		{"id": 1, "meta": {"url": "https://example.com/?a=1&b=<2>", "z": 1, "a": [1.50, true, null]}},
+		{"id": 2, "meta": [{"q": "AT&T"}, {"b": 2, "a": 1}]}
+	]`))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("meta", s.Key("meta"))
+	}
+
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{
+			name: "compact without HTML escaping",
+			want: "id,meta\n" +
+				`1,"{""a"":[1.50,true,null],""url"":""https://example.com/?a=1&b=<2>"",""z"":1}"` + "\n" +
+				`2,"[{""q"":""AT&T""},{""a"":1,""b"":2}]"` + "\n",
+		},
+		{
+			name: "indented",
+			opts: []ExportOption{WithIndentedJSONCells("  "), WithCellNewlineReplacement(" ")},
+			want: "id,meta\n" +
+				`1,"{   ""a"": [     1.50,     true,     null   ],   ""url"": ""https://example.com/?a=1&b=<2>"",   ""z"": 1 }"` + "\n" +
+				`2,"[   {     ""q"": ""AT&T""   },   {     ""a"": 1,     ""b"": 2   } ]"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The output must be the same on every run
+			for i := 0; i < 10; i++ {
+				var buf bytes.Buffer
+				if err := data.GetCSV(flattener, tt.opts...).Export(&buf); err != nil {
+					t.Fatalf("CSV.Export() unexpected error = %v", err)
+				}
+
+				if got := buf.String(); got != tt.want {
+					t.Fatalf("CSV.Export() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ErrTooManySplitValues is returned when a SplitByValue column has more distinct values than allowed.
+var ErrTooManySplitValues = errors.New("too many distinct split values")
+
+// defaultMaxSplitValues is the default number of distinct values, and so of writers, of a SplitByValue.
+const defaultMaxSplitValues = 100
+
+// ValueSplitOption is a function that customizes a SplitByValue.
+type ValueSplitOption func(*valueSplitWriter)
+
+// WithMaxSplitValues sets the maximum number of distinct values, and so of writers, of a SplitByValue.
+// The default maximum is 100 values.
+func WithMaxSplitValues(n int) ValueSplitOption {
+	return func(s *valueSplitWriter) {
+		s.maxValues = n
+	}
+}
+
+// valueSplitWriter implements the splitWriter interface
+// writing each row to the writer of the value of its split header.
+type valueSplitWriter struct {
+	header    string
+	newWriter func(value string) (io.WriteCloser, error)
+	maxValues int
+}
+
+// SplitByValue creates a split instance that writes each row to a writer per distinct value of the header column.
+// newWriter is called the first time a value appears to create its writer, i.e. a file named after the value,
+// and each writer gets its own header line. Rows where the column is missing or null use the empty value.
+// Values are rendered like the column cells, using the export options but without formatting the cell.
+// All the created writers are closed when the export ends, and the first close error is returned.
+// The export fails with ErrTooManySplitValues if the column has more distinct values than allowed,
+// see WithMaxSplitValues. SplitByValue cannot be used with WithAggregates.
+func SplitByValue(header string, newWriter func(value string) (io.WriteCloser, error), opts ...ValueSplitOption) splitWriter {
+	s := &valueSplitWriter{
+		header:    header,
+		newWriter: newWriter,
+		maxValues: defaultMaxSplitValues,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// Write fails, the rows are written to the writers created for each value.
+func (s *valueSplitWriter) Write(_ []byte) (int, error) {
+	return 0, fmt.Errorf("split by value writes to the writer of each value")
+}
+
+// shouldInclude always includes the data, every row is written to the writer of its value.
+func (s *valueSplitWriter) shouldInclude(_ string, _ *DynamicValue) (bool, error) {
+	return true, nil
+}
+
+// shouldIncludeRow always includes the row, every row is written to the writer of its value.
+func (s *valueSplitWriter) shouldIncludeRow(_ map[string]*DynamicValue) (bool, error) {
+	return true, nil
+}
+
+// valueEncoder implements the RowEncoder interface writing each row to the encoder of its value,
+// creating the writers and their encoders as new values appear.
+type valueEncoder struct {
+	split      *valueSplitWriter
+	newEncoder func(w io.Writer) RowEncoder
+	count      *int64
+	headers    []string
+	nextValue  string
+	values     []string
+	closers    []io.Closer
+	encoders   map[string]RowEncoder
+}
+
+// newValueEncoder creates a valueEncoder counting the bytes written to all its writers in count.
+func newValueEncoder(split *valueSplitWriter, newEncoder func(w io.Writer) RowEncoder, count *int64) *valueEncoder {
+	return &valueEncoder{
+		split:      split,
+		newEncoder: newEncoder,
+		count:      count,
+		encoders:   make(map[string]RowEncoder),
+	}
+}
+
+// route sets the value whose encoder gets the next row, taken from the split header of the row.
+func (v *valueEncoder) route(r *row, opts *exportOptions) error {
+	v.nextValue = ""
+	if column, exists := r.columns[v.split.header]; exists {
+		value, err := column.strValWithOptions(opts)
+		if err != nil {
+			return withColumn(v.split.header, fmt.Errorf("failed to get split value: %w", err))
+		}
+		v.nextValue = value
+	}
+	return nil
+}
+
+// WriteHeader keeps the headers, they are written to each encoder when it is created.
+func (v *valueEncoder) WriteHeader(headers []string) error {
+	v.headers = slices.Clone(headers)
+	return nil
+}
+
+// WriteRow writes the row to the encoder of the routed value.
+func (v *valueEncoder) WriteRow(cells []Cell) error {
+	encoder, err := v.encoder(v.nextValue)
+	if err != nil {
+		return err
+	}
+	return encoder.WriteRow(cells)
+}
+
+// encoder returns the encoder of value, creating it and writing the headers if it does not exist.
+func (v *valueEncoder) encoder(value string) (RowEncoder, error) {
+	if encoder, exists := v.encoders[value]; exists {
+		return encoder, nil
+	}
+
+	if len(v.encoders) >= v.split.maxValues {
+		return nil, fmt.Errorf("%w: column %s has more than %d values", ErrTooManySplitValues, v.split.header, v.split.maxValues)
+	}
+
+	wc, err := v.split.newWriter(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create writer for value %q: %w", value, err)
+	}
+	v.values = append(v.values, value)
+	v.closers = append(v.closers, wc)
+
+	encoder := v.newEncoder(countingWriter{w: wc, count: v.count})
+	if err := encoder.WriteHeader(v.headers); err != nil {
+		return nil, fmt.Errorf("failed to write CSV headers for value %q: %w", value, err)
+	}
+
+	v.encoders[value] = encoder
+	return encoder, nil
+}
+
+// Flush flushes the encoders of every value and returns the first error.
+func (v *valueEncoder) Flush() error {
+	for _, value := range v.values {
+		if encoder, exists := v.encoders[value]; exists {
+			if err := encoder.Flush(); err != nil {
+				return fmt.Errorf("value %q: %w", value, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes every created writer and returns the first close error.
+func (v *valueEncoder) Close() error {
+	var firstErr error
+	for i, c := range v.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close writer for value %q: %w", v.values[i], err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,150 @@
+package flat
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// testWriteCloser is a bytes.Buffer that records when it is closed.
+type testWriteCloser struct {
+	bytes.Buffer
+	closed   bool
+	closeErr error
+}
+
+func (w *testWriteCloser) Close() error {
+	w.closed = true
+	return w.closeErr
+}
+
+// testWriters creates testWriteClosers by value, keeping the order they are created.
+type testWriters struct {
+	writers  map[string]*testWriteCloser
+	order    []string
+	closeErr error
+}
+
+func newTestWriters() *testWriters {
+	return &testWriters{writers: make(map[string]*testWriteCloser)}
+}
+
+func (tw *testWriters) newWriter(value string) (io.WriteCloser, error) {
+	w := &testWriteCloser{closeErr: tw.closeErr}
+	tw.writers[value] = w
+	tw.order = append(tw.order, value)
+	return w, nil
+}
+
+func TestSplitByValue(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"name": "John", "age": 30, "city": "New York"},
+		{"name": "Jane", "age": 25, "city": "Boston"},
+		{"name": "Bob", "age": 35, "city": "Chicago"},
+		{"name": "Alice", "age": 28, "city": "Boston"},
+		{"name": "Tom", "age": 40, "city": "New York"},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+		d.Col("city", s.Key("city"))
+	}
+
+	t.Run("one writer per value", func(t *testing.T) {
+		tw := newTestWriters()
+		var all bytes.Buffer
+		stats, err := data.GetCSV(flattener).ExportSplitWithStats(SplitByValue("city", tw.newWriter), NoSplit(&all))
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+
+		want := map[string]string{
+			"New York": "name,age,city\nJohn,30,New York\nTom,40,New York\n",
+			"Boston":   "name,age,city\nJane,25,Boston\nAlice,28,Boston\n",
+			"Chicago":  "name,age,city\nBob,35,Chicago\n",
+		}
+		if len(tw.writers) != len(want) {
+			t.Fatalf("SplitByValue() created %d writers %q, want %d", len(tw.writers), tw.order, len(want))
+		}
+		if tw.order[0] != "New York" || tw.order[1] != "Boston" || tw.order[2] != "Chicago" {
+			t.Errorf("SplitByValue() created writers in order %q, want first appearance order", tw.order)
+		}
+
+		var total int64
+		for city, w := range tw.writers {
+			if w.String() != want[city] {
+				t.Errorf("writer %q = %q, want %q", city, w.String(), want[city])
+			}
+			if !w.closed {
+				t.Errorf("writer %q was not closed", city)
+			}
+			total += int64(w.Len())
+		}
+
+		if stats.Writers[0].RowsWritten != 5 || stats.Writers[0].BytesWritten != total {
+			t.Errorf("stats = %+v, want 5 rows and %d bytes", stats.Writers[0], total)
+		}
+		if all.String() != "name,age,city\nJohn,30,New York\nJane,25,Boston\nBob,35,Chicago\nAlice,28,Boston\nTom,40,New York\n" {
+			t.Errorf("NoSplit writer = %q", all.String())
+		}
+	})
+
+	t.Run("too many values", func(t *testing.T) {
+		tw := newTestWriters()
+		err := data.GetCSV(flattener).ExportSplit(SplitByValue("city", tw.newWriter, WithMaxSplitValues(2)))
+		if !errors.Is(err, ErrTooManySplitValues) {
+			t.Fatalf("CSV.ExportSplit() error = %v, want %v", err, ErrTooManySplitValues)
+		}
+		for city, w := range tw.writers {
+			if !w.closed {
+				t.Errorf("writer %q was not closed after the export failed", city)
+			}
+		}
+	})
+
+	t.Run("close error", func(t *testing.T) {
+		closeErr := errors.New("disk full")
+		tw := newTestWriters()
+		tw.closeErr = closeErr
+
+		err := data.GetCSV(flattener).ExportSplit(SplitByValue("city", tw.newWriter))
+		if !errors.Is(err, closeErr) {
+			t.Fatalf("CSV.ExportSplit() error = %v, want %v", err, closeErr)
+		}
+		for city, w := range tw.writers {
+			if !w.closed {
+				t.Errorf("writer %q was not closed", city)
+			}
+		}
+	})
+
+	t.Run("writer creation error", func(t *testing.T) {
+		createErr := errors.New("permission denied")
+		err := data.GetCSV(flattener).ExportSplit(SplitByValue("city", func(string) (io.WriteCloser, error) {
+			return nil, createErr
+		}))
+		if !errors.Is(err, createErr) {
+			t.Errorf("CSV.ExportSplit() error = %v, want %v", err, createErr)
+		}
+	})
+
+	t.Run("missing column uses empty value", func(t *testing.T) {
+		tw := newTestWriters()
+		err := data.GetCSV(flattener).ExportSplit(SplitByValue("country", tw.newWriter))
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+		if len(tw.writers) != 1 || tw.writers[""] == nil {
+			t.Errorf("SplitByValue() created writers %q, want only the empty value", tw.order)
+		}
+	})
+
+	t.Run("aggregates not supported", func(t *testing.T) {
+		tw := newTestWriters()
+		err := data.GetCSV(flattener, WithAggregates(map[string]AggFunc{"age": AggSum})).ExportSplit(SplitByValue("city", tw.newWriter))
+		if err == nil {
+			t.Error("CSV.ExportSplit() expected error for aggregates with SplitByValue, got nil")
+		}
+	})
+}
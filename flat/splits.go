@@ -9,6 +9,8 @@ import (
 type splitter interface {
 	// shouldInclude checks if the data should be skipped based on the header and the DynamicValue.
 	shouldInclude(header string, dv *DynamicValue) (bool, error)
+	// shouldIncludeRow checks if the row should be skipped based on the values of its columns by header.
+	shouldIncludeRow(row map[string]*DynamicValue) (bool, error)
 }
 
 // splitWriter defines an interface that combines io.Writer and splitter.
@@ -37,23 +39,11 @@ func NewSplitter[T any](header string, rawIncludeFunc func(T) bool) splitter {
 // getSplitFunc returns a function that checks if a DynamicValue should be split based on the provided rawSplitFunc.
 func getSplitFunc[T any](rawSplitFunc func(T) bool) func(*DynamicValue) (bool, error) {
 	return func(dv *DynamicValue) (bool, error) {
-		expectedType := getDataTypeFromType[T]()
-		dvType := dv.DataType()
-
-		// Handle numeric type conversions
-		if expectedType == DataTypeInt && dvType == DataTypeFloat {
-			if fval, ok := dv.value.(float64); ok {
-				if ival := int(fval); float64(ival) == fval { // Only convert if no precision is lost
-					return rawSplitFunc(any(ival).(T)), nil
-				}
-			}
-		}
-
-		if dvType != expectedType {
+		rawValue, ok := valueAs[T](dv)
+		if !ok {
 			return false, fmt.Errorf("split function type mismatch with data type")
 		}
 
-		rawValue := dv.value.(T)
 		return rawSplitFunc(rawValue), nil
 	}
 }
@@ -100,6 +90,79 @@ func (s *singleSplitter) shouldInclude(header string, dv *DynamicValue) (bool, e
 	return s.includeFunc(dv)
 }
 
+// shouldIncludeRow checks the split function on the value of the split header.
+// Rows without the split header are included.
+func (s *singleSplitter) shouldIncludeRow(row map[string]*DynamicValue) (bool, error) {
+	dv, exists := row[s.header]
+	if !exists {
+		return true, nil
+	}
+
+	include, err := s.shouldInclude(s.header, dv)
+	if err != nil {
+		return false, withColumn(s.header, err)
+	}
+	return include, nil
+}
+
+// rowFuncSplitter implements the splitter interface
+// splitting the data based on a function that receives all the columns of the row.
+type rowFuncSplitter struct {
+	includeFunc func(row map[string]*DynamicValue) (bool, error)
+}
+
+// NewRowSplitter creates a new splitter instance that uses the provided includeFunc to compare columns of a row.
+// The includeFunc receives the values of the row by header and should return false if the row should be skipped.
+// An error returned by includeFunc fails the row, like a type mismatch in the other splitters.
+func NewRowSplitter(includeFunc func(row map[string]*DynamicValue) (bool, error)) splitter {
+	return &rowFuncSplitter{
+		includeFunc: includeFunc,
+	}
+}
+
+// SplitRow creates a split instance that writes to the provided writer and uses includeFunc
+// to determine if the row should be included in the split, see NewRowSplitter.
+func SplitRow(w io.Writer, includeFunc func(row map[string]*DynamicValue) (bool, error)) splitWriter {
+	return singleSplitWriter{
+		Writer:   w,
+		splitter: NewRowSplitter(includeFunc),
+	}
+}
+
+// ColumnAs returns the value of the header column of a row as T, applying the same conversions as
+// the split functions. It returns an error if the row has no such column or the value is not a T.
+// It is meant to be used by the functions provided to NewRowSplitter.
+func ColumnAs[T any](row map[string]*DynamicValue, header string) (T, error) {
+	dv, exists := row[header]
+	if !exists {
+		return *new(T), fmt.Errorf("column %s does not exist", header)
+	}
+
+	value, ok := valueAs[T](dv)
+	if !ok {
+		return *new(T), fmt.Errorf("column %s type mismatch with data type", header)
+	}
+	return value, nil
+}
+
+// shouldInclude always includes the data, the row function is checked on the whole row.
+func (s *rowFuncSplitter) shouldInclude(_ string, _ *DynamicValue) (bool, error) {
+	return true, nil
+}
+
+// shouldIncludeRow checks the row function on the row.
+func (s *rowFuncSplitter) shouldIncludeRow(row map[string]*DynamicValue) (bool, error) {
+	if s.includeFunc == nil {
+		return true, nil
+	}
+
+	include, err := s.includeFunc(row)
+	if err != nil {
+		return false, fmt.Errorf("error checking row split condition: %w", err)
+	}
+	return include, nil
+}
+
 // splitOperation defines the type of logical operation to be performed
 // when combining multiple splitters
 type splitOperation int
@@ -163,3 +226,27 @@ func (s *splitWriterOperation) shouldInclude(header string, dv *DynamicValue) (b
 	// If all splitters returned true in AND operation, include; otherwise skip
 	return s.operation == splitAndOperation, nil
 }
+
+// shouldIncludeRow combines the row checks of all the splitters with the logical operation.
+func (s *splitWriterOperation) shouldIncludeRow(row map[string]*DynamicValue) (bool, error) {
+	// If no splitters are defined, include all data
+	if len(s.splitters) == 0 {
+		return true, nil
+	}
+
+	for _, splitter := range s.splitters {
+		include, err := splitter.shouldIncludeRow(row)
+		if err != nil {
+			return false, fmt.Errorf("error checking split condition: %w", err)
+		}
+
+		if s.operation == splitAndOperation && !include {
+			return false, nil // If any splitter returns false in AND operation, skip
+		}
+		if s.operation == splitOrOperation && include {
+			return true, nil // If any splitter returns true in OR operation, include
+		}
+	}
+
+	return s.operation == splitAndOperation, nil
+}
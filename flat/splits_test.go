@@ -2,6 +2,8 @@ package flat
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -61,6 +63,25 @@ func TestSingleSplitter(t *testing.T) {
 		})
 	}
 
+	t.Run("json number coerces to numeric types", func(t *testing.T) {
+		intSplitter := NewSplitter("test", func(v int) bool { return v == 9007199254740993 })
+		include, err := intSplitter.shouldInclude("test", newDynamicValue(json.Number("9007199254740993")))
+		if err != nil || !include {
+			t.Errorf("shouldInclude() = %v, %v, want true, nil", include, err)
+		}
+
+		floatSplitter := NewSplitter("test", func(v float64) bool { return v > 1.5 })
+		include, err = floatSplitter.shouldInclude("test", newDynamicValue(json.Number("2.5")))
+		if err != nil || !include {
+			t.Errorf("shouldInclude() = %v, %v, want true, nil", include, err)
+		}
+
+		_, err = intSplitter.shouldInclude("test", newDynamicValue(json.Number("2.5")))
+		if err == nil {
+			t.Error("shouldInclude() expected error for fractional number with int split, got nil")
+		}
+	})
+
 	t.Run("type mismatch causes error", func(t *testing.T) {
 		s := NewSplitter("test", func(v string) bool {
 			return v == "value"
@@ -300,3 +321,86 @@ func TestIntegration_CSVSplitting(t *testing.T) {
 		t.Errorf("Old CSV contains unexpected data: %s", oldCSV)
 	}
 }
+
+func TestRowSplitter(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"symbol": "AAPL", "buy_price": 150.5, "sell_price": 170.25},
+		{"symbol": "TSLA", "buy_price": 250.0, "sell_price": 200.0},
+		{"symbol": "MSFT", "buy_price": json.Number("300"), "sell_price": json.Number("310")},
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("symbol", s.Key("symbol"))
+		d.Col("buy_price", s.Key("buy_price"))
+		d.Col("sell_price", s.Key("sell_price"))
+	}
+	profit := func(row map[string]*DynamicValue) (bool, error) {
+		buy, err := ColumnAs[float64](row, "buy_price")
+		if err != nil {
+			return false, err
+		}
+		sell, err := ColumnAs[float64](row, "sell_price")
+		if err != nil {
+			return false, err
+		}
+		return sell > buy, nil
+	}
+
+	t.Run("compare two columns", func(t *testing.T) {
+		var gains, losses, gainsOver200 bytes.Buffer
+		err := data.GetCSV(flattener).ExportSplit(
+			SplitRow(&gains, profit),
+			SplitRow(&losses, func(row map[string]*DynamicValue) (bool, error) {
+				include, err := profit(row)
+				return !include, err
+			}),
+			SplitAnd(&gainsOver200, NewRowSplitter(profit), NewSplitter("buy_price", func(v float64) bool { return v > 200 })),
+		)
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+
+		if want := "symbol,buy_price,sell_price\nAAPL,150.5,170.25\nMSFT,300,310\n"; gains.String() != want {
+			t.Errorf("gains = %q, want %q", gains.String(), want)
+		}
+		if want := "symbol,buy_price,sell_price\nTSLA,250,200\n"; losses.String() != want {
+			t.Errorf("losses = %q, want %q", losses.String(), want)
+		}
+		if want := "symbol,buy_price,sell_price\nMSFT,300,310\n"; gainsOver200.String() != want {
+			t.Errorf("gains over 200 = %q, want %q", gainsOver200.String(), want)
+		}
+	})
+
+	t.Run("or across columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := data.GetCSV(flattener).ExportSplit(SplitOr(&buf,
+			NewSplitter("symbol", func(v string) bool { return v == "AAPL" }),
+			NewRowSplitter(func(row map[string]*DynamicValue) (bool, error) {
+				sell, err := ColumnAs[float64](row, "sell_price")
+				return sell < 250, err
+			}),
+		))
+		if err != nil {
+			t.Fatalf("CSV.ExportSplit() unexpected error = %v", err)
+		}
+
+		if want := "symbol,buy_price,sell_price\nAAPL,150.5,170.25\nTSLA,250,200\n"; buf.String() != want {
+			t.Errorf("CSV.ExportSplit() = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := data.GetCSV(flattener).ExportSplit(SplitRow(&buf, func(row map[string]*DynamicValue) (bool, error) {
+			fee, err := ColumnAs[float64](row, "fee")
+			return fee > 0, err
+		}))
+		if err == nil || !strings.Contains(err.Error(), "column fee does not exist") {
+			t.Errorf("CSV.ExportSplit() error = %v, want missing column error", err)
+		}
+
+		var rowErr *RowError
+		if !errors.As(err, &rowErr) || rowErr.Row != 0 {
+			t.Errorf("CSV.ExportSplit() error = %v, want RowError for row 0", err)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportStats reports the result of an export.
+type ExportStats struct {
+	// RowsProcessed is the number of data rows produced by the flattener.
+	RowsProcessed int
+	// Writers holds the stats of each writer, in the same order the writers were provided.
+	Writers []WriterStats
+	// RowErrors holds the rows skipped because of an error when ContinueOnRowError is used.
+	RowErrors []*ExportError
+}
+
+// ExportError describes an error raised while exporting a row, with the location where it happened.
+type ExportError struct {
+	// Row is the zero based index of the row in the source data.
+	Row int
+	// Column is the header of the column that failed, or empty if the error is not related to a column.
+	Column string
+	// Writer is the zero based index of the writer that failed, or -1 if the error is not related to a writer.
+	Writer int
+	// Err is the error that made the row fail.
+	Err error
+}
+
+// RowError describes a row that failed and was skipped during an export.
+//
+// Deprecated: RowError is an alias of ExportError, use ExportError instead.
+type RowError = ExportError
+
+// Error returns the error message including the row index, and the column and writer when they are known.
+func (e *ExportError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "row %d", e.Row)
+	if e.Column != "" {
+		fmt.Fprintf(&b, ", column %q", e.Column)
+	}
+	if e.Writer >= 0 {
+		fmt.Fprintf(&b, ", writer %d", e.Writer)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExportError) Unwrap() error {
+	return e.Err
+}
+
+// errorLocation annotates an error with the column and writer where it was raised.
+// The export reports them in the ExportError of the row, so the message is the message of err.
+type errorLocation struct {
+	column string
+	writer int
+	err    error
+}
+
+// withColumn annotates err with the column where it was raised.
+func withColumn(column string, err error) error {
+	return &errorLocation{column: column, writer: -1, err: err}
+}
+
+// withWriter annotates err with the writer where it was raised, keeping the column if already annotated.
+func withWriter(writer int, err error) error {
+	var loc *errorLocation
+	if errors.As(err, &loc) {
+		loc.writer = writer
+		return err
+	}
+	return &errorLocation{writer: writer, err: err}
+}
+
+// Error returns the message of the annotated error.
+func (e *errorLocation) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the annotated error.
+func (e *errorLocation) Unwrap() error {
+	return e.err
+}
+
+// newExportError creates the ExportError of a row, locating it with the annotations of err.
+func newExportError(row int, err error) *ExportError {
+	exportErr := &ExportError{Row: row, Writer: -1, Err: err}
+
+	var loc *errorLocation
+	if errors.As(err, &loc) {
+		exportErr.Column = loc.column
+		exportErr.Writer = loc.writer
+	}
+
+	return exportErr
+}
+
+// WriterStats reports the rows and bytes written to a single writer during an export.
+type WriterStats struct {
+	// RowsWritten is the number of data rows written, the header line is not included.
+	RowsWritten int
+	// RowsSkipped is the number of data rows excluded by the writer split condition.
+	RowsSkipped int
+	// RowsDeduped is the number of data rows skipped by WithDedupeConsecutive as duplicates of the previous row.
+	RowsDeduped int
+	// BytesWritten is the number of bytes written, including the header line.
+	BytesWritten int64
+}
+
+// newExportStats creates an ExportStats instance for the given number of writers.
+func newExportStats(writers int) *ExportStats {
+	return &ExportStats{
+		Writers: make([]WriterStats, writers),
+	}
+}
+
+// countingWriter wraps an io.Writer counting the bytes written to it.
+type countingWriter struct {
+	w     io.Writer
+	count *int64
+}
+
+// Write writes p to the underlying writer and adds the written bytes to the count.
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.count += int64(n)
+	return n, err
+}
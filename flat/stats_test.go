@@ -0,0 +1,268 @@
+package flat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExportSplitWithStats(t *testing.T) {
+	data := newDynamicValue([]map[string]any{
+		{"name": "John", "age": float64(30)},
+		{"name": "Jane", "age": float64(25)},
+		{"name": "Bob", "age": float64(35)},
+	})
+	csv := newCsv(data, func(s Source, d Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("age", s.Key("age"))
+	})
+
+	var buf1, buf2, buf3 bytes.Buffer
+	stats, err := csv.ExportSplitWithStats(
+		Split(&buf1, "age", func(v float64) bool { return v >= 30 }),
+		Split(&buf2, "age", func(v float64) bool { return v < 30 }),
+		NoSplit(&buf3),
+	)
+	if err != nil {
+		t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+	}
+
+	want := &ExportStats{
+		RowsProcessed: 3,
+		Writers: []WriterStats{
+			{RowsWritten: 2, RowsSkipped: 1, BytesWritten: int64(buf1.Len())},
+			{RowsWritten: 1, RowsSkipped: 2, BytesWritten: int64(buf2.Len())},
+			{RowsWritten: 3, RowsSkipped: 0, BytesWritten: int64(buf3.Len())},
+		},
+	}
+
+	if stats.RowsProcessed != want.RowsProcessed {
+		t.Errorf("RowsProcessed = %d, want %d", stats.RowsProcessed, want.RowsProcessed)
+	}
+	for i, w := range want.Writers {
+		if stats.Writers[i] != w {
+			t.Errorf("Writers[%d] = %+v, want %+v", i, stats.Writers[i], w)
+		}
+	}
+
+	if got := stats.Writers[0].BytesWritten; got != int64(len("name,age\nJohn,30\nBob,35\n")) {
+		t.Errorf("Writers[0].BytesWritten = %d, want %d", got, len("name,age\nJohn,30\nBob,35\n"))
+	}
+
+	t.Run("stats on error", func(t *testing.T) {
+		stats, err := newErrorCsv(fmt.Errorf("test error")).ExportSplitWithStats(NoSplit(&bytes.Buffer{}))
+		if err == nil {
+			t.Fatal("CSV.ExportSplitWithStats() expected error, got nil")
+		}
+		if stats == nil || len(stats.Writers) != 1 || stats.RowsProcessed != 0 {
+			t.Errorf("CSV.ExportSplitWithStats() stats = %+v, want empty stats for 1 writer", stats)
+		}
+	})
+}
+
+func TestContinueOnRowError(t *testing.T) {
+	stream := `{"id": 1, "price": "1.5"}
+{"id": 2, "price": broken}
+{"id": 3, "price": "2.5"}
+["not", "an", "object"]
+{"id": 5, "price": "abc"}
+{"id": 6, "price": "3"}
+`
+	parsePrice := NewFormatter(func(v string) (float64, error) {
+		return strconv.ParseFloat(v, 64)
+	})
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.ColFormatted("price", s.Key("price"), parsePrice)
+	}
+
+	t.Run("skip and collect", func(t *testing.T) {
+		var buf bytes.Buffer
+		stats, err := StreamJSONFromReader(strings.NewReader(stream)).
+			GetCSV(flattener, ContinueOnRowError(10)).
+			ExportSplitWithStats(NoSplit(&buf))
+		if err != nil {
+			t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+		}
+
+		want := "id,price\n1,1.5\n3,2.5\n6,3\n"
+		if got := buf.String(); got != want {
+			t.Errorf("CSV.ExportSplitWithStats() = %q, want %q", got, want)
+		}
+
+		gotRows := []int{}
+		for _, rowErr := range stats.RowErrors {
+			gotRows = append(gotRows, rowErr.Row)
+		}
+		if !slices.Equal(gotRows, []int{1, 3, 4}) {
+			t.Errorf("RowErrors rows = %v, want %v", gotRows, []int{1, 3, 4})
+		}
+		if stats.RowsProcessed != 6 || stats.Writers[0].RowsWritten != 3 {
+			t.Errorf("stats = %+v, want 6 rows processed and 3 written", stats)
+		}
+	})
+
+	t.Run("abort after max errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		stats, err := StreamJSONFromReader(strings.NewReader(stream)).
+			GetCSV(flattener, ContinueOnRowError(2)).
+			ExportSplitWithStats(NoSplit(&buf))
+		if err == nil {
+			t.Fatal("CSV.ExportSplitWithStats() expected error after too many row errors, got nil")
+		}
+
+		var rowErr *RowError
+		if !errors.As(err, &rowErr) || rowErr.Row != 4 {
+			t.Errorf("CSV.ExportSplitWithStats() error = %v, want row error for row 4", err)
+		}
+		if len(stats.RowErrors) != 3 {
+			t.Errorf("RowErrors = %d, want 3", len(stats.RowErrors))
+		}
+	})
+
+	t.Run("fail fast by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		stats, err := StreamJSONFromReader(strings.NewReader(stream)).
+			GetCSV(flattener).
+			ExportSplitWithStats(NoSplit(&buf))
+		if err == nil {
+			t.Fatal("CSV.ExportSplitWithStats() expected error, got nil")
+		}
+		if len(stats.RowErrors) != 0 {
+			t.Errorf("RowErrors = %d, want 0 in fail fast mode", len(stats.RowErrors))
+		}
+	})
+
+	t.Run("split on a failing row writes nothing", func(t *testing.T) {
+		var buf1, buf2 bytes.Buffer
+		data := newDynamicValue([]map[string]any{
+			{"id": 1, "price": "1"},
+			{"id": 2, "price": "bad"},
+		})
+		stats, err := data.GetCSV(flattener, ContinueOnRowError(-1)).ExportSplitWithStats(
+			Split(&buf1, "id", func(v int) bool { return true }),
+			NoSplit(&buf2),
+		)
+		if err != nil {
+			t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+		}
+		want := "id,price\n1,1\n"
+		if buf1.String() != want || buf2.String() != want {
+			t.Errorf("CSV.ExportSplitWithStats() = %q, %q, want %q", buf1.String(), buf2.String(), want)
+		}
+		if len(stats.RowErrors) != 1 || stats.RowErrors[0].Row != 1 {
+			t.Errorf("RowErrors = %v, want row 1", stats.RowErrors)
+		}
+	})
+
+	t.Run("csv input parse errors", func(t *testing.T) {
+		var buf bytes.Buffer
+		stats, err := ReadCSVFromReader(strings.NewReader("id,price\n1,1\n2\n3,3\n")).
+			GetCSV(flattener, ContinueOnRowError(5)).
+			ExportSplitWithStats(NoSplit(&buf))
+		if err != nil {
+			t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+		}
+		if want := "id,price\n1,1\n3,3\n"; buf.String() != want {
+			t.Errorf("CSV.ExportSplitWithStats() = %q, want %q", buf.String(), want)
+		}
+		if len(stats.RowErrors) != 1 || stats.RowErrors[0].Row != 1 {
+			t.Errorf("RowErrors = %v, want row 1", stats.RowErrors)
+		}
+	})
+}
+
+func TestExportErrorLocation(t *testing.T) {
+	const badRow = 731
+
+	items := make([]map[string]any, 1000)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "age": 20 + i%50, "price": "1.5"}
+	}
+	items[badRow]["age"] = "thirty"
+	items[badRow]["price"] = "abc"
+
+	parsePrice := NewFormatter(func(v string) (float64, error) {
+		return strconv.ParseFloat(v, 64)
+	})
+
+	tests := []struct {
+		name       string
+		flattener  flattener
+		splitters  func() []splitWriter
+		wantColumn string
+		wantWriter int
+		wantMsg    string
+	}{
+		{
+			name: "split type mismatch",
+			flattener: func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+				d.Col("age", s.Key("age"))
+			},
+			splitters: func() []splitWriter {
+				return []splitWriter{
+					NoSplit(&bytes.Buffer{}),
+					Split(&bytes.Buffer{}, "age", func(v int) bool { return v >= 30 }),
+				}
+			},
+			wantColumn: "age",
+			wantWriter: 1,
+			wantMsg:    `row 731, column "age", writer 1: split function type mismatch with data type`,
+		},
+		{
+			name: "formatter error",
+			flattener: func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+				d.ColFormatted("price", s.Key("price"), parsePrice)
+			},
+			splitters: func() []splitWriter {
+				return []splitWriter{NoSplit(&bytes.Buffer{})}
+			},
+			wantColumn: "price",
+			wantWriter: -1,
+			wantMsg:    `row 731, column "price": failed to get value: data contains error: error formatting data:`,
+		},
+		{
+			name: "row splitter error",
+			flattener: func(s Source, d Dest) {
+				d.Col("id", s.Key("id"))
+				d.Col("age", s.Key("age"))
+			},
+			splitters: func() []splitWriter {
+				return []splitWriter{
+					NoSplit(&bytes.Buffer{}),
+					NoSplit(&bytes.Buffer{}),
+					SplitRow(&bytes.Buffer{}, func(row map[string]*DynamicValue) (bool, error) {
+						age, err := ColumnAs[int](row, "age")
+						return age > 30, err
+					}),
+				}
+			},
+			wantWriter: 2,
+			wantMsg:    `row 731, writer 2: error checking row split condition: column age type mismatch with data type`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newDynamicValue(items).GetCSV(tt.flattener).ExportSplit(tt.splitters()...)
+
+			var exportErr *ExportError
+			if !errors.As(err, &exportErr) {
+				t.Fatalf("CSV.ExportSplit() error = %v, want ExportError", err)
+			}
+			if exportErr.Row != badRow || exportErr.Column != tt.wantColumn || exportErr.Writer != tt.wantWriter {
+				t.Errorf("ExportError = {Row: %d, Column: %q, Writer: %d}, want {Row: %d, Column: %q, Writer: %d}",
+					exportErr.Row, exportErr.Column, exportErr.Writer, badRow, tt.wantColumn, tt.wantWriter)
+			}
+			if !strings.HasPrefix(err.Error(), tt.wantMsg) {
+				t.Errorf("CSV.ExportSplit() error = %q, want prefix %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package flat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// structField describes how a struct field is exposed as an object key.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	quoted    bool
+}
+
+// structFieldsCache caches the exposed fields per struct type.
+var structFieldsCache sync.Map
+
+// structSlice holds a slice of structs that is converted to objects lazily, one element at a time.
+// This avoids materializing the whole dataset as maps when exporting large slices.
+type structSlice struct {
+	value reflect.Value
+}
+
+// FromStructs creates a new DynamicValue instance from a struct, a pointer to a struct or a slice of structs.
+// Fields are exposed using their json tag names following the encoding/json rules: nested structs,
+// pointers, embedded fields, "-", omitempty and string are supported, time.Time values are exposed as RFC3339 strings
+// and numbers keep the same literal representation that encoding/json would produce.
+// Slices of structs are converted lazily, each element is only converted when it is accessed.
+func FromStructs(v any) *DynamicValue {
+	return newDynamicValue(v)
+}
+
+// newDynamicValueFromReflection creates a DynamicValue from values that are not one of the JSON decoded types.
+func newDynamicValueFromReflection(d any) *DynamicValue {
+	rv := reflect.ValueOf(d)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return &DynamicValue{dataType: DataTypeNull, value: nil}
+		}
+		rv = rv.Elem()
+	}
+
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && isStructType(rv.Type().Elem()) {
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return &DynamicValue{dataType: DataTypeNull, value: nil}
+		}
+		return &DynamicValue{dataType: DataTypeArrayOfObjects, value: &structSlice{value: rv}}
+	}
+
+	value, err := reflectToJSONValue(rv)
+	if err != nil {
+		return errorDynamicValue(err)
+	}
+
+	return &DynamicValue{dataType: getDataTypeFromValue(value), value: value}
+}
+
+// isStructType reports whether t is a struct, or a pointer to a struct, that is not marshaled as a JSON scalar.
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !t.Implements(jsonMarshalerType) &&
+		!reflect.PointerTo(t).Implements(jsonMarshalerType)
+}
+
+// len returns the number of elements in the slice.
+func (s *structSlice) len() int {
+	return s.value.Len()
+}
+
+// object converts the element at index i into an object.
+func (s *structSlice) object(i int) (map[string]any, error) {
+	value, err := reflectToJSONValue(s.value.Index(i))
+	if err != nil {
+		return nil, err
+	}
+
+	obj, _ := value.(map[string]any)
+	return obj, nil
+}
+
+// MarshalJSON encodes the slice as the array of objects it represents.
+func (s *structSlice) MarshalJSON() ([]byte, error) {
+	value, err := reflectToJSONValue(s.value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// reflectToJSONValue converts a value into the representation produced by decoding its JSON encoding,
+// using json.Number for numbers.
+func reflectToJSONValue(rv reflect.Value) (any, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	if rv.Type() == timeType {
+		return rv.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+
+	if rv.Kind() != reflect.Pointer && rv.Kind() != reflect.Interface && rv.Type().Implements(jsonMarshalerType) {
+		return marshalerToJSONValue(rv)
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Kind() == reflect.Pointer && rv.Type().Implements(jsonMarshalerType) && rv.Elem().Type() != timeType {
+			return marshalerToJSONValue(rv)
+		}
+		return reflectToJSONValue(rv.Elem())
+	case reflect.Struct:
+		if reflect.PointerTo(rv.Type()).Implements(jsonMarshalerType) && rv.CanAddr() {
+			return marshalerToJSONValue(rv.Addr())
+		}
+		return structToJSONValue(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		obj := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			value, err := reflectToJSONValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			obj[fmt.Sprint(iter.Key().Interface())] = value
+		}
+		return obj, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 && rv.Kind() == reflect.Slice {
+			return base64.StdEncoding.EncodeToString(rv.Bytes()), nil
+		}
+		arr := make([]any, rv.Len())
+		for i := range arr {
+			value, err := reflectToJSONValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = value
+		}
+		return arr, nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return marshalerToJSONValue(rv)
+	default:
+		return nil, fmt.Errorf("unsupported value type: %s", rv.Type())
+	}
+}
+
+// marshalerToJSONValue converts a value by encoding it to JSON and decoding the result.
+func marshalerToJSONValue(rv reflect.Value) (any, error) {
+	data, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", rv.Type(), err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", rv.Type(), err)
+	}
+	return value, nil
+}
+
+// structToJSONValue converts a struct into an object keyed by the json names of its fields.
+func structToJSONValue(rv reflect.Value) (map[string]any, error) {
+	fields := getStructFields(rv.Type())
+	obj := make(map[string]any, len(fields))
+
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		var (
+			value any
+			err   error
+		)
+		if f.quoted {
+			value, err = quotedToJSONValue(fv)
+		} else {
+			value, err = reflectToJSONValue(fv)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		obj[f.name] = value
+	}
+
+	return obj, nil
+}
+
+// isEmptyValue reports whether a field tagged with omitempty is omitted, following the encoding/json rules:
+// false, 0, nil pointers and interfaces, and empty arrays, slices, maps and strings. Structs are never empty.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	default:
+		return false
+	}
+}
+
+// quotedToJSONValue converts a field tagged with the string option into the string holding its JSON encoding,
+// i.e. "123" for the int 123, as encoding/json does. Nil pointers are still converted to null.
+func quotedToJSONValue(rv reflect.Value) (any, error) {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	data, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", rv.Type(), err)
+	}
+	return string(data), nil
+}
+
+// fieldByIndex returns the nested field for index, it returns false if an embedded pointer is nil.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// getStructFields returns the exposed fields of a struct type, using a cache to avoid repeated lookups.
+func getStructFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	fields := collectStructFields(t, nil)
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// collectStructFields collects the exposed fields of t, promoting the fields of untagged embedded structs.
+// Fields declared closer to the root take precedence over promoted fields with the same name.
+func collectStructFields(t reflect.Type, parentIndex []int) []structField {
+	var own, promoted []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		index := append(append([]int{}, parentIndex...), i)
+
+		fieldType := sf.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if sf.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			promoted = append(promoted, collectStructFields(fieldType, index)...)
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		own = append(own, structField{
+			name:      name,
+			index:     index,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			quoted:    strings.Contains(","+opts+",", ",string,") && isQuotableType(fieldType),
+		})
+	}
+
+	fields := own
+	for _, p := range promoted {
+		if !containsField(fields, p.name) {
+			fields = append(fields, p)
+		}
+	}
+
+	return fields
+}
+
+// isQuotableType reports whether the string option applies to fields of type t, like in encoding/json:
+// strings, booleans and numbers, unless they are marshaled by their own MarshalJSON method.
+func isQuotableType(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// containsField reports whether fields contains a field with the given name.
+func containsField(fields []structField, name string) bool {
+	for _, f := range fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
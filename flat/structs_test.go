@@ -0,0 +1,288 @@
+package flat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type testAudit struct {
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testCustomer struct {
+	Name  string  `json:"name"`
+	Email *string `json:"email"`
+}
+
+type testOrder struct {
+	testAudit
+	ID       int64           `json:"id"`
+	Total    float64         `json:"total"`
+	Price    decimal.Decimal `json:"price"`
+	Paid     bool            `json:"paid"`
+	Customer *testCustomer   `json:"customer"`
+	Items    []string        `json:"items"`
+	Notes    string          `json:"notes,omitempty"`
+	Secret   string          `json:"-"`
+	Untagged int
+	internal string
+}
+
+func testOrders() []testOrder {
+	email := "john@example.com"
+	created := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	return []testOrder{
+		{
+			testAudit: testAudit{CreatedBy: "admin", CreatedAt: created},
+			ID:        9007199254740993,
+			Total:     0.0000001,
+			Price:     decimal.RequireFromString("0.00001234"),
+			Paid:      true,
+			Customer:  &testCustomer{Name: "John", Email: &email},
+			Items:     []string{"a", "b"},
+			Notes:     "first",
+			Secret:    "hidden",
+			Untagged:  1,
+			internal:  "internal",
+		},
+		{
+			testAudit: testAudit{CreatedBy: "system", CreatedAt: created.Add(time.Hour)},
+			ID:        2,
+			Total:     12345678.9,
+			Price:     decimal.NewFromInt(5),
+			Customer:  &testCustomer{Name: "Jane"},
+		},
+		{
+			ID:    3,
+			Total: 1e21,
+		},
+	}
+}
+
+func testOrderFlattener(s Source, d Dest) {
+	d.Col("id", s.Key("id"))
+	d.Col("total", s.Key("total"))
+	d.Col("price", s.Key("price"))
+	d.Col("paid", s.Key("paid"))
+	d.Col("customer", s.Key("customer", "name"))
+	d.Col("email", s.Key("customer", "email"))
+	d.Col("customer_obj", s.Key("customer"))
+	d.Col("first_item", s.Key("items").Idx(0))
+	d.Col("items", s.Key("items"))
+	d.Col("notes", s.Key("notes"))
+	d.Col("secret", s.Key("Secret"))
+	d.Col("untagged", s.Key("Untagged"))
+	d.Col("internal", s.Key("internal"))
+	d.Col("created_by", s.Key("created_by"))
+	d.Col("created_at", s.Key("created_at"))
+}
+
+// TestFromStructsMatchesJSON tests that struct inputs export the same CSV as their JSON decoded equivalent
+func TestFromStructsMatchesJSON(t *testing.T) {
+	orders := testOrders()
+
+	encoded, err := json.Marshal(orders)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error = %v", err)
+	}
+
+	var jsonBuf, structBuf, ptrBuf bytes.Buffer
+	if err := ReadJSONFromReader(bytes.NewReader(encoded)).GetCSV(testOrderFlattener).Export(&jsonBuf); err != nil {
+		t.Fatalf("JSON CSV.Export() unexpected error = %v", err)
+	}
+	if err := FromStructs(orders).GetCSV(testOrderFlattener).Export(&structBuf); err != nil {
+		t.Fatalf("struct CSV.Export() unexpected error = %v", err)
+	}
+	if err := FromStructs(&orders).GetCSV(testOrderFlattener).Export(&ptrBuf); err != nil {
+		t.Fatalf("pointer CSV.Export() unexpected error = %v", err)
+	}
+
+	if structBuf.String() != jsonBuf.String() {
+		t.Errorf("FromStructs() CSV = %q, want %q", structBuf.String(), jsonBuf.String())
+	}
+	if ptrBuf.String() != jsonBuf.String() {
+		t.Errorf("FromStructs() pointer CSV = %q, want %q", ptrBuf.String(), jsonBuf.String())
+	}
+}
+
+type testTagged struct {
+	Audit    testAudit      `json:"audit,omitempty"`
+	Empty    []string       `json:"empty,omitempty"`
+	EmptyMap map[string]int `json:"empty_map,omitempty"`
+	NilMap   map[string]int `json:"nil_map,omitempty"`
+	Array    [0]int         `json:"array,omitempty"`
+	Zero     int            `json:"zero,omitempty,string"`
+	Count    int64          `json:"count,string"`
+	Ratio    float64        `json:"ratio,string"`
+	Paid     *bool          `json:"paid,string"`
+	Missing  *int           `json:"missing,string"`
+	Name     string         `json:"name,string"`
+	Items    []int          `json:"items,string"`
+	Price    testPrice      `json:"price,string"`
+}
+
+// testPrice is marshaled by its own method, which the string option does not quote
+type testPrice float64
+
+func (p testPrice) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%.2f", float64(p))), nil
+}
+
+// TestFromStructsTagOptions tests that omitempty and string follow the encoding/json rules
+func TestFromStructsTagOptions(t *testing.T) {
+	paid := true
+	tagged := []testTagged{
+		{
+			Empty:    []string{},
+			EmptyMap: map[string]int{},
+			Count:    9007199254740993,
+			Ratio:    1e21,
+			Paid:     &paid,
+			Name:     `a "quoted" <name>`,
+			Items:    []int{1, 2},
+			Price:    12.5,
+		},
+		{Audit: testAudit{CreatedBy: "admin"}, Empty: []string{"a"}, NilMap: map[string]int{"a": 1}, Zero: 7},
+	}
+	columns := []string{"audit", "empty", "empty_map", "nil_map", "array", "zero", "count", "ratio", "paid", "missing", "name", "items", "price"}
+	flattener := func(s Source, d Dest) {
+		d.Col("keys", Source{data: newDynamicValue(strings.Join(s.Keys(), " "))})
+		for _, column := range columns {
+			d.Col(column, s.Key(column))
+		}
+	}
+
+	encoded, err := json.Marshal(tagged)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error = %v", err)
+	}
+
+	want, err := ReadJSONFromReader(bytes.NewReader(encoded)).GetCSV(flattener).ExportRecords()
+	if err != nil {
+		t.Fatalf("JSON CSV.ExportRecords() unexpected error = %v", err)
+	}
+	got, err := FromStructs(tagged).GetCSV(flattener).ExportRecords()
+	if err != nil {
+		t.Fatalf("struct CSV.ExportRecords() unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStructs() records = %q, want %q", got, want)
+	}
+
+	wantKeys := []string{
+		"audit count items missing name paid price ratio",
+		"audit count empty items missing name nil_map paid price ratio zero",
+	}
+	for i, keys := range wantKeys {
+		if got[i+1][0] != keys {
+			t.Errorf("FromStructs() element %d keys = %q, want %q", i, got[i+1][0], keys)
+		}
+	}
+	if wantQuoted := []string{"9007199254740993", "1e+21", "true", "", `"a \"quoted\" \u003cname\u003e"`, "[1,2]", "12.5"}; !reflect.DeepEqual(got[1][7:], wantQuoted) {
+		t.Errorf("FromStructs() quoted values = %q, want %q", got[1][7:], wantQuoted)
+	}
+}
+
+func TestFromStructs(t *testing.T) {
+	orders := testOrders()
+
+	tests := []struct {
+		name     string
+		data     *DynamicValue
+		wantType DataType
+		want     string
+	}{
+		{name: "slice of structs", data: FromStructs(orders), wantType: DataTypeArrayOfObjects},
+		{name: "slice of pointers", data: FromStructs([]*testOrder{&orders[0]}), wantType: DataTypeArrayOfObjects},
+		{name: "single struct", data: FromStructs(orders[1]), wantType: DataTypeObject},
+		{name: "nil pointer", data: FromStructs((*testOrder)(nil)), wantType: DataTypeNull},
+		{name: "int field", data: FromStructs(orders[0]).Key("id"), wantType: DataTypeNumber, want: "9007199254740993"},
+		{name: "embedded field", data: FromStructs(orders[0]).Key("created_by"), wantType: DataTypeString, want: "admin"},
+		{name: "time field", data: FromStructs(orders[0]).Key("created_at"), wantType: DataTypeString, want: "2024-03-15T10:30:00Z"},
+		{name: "nested pointer", data: FromStructs(orders[0]).Key("customer", "email"), wantType: DataTypeString, want: "john@example.com"},
+		{name: "nil nested pointer", data: FromStructs(orders[1]).Key("customer", "email"), wantType: DataTypeNull, want: ""},
+		{name: "marshaler field", data: FromStructs(orders[0]).Key("price"), wantType: DataTypeString, want: "0.00001234"},
+		{name: "last element", data: FromStructs(orders).Idx(-1).Key("id"), wantType: DataTypeNumber, want: "3"},
+		{name: "map of structs", data: FromStructs(map[string]testCustomer{"c": {Name: "John"}}).Key("c", "name"), wantType: DataTypeString, want: "John"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.data.Error() != nil {
+				t.Fatalf("FromStructs() unexpected error = %v", tt.data.Error())
+			}
+			if tt.data.DataType() != tt.wantType {
+				t.Errorf("FromStructs() type = %v, want %v", tt.data.DataType(), tt.wantType)
+			}
+			if tt.want == "" {
+				return
+			}
+			got, err := tt.data.strVal()
+			if err != nil {
+				t.Fatalf("strVal() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("strVal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("len and omitempty", func(t *testing.T) {
+		data := FromStructs(orders)
+		if got := data.Len(); got != len(orders) {
+			t.Errorf("Len() = %d, want %d", got, len(orders))
+		}
+		if data.Idx(1).Key("notes").Exists() {
+			t.Error("omitempty field exists, want missing")
+		}
+		if data.Idx(0).Key("Secret").Exists() {
+			t.Error(`"-" tagged field exists, want missing`)
+		}
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		if FromStructs(func() {}).Error() == nil {
+			t.Error("FromStructs() expected error for unsupported value, got nil")
+		}
+	})
+}
+
+func BenchmarkFromStructs(b *testing.B) {
+	orders := make([]testOrder, 0, 1000)
+	for i := 0; i < 1000/len(testOrders()); i++ {
+		orders = append(orders, testOrders()...)
+	}
+
+	b.Run("reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			if err := FromStructs(orders).GetCSV(testOrderFlattener).Export(&buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("marshal round trip", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			encoded, err := json.Marshal(orders)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := ReadJSONFromReader(bytes.NewReader(encoded)).GetCSV(testOrderFlattener).Export(&buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
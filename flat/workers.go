@@ -0,0 +1,96 @@
+package flat
+
+import (
+	"context"
+	"sync"
+)
+
+// workerWindowFactor is the number of rows per worker that can be in flight before they are written.
+// It bounds the memory used to reorder the rows when a slow row holds back the following ones.
+const workerWindowFactor = 4
+
+// flattenConcurrently flattens the rows received from source using the configured number of workers.
+// Rows are numbered in the order they are received and reassembled in that order before being sent
+// to the returned channel, so the first row keeps defining the headers.
+// All the goroutines stop when ctx is cancelled, and the returned channel is closed once source is closed
+// and every row has been sent.
+func (t *CSV) flattenConcurrently(ctx context.Context, source <-chan *row) <-chan *row {
+	workers := t.options.workers
+	window := make(chan struct{}, workers*workerWindowFactor)
+	jobs := make(chan *row, workers)
+	results := make(chan *row, workers)
+	out := make(chan *row, workers)
+
+	// Dispatch the rows numbering them in order
+	go func() {
+		defer close(jobs)
+		seq := 0
+		for r := range source {
+			r.seq = seq
+			seq++
+
+			select {
+			case <-ctx.Done():
+				return
+			case window <- struct{}{}:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- r:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				if r.err == nil {
+					t.flattenRow(r)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case results <- r:
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Reassemble the rows in order
+	go func() {
+		defer close(out)
+		pending := make(map[int]*row)
+		next := 0
+		for r := range results {
+			pending[r.seq] = r
+			for {
+				ready, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+
+				select {
+				case <-ctx.Done():
+					return
+				case out <- ready:
+				}
+				<-window
+			}
+		}
+	}()
+
+	return out
+}
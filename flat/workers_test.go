@@ -0,0 +1,141 @@
+package flat
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func testWorkerRows(n int) []map[string]any {
+	rows := make([]map[string]any, n)
+	for i := range rows {
+		rows[i] = map[string]any{"id": i, "name": fmt.Sprintf("row-%d", i), "price": float64(i) / 4}
+	}
+	return rows
+}
+
+// TestWorkersPreserveOrder tests that concurrent flattening produces the same output as sequential flattening
+func TestWorkersPreserveOrder(t *testing.T) {
+	data := newDynamicValue(testWorkerRows(1000))
+
+	// Random delays make the workers finish out of order
+	flattener := func(s Source, d Dest) {
+		time.Sleep(time.Duration(rand.Intn(50)) * time.Microsecond)
+		d.Col("id", s.Key("id"))
+		d.Col("name", s.Key("name"))
+		d.Col("price", s.Key("price"))
+	}
+
+	var want bytes.Buffer
+	if err := data.GetCSV(flattener).Export(&want); err != nil {
+		t.Fatalf("CSV.Export() unexpected error = %v", err)
+	}
+
+	for _, workers := range []int{0, 1, 2, 8} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			var got bytes.Buffer
+			stats, err := data.GetCSV(flattener, WithWorkers(workers)).ExportSplitWithStats(NoSplit(&got))
+			if err != nil {
+				t.Fatalf("CSV.ExportSplitWithStats() unexpected error = %v", err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("CSV.ExportSplitWithStats() output differs from sequential export")
+			}
+			if stats.RowsProcessed != 1000 {
+				t.Errorf("RowsProcessed = %d, want 1000", stats.RowsProcessed)
+			}
+		})
+	}
+
+	t.Run("stream source", func(t *testing.T) {
+		ch := make(chan map[string]any)
+		go func() {
+			defer close(ch)
+			for _, r := range testWorkerRows(1000) {
+				ch <- r
+			}
+		}()
+
+		var got bytes.Buffer
+		if err := StreamFromChannel(ch).GetCSV(flattener, WithWorkers(4)).Export(&got); err != nil {
+			t.Fatalf("CSV.Export() unexpected error = %v", err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("CSV.Export() output differs from sequential export")
+		}
+	})
+}
+
+// TestWorkersError tests that a failing row stops the concurrent export and reports the row
+func TestWorkersError(t *testing.T) {
+	rows := testWorkerRows(1000)
+	rows[500]["price"] = "not a price"
+
+	var buf bytes.Buffer
+	err := newDynamicValue(rows).GetCSV(func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.ColFormatted("price", s.Key("price"), NewSafeFormatter(func(v float64) float64 { return v * 2 }))
+	}, WithWorkers(8)).Export(&buf)
+
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != 500 {
+		t.Errorf("CSV.Export() error = %v, want row error for row 500", err)
+	}
+}
+
+// TestWorkersCancellation tests that cancelling an export flattened by workers returns the context error,
+// even when every row has already been produced. Run with -race to check the producer is joined.
+func TestWorkersCancellation(t *testing.T) {
+	data := newDynamicValue(testWorkerRows(50))
+
+	for i := 0; i < 200; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		flattener := func(s Source, d Dest) {
+			if id, _ := SourceAs[int](s.Key("id")); id == 10 {
+				cancel()
+			}
+			d.Col("id", s.Key("id"))
+		}
+
+		var buf bytes.Buffer
+		err := data.GetCSV(flattener, WithWorkers(4), WithRowBuffer(64)).ExportContext(ctx, &buf)
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("export %d: CSV.ExportContext() error = %v, want %v", i, err, context.Canceled)
+		}
+	}
+}
+
+// slowFormatter is a CPU-bound formatter used to benchmark concurrent flattening
+var slowFormatter = NewSafeFormatter(func(v string) string {
+	sum := []byte(v)
+	for i := 0; i < 500; i++ {
+		h := sha256.Sum256(sum)
+		sum = h[:]
+	}
+	return fmt.Sprintf("%x", sum[:4])
+})
+
+func BenchmarkWorkers(b *testing.B) {
+	data := newDynamicValue(testWorkerRows(2000))
+	flattener := func(s Source, d Dest) {
+		d.Col("id", s.Key("id"))
+		d.ColFormatted("hash", s.Key("name"), slowFormatter)
+	}
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var buf bytes.Buffer
+				if err := data.GetCSV(flattener, WithWorkers(workers)).Export(&buf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package flat_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/flat"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestExportFlushError(t *testing.T) {
+	writeErr := errors.New("disk full")
+	data := flat.ReadJSONFromReader(strings.NewReader(`[{"name": "John"}]`))
+
+	// the CSV writer is buffered, so the output is only written when the export flushes it
+	w := stmocks.FailingWriter(1, writeErr)
+	err := data.GetCSV(func(s flat.Source, d flat.Dest) {
+		d.Col("name", s.Key("name"))
+	}).Export(w)
+
+	if !errors.Is(err, writeErr) || !strings.Contains(err.Error(), "failed to flush CSV writer") {
+		t.Errorf("CSV.Export() error = %v, want flush error wrapping %v", err, writeErr)
+	}
+	if w.Writes() != 1 || w.Bytes() != 0 {
+		t.Errorf("Writes(), Bytes() = %d, %d, want the single flush write to fail", w.Writes(), w.Bytes())
+	}
+}
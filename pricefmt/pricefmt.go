@@ -2,6 +2,7 @@ package pricefmt
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/shopspring/decimal"
@@ -36,7 +37,12 @@ type priceInput interface {
 
 // PriceFormatted holds the formatted price data, including currency and subscript information.
 type PriceFormatted struct {
-	UseSubscript      bool
+	// UseSubscript is set when the small decimal has at least the subscript length of leading zeros.
+	UseSubscript bool
+	// IsSmallDecimal is set when the absolute value is between 0 and 1 exclusive and has at least one leading zero
+	// after the decimal point, i.e. 0.05, whether it uses subscript formatting or not. ZerosAfterDecimal and
+	// AfterZerosValue are only set for small decimals.
+	IsSmallDecimal    bool
 	RawValue          string
 	CurrencyCode      string
 	CurrencyString    string
@@ -138,12 +144,91 @@ func FormatWithOptions[T priceInput](price T, currencyCode string, subscriptLeng
 	afterZerosValue := afterZerosValueDecimal.IntPart()
 
 	priceData.UseSubscript = leadingZeroesCount >= subscriptLength
+	priceData.IsSmallDecimal = true
 	priceData.ZerosAfterDecimal = &leadingZeroesCount
 	priceData.AfterZerosValue = &afterZerosValue
 
 	return priceData, nil
 }
 
+// PairFormatted holds the formatted data of two prices displayed side by side, i.e. the bid and ask of an
+// order book, and of their spread.
+type PairFormatted struct {
+	A *PriceFormatted
+	B *PriceFormatted
+	// Spread is B minus A, negative when B is the lower price.
+	Spread *PriceFormatted
+}
+
+// FormatPair gets formatting data for two prices displayed side by side so they share the same precision.
+// The RawValue of both prices and of their spread has the decimals of the more precise of the two, and
+// their AfterZerosValue keeps the digits up to that precision, limited to the default value length.
+// When either price uses subscript formatting, every small decimal of the pair does.
+func FormatPair[T priceInput](a, b T, currencyCode string) (*PairFormatted, error) {
+	dA, err := getDecimalValue(a)
+	if err != nil {
+		return nil, fmt.Errorf("error converting first price to decimal: %w", err)
+	}
+	dB, err := getDecimalValue(b)
+	if err != nil {
+		return nil, fmt.Errorf("error converting second price to decimal: %w", err)
+	}
+
+	places := max(decimalPlaces(dA), decimalPlaces(dB))
+
+	pair := &PairFormatted{}
+	if pair.A, err = formatFixed(dA, places, currencyCode); err != nil {
+		return nil, err
+	}
+	if pair.B, err = formatFixed(dB, places, currencyCode); err != nil {
+		return nil, err
+	}
+	if pair.Spread, err = formatFixed(dB.Sub(dA), places, currencyCode); err != nil {
+		return nil, err
+	}
+
+	if pair.A.UseSubscript || pair.B.UseSubscript {
+		for _, priceData := range []*PriceFormatted{pair.A, pair.B, pair.Spread} {
+			priceData.UseSubscript = priceData.IsSmallDecimal
+		}
+	}
+
+	return pair, nil
+}
+
+// decimalPlaces returns the number of decimals of a price, including its trailing zeros.
+func decimalPlaces(d decimal.Decimal) int32 {
+	return max(-d.Exponent(), 0)
+}
+
+// formatFixed gets formatting data for a price with its RawValue and AfterZerosValue padded to places decimals.
+// places must not be lower than the decimals of the price.
+func formatFixed(d decimal.Decimal, places int32, currencyCode string) (*PriceFormatted, error) {
+	priceData, err := FormatWithCurrency(d, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	priceData.RawValue = d.StringFixed(places)
+	if !priceData.IsSmallDecimal {
+		return priceData, nil
+	}
+
+	decimalPart := strings.SplitN(d.Abs().StringFixed(places), ".", 2)[1]
+	afterZerosStr := decimalPart[*priceData.ZerosAfterDecimal:]
+	if len(afterZerosStr) > defaultValueLength {
+		afterZerosStr = afterZerosStr[:defaultValueLength]
+	}
+
+	afterZerosValue, err := strconv.ParseInt(afterZerosStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing after zeros value: %w", err)
+	}
+	priceData.AfterZerosValue = &afterZerosValue
+
+	return priceData, nil
+}
+
 // getDecimalValue converts various types of price inputs to a decimal.Decimal.
 func getDecimalValue(price any) (decimal.Decimal, error) {
 	switch v := price.(type) {
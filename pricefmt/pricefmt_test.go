@@ -34,6 +34,7 @@ func runFormatTests[T priceInput](t *testing.T, tests []formatTestCase[T]) {
 				assert.NoError(t, err, "FormatWithCurrency should not return an error for valid input")
 				assert.NotNil(t, formatted, "Formatted price should not be nil")
 				assert.Equal(t, tt.expected.UseSubscript, formatted.UseSubscript, "UseSubscript mismatch")
+				assert.Equal(t, tt.expected.IsSmallDecimal, formatted.IsSmallDecimal, "IsSmallDecimal mismatch")
 				assert.Equal(t, tt.expected.RawValue, formatted.RawValue, "RawValue mismatch")
 				assert.Equal(t, tt.expected.CurrencyCode, formatted.CurrencyCode, "CurrencyCode mismatch")
 				assert.Equal(t, tt.expected.CurrencyString, formatted.CurrencyString, "CurrencyString mismatch")
@@ -49,6 +50,7 @@ func runFormatTests[T priceInput](t *testing.T, tests []formatTestCase[T]) {
 			} else {
 				assert.NotNil(t, tryFormatted, "TryFormatWithCurrency should not return nil")
 				assert.Equal(t, tt.expected.UseSubscript, tryFormatted.UseSubscript, "TryFormatWithCurrency UseSubscript mismatch")
+				assert.Equal(t, tt.expected.IsSmallDecimal, tryFormatted.IsSmallDecimal, "TryFormatWithCurrency IsSmallDecimal mismatch")
 				assert.Equal(t, tt.expected.RawValue, tryFormatted.RawValue, "TryFormatWithCurrency RawValue mismatch")
 				assert.Equal(t, tt.expected.CurrencyCode, tryFormatted.CurrencyCode, "TryFormatWithCurrency CurrencyCode mismatch")
 				assert.Equal(t, tt.expected.CurrencyString, tryFormatted.CurrencyString, "TryFormatWithCurrency CurrencyString mismatch")
@@ -73,6 +75,7 @@ func runFormatDefaultCurrencyTests[T priceInput](t *testing.T, tests []formatTes
 				assert.NoError(t, err, "Format should not return an error for valid input")
 				assert.NotNil(t, formatted, "Formatted price should not be nil")
 				assert.Equal(t, tt.expected.UseSubscript, formatted.UseSubscript, "UseSubscript mismatch")
+				assert.Equal(t, tt.expected.IsSmallDecimal, formatted.IsSmallDecimal, "IsSmallDecimal mismatch")
 				assert.Equal(t, tt.expected.RawValue, formatted.RawValue, "RawValue mismatch")
 				assert.Equal(t, tt.expected.CurrencyCode, formatted.CurrencyCode, "CurrencyCode mismatch")
 				assert.Equal(t, tt.expected.CurrencyString, formatted.CurrencyString, "CurrencyString mismatch")
@@ -88,6 +91,7 @@ func runFormatDefaultCurrencyTests[T priceInput](t *testing.T, tests []formatTes
 			} else {
 				assert.NotNil(t, tryFormatted, "TryFormat should not return nil")
 				assert.Equal(t, tt.expected.UseSubscript, tryFormatted.UseSubscript, "TryFormat UseSubscript mismatch")
+				assert.Equal(t, tt.expected.IsSmallDecimal, tryFormatted.IsSmallDecimal, "TryFormat IsSmallDecimal mismatch")
 				assert.Equal(t, tt.expected.RawValue, tryFormatted.RawValue, "TryFormat RawValue mismatch")
 				assert.Equal(t, tt.expected.CurrencyCode, tryFormatted.CurrencyCode, "TryFormat CurrencyCode mismatch")
 				assert.Equal(t, tt.expected.CurrencyString, tryFormatted.CurrencyString, "TryFormat CurrencyString mismatch")
@@ -113,6 +117,7 @@ func TestFormatWithCurrency_Int(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -128,6 +133,7 @@ func TestFormatWithCurrency_Int(t *testing.T) {
 				CurrencyCode:      CurrencyCodeGBP,
 				CurrencyString:    "£",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -143,6 +149,7 @@ func TestFormatWithCurrency_Int(t *testing.T) {
 				CurrencyCode:      CurrencyCodeAUD,
 				CurrencyString:    "A$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -158,6 +165,7 @@ func TestFormatWithCurrency_Int(t *testing.T) {
 				CurrencyCode:      "XYZ",
 				CurrencyString:    "XYZ", // Should fall back to code itself
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -178,6 +186,7 @@ func TestFormatWithCurrency_Int(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -201,6 +210,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -216,6 +226,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -231,6 +242,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(1),
 				AfterZerosValue:   newPtr[int64](123),
 			},
@@ -246,6 +258,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(5),
 				AfterZerosValue:   newPtr[int64](456),
 			},
@@ -261,6 +274,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(2),
 				AfterZerosValue:   newPtr[int64](1),
 			},
@@ -276,6 +290,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](1),
 			},
@@ -291,6 +306,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -306,6 +322,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeEUR,
 				CurrencyString:    "€",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(2),
 				AfterZerosValue:   newPtr[int64](3),
 			},
@@ -321,11 +338,28 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodePHP,
 				CurrencyString:    "₱",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(9),
 				AfterZerosValue:   newPtr[int64](1),
 			},
 			expectedErr: false,
 		},
+		{
+			name:         "USD exactly one",
+			price:        1.0,
+			currencyCode: CurrencyCodeUSD,
+			expected: &PriceFormatted{
+				UseSubscript:      false,
+				IsSmallDecimal:    false,
+				RawValue:          "1",
+				CurrencyCode:      CurrencyCodeUSD,
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: nil,
+				AfterZerosValue:   nil,
+			},
+			expectedErr: false,
+		},
 		{
 			name:         "NZD just over zero",
 			price:        0.5,
@@ -336,6 +370,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeNZD,
 				CurrencyString:    "NZ$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -355,6 +390,7 @@ func TestFormatWithCurrency_Float64(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](5),
 			},
@@ -378,6 +414,7 @@ func TestFormatWithCurrency_String(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -393,6 +430,7 @@ func TestFormatWithCurrency_String(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](1),
 			},
@@ -408,6 +446,7 @@ func TestFormatWithCurrency_String(t *testing.T) {
 				CurrencyCode:      CurrencyCodeCAD,
 				CurrencyString:    "CA$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(4),
 				AfterZerosValue:   newPtr[int64](5),
 			},
@@ -449,6 +488,7 @@ func TestFormatWithCurrency_Decimal(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -464,6 +504,7 @@ func TestFormatWithCurrency_Decimal(t *testing.T) {
 				CurrencyCode:      CurrencyCodeINR,
 				CurrencyString:    "₹",
 				IsNegative:        false,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -579,6 +620,7 @@ func TestFormatWithCurrency_NegativeValues(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        true,
+				IsSmallDecimal:    false,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -594,6 +636,39 @@ func TestFormatWithCurrency_NegativeValues(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        true,
+				IsSmallDecimal:    false,
+				ZerosAfterDecimal: nil,
+				AfterZerosValue:   nil,
+			},
+			expectedErr: false,
+		},
+		{
+			name:         "Negative one",
+			price:        -1.0,
+			currencyCode: CurrencyCodeUSD,
+			expected: &PriceFormatted{
+				UseSubscript:      false,
+				IsSmallDecimal:    false,
+				RawValue:          "-1",
+				CurrencyCode:      CurrencyCodeUSD,
+				CurrencyString:    "$",
+				IsNegative:        true,
+				ZerosAfterDecimal: nil,
+				AfterZerosValue:   nil,
+			},
+			expectedErr: false,
+		},
+		{
+			name:         "Negative decimal without leading zeros",
+			price:        -0.5,
+			currencyCode: CurrencyCodeUSD,
+			expected: &PriceFormatted{
+				UseSubscript:      false,
+				IsSmallDecimal:    false,
+				RawValue:          "-0.5",
+				CurrencyCode:      CurrencyCodeUSD,
+				CurrencyString:    "$",
+				IsNegative:        true,
 				ZerosAfterDecimal: nil,
 				AfterZerosValue:   nil,
 			},
@@ -609,6 +684,7 @@ func TestFormatWithCurrency_NegativeValues(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        true,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(5),
 				AfterZerosValue:   newPtr[int64](456),
 			},
@@ -624,6 +700,7 @@ func TestFormatWithCurrency_NegativeValues(t *testing.T) {
 				CurrencyCode:      CurrencyCodeEUR,
 				CurrencyString:    "€",
 				IsNegative:        true,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](1),
 			},
@@ -656,6 +733,7 @@ func TestFormatWithOptions(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](123),
 			},
@@ -673,6 +751,7 @@ func TestFormatWithOptions(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](123),
 			},
@@ -690,6 +769,7 @@ func TestFormatWithOptions(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(5),
 				AfterZerosValue:   newPtr[int64](12), // Truncated from 1234 to 12
 			},
@@ -707,6 +787,7 @@ func TestFormatWithOptions(t *testing.T) {
 				CurrencyCode:      CurrencyCodeUSD,
 				CurrencyString:    "$",
 				IsNegative:        false,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(5),
 				AfterZerosValue:   newPtr[int64](1), // Truncated from 1234 to 1
 			},
@@ -724,6 +805,7 @@ func TestFormatWithOptions(t *testing.T) {
 				CurrencyCode:      CurrencyCodeEUR,
 				CurrencyString:    "€",
 				IsNegative:        true,
+				IsSmallDecimal:    true,
 				ZerosAfterDecimal: newPtr(3),
 				AfterZerosValue:   newPtr[int64](45), // Truncated from 456 to 45
 			},
@@ -741,6 +823,7 @@ func TestFormatWithOptions(t *testing.T) {
 				assert.NoError(t, err, "FormatWithOptions should not return an error for valid input")
 				assert.NotNil(t, formatted, "Formatted price should not be nil")
 				assert.Equal(t, tt.expected.UseSubscript, formatted.UseSubscript, "UseSubscript mismatch")
+				assert.Equal(t, tt.expected.IsSmallDecimal, formatted.IsSmallDecimal, "IsSmallDecimal mismatch")
 				assert.Equal(t, tt.expected.RawValue, formatted.RawValue, "RawValue mismatch")
 				assert.Equal(t, tt.expected.CurrencyCode, formatted.CurrencyCode, "CurrencyCode mismatch")
 				assert.Equal(t, tt.expected.CurrencyString, formatted.CurrencyString, "CurrencyString mismatch")
@@ -751,3 +834,112 @@ func TestFormatWithOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatPair(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected [3]*PriceFormatted // A, B and Spread
+	}{
+		{
+			name: "one side needs subscript",
+			a:    "0.0000012",
+			b:    "0.00015",
+			expected: [3]*PriceFormatted{
+				{UseSubscript: true, IsSmallDecimal: true, RawValue: "0.0000012", ZerosAfterDecimal: newPtr(5), AfterZerosValue: newPtr(int64(12))},
+				{UseSubscript: true, IsSmallDecimal: true, RawValue: "0.0001500", ZerosAfterDecimal: newPtr(3), AfterZerosValue: newPtr(int64(1500))},
+				{UseSubscript: true, IsSmallDecimal: true, RawValue: "0.0001488", ZerosAfterDecimal: newPtr(3), AfterZerosValue: newPtr(int64(1488))},
+			},
+		},
+		{
+			name: "subscript and whole price",
+			a:    "0.0000012",
+			b:    "2",
+			expected: [3]*PriceFormatted{
+				{UseSubscript: true, IsSmallDecimal: true, RawValue: "0.0000012", ZerosAfterDecimal: newPtr(5), AfterZerosValue: newPtr(int64(12))},
+				{RawValue: "2.0000000"},
+				{RawValue: "1.9999988"},
+			},
+		},
+		{
+			name: "no subscript",
+			a:    "1.5",
+			b:    "1.5025",
+			expected: [3]*PriceFormatted{
+				{RawValue: "1.5000"},
+				{RawValue: "1.5025"},
+				{IsSmallDecimal: true, RawValue: "0.0025", ZerosAfterDecimal: newPtr(2), AfterZerosValue: newPtr(int64(25))},
+			},
+		},
+		{
+			name: "mismatched signs",
+			a:    "-0.5",
+			b:    "0.25",
+			expected: [3]*PriceFormatted{
+				{RawValue: "-0.50", IsNegative: true},
+				{RawValue: "0.25"},
+				{RawValue: "0.75"},
+			},
+		},
+		{
+			name: "negative spread",
+			a:    "0.03",
+			b:    "0.02",
+			expected: [3]*PriceFormatted{
+				{IsSmallDecimal: true, RawValue: "0.03", ZerosAfterDecimal: newPtr(1), AfterZerosValue: newPtr(int64(3))},
+				{IsSmallDecimal: true, RawValue: "0.02", ZerosAfterDecimal: newPtr(1), AfterZerosValue: newPtr(int64(2))},
+				{IsSmallDecimal: true, RawValue: "-0.01", IsNegative: true, ZerosAfterDecimal: newPtr(1), AfterZerosValue: newPtr(int64(1))},
+			},
+		},
+		{
+			name: "zero spread",
+			a:    "0.00000123",
+			b:    "0.00000123",
+			expected: [3]*PriceFormatted{
+				{UseSubscript: true, IsSmallDecimal: true, RawValue: "0.00000123", ZerosAfterDecimal: newPtr(5), AfterZerosValue: newPtr(int64(123))},
+				{UseSubscript: true, IsSmallDecimal: true, RawValue: "0.00000123", ZerosAfterDecimal: newPtr(5), AfterZerosValue: newPtr(int64(123))},
+				{RawValue: "0.00000000"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, err := FormatPair(tt.a, tt.b, CurrencyCodeEUR)
+			assert.NoError(t, err, "FormatPair should not return an error for valid input")
+			assert.NotNil(t, pair, "Formatted pair should not be nil")
+
+			for i, formatted := range []*PriceFormatted{pair.A, pair.B, pair.Spread} {
+				expected := tt.expected[i]
+				assert.Equal(t, expected.UseSubscript, formatted.UseSubscript, "UseSubscript mismatch for value %d", i)
+				assert.Equal(t, expected.IsSmallDecimal, formatted.IsSmallDecimal, "IsSmallDecimal mismatch for value %d", i)
+				assert.Equal(t, expected.RawValue, formatted.RawValue, "RawValue mismatch for value %d", i)
+				assert.Equal(t, CurrencyCodeEUR, formatted.CurrencyCode, "CurrencyCode mismatch for value %d", i)
+				assert.Equal(t, "€", formatted.CurrencyString, "CurrencyString mismatch for value %d", i)
+				assert.Equal(t, expected.IsNegative, formatted.IsNegative, "IsNegative mismatch for value %d", i)
+				assert.Equal(t, expected.ZerosAfterDecimal, formatted.ZerosAfterDecimal, "ZerosAfterDecimal mismatch for value %d", i)
+				assert.Equal(t, expected.AfterZerosValue, formatted.AfterZerosValue, "AfterZerosValue mismatch for value %d", i)
+			}
+		})
+	}
+}
+
+func TestFormatPair_Types(t *testing.T) {
+	floats, err := FormatPair(0.1, 0.125, CurrencyCodeUSD)
+	assert.NoError(t, err, "FormatPair should not return an error for floats")
+	assert.Equal(t, "0.100", floats.A.RawValue, "RawValue mismatch for A")
+	assert.Equal(t, "0.025", floats.Spread.RawValue, "RawValue mismatch for Spread")
+
+	decimals, err := FormatPair(decimal.RequireFromString("10.10"), decimal.RequireFromString("10.2"), CurrencyCodeUSD)
+	assert.NoError(t, err, "FormatPair should not return an error for decimals")
+	assert.Equal(t, "10.20", decimals.B.RawValue, "RawValue mismatch for B")
+
+	ints, err := FormatPair(3, 5, CurrencyCodeUSD)
+	assert.NoError(t, err, "FormatPair should not return an error for ints")
+	assert.Equal(t, "2", ints.Spread.RawValue, "RawValue mismatch for Spread")
+
+	pair, err := FormatPair("1.5", "invalid", CurrencyCodeUSD)
+	assert.Error(t, err, "FormatPair should return an error for invalid input")
+	assert.Nil(t, pair, "Formatted pair should be nil on error")
+}
@@ -0,0 +1,159 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/stocktwits/go-infrastructure/v2/sterrors"
+)
+
+// Error codes classifying the failures of a load, owned by the factory returned by NewErrorFactory.
+const (
+	// ErrCodeConfig is a configuration failure: a missing or invalid path, a bad local file, invalid keys,
+	// missing required keys or a parameter rejected by SSM, i.e. ValidationException or ParameterNotFound.
+	ErrCodeConfig sterrors.ErrorCode = 7101
+	// ErrCodePermission is a call rejected because of the credentials or permissions, i.e. AccessDeniedException.
+	ErrCodePermission sterrors.ErrorCode = 7102
+	// ErrCodeThrottled is a call still throttled once the attempts are exhausted.
+	ErrCodeThrottled sterrors.ErrorCode = 7103
+	// ErrCodeUnavailable is a call failing for another reason, i.e. a server error once the attempts are exhausted.
+	ErrCodeUnavailable sterrors.ErrorCode = 7104
+	// ErrCodeApply is a failure to set the environment variables.
+	ErrCodeApply sterrors.ErrorCode = 7105
+)
+
+// Range of the error codes of the package.
+const (
+	minErrorCode sterrors.ErrorCode = 7100
+	maxErrorCode sterrors.ErrorCode = 7199
+)
+
+// ErrorConfig holds the configuration of the error codes of the package. Merge it into the config of the
+// factory set with WithErrorFactory so the codes get their message.
+var ErrorConfig = sterrors.ErrorConfig{
+	ErrCodeConfig:      {ErrorType: "SSMConfiguration", Message: "invalid SSM configuration", Http_code: 500},
+	ErrCodePermission:  {ErrorType: "SSMPermission", Message: "SSM access denied", Http_code: 500},
+	ErrCodeThrottled:   {ErrorType: "SSMThrottled", Message: "SSM calls throttled", Http_code: 503},
+	ErrCodeUnavailable: {ErrorType: "SSMUnavailable", Message: "SSM unavailable", Http_code: 503},
+	ErrCodeApply:       {ErrorType: "SSMApply", Message: "failed to set environment variables", Http_code: 500},
+}
+
+// permissionCodes are the AWS error codes of the calls rejected because of the credentials or permissions.
+var permissionCodes = map[string]bool{
+	"AccessDeniedException":       true,
+	"UnrecognizedClientException": true,
+	"InvalidSignatureException":   true,
+}
+
+// defaultErrorFactory creates the errors of the loads without WithErrorFactory.
+var defaultErrorFactory = NewErrorFactory()
+
+// NewErrorFactory returns a factory of the error codes of the package, in the "ssmenv" namespace and owning
+// the codes 7100 to 7199, so it can be registered in an sterrors.Registry.
+func NewErrorFactory() *sterrors.ErrorFactory {
+	factory, err := sterrors.NewFactoryWithRange(ErrorConfig, "failed to load SSM parameters", 500, minErrorCode, maxErrorCode)
+	if err != nil {
+		panic(err)
+	}
+	factory.SetNamespace("ssmenv")
+
+	return factory
+}
+
+// WithErrorFactory creates the errors classifying the failures with factory instead of the factory returned
+// by NewErrorFactory.
+func WithErrorFactory(factory *sterrors.ErrorFactory) Option {
+	return func(o *options) {
+		o.errorFactory = factory
+	}
+}
+
+// LoadError is returned when InitEnvVars, InitEnvVarsContext, InitEnvVarsWithClient or LoadParamsContext fail,
+// except when the context is done. It classifies the failure with one of the ErrCode* codes and wraps both the
+// *sterrors.Error of the code and the original error, so errors.As finds either of them, i.e. a *CallError.
+type LoadError struct {
+	// Code classifies the failure.
+	Code sterrors.ErrorCode
+	// Err is the original error.
+	Err error
+
+	stErr error
+}
+
+// Error returns the message of the original error.
+func (e *LoadError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the *sterrors.Error of the code and the original error.
+func (e *LoadError) Unwrap() []error {
+	return []error{e.stErr, e.Err}
+}
+
+// applyError is a failure to set the environment variables.
+type applyError struct {
+	err error
+}
+
+func (e *applyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *applyError) Unwrap() error {
+	return e.err
+}
+
+// errorCodeOf returns the code classifying err. It returns false for the context errors, which are not failures
+// of the load.
+func errorCodeOf(err error) (sterrors.ErrorCode, bool) {
+	var callErr *CallError
+	if errors.As(err, &callErr) {
+		switch {
+		case callErr.Class == ErrorClassThrottled:
+			return ErrCodeThrottled, true
+		case permissionCodes[callErr.Code]:
+			return ErrCodePermission, true
+		case callErr.Class == ErrorClassPermanent && callErr.Code != "":
+			return ErrCodeConfig, true
+		default:
+			return ErrCodeUnavailable, true
+		}
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	var applyErr *applyError
+	if errors.As(err, &applyErr) {
+		return ErrCodeApply, true
+	}
+
+	return ErrCodeConfig, true
+}
+
+// loadError classifies err in a *LoadError and logs it with the paths of the load if a logger is set.
+// Context errors are returned as is.
+func (o *options) loadError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code, ok := errorCodeOf(err)
+	if !ok {
+		return err
+	}
+
+	factory := o.errorFactory
+	if factory == nil {
+		factory = defaultErrorFactory
+	}
+	loadErr := &LoadError{Code: code, Err: err, stErr: factory.NewError(code, err)}
+
+	if o.logger != nil {
+		sterrors.Log(o.logger.WithData("path", strings.Join(o.loadPaths(), ",")), loadErr)
+	}
+
+	return loadErr
+}
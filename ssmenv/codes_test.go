@@ -0,0 +1,133 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stocktwits/go-infrastructure/v2/sterrors"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestInitEnvVarsContextErrorCodes(t *testing.T) {
+	serverErr := requestFailure("InternalServerError", "internal error", 500)
+	failSetenv := func(o *options) {
+		o.setenv = func(key, value string) error { return errors.New("setenv failed") }
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		failure  error
+		opts     []Option
+		wantCode sterrors.ErrorCode
+		wantCall bool
+	}{
+		{name: "wrong path", path: "", wantCode: ErrCodeConfig},
+		{name: "bad path", path: "/myapp/", failure: &smithy.GenericAPIError{Code: "ValidationException", Message: "invalid path"}, wantCode: ErrCodeConfig, wantCall: true},
+		{name: "access denied", path: "/myapp/", failure: stmocks.SSMAccessDeniedError(), wantCode: ErrCodePermission, wantCall: true},
+		{name: "throttled", path: "/myapp/", failure: stmocks.SSMThrottlingError(), wantCode: ErrCodeThrottled, wantCall: true},
+		{name: "server error", path: "/myapp/", failure: serverErr, wantCode: ErrCodeUnavailable, wantCall: true},
+		{name: "missing keys", path: "/myapp/", opts: []Option{WithRequiredKeys("SSMENV_TEST_MISSING")}, wantCode: ErrCodeConfig},
+		{name: "apply", path: "/myapp/", opts: []Option{failSetenv}, wantCode: ErrCodeApply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t, "SSMENV_TEST_CODE")
+
+			client := stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_code": "s3cr3t"})
+			if tt.failure != nil {
+				client.FailFirst(10, tt.failure)
+			}
+			logger := stmocks.NewLogger()
+
+			opts := append([]Option{
+				WithPath(tt.path),
+				WithClient(client),
+				WithMaxAttempts(2),
+				WithBackoff(0, 0),
+				WithLogger(logger),
+			}, tt.opts...)
+			_, err := InitEnvVarsContext(context.Background(), opts...)
+
+			var loadErr *LoadError
+			if !errors.As(err, &loadErr) || loadErr.Code != tt.wantCode {
+				t.Fatalf("InitEnvVarsContext() error = %v, want a LoadError with code %d", err, tt.wantCode)
+			}
+			if !sterrors.IsCode(err, tt.wantCode) {
+				t.Errorf("sterrors.IsCode(%v, %d) = false, want true", err, tt.wantCode)
+			}
+
+			var stErr *sterrors.Error
+			if !errors.As(err, &stErr) || stErr.Namespace != "ssmenv" || stErr.Type != ErrorConfig[tt.wantCode].ErrorType {
+				t.Errorf("InitEnvVarsContext() error = %v, want an ssmenv sterrors.Error", err)
+			}
+
+			var callErr *CallError
+			if errors.As(err, &callErr) != tt.wantCall {
+				t.Errorf("errors.As(*CallError) = %v, want %v", !tt.wantCall, tt.wantCall)
+			}
+
+			failures := logger.EntriesAt(stlogs.ERROR)
+			if len(failures) != 1 {
+				t.Fatalf("logged %d error entries, want 1", len(failures))
+			}
+			if failures[0].Data["path"] != tt.path || failures[0].Data["error_code"] != tt.wantCode {
+				t.Errorf("logged data = %v, want path %q and error_code %d", failures[0].Data, tt.path, tt.wantCode)
+			}
+			for key, value := range failures[0].Data {
+				if strings.Contains(fmt.Sprint(value), "s3cr3t") {
+					t.Errorf("logged %s = %v, want no parameter value", key, value)
+				}
+			}
+		})
+	}
+}
+
+func TestInitEnvVarsContextErrorCodesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := stmocks.NewFakeSSM(nil)
+	client.FailFirst(10, stmocks.SSMThrottlingError())
+
+	_, err := InitEnvVarsContext(ctx, WithPath("/myapp/"), WithClient(client))
+	var loadErr *LoadError
+	if !errors.Is(err, context.Canceled) || errors.As(err, &loadErr) {
+		t.Errorf("InitEnvVarsContext() error = %v, want the unclassified context error", err)
+	}
+}
+
+func TestWithErrorFactory(t *testing.T) {
+	config, err := ErrorConfig.Merge(sterrors.ErrorConfig{
+		1001: {Message: "not found", Http_code: 404},
+	})
+	if err != nil {
+		t.Fatalf("ErrorConfig.Merge() unexpected error = %v", err)
+	}
+	factory := sterrors.NewFactory(config, "internal error", 500)
+
+	err = InitEnvVarsWithClient("", nil)
+	if !sterrors.IsCode(err, ErrCodeConfig) {
+		t.Errorf("InitEnvVarsWithClient() error = %v, want code %d", err, ErrCodeConfig)
+	}
+
+	_, err = LoadParamsContext(context.Background(), "", WithErrorFactory(factory))
+	var stErr *sterrors.Error
+	if !errors.As(err, &stErr) || stErr.Code != ErrCodeConfig || stErr.Namespace != "" {
+		t.Errorf("LoadParamsContext() error = %v, want code %d created by the factory", err, ErrCodeConfig)
+	}
+
+	registry := sterrors.NewRegistry()
+	if err := registry.Register(NewErrorFactory()); err != nil {
+		t.Fatalf("Registry.Register() unexpected error = %v", err)
+	}
+	if owner, ok := registry.FromError(InitEnvVarsWithClient("/myapp/", nil)); !ok || owner.Namespace() != "ssmenv" {
+		t.Errorf("Registry.FromError() = %v, %v, want the ssmenv factory", owner, ok)
+	}
+}
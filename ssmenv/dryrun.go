@@ -0,0 +1,150 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// ParamInfo describes a parameter that would be loaded, without its value unless WithValues is used.
+type ParamInfo struct {
+	// Name is the full name of the parameter, i.e. /myapp/db/password.
+	Name string
+	// Keys are the environment variables the parameter would set, sorted, several if it is expanded.
+	Keys []string
+	// Type is String, SecureString or StringList.
+	Type string
+	// Version is the version of the parameter that would be loaded.
+	Version int64
+	// LastModified is the time the version was created.
+	LastModified time.Time
+	// Value is the value of the parameter, decrypted, only set with WithValues.
+	Value string
+}
+
+// WithValues includes the decrypted values of the parameters in the ParamInfo returned by DryRun.
+func WithValues() Option {
+	return func(o *options) {
+		o.withValues = true
+	}
+}
+
+// DryRun lists the parameters under path that InitEnvVars would load, with the keys they would set,
+// without changing the environment. Values are neither decrypted nor returned, see WithValues.
+func DryRun(path string) ([]ParamInfo, error) {
+	return DryRunContext(context.Background(), path)
+}
+
+// DryRunContext lists the parameters like DryRun, and stops when ctx is cancelled. Options control the
+// client, the paths, the label and the keys like for InitEnvVarsContext. Without WithValues, the keys of
+// SecureString parameters holding JSON objects are not expanded since their values are not decrypted.
+// Parameters are sorted by name.
+func DryRunContext(ctx context.Context, path string, opts ...Option) ([]ParamInfo, error) {
+	o := newOptions(path)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if slices.Contains(o.loadPaths(), "") {
+		return nil, fmt.Errorf("wrong path configuration")
+	}
+
+	if o.client == nil {
+		var err error
+		if o.client, err = newClient(ctx, o); err != nil {
+			return nil, err
+		}
+	}
+
+	var infos []ParamInfo
+	for _, p := range o.loadPaths() {
+		pathOptions := *o
+		pathOptions.path = p
+		if !o.withValues {
+			// Encrypted values are not JSON objects, keys are computed without warnings
+			pathOptions.noDecryption = true
+			pathOptions.warn = func(string) {}
+		}
+
+		params, err := fetchParams(ctx, &pathOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, param := range params {
+			keys := make(map[string]string)
+			pathOptions.addParam(keys, param)
+
+			info := ParamInfo{
+				Name:         aws.ToString(param.Name),
+				Type:         string(param.Type),
+				Version:      param.Version,
+				LastModified: aws.ToTime(param.LastModifiedDate),
+			}
+			for key := range keys {
+				info.Keys = append(info.Keys, key)
+			}
+			sort.Strings(info.Keys)
+			if o.withValues {
+				info.Value = aws.ToString(param.Value)
+			}
+
+			infos = append(infos, info)
+		}
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos, nil
+}
+
+// PrintDryRun writes the parameters listed by DryRun as an aligned table, with their values if they were
+// listed WithValues, so a command can show what a service would load.
+func PrintDryRun(w io.Writer, infos []ParamInfo) error {
+	withValues := false
+	for _, info := range infos {
+		withValues = withValues || info.Value != ""
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "NAME\tKEYS\tTYPE\tVERSION\tLAST MODIFIED"
+	if withValues {
+		header += "\tVALUE"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, info := range infos {
+		lastModified := ""
+		if !info.LastModified.IsZero() {
+			lastModified = info.LastModified.UTC().Format(time.RFC3339)
+		}
+
+		line := strings.Join([]string{
+			info.Name,
+			strings.Join(info.Keys, ","),
+			info.Type,
+			strconv.FormatInt(info.Version, 10),
+			lastModified,
+		}, "\t")
+		if withValues {
+			line += "\t" + strconv.Quote(info.Value)
+		}
+		fmt.Fprintln(tw, line)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write dry run: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package ssmenv
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// putVersions stores the value as the versions of a new parameter, up to version.
+func putVersions(client *stmocks.FakeSSM, name, value string, version int64) {
+	for v := int64(1); v <= version; v++ {
+		client.Put(name, value)
+	}
+}
+
+func TestDryRunContext(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := stmocks.NewClock(modified)
+
+	client := stmocks.NewFakeSSM(nil).WithClock(clock)
+	client.PageSize = 2
+	putVersions(client, "/myapp/ssmenv_test_host", "db.local", 3)
+	putVersions(client, "/myapp/hosts", "a,b", 1)
+	putVersions(client, "/myapp/cache", `{"ttl": "1m"}`, 2)
+	clock.Advance(time.Hour)
+	putVersions(client, "/myapp/db/password", "hunter2", 7)
+	client.SetSecure("/myapp/db/password")
+	client.SetStringList("/myapp/hosts")
+
+	opts := []Option{WithClient(client), ExpandStringList(StringListBoth), ExpandJSON(), WithPrefix("APP_")}
+	want := []ParamInfo{
+		{Name: "/myapp/cache", Keys: []string{"APP_CACHE_TTL"}, Type: "String", Version: 2, LastModified: modified},
+		{Name: "/myapp/db/password", Keys: []string{"APP_DB_PASSWORD"}, Type: "SecureString", Version: 7, LastModified: modified.Add(time.Hour)},
+		{Name: "/myapp/hosts", Keys: []string{"APP_HOSTS", "APP_HOSTS_0", "APP_HOSTS_1"}, Type: "StringList", Version: 1, LastModified: modified},
+		{Name: "/myapp/ssmenv_test_host", Keys: []string{"APP_SSMENV_TEST_HOST"}, Type: "String", Version: 3, LastModified: modified},
+	}
+
+	t.Run("without values", func(t *testing.T) {
+		unsetEnv(t, "APP_SSMENV_TEST_HOST")
+
+		infos, err := DryRunContext(context.Background(), "/myapp/", opts...)
+		if err != nil {
+			t.Fatalf("DryRunContext() unexpected error = %v", err)
+		}
+		if !reflect.DeepEqual(infos, want) {
+			t.Errorf("DryRunContext() = %+v, want %+v", infos, want)
+		}
+
+		for _, input := range client.Inputs() {
+			if aws.ToBool(input.WithDecryption) {
+				t.Errorf("GetParametersByPath() input = %v, want no decryption", input)
+			}
+		}
+		if _, exists := os.LookupEnv("APP_SSMENV_TEST_HOST"); exists {
+			t.Error("DryRunContext() set environment variables")
+		}
+	})
+
+	t.Run("with values", func(t *testing.T) {
+		infos, err := DryRunContext(context.Background(), "/myapp/", append(opts, WithValues())...)
+		if err != nil {
+			t.Fatalf("DryRunContext() unexpected error = %v", err)
+		}
+
+		wantValues := []string{`{"ttl": "1m"}`, "hunter2", "a,b", "db.local"}
+		for i, info := range infos {
+			if info.Value != wantValues[i] {
+				t.Errorf("DryRunContext()[%d].Value = %q, want %q", i, info.Value, wantValues[i])
+			}
+		}
+		if inputs := client.Inputs(); !aws.ToBool(inputs[len(inputs)-1].WithDecryption) {
+			t.Errorf("GetParametersByPath() input = %v, want decryption", inputs[len(inputs)-1])
+		}
+	})
+}
+
+func TestPrintDryRun(t *testing.T) {
+	infos := []ParamInfo{
+		{Name: "/myapp/db/password", Keys: []string{"DB_PASSWORD"}, Type: "SecureString", Version: 7, LastModified: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{Name: "/myapp/hosts", Keys: []string{"HOSTS_0", "HOSTS_1"}, Type: "StringList", Version: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintDryRun(&buf, infos); err != nil {
+		t.Fatalf("PrintDryRun() unexpected error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"NAME                KEYS             TYPE          VERSION  LAST MODIFIED",
+		"/myapp/db/password  DB_PASSWORD      SecureString  7        2024-03-01T12:00:00Z",
+		"/myapp/hosts        HOSTS_0,HOSTS_1  StringList    1        ",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("PrintDryRun() = %q, want %q", buf.String(), want)
+	}
+}
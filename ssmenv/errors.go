@@ -0,0 +1,105 @@
+package ssmenv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+)
+
+// maxPageSize is the highest number of parameters GetParametersByPath returns per page with decryption.
+const maxPageSize = 10
+
+// ErrorClass classifies the failures of the calls to AWS.
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent failures, such as AccessDeniedException or a ValidationException for a bad path,
+	// are returned immediately.
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassThrottled failures are retried with an exponential backoff and jitter.
+	ErrorClassThrottled
+	// ErrorClassTransient failures, such as server errors and timeouts, are retried with an exponential backoff
+	// and jitter.
+	ErrorClassTransient
+)
+
+// permanentCodes are the error codes never retried, even if the SDK considers them retryable.
+var permanentCodes = map[string]bool{
+	"AccessDeniedException":       true,
+	"UnrecognizedClientException": true,
+	"InvalidSignatureException":   true,
+	"ValidationException":         true,
+	"InvalidParameterException":   true,
+	"InvalidRequestException":     true,
+	"InvalidKeyId":                true,
+	"InvalidFilterKey":            true,
+	"InvalidFilterOption":         true,
+	"InvalidFilterValue":          true,
+	"ParameterNotFound":           true,
+	"ParameterVersionNotFound":    true,
+	"ResourceNotFoundException":   true,
+}
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassPermanent:
+		return "permanent"
+	case ErrorClassThrottled:
+		return "throttled"
+	case ErrorClassTransient:
+		return "transient"
+	default:
+		return fmt.Sprintf("ErrorClass(%d)", int(c))
+	}
+}
+
+// CallError is returned when a call to AWS fails, immediately for permanent failures or once the attempts
+// are exhausted for throttled and transient failures. It wraps the error of the last attempt.
+type CallError struct {
+	// Class is the class of the failure.
+	Class ErrorClass
+	// Code is the AWS error code, i.e. AccessDeniedException, empty if the failure is not an AWS error.
+	Code string
+	// Attempts is the number of calls made.
+	Attempts int
+	// Err is the error of the last call.
+	Err error
+}
+
+func (e *CallError) Error() string {
+	return fmt.Sprintf("%s failure after %d attempt(s): %v", e.Class, e.Attempts, e.Err)
+}
+
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// classifyError returns the class of the failure of a call.
+func classifyError(err error) ErrorClass {
+	if err == nil || permanentCodes[errorCode(err)] {
+		return ErrorClassPermanent
+	}
+
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary {
+		return ErrorClassThrottled
+	}
+
+	// Server errors, connection failures and request timeouts are retried by the SDK retryer too
+	if retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary {
+		return ErrorClassTransient
+	}
+
+	return ErrorClassPermanent
+}
+
+// errorCode returns the AWS error code of the error, empty if it is not an AWS error.
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// requestFailure returns the error of an SSM call answered with the HTTP status and the error code.
+func requestFailure(code, message string, status int) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      &smithy.GenericAPIError{Code: code, Message: message},
+	}
+}
+
+func TestCallErrorClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantClass    ErrorClass
+		wantCode     string
+		wantAttempts int
+	}{
+		{
+			name:         "throttled",
+			err:          &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"},
+			wantClass:    ErrorClassThrottled,
+			wantCode:     "ThrottlingException",
+			wantAttempts: 3,
+		},
+		{
+			name:         "server error",
+			err:          requestFailure("InternalServerError", "unavailable", 503),
+			wantClass:    ErrorClassTransient,
+			wantCode:     "InternalServerError",
+			wantAttempts: 3,
+		},
+		{
+			name:         "access denied",
+			err:          requestFailure("AccessDeniedException", "not authorized", 400),
+			wantClass:    ErrorClassPermanent,
+			wantCode:     "AccessDeniedException",
+			wantAttempts: 1,
+		},
+		{
+			name:         "bad path",
+			err:          requestFailure("ValidationException", "invalid path", 400),
+			wantClass:    ErrorClassPermanent,
+			wantCode:     "ValidationException",
+			wantAttempts: 1,
+		},
+		{
+			name:         "not an AWS error",
+			err:          fmt.Errorf("unexpected"),
+			wantClass:    ErrorClassPermanent,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := stmocks.NewFakeSSM(nil)
+			client.FailFirst(3, tt.err)
+
+			start := time.Now()
+			_, err := LoadParamsContext(context.Background(), "/myapp/", WithClient(client), WithMaxAttempts(3),
+				WithBackoff(time.Millisecond, time.Millisecond))
+
+			var callErr *CallError
+			if !errors.As(err, &callErr) {
+				t.Fatalf("LoadParamsContext() error = %v, want a CallError", err)
+			}
+			if callErr.Class != tt.wantClass || callErr.Code != tt.wantCode || callErr.Attempts != tt.wantAttempts {
+				t.Errorf("CallError = %+v, want class %v, code %q and %d attempts", callErr, tt.wantClass, tt.wantCode, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("LoadParamsContext() error = %v, want it to wrap %v", err, tt.err)
+			}
+			if calls := len(client.Inputs()); calls != tt.wantAttempts {
+				t.Errorf("GetParametersByPath() calls = %d, want %d", calls, tt.wantAttempts)
+			}
+			if elapsed := time.Since(start); tt.wantClass == ErrorClassPermanent && elapsed > 100*time.Millisecond {
+				t.Errorf("LoadParamsContext() returned after %v, want immediate failure", elapsed)
+			}
+		})
+	}
+}
+
+func TestPermanentErrorDefaultBackoff(t *testing.T) {
+	denied := requestFailure("AccessDeniedException", "not authorized", 400)
+	client := stmocks.NewFakeSSM(nil)
+	client.FailFirst(1, denied)
+
+	start := time.Now()
+	if _, err := LoadParamsContext(context.Background(), "/myapp/", WithClient(client)); !errors.Is(err, denied) {
+		t.Fatalf("LoadParamsContext() error = %v, want %v", err, denied)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("LoadParamsContext() returned after %v, want no backoff for permanent failures", elapsed)
+	}
+}
+
+func TestFetchPagesMaxResults(t *testing.T) {
+	client := stmocks.NewFakeSSM(map[string]string{"/myapp/a": "1", "/myapp/b": "2"})
+	client.PageSize = 1
+	if _, err := LoadParamsContext(context.Background(), "/myapp/", WithClient(client)); err != nil {
+		t.Fatalf("LoadParamsContext() unexpected error = %v", err)
+	}
+
+	for _, input := range client.Inputs() {
+		if aws.ToInt32(input.MaxResults) != 10 {
+			t.Errorf("GetParametersByPath() MaxResults = %v, want 10", input.MaxResults)
+		}
+	}
+}
+
+func TestWithSDKRetryer(t *testing.T) {
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+	client := stmocks.NewFakeSSM(map[string]string{"/myapp/a": "1"})
+	client.FailFirst(1, throttled)
+
+	standard := func() aws.Retryer { return retry.NewStandard() }
+	_, err := LoadParamsContext(context.Background(), "/myapp/", WithClient(client), WithSDKRetryer(standard),
+		WithMaxAttempts(3), WithBackoff(time.Millisecond, time.Millisecond))
+
+	var callErr *CallError
+	if !errors.As(err, &callErr) || callErr.Class != ErrorClassThrottled || callErr.Attempts != 1 {
+		t.Fatalf("LoadParamsContext() error = %v, want a throttled CallError after 1 attempt", err)
+	}
+	if calls := len(client.Inputs()); calls != 1 {
+		t.Errorf("GetParametersByPath() calls = %d, want 1, the retries are left to the SDK", calls)
+	}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantRetryer aws.Retryer
+	}{
+		{name: "default", wantRetryer: aws.NopRetryer{}},
+		{name: "sdk retryer", opts: []Option{WithSDKRetryer(standard)}, wantRetryer: retry.NewStandard()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions("/myapp/")
+			for _, opt := range append(tt.opts, WithRegion("us-east-1")) {
+				opt(o)
+			}
+
+			cfg, err := o.awsConfig(context.Background())
+			if err != nil {
+				t.Fatalf("awsConfig() unexpected error = %v", err)
+			}
+			if got := cfg.Retryer(); fmt.Sprintf("%T", got) != fmt.Sprintf("%T", tt.wantRetryer) {
+				t.Errorf("awsConfig() retryer = %T, want %T", got, tt.wantRetryer)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package ssmenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// StringListMode defines how ExpandStringList exposes the values of StringList parameters.
+type StringListMode int
+
+const (
+	// StringListJoined keeps the comma-separated values in a single key, i.e. LIST=a,b.
+	StringListJoined StringListMode = iota
+	// StringListIndexed sets a key per value suffixed with its index, i.e. LIST_0=a and LIST_1=b.
+	StringListIndexed
+	// StringListBoth sets both the joined key and the indexed keys.
+	StringListBoth
+)
+
+// ExpandStringList exposes the values of StringList parameters according to mode.
+// By default they are joined with commas like String parameters.
+func ExpandStringList(mode StringListMode) Option {
+	return func(o *options) {
+		o.stringListMode = mode
+	}
+}
+
+// ExpandJSON sets a key per field of the parameters holding a flat JSON object, prefixed by the key of the
+// parameter: {"user": "admin", "port": 5432} in /myapp/db sets DB_USER=admin and DB_PORT=5432.
+// Field names are upper-cased unless a key transform is used. Values starting with { that are not a flat
+// JSON object, i.e. malformed or with nested objects or arrays, are kept raw and reported as a warning.
+func ExpandJSON() Option {
+	return func(o *options) {
+		o.expandJSON = true
+	}
+}
+
+// WithWarningHandler calls handle with the warnings of the load, such as a parameter not expanded by ExpandJSON,
+// instead of logging them with the standard logger. Handle is called one warning at a time, even while several
+// paths are fetched concurrently, so it does not need to be safe for concurrent use.
+func WithWarningHandler(handle func(msg string)) Option {
+	return func(o *options) {
+		o.warn = handle
+	}
+}
+
+// warning passes msg to the warning handler. The calls are serialized across the copies of the options made
+// for each path, which share the same mutex.
+func (o *options) warning(msg string) {
+	o.warnMu.Lock()
+	defer o.warnMu.Unlock()
+
+	o.warn(msg)
+}
+
+// addParam adds the keys and values of a parameter to params, expanded according to the options.
+// Keys are cleaned by setParam.
+func (o *options) addParam(params map[string]string, param types.Parameter) {
+	key, value := o.paramKey(*param.Name), aws.ToString(param.Value)
+
+	if o.expandJSON && strings.HasPrefix(strings.TrimSpace(value), "{") {
+		fields, err := flatJSONObject(value)
+		if err == nil {
+			for field, fieldValue := range fields {
+				if o.keyTransform == nil {
+					field = strings.ToUpper(field)
+				}
+				o.setParam(params, *param.Name, key+"_"+field, fieldValue)
+			}
+			return
+		}
+		o.warning(fmt.Sprintf("ssmenv: parameter %s is not a flat JSON object, keeping the raw value: %v", *param.Name, err))
+	}
+
+	if param.Type == types.ParameterTypeStringList && o.stringListMode != StringListJoined {
+		for i, item := range strings.Split(value, ",") {
+			o.setParam(params, *param.Name, key+"_"+strconv.Itoa(i), item)
+		}
+		if o.stringListMode == StringListIndexed {
+			return
+		}
+	}
+
+	o.setParam(params, *param.Name, key, value)
+}
+
+// flatJSONObject decodes a JSON object whose values are strings, numbers, booleans or nulls.
+// Numbers keep their literal digits and nulls are empty.
+func flatJSONObject(value string) (map[string]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON object")
+	}
+
+	fields := make(map[string]string, len(object))
+	for field, v := range object {
+		switch v := v.(type) {
+		case string:
+			fields[field] = v
+		case json.Number:
+			fields[field] = v.String()
+		case bool:
+			fields[field] = strconv.FormatBool(v)
+		case nil:
+			fields[field] = ""
+		default:
+			return nil, fmt.Errorf("field %s is not a string, number or boolean", field)
+		}
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,131 @@
+package ssmenv
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name         string
+		param        string
+		value        string
+		stringList   bool
+		opts         []Option
+		want         map[string]string
+		wantWarnings []string
+	}{
+		{
+			name:       "string list joined by default",
+			param:      "/myapp/hosts",
+			value:      "a,b",
+			stringList: true,
+			want:       map[string]string{"HOSTS": "a,b"},
+		},
+		{
+			name:       "string list indexed",
+			param:      "/myapp/hosts",
+			value:      "a,b",
+			stringList: true,
+			opts:       []Option{ExpandStringList(StringListIndexed)},
+			want:       map[string]string{"HOSTS_0": "a", "HOSTS_1": "b"},
+		},
+		{
+			name:       "string list both",
+			param:      "/myapp/hosts",
+			value:      "a,b",
+			stringList: true,
+			opts:       []Option{ExpandStringList(StringListBoth)},
+			want:       map[string]string{"HOSTS": "a,b", "HOSTS_0": "a", "HOSTS_1": "b"},
+		},
+		{
+			name:  "string not expanded as list",
+			param: "/myapp/hosts",
+			value: "a,b",
+			opts:  []Option{ExpandStringList(StringListIndexed)},
+			want:  map[string]string{"HOSTS": "a,b"},
+		},
+		{
+			name:  "JSON kept raw by default",
+			param: "/myapp/db",
+			value: `{"user": "admin"}`,
+			want:  map[string]string{"DB": `{"user": "admin"}`},
+		},
+		{
+			name:  "JSON object",
+			param: "/myapp/db",
+			value: `{"user": "admin", "port": 5432, "ratio": 0.10, "tls": true, "replica": null}`,
+			opts:  []Option{ExpandJSON()},
+			want:  map[string]string{"DB_USER": "admin", "DB_PORT": "5432", "DB_RATIO": "0.10", "DB_TLS": "true", "DB_REPLICA": ""},
+		},
+		{
+			name:  "JSON object with key transform",
+			param: "/myapp/db",
+			value: `{"user": "admin"}`,
+			opts:  []Option{ExpandJSON(), WithKeyTransform(strings.ToLower)},
+			want:  map[string]string{"/myapp/db_user": "admin"},
+		},
+		{
+			name:  "not JSON",
+			param: "/myapp/name",
+			value: "plain",
+			opts:  []Option{ExpandJSON()},
+			want:  map[string]string{"NAME": "plain"},
+		},
+		{
+			name:         "malformed JSON",
+			param:        "/myapp/db",
+			value:        `{"user": `,
+			opts:         []Option{ExpandJSON()},
+			want:         map[string]string{"DB": `{"user": `},
+			wantWarnings: []string{"/myapp/db"},
+		},
+		{
+			name:         "nested JSON",
+			param:        "/myapp/db",
+			value:        `{"primary": {"host": "a"}}`,
+			opts:         []Option{ExpandJSON()},
+			want:         map[string]string{"DB": `{"primary": {"host": "a"}}`},
+			wantWarnings: []string{"/myapp/db", "primary"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := stmocks.NewFakeSSM(map[string]string{tt.param: tt.value})
+			if tt.stringList {
+				client.SetStringList(tt.param)
+			}
+
+			var warnings []string
+			opts := append([]Option{
+				WithClient(client),
+				WithWarningHandler(func(msg string) { warnings = append(warnings, msg) }),
+			}, tt.opts...)
+
+			got, err := LoadParamsContext(context.Background(), "/myapp/", opts...)
+			if err != nil {
+				t.Fatalf("LoadParamsContext() unexpected error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("LoadParamsContext() = %v, want %v", got, tt.want)
+			}
+
+			if len(tt.wantWarnings) == 0 && len(warnings) > 0 {
+				t.Errorf("LoadParamsContext() warnings = %q, want none", warnings)
+			}
+			if len(tt.wantWarnings) > 0 && len(warnings) != 1 {
+				t.Fatalf("LoadParamsContext() warnings = %q, want 1", warnings)
+			}
+			for _, want := range tt.wantWarnings {
+				if !strings.Contains(warnings[0], want) {
+					t.Errorf("LoadParamsContext() warning = %q, want it to contain %q", warnings[0], want)
+				}
+			}
+		})
+	}
+}
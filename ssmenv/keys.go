@@ -0,0 +1,69 @@
+package ssmenv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// defaultKeyReplacer replaces the separators commonly found in parameter names.
+	defaultKeyReplacer = strings.NewReplacer("-", "_", ".", "_")
+	// validKey matches the portable environment variable names.
+	validKey = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+	// validLegacyKey matches the keys of WithLegacyNestedKeys, which keep their separators.
+	validLegacyKey = regexp.MustCompile(`^[A-Z_/][A-Z0-9_/]*$`)
+)
+
+// WithKeyReplacer replaces the characters of the keys that cannot be used in environment variable names,
+// after their normalization or transform. By default dashes and dots are replaced with underscores, a nil
+// replacer keeps the keys unchanged.
+// Keys are then validated against [A-Z_][A-Z0-9_]*, slashes being allowed with WithLegacyNestedKeys, and
+// the load fails with an InvalidKeysError before changing the environment if some are invalid.
+func WithKeyReplacer(replacer *strings.Replacer) Option {
+	return func(o *options) {
+		o.keyReplacer = replacer
+	}
+}
+
+// InvalidKeysError is returned when the keys of parameters cannot be used as environment variable names,
+// see WithKeyReplacer.
+type InvalidKeysError struct {
+	// Keys are the invalid keys, keyed by the name of their parameter.
+	Keys map[string]string
+}
+
+// Error returns the list of parameters with an invalid key, in name order.
+func (e *InvalidKeysError) Error() string {
+	names := make([]string, 0, len(e.Keys))
+	for name := range e.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	invalid := make([]string, len(names))
+	for i, name := range names {
+		invalid[i] = fmt.Sprintf("%s (%q)", name, e.Keys[name])
+	}
+
+	return fmt.Sprintf("parameters with invalid environment variable names: %s", strings.Join(invalid, ", "))
+}
+
+// setParam adds a key of the parameter to params, with its invalid characters replaced.
+// Keys still invalid are recorded to fail the load.
+func (o *options) setParam(params map[string]string, name, key, value string) {
+	if o.keyReplacer != nil {
+		key = o.keyReplacer.Replace(key)
+	}
+
+	valid := validKey
+	if o.legacyKeys {
+		valid = validLegacyKey
+	}
+	if !valid.MatchString(key) {
+		o.stats.addInvalidKey(name, key)
+	}
+
+	params[key] = value
+}
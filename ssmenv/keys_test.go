@@ -0,0 +1,117 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestInitEnvVarsContextKeyValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]string
+		opts        []Option
+		want        map[string]string
+		wantInvalid map[string]string
+	}{
+		{
+			name: "dashes and dots replaced",
+			params: map[string]string{
+				"/myapp/ssmenv-test-host":    "db.local",
+				"/myapp/ssmenv.test.port":    "5432",
+				"/myapp/ssmenv_test/db-user": "admin",
+			},
+			want: map[string]string{"SSMENV_TEST_HOST": "db.local", "SSMENV_TEST_PORT": "5432", "SSMENV_TEST_DB_USER": "admin"},
+		},
+		{
+			name:   "expanded JSON fields replaced",
+			params: map[string]string{"/myapp/ssmenv-test": `{"api-key": "abc", "api.url": "http://api"}`},
+			opts:   []Option{ExpandJSON()},
+			want:   map[string]string{"SSMENV_TEST_API_KEY": "abc", "SSMENV_TEST_API_URL": "http://api"},
+		},
+		{
+			name: "custom replacer",
+			params: map[string]string{
+				"/myapp/ssmenv-test-host": "db.local",
+				"/myapp/ssmenv+test+port": "5432",
+			},
+			opts: []Option{WithKeyReplacer(strings.NewReplacer("-", "_", "+", "_"))},
+			want: map[string]string{"SSMENV_TEST_HOST": "db.local", "SSMENV_TEST_PORT": "5432"},
+		},
+		{
+			name: "without replacer",
+			params: map[string]string{
+				"/myapp/ssmenv-test-host": "db.local",
+				"/myapp/ssmenv_test_port": "5432",
+			},
+			opts:        []Option{WithKeyReplacer(nil)},
+			wantInvalid: map[string]string{"/myapp/ssmenv-test-host": "SSMENV-TEST-HOST"},
+		},
+		{
+			name: "unrepresentable names",
+			params: map[string]string{
+				"/myapp/ssmenv test host":  "db.local",
+				"/myapp/1ssmenv_test_port": "5432",
+				"/myapp/ssmenv_test_user":  "admin",
+			},
+			wantInvalid: map[string]string{
+				"/myapp/ssmenv test host":  "SSMENV TEST HOST",
+				"/myapp/1ssmenv_test_port": "1SSMENV_TEST_PORT",
+			},
+		},
+		{
+			name:        "lowercase transform",
+			params:      map[string]string{"/myapp/ssmenv_test_host": "db.local"},
+			opts:        []Option{WithKeyTransform(strings.ToLower)},
+			wantInvalid: map[string]string{"/myapp/ssmenv_test_host": "/myapp/ssmenv_test_host"},
+		},
+		{
+			name:   "legacy nested keys",
+			params: map[string]string{"/myapp/ssmenv_test/db-user": "admin"},
+			opts:   []Option{WithLegacyNestedKeys()},
+			want:   map[string]string{"SSMENV_TEST/DB_USER": "admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t, "SSMENV_TEST_HOST", "SSMENV_TEST_PORT", "SSMENV_TEST_USER", "SSMENV_TEST_DB_USER",
+				"SSMENV_TEST_API_KEY", "SSMENV_TEST_API_URL", "SSMENV_TEST/DB_USER")
+
+			opts := append([]Option{WithPath("/myapp/"), WithClient(stmocks.NewFakeSSM(tt.params))}, tt.opts...)
+			_, err := InitEnvVarsContext(context.Background(), opts...)
+
+			if tt.wantInvalid != nil {
+				var invalidErr *InvalidKeysError
+				if !errors.As(err, &invalidErr) || !reflect.DeepEqual(invalidErr.Keys, tt.wantInvalid) {
+					t.Fatalf("InitEnvVarsContext() error = %v, want InvalidKeysError for %v", err, tt.wantInvalid)
+				}
+				for name := range tt.wantInvalid {
+					if !strings.Contains(err.Error(), name) {
+						t.Errorf("InitEnvVarsContext() error = %v, want it to list %s", err, name)
+					}
+				}
+				for _, key := range []string{"SSMENV_TEST_PORT", "SSMENV_TEST_USER"} {
+					if _, exists := os.LookupEnv(key); exists {
+						t.Errorf("InitEnvVarsContext() set %s despite invalid keys", key)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+			}
+			for key, value := range tt.want {
+				if got := os.Getenv(key); got != value {
+					t.Errorf("os.Getenv(%q) = %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package ssmenv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// WithLocalFile reads the parameters from a local file instead of SSM, so services can run without AWS
+// credentials. It overrides the SSM_LOCAL_FILE environment variable.
+// Files with the .json extension hold a JSON object, other files hold dotenv-style KEY=value lines, where
+// blank lines and lines starting with # are ignored, values can be single or double quoted, and unquoted
+// values end at a " #" comment. Keys are the parameter names relative to the path, i.e. db/password or
+// DB_PASSWORD, and go through the same normalization, expansion and no-override logic as SSM parameters.
+// Snapshot writes the parameters of SSM in this format.
+func WithLocalFile(file string) Option {
+	return func(o *options) {
+		o.localFile = file
+	}
+}
+
+// readLocalFile reads the parameters of the local file, named after the path.
+func readLocalFile(o *options) ([]types.Parameter, error) {
+	data, err := os.ReadFile(o.localFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local parameters file: %w", err)
+	}
+
+	var pairs [][2]string
+	if filepath.Ext(o.localFile) == ".json" {
+		pairs, err = parseJSONFile(data)
+	} else {
+		pairs, err = parseDotenv(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse local parameters file %s: %w", o.localFile, err)
+	}
+
+	params := make([]types.Parameter, len(pairs))
+	for i, pair := range pairs {
+		params[i] = types.Parameter{
+			Name:  aws.String(strings.TrimSuffix(o.path, "/") + "/" + strings.TrimPrefix(pair[0], "/")),
+			Value: aws.String(pair[1]),
+			Type:  types.ParameterTypeString,
+		}
+	}
+
+	return params, nil
+}
+
+// maxDotenvLineSize is the maximum size of a line of a dotenv file, large enough for values holding
+// a certificate chain or a JSON document.
+const maxDotenvLineSize = 4 << 20
+
+// parseDotenv parses the KEY=value lines of a dotenv file, in order.
+func parseDotenv(r io.Reader) ([][2]string, error) {
+	var pairs [][2]string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxDotenvLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", line)
+		}
+
+		value, err := parseDotenvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		pairs = append(pairs, [2]string{key, value})
+	}
+
+	return pairs, scanner.Err()
+}
+
+// parseDotenvValue unquotes a value: double quoted values support Go escape sequences, single quoted values
+// are literal, and unquoted values end at a " #" comment.
+func parseDotenvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated double quoted value")
+		}
+		return strconv.Unquote(value[:end+1])
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quoted value")
+		}
+		return value[1 : end+1], nil
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// closingQuote returns the index of the unescaped double quote closing the value, or -1.
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseJSONFile parses a JSON object file, in key order. String values are used as is, other values
+// keep their JSON encoding.
+func parseJSONFile(data []byte) ([][2]string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([][2]string, len(keys))
+	for i, key := range keys {
+		value := string(object[key])
+		var str string
+		if err := json.Unmarshal(object[key], &str); err == nil {
+			value = str
+		}
+		pairs[i] = [2]string{key, value}
+	}
+
+	return pairs, nil
+}
+
+// Snapshot writes the parameters under path to w as a dotenv file that can be loaded with WithLocalFile
+// or SSM_LOCAL_FILE, so developers can refresh their local copy.
+func Snapshot(path string, w io.Writer) error {
+	return SnapshotContext(context.Background(), path, w)
+}
+
+// SnapshotContext writes the parameters like Snapshot, and stops when ctx is cancelled.
+// Options control the client and the retries like for InitEnvVarsContext.
+// Each parameter is written on a line with its name relative to path and its double quoted value,
+// in name order. The types of the parameters are not kept.
+func SnapshotContext(ctx context.Context, path string, w io.Writer, opts ...Option) error {
+	o := newOptions(path)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.path == "" {
+		return fmt.Errorf("wrong path configuration")
+	}
+
+	if o.client == nil {
+		var err error
+		if o.client, err = newClient(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	params, err := fetchParams(ctx, o)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(params))
+	for i, param := range params {
+		name := strings.TrimPrefix(strings.Replace(*param.Name, o.path, "", 1), "/")
+		lines[i] = name + "=" + strconv.Quote(aws.ToString(param.Value)) + "\n"
+	}
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package ssmenv
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// writeFile writes content to a file named name in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestParseDotenv(t *testing.T) {
+	content := `# Local configuration
+
+DB_HOST=db.local
+export DB_PORT = 5432
+DB_USER=admin # inline comment
+DB_PASSWORD="p@ss #1 \"quoted\"\nnext"
+DB_NAME='literal \n # value'
+URL=http://host/?a=b#fragment
+EMPTY=
+`
+
+	pairs, err := parseDotenv(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseDotenv() unexpected error = %v", err)
+	}
+
+	want := [][2]string{
+		{"DB_HOST", "db.local"},
+		{"DB_PORT", "5432"},
+		{"DB_USER", "admin"},
+		{"DB_PASSWORD", "p@ss #1 \"quoted\"\nnext"},
+		{"DB_NAME", `literal \n # value`},
+		{"URL", "http://host/?a=b#fragment"},
+		{"EMPTY", ""},
+	}
+	if len(pairs) != len(want) {
+		t.Fatalf("parseDotenv() = %q, want %q", pairs, want)
+	}
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Errorf("parseDotenv() pair %d = %q, want %q", i, pairs[i], want[i])
+		}
+	}
+
+	for _, invalid := range []string{"NO_VALUE", "=value", `KEY="unterminated`, "KEY='unterminated"} {
+		if _, err := parseDotenv(strings.NewReader("A=1\n" + invalid)); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("parseDotenv(%q) error = %v, want error on line 2", invalid, err)
+		}
+	}
+}
+
+func TestParseDotenvLongValue(t *testing.T) {
+	// A certificate chain escaped on one line is longer than the default 64KB token of a bufio.Scanner
+	cert := `-----BEGIN CERTIFICATE-----\n` + strings.Repeat(`MIIDdzCCAl+gAwIBAgIEAgAAuTANBgkqhkiG9w0BAQUFADBa\n`, 2000) + `-----END CERTIFICATE-----`
+	content := "TLS_CERT=\"" + cert + "\"\nDB_HOST=db.local\n"
+
+	pairs, err := parseDotenv(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseDotenv() unexpected error = %v", err)
+	}
+
+	wantCert := strings.ReplaceAll(cert, `\n`, "\n")
+	if len(pairs) != 2 || pairs[0] != [2]string{"TLS_CERT", wantCert} || pairs[1] != [2]string{"DB_HOST", "db.local"} {
+		t.Errorf("parseDotenv() = %d pairs, want the certificate and DB_HOST", len(pairs))
+	}
+}
+
+func TestInitEnvVarsLocalFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "dotenv", file: "local.env", content: "ssmenv_test_local=from-file\nssmenv_test_db/password=\"secret\"\n"},
+		{name: "json", file: "local.json", content: `{"ssmenv_test_local": "from-file", "ssmenv_test_db/password": "secret"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SSM_PATH", "")
+			t.Setenv("SSM_LOCAL_FILE", writeFile(t, tt.file, tt.content))
+			t.Setenv("SSMENV_TEST_LOCAL", "local")
+			unsetEnv(t, "SSMENV_TEST_DB_PASSWORD")
+
+			// No client is set, the load must not call AWS
+			result, err := InitEnvVarsWithOptions(WithNoOverride(true))
+			if err != nil {
+				t.Fatalf("InitEnvVarsWithOptions() unexpected error = %v", err)
+			}
+
+			if got := os.Getenv("SSMENV_TEST_DB_PASSWORD"); got != "secret" {
+				t.Errorf("os.Getenv() = %q, want %q", got, "secret")
+			}
+			if got := os.Getenv("SSMENV_TEST_LOCAL"); got != "local" {
+				t.Errorf("os.Getenv() = %q, want the existing value kept", got)
+			}
+			if len(result.Skipped) != 1 || result.Skipped[0] != "SSMENV_TEST_LOCAL" {
+				t.Errorf("InitEnvVarsWithOptions() skipped = %v, want [SSMENV_TEST_LOCAL]", result.Skipped)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadParamsContext(context.Background(), "/myapp/", WithLocalFile(filepath.Join(t.TempDir(), "missing.env")))
+		if err == nil {
+			t.Error("LoadParamsContext() expected error for missing file, got nil")
+		}
+	})
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	client := stmocks.NewFakeSSM(map[string]string{
+		"/myapp/db/password": "p@ss #1 \"quoted\"\nnext",
+		"/myapp/db/host":     "db.local",
+		"/myapp/url":         "http://host/?a=b#fragment",
+		"/myapp/config":      `{"user": "admin", "port": 5432}`,
+	})
+	client.PageSize = 2
+
+	var snapshot bytes.Buffer
+	if err := SnapshotContext(context.Background(), "/myapp/", &snapshot, WithClient(client)); err != nil {
+		t.Fatalf("SnapshotContext() unexpected error = %v", err)
+	}
+
+	wantSnapshot := `config="{\"user\": \"admin\", \"port\": 5432}"
+db/host="db.local"
+db/password="p@ss #1 \"quoted\"\nnext"
+url="http://host/?a=b#fragment"
+`
+	if snapshot.String() != wantSnapshot {
+		t.Errorf("SnapshotContext() = %q, want %q", snapshot.String(), wantSnapshot)
+	}
+
+	file := writeFile(t, "snapshot.env", snapshot.String())
+	for _, path := range []string{"/myapp/", "/myapp"} {
+		for _, opts := range [][]Option{nil, {ExpandJSON(), WithPrefix("APP_")}} {
+			fromSSM, err := LoadParamsContext(context.Background(), path, append(opts, WithClient(client))...)
+			if err != nil {
+				t.Fatalf("LoadParamsContext() unexpected error = %v", err)
+			}
+
+			fromFile, err := LoadParamsContext(context.Background(), path, append(opts, WithLocalFile(file))...)
+			if err != nil {
+				t.Fatalf("LoadParamsContext() unexpected error = %v", err)
+			}
+
+			if !maps.Equal(fromFile, fromSSM) {
+				t.Errorf("LoadParamsContext(%s) from snapshot = %v, want %v", path, fromFile, fromSSM)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ssmenv
+
+import (
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// LoadMetrics describes the timing and the calls of a load, reported by WithMetrics.
+type LoadMetrics struct {
+	// Success is set when the load succeeded.
+	Success bool
+	// Duration is the time taken by the load, measured on the clock of the load, see WithClock.
+	Duration time.Duration
+	// PageLatencies are the time taken to fetch each page of parameters under a path, retries included,
+	// in the order the pages were fetched. Failed pages are not included.
+	PageLatencies []time.Duration
+	// Params is the number of parameters fetched or read from the local file, secrets included.
+	Params int
+	// Retries is the number of calls retried after a throttled or transient failure.
+	Retries int
+	// Throttles is the number of calls throttled by AWS, whether they were retried or not.
+	Throttles int
+}
+
+// WithMetrics calls report once the load succeeds or fails with the metrics of the load, i.e. to graph how long
+// the loads take. It is called by InitEnvVars, InitEnvVarsContext, LoadParamsContext and Init, but not when
+// the load is disabled by SSM_DISABLED. See LogMetrics for a report logging the metrics.
+func WithMetrics(report func(m LoadMetrics)) Option {
+	return func(o *options) {
+		o.metrics = report
+	}
+}
+
+// LogMetrics returns a report for WithMetrics logging the metrics of each load through logger,
+// at info level, or warning level if the load failed.
+func LogMetrics(logger stlogs.Logger) func(m LoadMetrics) {
+	return func(m LoadMetrics) {
+		latencies := make([]int64, len(m.PageLatencies))
+		for i, latency := range m.PageLatencies {
+			latencies[i] = latency.Milliseconds()
+		}
+
+		entry := logger.WithData("success", m.Success).
+			AddData("duration_ms", m.Duration.Milliseconds()).
+			AddData("page_latencies_ms", latencies).
+			AddData("params", m.Params).
+			AddData("retries", m.Retries).
+			AddData("throttles", m.Throttles)
+
+		if !m.Success {
+			entry.Warnf("ssmenv: load failed after %v", m.Duration)
+			return
+		}
+		entry.Infof("ssmenv: loaded %d parameters in %v", m.Params, m.Duration)
+	}
+}
+
+// reportMetrics calls the metrics report, if any, with the metrics of the load started at start and
+// ended with err.
+func (o *options) reportMetrics(start time.Time, err error) {
+	if o.metrics == nil {
+		return
+	}
+
+	o.stats.mu.Lock()
+	m := LoadMetrics{
+		Success:       err == nil,
+		Duration:      o.clock.Now().Sub(start),
+		PageLatencies: append([]time.Duration(nil), o.stats.pageLatencies...),
+		Retries:       o.stats.retries,
+		Throttles:     o.stats.throttles,
+	}
+	for _, count := range o.stats.pathCounts {
+		m.Params += count
+	}
+	o.stats.mu.Unlock()
+
+	o.metrics(m)
+}
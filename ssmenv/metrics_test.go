@@ -0,0 +1,142 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// latencyClient is a fake client whose calls take latency on the clock.
+type latencyClient struct {
+	client  *stmocks.FakeSSM
+	clock   *stmocks.ClockMock
+	latency time.Duration
+}
+
+func (c *latencyClient) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	c.clock.Advance(c.latency)
+	return c.client.GetParametersByPath(ctx, input, optFns...)
+}
+
+func TestWithMetrics(t *testing.T) {
+	params := make(map[string]string)
+	for i := 0; i < 25; i++ {
+		params[fmt.Sprintf("/myapp/ssmenv_test_metric_%02d", i)] = "value"
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		want     LoadMetrics
+	}{
+		{
+			name:     "throttled then loaded",
+			failures: 2,
+			want: LoadMetrics{
+				Success:       true,
+				Duration:      500 * time.Millisecond,
+				PageLatencies: []time.Duration{300 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond},
+				Params:        25,
+				Retries:       2,
+				Throttles:     2,
+			},
+		},
+		{
+			name:     "throttled until failure",
+			failures: 10,
+			want: LoadMetrics{
+				Duration:  300 * time.Millisecond,
+				Retries:   2,
+				Throttles: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := stmocks.NewFakeSSM(params)
+			fake.FailFirst(tt.failures, stmocks.SSMThrottlingError())
+			clock := stmocks.NewClock(time.Unix(1700000000, 0))
+
+			var got []LoadMetrics
+			_, err := LoadParamsContext(context.Background(), "/myapp/",
+				WithClient(&latencyClient{client: fake, clock: clock, latency: 100 * time.Millisecond}),
+				WithClock(clock),
+				WithMaxAttempts(3),
+				WithBackoff(0, 0),
+				WithMetrics(func(m LoadMetrics) { got = append(got, m) }),
+			)
+			if (err == nil) != tt.want.Success {
+				t.Fatalf("LoadParamsContext() error = %v, want success %v", err, tt.want.Success)
+			}
+
+			if len(got) != 1 {
+				t.Fatalf("metrics reported %d times, want once", len(got))
+			}
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("metrics = %+v, want %+v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMetricsInitEnvVars(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_METRIC")
+
+	var got []LoadMetrics
+	report := func(m LoadMetrics) { got = append(got, m) }
+
+	client := stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_metric": "value"})
+	if _, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client), WithMetrics(report)); err != nil {
+		t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+	}
+	if _, err := InitEnvVarsContext(context.Background(), WithPath(""), WithMetrics(report)); err == nil {
+		t.Fatal("InitEnvVarsContext() expected error, got nil")
+	}
+
+	if len(got) != 2 || !got[0].Success || got[0].Params != 1 || len(got[0].PageLatencies) != 1 || got[1].Success {
+		t.Errorf("metrics = %+v, want a successful load of 1 parameter then a failed load", got)
+	}
+}
+
+func TestLogMetrics(t *testing.T) {
+	logger := stmocks.NewLogger()
+	report := LogMetrics(logger)
+
+	report(LoadMetrics{
+		Success:       true,
+		Duration:      1500 * time.Millisecond,
+		PageLatencies: []time.Duration{time.Second, 500 * time.Millisecond},
+		Params:        12,
+		Retries:       1,
+		Throttles:     1,
+	})
+	report(LoadMetrics{Duration: time.Second, Throttles: 6})
+
+	loaded := logger.EntriesAt(stlogs.INFO)
+	if len(loaded) != 1 {
+		t.Fatalf("logged %d info entries, want 1", len(loaded))
+	}
+	want := map[string]any{
+		"success":           true,
+		"duration_ms":       int64(1500),
+		"page_latencies_ms": []int64{1000, 500},
+		"params":            12,
+		"retries":           1,
+		"throttles":         1,
+	}
+	if !reflect.DeepEqual(loaded[0].Data, want) {
+		t.Errorf("logged data = %v, want %v", loaded[0].Data, want)
+	}
+
+	failed := logger.EntriesAt(stlogs.WARN)
+	if len(failed) != 1 || failed[0].Data["success"] != false || failed[0].Data["throttles"] != 6 {
+		t.Errorf("warning entries = %v, want the failed load", failed)
+	}
+}
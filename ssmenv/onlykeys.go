@@ -0,0 +1,114 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// maxBatchSize is the highest number of parameters GetParameters accepts per call.
+const maxBatchSize = 10
+
+// BatchParamClient is a ParamClient fetching parameters by name, required by WithOnlyKeys,
+// implemented by *ssm.Client.
+type BatchParamClient interface {
+	ParamClient
+	GetParameters(ctx context.Context, input *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+}
+
+// WithOnlyKeys loads only the parameters named by keys relative to the path, i.e. api_key or db/password for
+// /myapp/api_key and /myapp/db/password, and ignores the others. It overrides the SSM_ONLY_KEYS environment
+// variable, a comma-separated list of keys, which is honored even if SSM_DISABLED is true so that most values
+// can be set locally while a few are pulled from SSM. The parameters are always fetched from SSM, ignoring
+// the local file, and keys missing under every path make the load fail with a MissingParamsError.
+// The client must implement BatchParamClient.
+func WithOnlyKeys(keys ...string) Option {
+	return func(o *options) {
+		o.onlyKeys = keys
+	}
+}
+
+// fetchOnlyKeys fetches the parameters named by the only keys under each path, by batches of maxBatchSize,
+// keyed by paramKey relative to their path. Keys of later paths take precedence over the same keys of earlier
+// paths. The label and the pinned versions select the versions fetched.
+func fetchOnlyKeys(ctx context.Context, o *options) (map[string]string, error) {
+	client, ok := o.client.(BatchParamClient)
+	if !ok {
+		return nil, fmt.Errorf("only keys require a client implementing BatchParamClient")
+	}
+
+	paths := o.loadPaths()
+	var names []string
+	for _, path := range paths {
+		for _, key := range o.onlyKeys {
+			names = append(names, strings.TrimSuffix(path, "/")+"/"+strings.TrimPrefix(key, "/"))
+		}
+	}
+
+	found := make(map[string]types.Parameter, len(names))
+	for start := 0; start < len(names); start += maxBatchSize {
+		batch := names[start:min(start+maxBatchSize, len(names))]
+
+		input := &ssm.GetParametersInput{WithDecryption: aws.Bool(!o.noDecryption)}
+		for _, name := range batch {
+			input.Names = append(input.Names, o.selector(name))
+		}
+
+		var output *ssm.GetParametersOutput
+		err := retryCall(ctx, o, func(ctx context.Context) error {
+			var err error
+			output, err = client.GetParameters(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to ssm store %w", err)
+		}
+
+		for _, param := range output.Parameters {
+			found[aws.ToString(param.Name)] = param
+		}
+	}
+
+	params := make(map[string]string)
+	loaded := make([]bool, len(o.onlyKeys))
+	for i, path := range paths {
+		pathOptions := *o
+		pathOptions.path = path
+
+		for j := range o.onlyKeys {
+			if param, ok := found[names[i*len(o.onlyKeys)+j]]; ok {
+				pathOptions.addParam(params, param)
+				o.stats.addCount(path, 1)
+				loaded[j] = true
+			}
+		}
+	}
+
+	var missing []string
+	for j, key := range o.onlyKeys {
+		if !loaded[j] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingParamsError{Keys: missing}
+	}
+
+	return params, nil
+}
+
+// selector returns the name of the parameter followed by its pinned version or the label, if any.
+func (o *options) selector(name string) string {
+	if version, pinned := o.versions[name]; pinned {
+		return name + ":" + strconv.FormatInt(version, 10)
+	}
+	if o.label != "" {
+		return name + ":" + o.label
+	}
+	return name
+}
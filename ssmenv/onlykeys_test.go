@@ -0,0 +1,129 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+var _ BatchParamClient = (*ssm.Client)(nil)
+var _ BatchParamClient = (*stmocks.FakeSSM)(nil)
+
+// onlyKeysClient returns a fake client serving the parameters by name, followed by a label if any, and a decoy
+// parameter. The labeled parameters hold a later unlabeled version, so only the labeled one is served "from "+name.
+func onlyKeysClient(names ...string) *stmocks.FakeSSM {
+	client := stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_decoy": "by path"})
+	for _, name := range names {
+		paramName, label, labeled := strings.Cut(name, ":")
+		version := client.Put(paramName, "from "+name)
+		if labeled {
+			client.LabelVersion(paramName, version, label)
+			client.Put(paramName, "latest")
+		}
+	}
+	return client
+}
+
+func TestInitEnvVarsContextOnlyKeysChunks(t *testing.T) {
+	var keys, names, envKeys []string
+	for i := 0; i < 23; i++ {
+		key := fmt.Sprintf("ssmenv_test_only_%02d", i)
+		keys = append(keys, key)
+		names = append(names, "/myapp/"+key)
+		envKeys = append(envKeys, fmt.Sprintf("SSMENV_TEST_ONLY_%02d", i))
+	}
+	unsetEnv(t, envKeys...)
+
+	client := onlyKeysClient(names...)
+	report, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client), WithOnlyKeys(keys...))
+	if err != nil {
+		t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+	}
+
+	if !slices.Equal(report.Set, envKeys) {
+		t.Errorf("InitEnvVarsContext() set %v, want %v", report.Set, envKeys)
+	}
+	for i, key := range envKeys {
+		if got, want := os.Getenv(key), "from "+names[i]; got != want {
+			t.Errorf("os.Getenv(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	var sizes []int
+	for _, input := range client.BatchInputs() {
+		sizes = append(sizes, len(input.Names))
+	}
+	if want := []int{10, 10, 3}; !slices.Equal(sizes, want) {
+		t.Errorf("GetParameters() batch sizes = %v, want %v", sizes, want)
+	}
+	if calls := len(client.Inputs()); calls != 0 {
+		t.Errorf("GetParametersByPath() calls = %d, want 0", calls)
+	}
+}
+
+func TestInitEnvVarsContextOnlyKeysMissing(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_API_KEY")
+
+	client := onlyKeysClient("/myapp/ssmenv_test_api_key")
+	_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client),
+		WithOnlyKeys("ssmenv_test_api_key", "db/password", "ssmenv_test_missing"))
+
+	var missingErr *MissingParamsError
+	if !errors.As(err, &missingErr) || !slices.Equal(missingErr.Keys, []string{"db/password", "ssmenv_test_missing"}) {
+		t.Fatalf("InitEnvVarsContext() error = %v, want MissingParamsError for db/password and ssmenv_test_missing", err)
+	}
+	if _, exists := os.LookupEnv("SSMENV_TEST_API_KEY"); exists {
+		t.Error("InitEnvVarsContext() set variables despite missing keys")
+	}
+}
+
+func TestInitEnvVarsContextOnlyKeysDisabled(t *testing.T) {
+	t.Setenv("SSM_DISABLED", "true")
+	t.Setenv("SSM_PATH", "/myapp/")
+	t.Setenv("SSMENV_TEST_LOCAL", "local")
+	unsetEnv(t, "SSMENV_TEST_API_KEY", "SSMENV_TEST_DECOY")
+
+	t.Run("disabled", func(t *testing.T) {
+		client := onlyKeysClient("/myapp/ssmenv_test_api_key")
+		report, err := InitEnvVarsContext(context.Background(), WithClient(client))
+		if err != nil || len(report.Set) > 0 || len(client.BatchInputs())+len(client.Inputs()) > 0 {
+			t.Errorf("InitEnvVarsContext() = %+v, %v, want nothing loaded", report, err)
+		}
+	})
+
+	t.Run("only keys", func(t *testing.T) {
+		t.Setenv("SSM_ONLY_KEYS", "ssmenv_test_api_key,ssmenv_test_local")
+		t.Setenv("SSM_NO_OVERRIDE", "true")
+
+		client := onlyKeysClient("/myapp/ssmenv_test_api_key:stable", "/myapp/ssmenv_test_local:stable")
+		report, err := InitEnvVarsContext(context.Background(), WithClient(client), WithLabel("stable"))
+		if err != nil {
+			t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+		}
+
+		if got := os.Getenv("SSMENV_TEST_API_KEY"); got != "from /myapp/ssmenv_test_api_key:stable" {
+			t.Errorf("os.Getenv() = %q, want the labeled value", got)
+		}
+		if got := os.Getenv("SSMENV_TEST_LOCAL"); got != "local" {
+			t.Errorf("os.Getenv() = %q, want %q kept by no-override", got, "local")
+		}
+		if _, exists := os.LookupEnv("SSMENV_TEST_DECOY"); exists {
+			t.Error("InitEnvVarsContext() loaded a parameter not listed in SSM_ONLY_KEYS")
+		}
+		if !slices.Equal(report.Skipped, []string{"SSMENV_TEST_LOCAL"}) {
+			t.Errorf("InitEnvVarsContext() skipped %v, want [SSMENV_TEST_LOCAL]", report.Skipped)
+		}
+
+		inputs := client.BatchInputs()
+		if len(inputs) != 1 || inputs[0].Names[0] != "/myapp/ssmenv_test_api_key:stable" {
+			t.Errorf("GetParameters() inputs = %v, want a single labeled batch", inputs)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package ssmenv
+
+import (
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
+	"github.com/stocktwits/go-infrastructure/v2/sterrors"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+const (
+	defaultMaxAttempts    = 6
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 5 * time.Second
+	defaultConcurrency    = 4
+)
+
+// Option customizes how InitEnvVarsWithOptions loads the parameters.
+type Option func(*options)
+
+// options holds the settings of a load, initialized from the SSM_* environment variables.
+type options struct {
+	path             string
+	paths            []string
+	concurrency      int
+	client           ParamClient
+	noOverride       bool
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	callTimeout      time.Duration
+	sdkRetryer       func() aws.Retryer
+	keyTransform     func(paramName string) string
+	prefix           string
+	legacyKeys       bool
+	stringListMode   StringListMode
+	expandJSON       bool
+	warn             func(msg string)
+	warnMu           *sync.Mutex
+	localFile        string
+	secrets          []string
+	secretsClient    SecretsClient
+	requiredKeys     []string
+	logger           stlogs.Logger
+	sensitivePattern *regexp.Regexp
+	stats            *loadStats
+	label            string
+	versions         map[string]int64
+	cacheTTL         time.Duration
+	clock            stclock.Clock
+	region           string
+	endpoint         string
+	withValues       bool
+	noDecryption     bool
+	onlyKeys         []string
+	keyReplacer      *strings.Replacer
+	atomicApply      bool
+	setenv           func(key, value string) error
+	errorFactory     *sterrors.ErrorFactory
+	metrics          func(m LoadMetrics)
+}
+
+// newOptions returns the default options to load the parameters under path.
+// The region and endpoint of the clients are read from SSM_REGION and SSM_ENDPOINT by every function
+// of the package, so tests and CI can target localstack without changing the shared AWS configuration.
+func newOptions(path string) *options {
+	return &options{
+		path:           path,
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		concurrency:    defaultConcurrency,
+		warn:           func(msg string) { log.Print(msg) },
+		warnMu:         &sync.Mutex{},
+		stats:          newLoadStats(),
+		clock:          stclock.System(),
+		region:         os.Getenv("SSM_REGION"),
+		endpoint:       os.Getenv("SSM_ENDPOINT"),
+		keyReplacer:    defaultKeyReplacer,
+		atomicApply:    true,
+		setenv:         os.Setenv,
+	}
+}
+
+// WithPath loads the parameters under path instead of the SSM_PATH environment variable.
+func WithPath(path string) Option {
+	return func(o *options) {
+		o.path = path
+		o.paths = nil
+	}
+}
+
+// WithPaths loads the parameters under each path instead of the SSM_PATH environment variable, which also
+// accepts a comma-separated list of paths. Keys are relative to the path of each parameter, and the keys of
+// later paths take precedence over the same keys of earlier paths. Paths are fetched concurrently, see
+// WithConcurrency. A local file replaces all the paths.
+func WithPaths(paths ...string) Option {
+	return func(o *options) {
+		o.paths = paths
+	}
+}
+
+// WithConcurrency sets the number of paths fetched at the same time, each path having at most one call
+// in flight, to avoid SSM throttling. Values lower than 1 fetch the paths one at a time. Defaults to 4.
+// The keys of a page are computed while the next page is in flight, but the environment variables are
+// set once every path is fetched, so that the required and invalid keys are checked first.
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = max(concurrency, 1)
+	}
+}
+
+// WithClient uses client instead of an SSM client created from the shared AWS configuration.
+// The client is called with the context of the load.
+func WithClient(client ParamClient) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithNoOverride skips the parameters whose key is already set in the environment, so values set in the
+// container spec for local overrides are kept. It overrides the SSM_NO_OVERRIDE environment variable.
+// By default every parameter is set.
+func WithNoOverride(noOverride bool) Option {
+	return func(o *options) {
+		o.noOverride = noOverride
+	}
+}
+
+// WithMaxAttempts sets the number of calls made to fetch a page of parameters before failing,
+// including the first one. Values lower than 1 disable the retries. Defaults to 6.
+func WithMaxAttempts(attempts int) Option {
+	return func(o *options) {
+		o.maxAttempts = max(attempts, 1)
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled on each retry up to maxBackoff.
+// A random jitter of up to half the delay is removed from each wait. Defaults to 1s and 5s.
+func WithBackoff(initial, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.initialBackoff = initial
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithSDKRetryer leaves the retries to the retryer of aws-sdk-go-v2, i.e. retry.NewStandard or retry.NewAdaptiveMode,
+// instead of the retries of the package: the clients created from the shared AWS configuration use the retryer,
+// and each call is made once by the package, ignoring WithMaxAttempts and WithBackoff. Clients set with WithClient
+// keep their own retryer. By default the created clients do not retry, and the package retries the throttled and
+// transient failures with a backoff, counting them in the report and the metrics.
+func WithSDKRetryer(retryer func() aws.Retryer) Option {
+	return func(o *options) {
+		o.sdkRetryer = retryer
+	}
+}
+
+// WithCallTimeout limits the duration of each call to SSM, a call timing out is retried.
+// By default calls are only limited by the context of the load.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.callTimeout = timeout
+	}
+}
+
+// WithClock reads the time and waits the retry backoff with clock instead of the system clock, i.e. the fake
+// clock of stmocks, so the retries and the cache TTL can be tested without sleeping.
+func WithClock(clock stclock.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithKeyTransform computes the key of each parameter from its full name, i.e. /myapp/db/password, instead
+// of the default normalization, which strips the path, replaces the separators of nested parameters with
+// underscores and upper-cases the name. The keys are validated like the normalized ones, see WithKeyReplacer.
+func WithKeyTransform(transform func(paramName string) string) Option {
+	return func(o *options) {
+		o.keyTransform = transform
+	}
+}
+
+// WithPrefix prepends prefix, i.e. "MYAPP_", to the key of each parameter.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithLegacyNestedKeys keeps the separators of nested parameters in their keys, so /myapp/db/password
+// is DB/PASSWORD as in the previous versions instead of DB_PASSWORD.
+func WithLegacyNestedKeys() Option {
+	return func(o *options) {
+		o.legacyKeys = true
+	}
+}
+
+// WithRequiredKeys fails the load with a MissingParamsError listing the keys that are neither loaded nor
+// already set in the environment. The environment is not changed when keys are missing.
+func WithRequiredKeys(keys ...string) Option {
+	return func(o *options) {
+		o.requiredKeys = keys
+	}
+}
+
+// WithRegion creates the SSM and Secrets Manager clients in region instead of the region of the shared
+// AWS configuration. It overrides the SSM_REGION environment variable, and does not apply to the clients
+// set with WithClient or WithSecretsClient.
+func WithRegion(region string) Option {
+	return func(o *options) {
+		o.region = region
+	}
+}
+
+// WithEndpoint sends the calls of the SSM and Secrets Manager clients to url, i.e. http://localhost:4566
+// for localstack. It overrides the SSM_ENDPOINT environment variable, and does not apply to the clients
+// set with WithClient or WithSecretsClient.
+func WithEndpoint(url string) Option {
+	return func(o *options) {
+		o.endpoint = url
+	}
+}
+
+// AtomicApply restores the environment variables already set to their prior values, or unsets them,
+// if setting a variable fails, so the environment is either fully loaded or unchanged. Parameters are
+// always fetched and converted before the environment is changed. Defaults to true.
+func AtomicApply(atomic bool) Option {
+	return func(o *options) {
+		o.atomicApply = atomic
+	}
+}
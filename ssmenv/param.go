@@ -0,0 +1,197 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+var (
+	sharedClientsMu sync.Mutex
+	sharedClients   = make(map[clientConfig]*ssm.Client)
+	paramCache      = newCache()
+)
+
+// clientConfig identifies the clients shared by GetParam.
+type clientConfig struct {
+	region   string
+	endpoint string
+}
+
+// WithCache serves GetParam from an in-memory cache shared by the calls using it, refreshing the parameters
+// fetched more than ttl ago. Values are cached per client, so calls with different clients, regions or endpoints
+// do not share them: the shared clients are identified by their region and endpoint, and the clients set with
+// WithClient by their address, so clients that are not pointers are not cached. Failures are not cached, and
+// expired values are evicted when new ones are cached. By default every call fetches the parameter.
+func WithCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
+
+// GetParam returns the value of a single parameter, identified by its full name such as /myapp/webhook/key,
+// optionally followed by a version or label selector. SecureString parameters are decrypted if decrypt is true.
+// Options control the client, the retries and the cache, see WithCache. Without client, a client created
+// from the shared AWS configuration is reused by the calls with the same region and endpoint.
+func GetParam(ctx context.Context, name string, decrypt bool, opts ...Option) (string, error) {
+	o := newOptions("")
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("missing parameter name")
+	}
+
+	key, cached := o.cacheKey(name, decrypt)
+	cached = cached && o.cacheTTL > 0
+	if cached {
+		if value, ok := paramCache.get(key, o.clock.Now().Add(-o.cacheTTL)); ok {
+			return value, nil
+		}
+	}
+
+	if o.client == nil {
+		var err error
+		if o.client, err = sharedClient(ctx, o); err != nil {
+			return "", fmt.Errorf("failed to fetch parameter %s: %w", name, err)
+		}
+	}
+
+	client, ok := o.client.(VersionParamClient)
+	if !ok {
+		return "", fmt.Errorf("fetching a parameter requires a client implementing VersionParamClient")
+	}
+
+	var output *ssm.GetParameterOutput
+	err := retryCall(ctx, o, func(ctx context.Context) error {
+		var err error
+		output, err = client.GetParameter(ctx, &ssm.GetParameterInput{
+			Name:           aws.String(name),
+			WithDecryption: aws.Bool(decrypt),
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch parameter %s: %w", name, err)
+	}
+
+	value := aws.ToString(output.Parameter.Value)
+	if cached {
+		paramCache.set(key, value, o.clock.Now(), o.cacheTTL)
+	}
+
+	return value, nil
+}
+
+// sharedClient returns the client created for the region and endpoint of the options, creating it if needed.
+// The client is created without holding the lock, so the calls loading the AWS configuration do not wait for
+// each other; if two calls create a client for the same configuration, the first one stored is kept.
+func sharedClient(ctx context.Context, o *options) (*ssm.Client, error) {
+	config := clientConfig{region: o.region, endpoint: o.endpoint}
+
+	sharedClientsMu.Lock()
+	client, ok := sharedClients[config]
+	sharedClientsMu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	client, err := newClient(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedClientsMu.Lock()
+	defer sharedClientsMu.Unlock()
+
+	if stored, ok := sharedClients[config]; ok {
+		return stored, nil
+	}
+	sharedClients[config] = client
+	return client, nil
+}
+
+// MustGetParam returns the value of a single parameter like GetParam, and panics if it fails.
+func MustGetParam(ctx context.Context, name string, decrypt bool, opts ...Option) string {
+	value, err := GetParam(ctx, name, decrypt, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// cacheKey identifies a cached parameter value by the client fetching it and the parameter.
+type cacheKey struct {
+	// config identifies the shared client of the calls without client
+	config clientConfig
+	// client is the client set with WithClient, always a pointer so keys compare it by address, nil for the
+	// shared clients
+	client  ParamClient
+	name    string
+	decrypt bool
+}
+
+// cacheKey returns the key of the parameter in the cache, and false if the client of the options has no
+// identity to be cached with: only the shared clients and the clients that are pointers do.
+func (o *options) cacheKey(name string, decrypt bool) (cacheKey, bool) {
+	key := cacheKey{name: name, decrypt: decrypt}
+	if o.client == nil {
+		key.config = clientConfig{region: o.region, endpoint: o.endpoint}
+		return key, true
+	}
+
+	if client := reflect.ValueOf(o.client); client.Kind() != reflect.Pointer || client.IsNil() {
+		return cacheKey{}, false
+	}
+	key.client = o.client
+	return key, true
+}
+
+// cacheEntry is a cached parameter value with the time it was fetched and the time it expires.
+type cacheEntry struct {
+	value     string
+	fetchedAt time.Time
+	expiresAt time.Time
+}
+
+// cache holds the parameter values fetched by GetParam, safe for concurrent use.
+type cache struct {
+	mu      sync.RWMutex
+	entries map[cacheKey]cacheEntry
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[cacheKey]cacheEntry)}
+}
+
+// get returns the cached value if it was fetched after the given time.
+func (c *cache) get(key cacheKey, fetchedAfter time.Time) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !entry.fetchedAt.After(fetchedAfter) {
+		return "", false
+	}
+	return entry.value, true
+}
+
+// set caches the value fetched at the given time for ttl, and evicts the expired values.
+func (c *cache) set(key cacheKey, value string, fetchedAt time.Time, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if !entry.expiresAt.After(fetchedAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cacheEntry{value: value, fetchedAt: fetchedAt, expiresAt: fetchedAt.Add(ttl)}
+}
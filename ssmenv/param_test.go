@@ -0,0 +1,192 @@
+package ssmenv
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// webhookClient returns a fake client serving a webhook key.
+func webhookClient() *stmocks.FakeSSM {
+	return stmocks.NewFakeSSM(map[string]string{"/myapp/webhook/key": "signing-key"})
+}
+
+func TestGetParamCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		calls     []time.Duration
+		wantCalls int
+	}{
+		{name: "no cache", calls: []time.Duration{0, 0, 0}, wantCalls: 3},
+		{name: "hits", ttl: time.Minute, calls: []time.Duration{0, 10 * time.Second, 49 * time.Second}, wantCalls: 1},
+		{name: "expired", ttl: time.Minute, calls: []time.Duration{0, time.Minute, 30 * time.Second}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paramCache = newCache()
+			clock := stmocks.NewClock(time.Unix(1700000000, 0))
+			client := webhookClient()
+
+			for i, advance := range tt.calls {
+				clock.Advance(advance)
+				value, err := GetParam(context.Background(), "/myapp/webhook/key", true, WithClient(client), WithCache(tt.ttl), WithClock(clock))
+				if err != nil {
+					t.Fatalf("GetParam() call %d unexpected error = %v", i, err)
+				}
+				if value != "signing-key" {
+					t.Errorf("GetParam() call %d = %q, want %q", i, value, "signing-key")
+				}
+			}
+
+			inputs := client.ParamInputs()
+			if len(inputs) != tt.wantCalls {
+				t.Errorf("GetParameter() calls = %d, want %d", len(inputs), tt.wantCalls)
+			}
+			for _, input := range inputs {
+				if !aws.ToBool(input.WithDecryption) {
+					t.Errorf("GetParameter() input = %v, want decryption", input)
+				}
+			}
+		})
+	}
+}
+
+func TestGetParamCacheKeys(t *testing.T) {
+	paramCache = newCache()
+	client := webhookClient()
+
+	for _, decrypt := range []bool{true, false, true} {
+		if _, err := GetParam(context.Background(), "/myapp/webhook/key", decrypt, WithClient(client), WithCache(time.Minute)); err != nil {
+			t.Fatalf("GetParam() unexpected error = %v", err)
+		}
+	}
+	if calls := len(client.ParamInputs()); calls != 2 {
+		t.Errorf("GetParameter() calls = %d, want 2 (one per decryption mode)", calls)
+	}
+
+	// Failures are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := GetParam(context.Background(), "/myapp/unknown", true, WithClient(client), WithCache(time.Minute)); err == nil {
+			t.Fatal("GetParam() expected error for an unknown parameter, got nil")
+		}
+	}
+	if calls := len(client.ParamInputs()); calls != 4 {
+		t.Errorf("GetParameter() calls = %d, want 4", calls)
+	}
+}
+
+func TestGetParamCacheScope(t *testing.T) {
+	paramCache = newCache()
+	clock := stmocks.NewClock(time.Unix(1700000000, 0))
+
+	staging := stmocks.NewFakeSSM(map[string]string{"/myapp/webhook/key": "staging-key", "/myapp/other": "other"})
+	clients := map[*stmocks.FakeSSM]string{webhookClient(): "signing-key", staging: "staging-key"}
+
+	for i := 0; i < 2; i++ {
+		for client, want := range clients {
+			value, err := GetParam(context.Background(), "/myapp/webhook/key", true, WithClient(client), WithCache(time.Minute), WithClock(clock))
+			if err != nil {
+				t.Fatalf("GetParam() unexpected error = %v", err)
+			}
+			if value != want {
+				t.Errorf("GetParam() = %q, want %q from its own client", value, want)
+			}
+		}
+	}
+	for client := range clients {
+		if calls := len(client.ParamInputs()); calls != 1 {
+			t.Errorf("GetParameter() calls = %d, want 1 per client", calls)
+		}
+	}
+
+	// Caching a value evicts the expired ones
+	clock.Advance(2 * time.Minute)
+	if _, err := GetParam(context.Background(), "/myapp/other", true, WithClient(staging), WithCache(time.Minute), WithClock(clock)); err != nil {
+		t.Fatalf("GetParam() unexpected error = %v", err)
+	}
+	if entries := len(paramCache.entries); entries != 1 {
+		t.Errorf("cache entries = %d, want 1 after the expired values are evicted", entries)
+	}
+}
+
+// valueClient is a client that is not a pointer, holding a client whose type is not comparable.
+type valueClient struct {
+	VersionParamClient
+}
+
+// mapClient is a client serving the values of a map.
+type mapClient map[string]string
+
+func (c mapClient) GetParametersByPath(context.Context, *ssm.GetParametersByPathInput, ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	return &ssm.GetParametersByPathOutput{}, nil
+}
+
+func (c mapClient) GetParameter(_ context.Context, input *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Name: input.Name, Value: aws.String(c[aws.ToString(input.Name)])}}, nil
+}
+
+func TestGetParamCacheIdentity(t *testing.T) {
+	paramCache = newCache()
+
+	// Clients that are not pointers are not cached, even if their type is comparable
+	client := valueClient{mapClient{"/myapp/webhook/key": "signing-key"}}
+	for i := 0; i < 2; i++ {
+		if value, err := GetParam(context.Background(), "/myapp/webhook/key", true, WithClient(client), WithCache(time.Minute)); err != nil || value != "signing-key" {
+			t.Fatalf("GetParam() = %q, %v, want %q", value, err, "signing-key")
+		}
+	}
+	if entries := len(paramCache.entries); entries != 0 {
+		t.Errorf("cache entries = %d, want none for a client that is not a pointer", entries)
+	}
+
+	// The shared clients are identified by their region and endpoint, so cached values are served without
+	// creating a client
+	clock := stmocks.NewClock(time.Unix(1700000000, 0))
+	shared := cacheKey{config: clientConfig{region: "eu-west-3", endpoint: "http://127.0.0.1:1"}, name: "/myapp/webhook/key", decrypt: true}
+	paramCache.set(shared, "shared-key", clock.Now(), time.Minute)
+
+	value, err := GetParam(context.Background(), "/myapp/webhook/key", true, WithRegion("eu-west-3"),
+		WithEndpoint("http://127.0.0.1:1"), WithCache(time.Minute), WithClock(clock))
+	if err != nil || value != "shared-key" {
+		t.Errorf("GetParam() = %q, %v, want the value cached for the region and endpoint", value, err)
+	}
+}
+
+func TestGetParamConcurrent(t *testing.T) {
+	paramCache = newCache()
+	client := webhookClient()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value := MustGetParam(context.Background(), "/myapp/webhook/key", true, WithClient(client), WithCache(time.Minute)); value != "signing-key" {
+				t.Errorf("MustGetParam() = %q, want %q", value, "signing-key")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := len(client.ParamInputs()); calls == 0 || calls > 20 {
+		t.Errorf("GetParameter() calls = %d, want between 1 and 20", calls)
+	}
+}
+
+func TestMustGetParamPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGetParam() expected panic for an unknown parameter")
+		}
+	}()
+
+	MustGetParam(context.Background(), "/myapp/unknown", true, WithClient(webhookClient()))
+}
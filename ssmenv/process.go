@@ -0,0 +1,250 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sliceSeparator separates the elements of a slice in a parameter, while sliceDefaultSeparator separates them
+// in a default value because the envconfig tag options are already separated by commas.
+const (
+	sliceSeparator        = ","
+	sliceDefaultSeparator = ";"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// MissingParamsError is returned by Process when required fields have neither a parameter nor a default value,
+// and by the loads when keys required by WithRequiredKeys or WithOnlyKeys are missing.
+type MissingParamsError struct {
+	// Keys are the keys of the missing parameters.
+	Keys []string
+}
+
+// Error returns the list of missing keys.
+func (e *MissingParamsError) Error() string {
+	return fmt.Sprintf("missing ssm parameters: %s", strings.Join(e.Keys, ", "))
+}
+
+// Process populates the struct pointed to by cfg with the parameters under path, without changing the environment.
+// Fields are matched like envconfig does, using the same `envconfig` tags:
+//   - the key is the field name in upper snake case (DBHost is DB_HOST or DBHOST), prefixed by the names
+//     of the parent structs, unless the tag sets a custom key
+//   - "default=value" is used when the parameter is missing, with slice elements separated by semicolons
+//   - "optional" leaves the field unchanged when the parameter is missing
+//   - "-" skips the field
+//
+// Supported field types are strings, ints, uints, bools, floats, time.Duration, and slices of those with the
+// elements separated by commas. Required fields without parameter make Process fail with a MissingParamsError
+// listing all the missing keys.
+func Process(path string, cfg interface{}) error {
+	return ProcessContext(context.Background(), path, cfg)
+}
+
+// ProcessContext populates cfg like Process, and stops when ctx is cancelled.
+// Options control the client and the retries like for InitEnvVarsContext.
+func ProcessContext(ctx context.Context, path string, cfg interface{}, opts ...Option) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a non-nil pointer to a struct")
+	}
+
+	params, err := LoadParamsContext(ctx, path, opts...)
+	if err != nil {
+		return err
+	}
+
+	return populate(params, value.Elem())
+}
+
+// populate sets the fields of the struct from the parameters.
+func populate(params map[string]string, value reflect.Value) error {
+	var missing []string
+	if err := populateStruct(params, value, "", false, &missing); err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return &MissingParamsError{Keys: missing}
+	}
+
+	return nil
+}
+
+// fieldTag holds the options of an envconfig tag.
+type fieldTag struct {
+	key        string
+	optional   bool
+	skip       bool
+	defaultVal string
+}
+
+// parseFieldTag parses an envconfig tag, the options are separated by commas.
+func parseFieldTag(s string) fieldTag {
+	var tag fieldTag
+	for _, token := range strings.Split(s, ",") {
+		switch {
+		case token == "":
+		case token == "-":
+			tag.skip = true
+		case token == "optional":
+			tag.optional = true
+		case strings.HasPrefix(token, "default="):
+			tag.defaultVal = strings.TrimPrefix(token, "default=")
+		default:
+			tag.key = token
+		}
+	}
+	return tag
+}
+
+// populateStruct sets the fields of a struct, the nested structs using their field chain as key prefix.
+// The keys of the required fields without value are appended to missing.
+func populateStruct(params map[string]string, value reflect.Value, chain string, optional bool, missing *[]string) error {
+	for i := 0; i < value.NumField(); i++ {
+		info := value.Type().Field(i)
+		tag := parseFieldTag(info.Tag.Get("envconfig"))
+		if !info.IsExported() || tag.skip {
+			continue
+		}
+
+		field := value.Field(i)
+		fieldChain := info.Name
+		if chain != "" {
+			fieldChain = chain + "." + info.Name
+		}
+
+		if field.Kind() == reflect.Struct {
+			if err := populateStruct(params, field, fieldChain, optional || tag.optional, missing); err != nil {
+				return err
+			}
+			continue
+		}
+
+		keys := fieldKeys(fieldChain, tag.key)
+		str, key, separator := "", keys[0], sliceSeparator
+		for _, k := range keys {
+			if v := params[k]; v != "" {
+				str, key = v, k
+				break
+			}
+		}
+
+		if str == "" {
+			switch {
+			case tag.defaultVal != "":
+				str, separator = tag.defaultVal, sliceDefaultSeparator
+			case optional || tag.optional:
+				continue
+			default:
+				*missing = append(*missing, key)
+				continue
+			}
+		}
+
+		if err := setFieldValue(field, str, separator); err != nil {
+			return fmt.Errorf("invalid value for ssm parameter %s (field %s): %w", key, fieldChain, err)
+		}
+	}
+
+	return nil
+}
+
+// fieldKeys returns the parameter keys matching a field, the first one being used in errors.
+// A custom key is matched as is and upper-cased, otherwise the field chain is upper-cased with
+// and without underscores between words, i.e. Cache.MaxSize is CACHE_MAX_SIZE or CACHE_MAXSIZE.
+func fieldKeys(chain, custom string) []string {
+	if custom != "" {
+		if upper := strings.ToUpper(custom); upper != custom {
+			return []string{custom, upper}
+		}
+		return []string{custom}
+	}
+
+	runes := []rune(chain)
+	var words, plain strings.Builder
+	for i, r := range runes {
+		if r == '.' {
+			words.WriteRune('_')
+			plain.WriteRune('_')
+			continue
+		}
+
+		// A word starts at an upper case letter following a lower case one, or followed by one in an acronym
+		if i > 0 && unicode.IsUpper(r) && runes[i-1] != '.' {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				words.WriteRune('_')
+			}
+		}
+
+		words.WriteRune(unicode.ToUpper(r))
+		plain.WriteRune(unicode.ToUpper(r))
+	}
+
+	if words.String() == plain.String() {
+		return []string{words.String()}
+	}
+	return []string{words.String(), plain.String()}
+}
+
+// setFieldValue parses str into the field, splitting slices with the separator.
+func setFieldValue(field reflect.Value, str, separator string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(str)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		elements := strings.Split(str, separator)
+		slice := reflect.MakeSlice(field.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := setFieldValue(slice.Index(i), strings.TrimSpace(element), separator); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
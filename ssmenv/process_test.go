@@ -0,0 +1,178 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+type testConfig struct {
+	DBHost   string
+	DBPort   int `envconfig:"default=5432"`
+	Debug    bool
+	Ratio    float64
+	Timeout  time.Duration
+	Hosts    []string
+	Retries  []int  `envconfig:"default=1;2;3"`
+	APIKey   string `envconfig:"SERVICE_KEY"`
+	Verbose  bool   `envconfig:"optional"`
+	Internal string `envconfig:"-"`
+	Cache    struct {
+		TTL     time.Duration
+		MaxSize uint `envconfig:"optional"`
+	}
+}
+
+// paramsClient returns a fake client serving the parameters under /myapp/.
+func paramsClient(params map[string]string) *stmocks.FakeSSM {
+	named := make(map[string]string, len(params))
+	for name, value := range params {
+		named["/myapp/"+name] = value
+	}
+	return stmocks.NewFakeSSM(named)
+}
+
+func TestProcess(t *testing.T) {
+	client := paramsClient(map[string]string{
+		"db_host":     "db.local",
+		"debug":       "true",
+		"ratio":       "0.75",
+		"timeout":     "1m30s",
+		"hosts":       "a.local, b.local",
+		"service_key": "secret",
+		"cache_ttl":   "10s",
+		"internal":    "ignored",
+	})
+
+	cfg := testConfig{Internal: "kept"}
+	if err := ProcessContext(context.Background(), "/myapp/", &cfg, WithClient(client)); err != nil {
+		t.Fatalf("ProcessContext() unexpected error = %v", err)
+	}
+
+	want := testConfig{
+		DBHost:   "db.local",
+		DBPort:   5432,
+		Debug:    true,
+		Ratio:    0.75,
+		Timeout:  90 * time.Second,
+		Hosts:    []string{"a.local", "b.local"},
+		Retries:  []int{1, 2, 3},
+		APIKey:   "secret",
+		Internal: "kept",
+	}
+	want.Cache.TTL = 10 * time.Second
+
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("ProcessContext() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	required := map[string]string{
+		"db_host": "db.local", "debug": "false", "ratio": "1", "timeout": "1s",
+		"hosts": "a", "service_key": "secret", "cache_ttl": "1s",
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		wantErr  []string
+		wantKeys []string
+	}{
+		{
+			name:    "invalid int",
+			params:  map[string]string{"db_port": "http"},
+			wantErr: []string{"DB_PORT", "DBPort"},
+		},
+		{
+			name:    "invalid duration in nested struct",
+			params:  map[string]string{"cache_ttl": "soon"},
+			wantErr: []string{"CACHE_TTL", "Cache.TTL"},
+		},
+		{
+			name:    "invalid slice element",
+			params:  map[string]string{"retries": "1,two"},
+			wantErr: []string{"RETRIES", "Retries", "element 1"},
+		},
+		{
+			name:     "missing parameters",
+			params:   map[string]string{"db_host": "", "service_key": "", "cache_ttl": ""},
+			wantKeys: []string{"DB_HOST", "SERVICE_KEY", "CACHE_TTL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := make(map[string]string)
+			for k, v := range required {
+				params[k] = v
+			}
+			for k, v := range tt.params {
+				if v == "" {
+					delete(params, k)
+				} else {
+					params[k] = v
+				}
+			}
+
+			err := ProcessContext(context.Background(), "/myapp/", &testConfig{}, WithClient(paramsClient(params)))
+			if err == nil {
+				t.Fatal("ProcessContext() expected error, got nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ProcessContext() error = %q, want it to contain %q", err, want)
+				}
+			}
+
+			if tt.wantKeys != nil {
+				var missingErr *MissingParamsError
+				if !errors.As(err, &missingErr) {
+					t.Fatalf("ProcessContext() error = %v, want MissingParamsError", err)
+				}
+				if !slices.Equal(missingErr.Keys, tt.wantKeys) {
+					t.Errorf("MissingParamsError.Keys = %v, want %v", missingErr.Keys, tt.wantKeys)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessInvalidConfig(t *testing.T) {
+	client := paramsClient(nil)
+	for _, cfg := range []interface{}{testConfig{}, (*testConfig)(nil), new(string)} {
+		if err := ProcessContext(context.Background(), "/myapp/", cfg, WithClient(client)); err == nil {
+			t.Errorf("ProcessContext(%T) expected error, got nil", cfg)
+		}
+	}
+	if calls := len(client.Inputs()); calls != 0 {
+		t.Errorf("GetParametersByPath() calls = %d, want 0 for invalid configs", calls)
+	}
+}
+
+func TestFieldKeys(t *testing.T) {
+	tests := []struct {
+		chain  string
+		custom string
+		want   []string
+	}{
+		{chain: "Timeout", want: []string{"TIMEOUT"}},
+		{chain: "DBHost", want: []string{"DB_HOST", "DBHOST"}},
+		{chain: "MaxSize", want: []string{"MAX_SIZE", "MAXSIZE"}},
+		{chain: "Cache.TTL", want: []string{"CACHE_TTL"}},
+		{chain: "APIKey", custom: "SERVICE_KEY", want: []string{"SERVICE_KEY"}},
+		{chain: "APIKey", custom: "serviceKey", want: []string{"serviceKey", "SERVICEKEY"}},
+	}
+
+	for _, tt := range tests {
+		if got := fieldKeys(tt.chain, tt.custom); !slices.Equal(got, tt.want) {
+			t.Errorf("fieldKeys(%q, %q) = %v, want %v", tt.chain, tt.custom, got, tt.want)
+		}
+	}
+}
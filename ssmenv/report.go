@@ -0,0 +1,174 @@
+package ssmenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// DefaultSensitivePattern matches the keys usually holding credentials, to be used with WithSensitivePattern.
+var DefaultSensitivePattern = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|CREDENTIAL|PRIVATE|API_?KEY)`)
+
+// Report describes a load of parameters by the keys of the environment variables, never their values.
+// Keys matching the sensitive pattern are replaced by their hash, see WithSensitivePattern.
+type Report struct {
+	// Set are the keys of the environment variables set from a parameter, in key order.
+	Set []string
+	// Skipped are the keys already present in the environment, left untouched because of WithNoOverride,
+	// in key order.
+	Skipped []string
+	// Overridden are the keys of Set that were already present in the environment with another value,
+	// in key order.
+	Overridden []string
+	// PathCounts are the number of parameters fetched under each path or read from the local file,
+	// and the number of secrets fetched for each prefix or ARN.
+	PathCounts map[string]int
+	// Duration is the time taken by the load, measured on the clock of the load, see WithClock.
+	Duration time.Duration
+	// Retries is the number of calls retried after a throttled or transient failure.
+	Retries int
+}
+
+// WithLogger logs a summary of the load through logger, with the keys of the Report but never the values.
+func WithLogger(logger stlogs.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithSensitivePattern replaces the keys matching pattern, i.e. DefaultSensitivePattern, by their hash in
+// the Report and the logged summary, so the names of the credentials loaded are not disclosed while loads
+// can still be compared. By default keys are reported as is.
+func WithSensitivePattern(pattern *regexp.Regexp) Option {
+	return func(o *options) {
+		o.sensitivePattern = pattern
+	}
+}
+
+// loadStats accumulates the counters of a load, shared by the copies of its options.
+type loadStats struct {
+	mu         sync.Mutex
+	start      time.Time
+	retries    int
+	throttles  int
+	pathCounts map[string]int
+	// pageLatencies are the time taken to fetch each page, in order
+	pageLatencies []time.Duration
+	// invalidKeys are the invalid keys, keyed by parameter name
+	invalidKeys map[string]string
+}
+
+// newLoadStats returns the stats of a load, started by startLoad.
+func newLoadStats() *loadStats {
+	return &loadStats{pathCounts: make(map[string]int)}
+}
+
+// startLoad records the start of the load on the clock of the options, and returns it.
+func (o *options) startLoad() time.Time {
+	o.stats.mu.Lock()
+	defer o.stats.mu.Unlock()
+
+	o.stats.start = o.clock.Now()
+	return o.stats.start
+}
+
+// addRetry counts a retried call.
+func (s *loadStats) addRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retries++
+}
+
+// addThrottle counts a throttled call.
+func (s *loadStats) addThrottle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.throttles++
+}
+
+// addPage records the time taken to fetch a page.
+func (s *loadStats) addPage(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pageLatencies = append(s.pageLatencies, latency)
+}
+
+// addCount counts the parameters fetched under a path.
+func (s *loadStats) addCount(path string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pathCounts[path] += count
+}
+
+// addInvalidKey records the invalid key of a parameter.
+func (s *loadStats) addInvalidKey(name, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.invalidKeys == nil {
+		s.invalidKeys = make(map[string]string)
+	}
+	s.invalidKeys[name] = key
+}
+
+// invalidKeysError returns an InvalidKeysError if invalid keys were recorded, nil otherwise.
+func (s *loadStats) invalidKeysError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.invalidKeys) == 0 {
+		return nil
+	}
+	return &InvalidKeysError{Keys: s.invalidKeys}
+}
+
+// newReport returns the report of the load with its stats.
+func (o *options) newReport() *Report {
+	o.stats.mu.Lock()
+	defer o.stats.mu.Unlock()
+
+	pathCounts := make(map[string]int, len(o.stats.pathCounts))
+	for path, count := range o.stats.pathCounts {
+		pathCounts[path] = count
+	}
+
+	return &Report{
+		PathCounts: pathCounts,
+		Duration:   o.clock.Now().Sub(o.stats.start),
+		Retries:    o.stats.retries,
+	}
+}
+
+// reportKey returns the key as named in the report, its hash if it matches the sensitive pattern.
+func (o *options) reportKey(key string) string {
+	if o.sensitivePattern == nil || !o.sensitivePattern.MatchString(key) {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// logReport logs the summary of the load if a logger is set.
+func (o *options) logReport(report *Report) {
+	if o.logger == nil {
+		return
+	}
+
+	o.logger.WithData("set", report.Set).
+		AddData("skipped", report.Skipped).
+		AddData("overridden", report.Overridden).
+		AddData("paths", report.PathCounts).
+		AddData("duration_ms", report.Duration.Milliseconds()).
+		AddData("retries", report.Retries).
+		Infof("ssmenv: set %d environment variables, skipped %d, overridden %d in %v",
+			len(report.Set), len(report.Skipped), len(report.Overridden), report.Duration)
+}
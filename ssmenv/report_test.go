@@ -0,0 +1,121 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestReport(t *testing.T) {
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+	password := (&options{sensitivePattern: DefaultSensitivePattern}).reportKey("SSMENV_TEST_DB_PASSWORD")
+
+	tests := []struct {
+		name           string
+		noOverride     bool
+		wantSet        []string
+		wantSkipped    []string
+		wantOverridden []string
+	}{
+		{
+			name:           "override",
+			wantSet:        []string{"SSMENV_TEST_DB_HOST", password, "SSMENV_TEST_LEVEL", "SSMENV_TEST_REGION", "SSMENV_TEST_SAME"},
+			wantOverridden: []string{"SSMENV_TEST_LEVEL"},
+		},
+		{
+			name:        "no override",
+			noOverride:  true,
+			wantSet:     []string{"SSMENV_TEST_DB_HOST", password, "SSMENV_TEST_REGION"},
+			wantSkipped: []string{"SSMENV_TEST_LEVEL", "SSMENV_TEST_SAME"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SSMENV_TEST_LEVEL", "local")
+			t.Setenv("SSMENV_TEST_SAME", "same")
+			unsetEnv(t, "SSMENV_TEST_DB_HOST", "SSMENV_TEST_DB_PASSWORD", "SSMENV_TEST_REGION")
+
+			client := stmocks.NewFakeSSM(map[string]string{
+				"/shared/ssmenv_test_region": "us-east-1",
+				"/shared/ssmenv_test_level":  "shared",
+				"/myapp/ssmenv_test_db":      `{"host": "db.local", "password": "hunter2"}`,
+				"/myapp/ssmenv_test_level":   "myapp",
+				"/myapp/ssmenv_test_same":    "same",
+			})
+			client.FailFirst(1, throttled)
+
+			logger := stmocks.NewLogger()
+			report, err := InitEnvVarsContext(context.Background(), WithPaths("/shared/", "/myapp/"), WithClient(client),
+				WithNoOverride(tt.noOverride), ExpandJSON(), WithBackoff(0, 0),
+				WithSensitivePattern(DefaultSensitivePattern), WithLogger(logger))
+			if err != nil {
+				t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(report.Set, tt.wantSet) {
+				t.Errorf("Report.Set = %v, want %v", report.Set, tt.wantSet)
+			}
+			if !reflect.DeepEqual(report.Skipped, tt.wantSkipped) {
+				t.Errorf("Report.Skipped = %v, want %v", report.Skipped, tt.wantSkipped)
+			}
+			if !reflect.DeepEqual(report.Overridden, tt.wantOverridden) {
+				t.Errorf("Report.Overridden = %v, want %v", report.Overridden, tt.wantOverridden)
+			}
+			if want := map[string]int{"/shared/": 2, "/myapp/": 3}; !reflect.DeepEqual(report.PathCounts, want) {
+				t.Errorf("Report.PathCounts = %v, want %v", report.PathCounts, want)
+			}
+			if report.Retries != 1 {
+				t.Errorf("Report.Retries = %d, want 1", report.Retries)
+			}
+			if report.Duration <= 0 {
+				t.Errorf("Report.Duration = %v, want a positive duration", report.Duration)
+			}
+
+			entries := logger.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("logged %v, want the summary of the load", entries)
+			}
+			if !strings.HasPrefix(entries[0].Msg, "ssmenv: set ") || entries[0].Data["retries"] != 1 {
+				t.Errorf("logged %v, want the summary of the load", entries[0])
+			}
+			output := fmt.Sprint(entries[0])
+			for _, leaked := range []string{"hunter2", "db.local", "us-east-1", "PASSWORD"} {
+				if strings.Contains(output, leaked) {
+					t.Errorf("logged %q, want no %q", output, leaked)
+				}
+			}
+		})
+	}
+}
+
+func TestReportDuration(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_DURATION")
+
+	clock := stmocks.NewClock(time.Unix(1700000000, 0))
+	client := &latencyClient{
+		client:  stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_duration": "value"}),
+		clock:   clock,
+		latency: 250 * time.Millisecond,
+	}
+
+	var metrics LoadMetrics
+	report, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client), WithClock(clock),
+		WithMetrics(func(m LoadMetrics) { metrics = m }))
+	if err != nil {
+		t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+	}
+
+	if report.Duration != 250*time.Millisecond {
+		t.Errorf("Report.Duration = %v, want 250ms", report.Duration)
+	}
+	if metrics.Duration != report.Duration {
+		t.Errorf("LoadMetrics.Duration = %v, want the Report.Duration %v", metrics.Duration, report.Duration)
+	}
+}
@@ -0,0 +1,247 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/vrischmann/envconfig"
+)
+
+// SecretsClient is the subset of the Secrets Manager API used to load secrets, implemented by the
+// *secretsmanager.Client of aws-sdk-go-v2.
+type SecretsClient interface {
+	GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	ListSecrets(ctx context.Context, input *secretsmanager.ListSecretsInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error)
+}
+
+// WithSecrets loads the secrets of Secrets Manager matching the prefixes or ARNs with Init, see InitSecrets.
+// It overrides the SSM_SECRETS environment variable, a comma-separated list of prefixes or ARNs.
+func WithSecrets(prefixOrARNs ...string) Option {
+	return func(o *options) {
+		o.secrets = prefixOrARNs
+	}
+}
+
+// WithSecretsClient uses client instead of a Secrets Manager client created from the shared AWS configuration.
+func WithSecretsClient(client SecretsClient) Option {
+	return func(o *options) {
+		o.secretsClient = client
+	}
+}
+
+// InitSecrets copies secrets of Secrets Manager to environment variables.
+// Values starting with arn: are the ARN of a secret, other values are name prefixes loading every secret whose
+// name starts with them. The key of a secret is its name stripped of the prefix, normalized like the name of
+// a parameter: myapp/db loaded with the prefix myapp/ is DB. JSON object secrets set a variable per field
+// prefixed by the key, i.e. DB_USER and DB_PASSWORD, while other secrets set a single variable.
+// Keys already set in the environment are overridden unless SSM_NO_OVERRIDE is true.
+func InitSecrets(prefixOrARNs ...string) error {
+	_, err := InitSecretsContext(context.Background(), prefixOrARNs)
+	return err
+}
+
+// InitSecretsContext copies the secrets like InitSecrets, and stops when ctx is cancelled.
+// Options control the clients, key normalization, no-override, required keys and retries like for
+// InitEnvVarsContext, and report the keys that were set and skipped.
+func InitSecretsContext(ctx context.Context, prefixOrARNs []string, opts ...Option) (*Report, error) {
+	cfg := &ssmConfig{}
+	if err := envconfig.Init(cfg); err != nil {
+		return nil, err
+	}
+
+	o := newOptions("")
+	o.noOverride = cfg.NoOverride
+	o.secrets = prefixOrARNs
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if len(o.secrets) == 0 {
+		return nil, fmt.Errorf("missing secret prefixes or ARNs")
+	}
+
+	o.startLoad()
+	params, err := loadSecrets(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyEnvVars(params, o)
+}
+
+// Init copies the parameters under SSM_PATH and the secrets of SSM_SECRETS to environment variables,
+// see InitEnvVarsContext and InitSecrets. Either one can be omitted. Secrets take precedence over parameters
+// with the same key, and values already set in the environment take precedence over both if SSM_NO_OVERRIDE
+// is true or WithNoOverride is used. It does nothing if SSM_DISABLED is true, except loading the parameters
+// of SSM_ONLY_KEYS, see WithOnlyKeys.
+func Init(ctx context.Context, opts ...Option) (report *Report, err error) {
+	cfg := &ssmConfig{}
+	if err := envconfig.Init(cfg); err != nil {
+		return nil, err
+	}
+
+	o := envOptions(cfg, opts)
+	if cfg.Disabled {
+		if len(o.onlyKeys) == 0 {
+			return &Report{}, nil
+		}
+		o.secrets = nil
+	}
+
+	start := o.startLoad()
+	defer func() { o.reportMetrics(start, err) }()
+
+	loadSSM := o.path != "NOT_SET" || len(o.paths) > 0
+	if !loadSSM && len(o.secrets) == 0 {
+		return nil, fmt.Errorf("missing SSM_PATH or SSM_SECRETS environment variable")
+	}
+
+	params := make(map[string]string)
+	if loadSSM {
+		if slices.Contains(o.loadPaths(), "") {
+			return nil, fmt.Errorf("wrong path configuration")
+		}
+
+		if o.client == nil && o.localFile == "" {
+			if o.client, err = newClient(ctx, o); err != nil {
+				return nil, err
+			}
+		}
+
+		if params, err = loadParams(ctx, o); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(o.secrets) > 0 {
+		secrets, err := loadSecrets(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range secrets {
+			params[k] = v
+		}
+	}
+
+	return applyEnvVars(params, o)
+}
+
+// loadSecrets fetches the secrets matching the prefixes or ARNs of the options, keyed by name.
+func loadSecrets(ctx context.Context, o *options) (map[string]string, error) {
+	client := o.secretsClient
+	if client == nil {
+		var err error
+		if client, err = newSecretsClient(ctx, o); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make(map[string]string)
+	for _, id := range o.secrets {
+		if strings.HasPrefix(id, "arn:") {
+			if err := addSecret(ctx, o, client, params, "", id); err != nil {
+				return nil, err
+			}
+			o.stats.addCount(id, 1)
+			continue
+		}
+
+		names, err := listSecrets(ctx, o, client, id)
+		if err != nil {
+			return nil, err
+		}
+		o.stats.addCount(id, len(names))
+
+		for _, name := range names {
+			if err := addSecret(ctx, o, client, params, id, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return params, nil
+}
+
+// newSecretsClient creates a Secrets Manager client from the shared AWS configuration, see awsConfig.
+func newSecretsClient(ctx context.Context, o *options) (*secretsmanager.Client, error) {
+	cfg, err := o.awsConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return secretsmanager.NewFromConfig(cfg, func(so *secretsmanager.Options) {
+		if o.endpoint != "" {
+			so.BaseEndpoint = aws.String(o.endpoint)
+		}
+	}), nil
+}
+
+// listSecrets returns the names of the secrets starting with the prefix.
+func listSecrets(ctx context.Context, o *options, client SecretsClient, prefix string) ([]string, error) {
+	var names []string
+
+	paginator := secretsmanager.NewListSecretsPaginator(client, &secretsmanager.ListSecretsInput{
+		Filters: []smtypes.Filter{{
+			Key:    smtypes.FilterNameStringTypeName,
+			Values: []string{prefix},
+		}},
+	})
+	for paginator.HasMorePages() {
+		var output *secretsmanager.ListSecretsOutput
+		err := retryCall(ctx, o, func(ctx context.Context) error {
+			var err error
+			output, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing secrets with prefix %s: %w", prefix, err)
+		}
+
+		for _, secret := range output.SecretList {
+			// The name filter matches words anywhere in the name, keep the prefixed ones
+			if name := aws.ToString(secret.Name); strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// addSecret fetches a secret by name or ARN and adds its keys to params, its name stripped of the prefix.
+// Secrets without a string value are skipped with a warning.
+func addSecret(ctx context.Context, o *options, client SecretsClient, params map[string]string, prefix, id string) error {
+	var output *secretsmanager.GetSecretValueOutput
+	err := retryCall(ctx, o, func(ctx context.Context) error {
+		var err error
+		output, err = client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching secret %s: %w", id, err)
+	}
+
+	if output.SecretString == nil {
+		o.warning(fmt.Sprintf("ssmenv: secret %s has no string value, skipping it", id))
+		return nil
+	}
+
+	// Secrets are named like parameters relative to the prefix, and JSON secrets are always expanded
+	secretOptions := *o
+	secretOptions.path = prefix
+	secretOptions.expandJSON = true
+	secretOptions.addParam(params, types.Parameter{
+		Name:  output.Name,
+		Value: output.SecretString,
+		Type:  types.ParameterTypeString,
+	})
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+var _ SecretsClient = (*secretsmanager.Client)(nil)
+var _ SecretsClient = (*stmocks.FakeSecretsManager)(nil)
+
+// secretsClient returns a fake client serving a JSON secret and a plain secret under myapp/, and a shared secret.
+func secretsClient() *stmocks.FakeSecretsManager {
+	return stmocks.NewFakeSecretsManager(map[string]string{
+		"myapp/ssmenv_test_db":      `{"user": "admin", "password": "s3cret"}`,
+		"myapp/ssmenv_test_api_key": "abc123",
+		"shared/ssmenv_test_token":  "tok",
+		"otherapp/ssmenv_test_key":  "other",
+	})
+}
+
+func TestInitSecretsContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		ids       []string
+		pageSize  int
+		want      map[string]string
+		wantCalls [2]int
+	}{
+		{
+			name: "prefix",
+			ids:  []string{"myapp/"},
+			want: map[string]string{
+				"SSMENV_TEST_DB_USER":     "admin",
+				"SSMENV_TEST_DB_PASSWORD": "s3cret",
+				"SSMENV_TEST_API_KEY":     "abc123",
+			},
+			wantCalls: [2]int{1, 2},
+		},
+		{
+			name:     "paginated prefix",
+			ids:      []string{"myapp/"},
+			pageSize: 1,
+			want: map[string]string{
+				"SSMENV_TEST_DB_USER":     "admin",
+				"SSMENV_TEST_DB_PASSWORD": "s3cret",
+				"SSMENV_TEST_API_KEY":     "abc123",
+			},
+			wantCalls: [2]int{2, 2},
+		},
+		{
+			name: "prefix and ARN",
+			ids:  []string{"myapp/", stmocks.SecretARN("shared/ssmenv_test_token")},
+			want: map[string]string{
+				"SSMENV_TEST_DB_USER":      "admin",
+				"SSMENV_TEST_DB_PASSWORD":  "s3cret",
+				"SSMENV_TEST_API_KEY":      "abc123",
+				"SHARED_SSMENV_TEST_TOKEN": "tok",
+			},
+			wantCalls: [2]int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t, "SSMENV_TEST_DB_USER", "SSMENV_TEST_DB_PASSWORD", "SSMENV_TEST_API_KEY", "SHARED_SSMENV_TEST_TOKEN")
+
+			client := secretsClient()
+			client.PageSize = tt.pageSize
+
+			result, err := InitSecretsContext(context.Background(), tt.ids, WithSecretsClient(client))
+			if err != nil {
+				t.Fatalf("InitSecretsContext() unexpected error = %v", err)
+			}
+
+			for key, value := range tt.want {
+				if got := os.Getenv(key); got != value {
+					t.Errorf("os.Getenv(%q) = %q, want %q", key, got, value)
+				}
+			}
+			if len(result.Set) != len(tt.want) {
+				t.Errorf("InitSecretsContext() set %v, want %d keys", result.Set, len(tt.want))
+			}
+			if list, value := client.Calls(); [2]int{list, value} != tt.wantCalls {
+				t.Errorf("Calls() = %d, %d, want %v", list, value, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestInitSecretsContextNoOverride(t *testing.T) {
+	t.Setenv("SSMENV_TEST_API_KEY", "local")
+	unsetEnv(t, "SSMENV_TEST_DB_USER", "SSMENV_TEST_DB_PASSWORD")
+
+	result, err := InitSecretsContext(context.Background(), []string{"myapp/"}, WithSecretsClient(secretsClient()), WithNoOverride(true))
+	if err != nil {
+		t.Fatalf("InitSecretsContext() unexpected error = %v", err)
+	}
+
+	if got := os.Getenv("SSMENV_TEST_API_KEY"); got != "local" {
+		t.Errorf("os.Getenv() = %q, want %q", got, "local")
+	}
+	if want := []string{"SSMENV_TEST_API_KEY"}; !slices.Equal(result.Skipped, want) {
+		t.Errorf("InitSecretsContext() skipped %v, want %v", result.Skipped, want)
+	}
+}
+
+func TestInitSecretsContextErrors(t *testing.T) {
+	t.Run("required keys", func(t *testing.T) {
+		unsetEnv(t, "SSMENV_TEST_DB_USER", "SSMENV_TEST_DB_PASSWORD", "SSMENV_TEST_API_KEY")
+
+		_, err := InitSecretsContext(context.Background(), []string{"myapp/"}, WithSecretsClient(secretsClient()),
+			WithRequiredKeys("SSMENV_TEST_DB_USER", "SSMENV_TEST_MISSING"))
+
+		var missingErr *MissingParamsError
+		if !errors.As(err, &missingErr) || !slices.Equal(missingErr.Keys, []string{"SSMENV_TEST_MISSING"}) {
+			t.Fatalf("InitSecretsContext() error = %v, want MissingParamsError for SSMENV_TEST_MISSING", err)
+		}
+		if _, ok := os.LookupEnv("SSMENV_TEST_DB_USER"); ok {
+			t.Error("InitSecretsContext() set variables despite missing required keys")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := InitSecretsContext(context.Background(), []string{stmocks.SecretARN("myapp/unknown")}, WithSecretsClient(secretsClient()))
+
+		var apiErr smithy.APIError
+		if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ResourceNotFoundException" {
+			t.Errorf("InitSecretsContext() error = %v, want %s", err, "ResourceNotFoundException")
+		}
+	})
+
+	t.Run("no secrets", func(t *testing.T) {
+		if _, err := InitSecretsContext(context.Background(), nil, WithSecretsClient(secretsClient())); err == nil {
+			t.Error("InitSecretsContext() expected error without secrets, got nil")
+		}
+	})
+}
+
+func TestInit(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_API_KEY", "SSMENV_TEST_HOST", "SSMENV_TEST_DB_USER", "SSMENV_TEST_DB_PASSWORD")
+	t.Setenv("SSM_PATH", "/myapp/")
+	t.Setenv("SSM_SECRETS", "myapp/")
+
+	client := stmocks.NewFakeSSM(map[string]string{
+		"/myapp/ssmenv_test_api_key": "from-ssm",
+		"/myapp/ssmenv_test_host":    "db.local",
+	})
+
+	result, err := Init(context.Background(), WithClient(client), WithSecretsClient(secretsClient()))
+	if err != nil {
+		t.Fatalf("Init() unexpected error = %v", err)
+	}
+
+	want := map[string]string{
+		"SSMENV_TEST_API_KEY": "abc123",
+		"SSMENV_TEST_HOST":    "db.local",
+		"SSMENV_TEST_DB_USER": "admin",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("os.Getenv(%q) = %q, want %q", key, got, value)
+		}
+	}
+	if len(result.Set) != 4 {
+		t.Errorf("Init() set %v, want 4 keys", result.Set)
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		t.Setenv("SSM_PATH", "")
+		os.Unsetenv("SSM_PATH")
+		t.Setenv("SSM_SECRETS", "")
+		os.Unsetenv("SSM_SECRETS")
+
+		if _, err := Init(context.Background()); err == nil {
+			t.Error("Init() expected error without SSM_PATH and SSM_SECRETS, got nil")
+		}
+	})
+}
@@ -1,105 +1,579 @@
 package ssmenv
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
 	"github.com/vrischmann/envconfig"
 )
 
+// ParamClient is the subset of the SSM API used to load the parameters, implemented by the *ssm.Client
+// of aws-sdk-go-v2. The pages of parameters are fetched with the ssm.GetParametersByPathPaginator.
+type ParamClient interface {
+	GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// ContextParamClient is a ParamClient supporting contexts.
+//
+// Deprecated: every ParamClient supports contexts since the move to aws-sdk-go-v2, use ParamClient.
+type ContextParamClient = ParamClient
+
 type ssmConfig struct {
-	Path     string `envconfig:"default=NOT_SET,SSM_PATH"`
-	Disabled bool   `envconfig:"default=False,SSM_DISABLED"`
+	Path       string   `envconfig:"default=NOT_SET,SSM_PATH"`
+	Disabled   bool     `envconfig:"default=False,SSM_DISABLED"`
+	NoOverride bool     `envconfig:"default=False,SSM_NO_OVERRIDE"`
+	LocalFile  string   `envconfig:"optional,SSM_LOCAL_FILE"`
+	Secrets    []string `envconfig:"optional,SSM_SECRETS"`
+	OnlyKeys   []string `envconfig:"optional,SSM_ONLY_KEYS"`
 }
 
 //Loads the SSM singleton instance and calls MustProcess
 func InitEnvVars() error {
+	_, err := InitEnvVarsWithOptions()
+	return err
+}
+
+// InitEnvVarsWithOptions copies the parameters under SSM_PATH to environment variables, like InitEnvVars,
+// and reports the keys that were set and skipped. It does nothing if SSM_DISABLED is true, unless SSM_ONLY_KEYS
+// is set, see WithOnlyKeys.
+// Keys already set in the environment are overridden unless SSM_NO_OVERRIDE is true or WithNoOverride is used.
+// SSM_PATH can list several paths separated by commas, see WithPaths.
+// If SSM_LOCAL_FILE is set, the parameters are read from that file instead of SSM, see WithLocalFile.
+func InitEnvVarsWithOptions(opts ...Option) (*Report, error) {
+	return InitEnvVarsContext(context.Background(), opts...)
+}
+
+// InitEnvVarsContext copies the parameters like InitEnvVarsWithOptions, and stops when ctx is cancelled.
+// Throttled and transient failures are retried with an exponential backoff, see WithMaxAttempts and
+// WithBackoff, while other failures such as AccessDenied or ParameterNotFound fail immediately.
+// Failed calls are returned as a *CallError classifying the failure.
+// Failures are returned as a *LoadError holding the sterrors code of the failure, see ErrorConfig.
+// It returns the context error if ctx is cancelled before the parameters are loaded.
+func InitEnvVarsContext(ctx context.Context, opts ...Option) (report *Report, err error) {
 	cfg := &ssmConfig{}
-	err := envconfig.Init(cfg)
-	if err != nil {
-		return err
+	cfgErr := envconfig.Init(cfg)
+	o := envOptions(cfg, opts)
+
+	if cfgErr == nil && cfg.Disabled && len(o.onlyKeys) == 0 {
+		return &Report{}, nil
 	}
 
-	if cfg.Disabled {
-		return nil
+	start := o.startLoad()
+	defer func() { o.reportMetrics(start, err) }()
+
+	if cfgErr != nil {
+		return nil, o.loadError(cfgErr)
 	}
 
-	path := cfg.Path
+	if o.path == "NOT_SET" && len(o.paths) == 0 {
+		return nil, o.loadError(fmt.Errorf("missing SSM_PATH environment variable"))
+	}
+
+	if slices.Contains(o.loadPaths(), "") {
+		return nil, o.loadError(fmt.Errorf("wrong path configuration"))
+	}
+
+	if o.client == nil && o.localFile == "" {
+		if o.client, err = newClient(ctx, o); err != nil {
+			return nil, o.loadError(err)
+		}
+	}
+
+	report, err = setEnvVars(ctx, o)
+	return report, o.loadError(err)
+}
+
+// envOptions returns the options initialized from the SSM_* environment variables and overridden by opts.
+func envOptions(cfg *ssmConfig, opts []Option) *options {
+	o := newOptions(cfg.Path)
+	if strings.Contains(cfg.Path, ",") {
+		o.paths = strings.Split(cfg.Path, ",")
+		for i, path := range o.paths {
+			o.paths[i] = strings.TrimSpace(path)
+		}
+	}
+	o.noOverride = cfg.NoOverride
+	o.localFile = cfg.LocalFile
+	o.secrets = cfg.Secrets
+	o.onlyKeys = cfg.OnlyKeys
+	for _, opt := range opts {
+		opt(o)
+	}
 
-	if path == "NOT_SET" {
-		return fmt.Errorf("missing SSM_PATH environment variable")
+	// Only keys are always fetched from SSM
+	if len(o.onlyKeys) > 0 {
+		o.localFile = ""
 	}
 
+	// Local files do not need a path, their keys are the parameter names relative to it
+	if o.localFile != "" && (o.path == "NOT_SET" || o.path == "" || len(o.paths) > 0) {
+		o.path = "/"
+		o.paths = nil
+	}
+
+	return o
+}
+
+// InitEnvVarsWithClient copies the parameters under path to environment variables using the given client,
+// so the loading can be tested with a fake client. Keys are stripped of the path, with the separators of nested
+// parameters replaced by underscores, and upper-cased.
+func InitEnvVarsWithClient(path string, client ParamClient) error {
 	if path == "" {
-		return fmt.Errorf("wrong path configuration")
+		return newOptions(path).loadError(fmt.Errorf("wrong path configuration"))
 	}
 
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	}))
+	if client == nil {
+		return newOptions(path).loadError(fmt.Errorf("missing SSM client"))
+	}
 
-	client := ssm.New(sess)
+	o := newOptions(path)
+	o.client = client
 
-	return setEnvVars(path, client)
+	_, err := setEnvVars(context.Background(), o)
+	return o.loadError(err)
 }
 
-func retryGetParameters(client *ssm.SSM, input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
-	count := 0
-	for {
-		output, err := client.GetParametersByPath(input)
+// retryCall makes a call to AWS, retrying throttled and transient failures with a backoff, unless the retries
+// are left to the SDK, see WithSDKRetryer. Failures are returned as a *CallError, except the context errors.
+func retryCall(ctx context.Context, o *options, call func(ctx context.Context) error) error {
+	maxAttempts := o.maxAttempts
+	if o.sdkRetryer != nil {
+		maxAttempts = 1
+	}
 
-		if err != nil {
-			if count >= 5 {
-				return nil, err
-			}
+	for attempt := 1; ; attempt++ {
+		class, err := callOnce(ctx, o, call)
+		if err == nil {
+			return nil
+		}
 
-			time.Sleep(5 * time.Second)
-			count++
-			continue
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if class == ErrorClassThrottled {
+			o.stats.addThrottle()
+		}
+
+		if class == ErrorClassPermanent || attempt >= maxAttempts {
+			return &CallError{Class: class, Code: errorCode(err), Attempts: attempt, Err: err}
 		}
 
-		return output, nil
+		o.stats.addRetry()
+		if err := sleepContext(ctx, o.clock, o.backoff(attempt)); err != nil {
+			return err
+		}
 	}
 }
 
-func setEnvVars(path string, client *ssm.SSM) error {
+// callOnce makes a single call with a context limited by the call timeout.
+// It returns the class of the failure, calls timing out being transient.
+func callOnce(ctx context.Context, o *options, call func(ctx context.Context) error) (ErrorClass, error) {
+	if err := ctx.Err(); err != nil {
+		return ErrorClassPermanent, err
+	}
 
-	var nextToken *string
-	for {
-		input := &ssm.GetParametersByPathInput{
-			WithDecryption: aws.Bool(true),
-			Recursive:      aws.Bool(true),
-			Path:           aws.String(path),
-			NextToken:      nextToken,
+	callCtx := ctx
+	if o.callTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, o.callTimeout)
+		defer cancel()
+	}
+
+	err := call(callCtx)
+	if err != nil && callCtx.Err() != nil && ctx.Err() == nil {
+		return ErrorClassTransient, fmt.Errorf("call timed out after %v: %w", o.callTimeout, err)
+	}
+
+	return classifyError(err), err
+}
+
+// backoff returns the delay before the retry following the given attempt, with a random jitter.
+func (o *options) backoff(attempt int) time.Duration {
+	delay := o.initialBackoff
+	for i := 1; i < attempt && delay < o.maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, o.maxBackoff)
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// sleepContext waits for the delay on the clock, or returns the context error if ctx is cancelled first.
+func sleepContext(ctx context.Context, clock stclock.Clock, delay time.Duration) error {
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+// LoadParams returns the parameters under path keyed like the environment variables set by InitEnvVars,
+// without changing the environment.
+func LoadParams(path string) (map[string]string, error) {
+	return LoadParamsContext(context.Background(), path)
+}
+
+// LoadParamsContext returns the parameters under path like LoadParams, and stops when ctx is cancelled.
+// Options control the client and the retries like for InitEnvVarsContext.
+func LoadParamsContext(ctx context.Context, path string, opts ...Option) (params map[string]string, err error) {
+	o := newOptions(path)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	start := o.startLoad()
+	defer func() { o.reportMetrics(start, err) }()
+
+	if len(o.onlyKeys) > 0 {
+		o.localFile = ""
+	}
+
+	if o.localFile != "" && (o.path == "" || len(o.paths) > 0) {
+		o.path = "/"
+		o.paths = nil
+	}
+
+	if slices.Contains(o.loadPaths(), "") {
+		return nil, o.loadError(fmt.Errorf("wrong path configuration"))
+	}
+
+	if o.client == nil && o.localFile == "" {
+		if o.client, err = newClient(ctx, o); err != nil {
+			return nil, o.loadError(err)
+		}
+	}
+
+	params, err = loadParams(ctx, o)
+	if err != nil {
+		return nil, o.loadError(err)
+	}
+
+	return params, nil
+}
+
+// newClient creates an SSM client from the shared AWS configuration, see awsConfig.
+func newClient(ctx context.Context, o *options) (*ssm.Client, error) {
+	cfg, err := o.awsConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssm.NewFromConfig(cfg, func(so *ssm.Options) {
+		if o.endpoint != "" {
+			so.BaseEndpoint = aws.String(o.endpoint)
 		}
+	}), nil
+}
+
+// awsConfig loads the shared AWS configuration, overridden by the region of the options.
+// The clients do not retry the failed calls, which are retried by retryCall, unless a retryer is set
+// with WithSDKRetryer.
+func (o *options) awsConfig(ctx context.Context) (aws.Config, error) {
+	retryer := o.sdkRetryer
+	if retryer == nil {
+		retryer = func() aws.Retryer { return aws.NopRetryer{} }
+	}
+
+	loadOptions := []func(*config.LoadOptions) error{config.WithRetryer(retryer)}
+	if o.region != "" {
+		loadOptions = append(loadOptions, config.WithRegion(o.region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load the AWS configuration: %w", err)
+	}
+
+	return cfg, nil
+}
 
-		output, err := retryGetParameters(client, input)
+// paramKey returns the key of a parameter, computed by the key transform if any, otherwise its name
+// stripped of the path, with the separators of nested parameters replaced by underscores and upper-cased.
+// The prefix is prepended to the key.
+func (o *options) paramKey(name string) string {
+	if o.keyTransform != nil {
+		return o.prefix + o.keyTransform(name)
+	}
+
+	k := strings.Replace(name, o.path, "", 1)
+	if !o.legacyKeys {
+		k = strings.ReplaceAll(strings.TrimPrefix(k, "/"), "/", "_")
+	}
+	return o.prefix + strings.ToUpper(k)
+}
+
+// loadPaths returns the paths to load, the paths set by WithPaths if any, otherwise the path.
+func (o *options) loadPaths() []string {
+	if len(o.paths) > 0 {
+		return o.paths
+	}
+	return []string{o.path}
+}
+
+// loadParams reads the parameters under the paths from SSM, or from the local file if set, keyed by paramKey.
+func loadParams(ctx context.Context, o *options) (map[string]string, error) {
+	if len(o.onlyKeys) > 0 {
+		return fetchOnlyKeys(ctx, o)
+	}
+
+	if o.localFile == "" {
+		return fetchPaths(ctx, o)
+	}
+
+	ssmParams, err := readLocalFile(o)
+	if err != nil {
+		return nil, err
+	}
+	o.stats.addCount(o.localFile, len(ssmParams))
+
+	params := make(map[string]string, len(ssmParams))
+	for _, param := range ssmParams {
+		o.addParam(params, param)
+	}
+
+	return params, nil
+}
+
+// fetchPaths fetches the parameters under each path from SSM, at most o.concurrency paths at a time, keyed
+// by paramKey relative to their path. Keys of later paths take precedence over the same keys of earlier paths.
+// Every path is fetched even if another one fails, and the errors of all the failed paths are returned.
+func fetchPaths(ctx context.Context, o *options) (map[string]string, error) {
+	if err := o.checkVersions(); err != nil {
+		return nil, err
+	}
+
+	paths := o.loadPaths()
+	if len(paths) == 1 {
+		return fetchPath(ctx, o)
+	}
+
+	results := make([]map[string]string, len(paths))
+	errs := make([]error, len(paths))
+	sem := make(chan struct{}, o.concurrency)
+
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			pathOptions := *o
+			pathOptions.path = path
+
+			var err error
+			if results[i], err = fetchPath(ctx, &pathOptions); err != nil {
+				errs[i] = fmt.Errorf("path %s: %w", path, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string)
+	for _, result := range results {
+		for k, v := range result {
+			params[k] = v
+		}
+	}
+
+	return params, nil
+}
+
+// fetchPath fetches the parameters under the path from SSM, keyed by paramKey.
+// The parameters of each page are added while the next page is in flight. The environment variables are
+// only set once every path is fetched, see applyEnvVars, so a failed load leaves the environment unchanged.
+func fetchPath(ctx context.Context, o *options) (map[string]string, error) {
+	pages := make(chan []types.Parameter, 1)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(pages)
+		errc <- fetchPages(ctx, o, func(page []types.Parameter) {
+			pages <- page
+		})
+	}()
+
+	params := make(map[string]string)
+	for page := range pages {
+		for _, param := range page {
+			if !o.isPinned(aws.ToString(param.Name)) {
+				o.addParam(params, param)
+			}
+		}
+	}
+
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+
+	if err := fetchPinned(ctx, o, params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// fetchParams fetches all the pages of parameters under the path from SSM.
+func fetchParams(ctx context.Context, o *options) ([]types.Parameter, error) {
+	var params []types.Parameter
+	err := fetchPages(ctx, o, func(page []types.Parameter) {
+		params = append(params, page...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// fetchPages fetches the pages of parameters under the path from SSM one after the other with the paginator,
+// passing each page to handlePage. A failed page is retried by retryCall, the paginator keeping its token.
+func fetchPages(ctx context.Context, o *options, handlePage func(page []types.Parameter)) error {
+	paginator := ssm.NewGetParametersByPathPaginator(o.client, &ssm.GetParametersByPathInput{
+		WithDecryption:   aws.Bool(!o.noDecryption),
+		Recursive:        aws.Bool(true),
+		Path:             aws.String(o.path),
+		MaxResults:       aws.Int32(maxPageSize),
+		ParameterFilters: o.labelFilters(),
+	})
+
+	for paginator.HasMorePages() {
+		start := o.clock.Now()
+		var output *ssm.GetParametersByPathOutput
+		err := retryCall(ctx, o, func(ctx context.Context) error {
+			var err error
+			output, err = paginator.NextPage(ctx)
+			return err
+		})
 		if err != nil {
-			err = fmt.Errorf("error connecting to ssm store %v", err)
+			err = fmt.Errorf("error connecting to ssm store %w", err)
 			return err
 		}
+		o.stats.addPage(o.clock.Now().Sub(start))
+
+		o.stats.addCount(o.path, len(output.Parameters))
+		handlePage(output.Parameters)
+	}
+
+	return nil
+}
+
+// setEnvVars copies the parameters to environment variables.
+func setEnvVars(ctx context.Context, o *options) (*Report, error) {
+	params, err := loadParams(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyEnvVars(params, o)
+}
+
+// applyEnvVars copies the loaded values to environment variables, in key order, and logs the report.
+// It fails without changing the environment if keys are invalid, or if required keys are neither loaded
+// nor already set.
+func applyEnvVars(params map[string]string, o *options) (*Report, error) {
+	if err := o.stats.invalidKeysError(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, k := range o.requiredKeys {
+		if _, loaded := params[k]; !loaded {
+			if _, exists := os.LookupEnv(k); !exists {
+				missing = append(missing, k)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingParamsError{Keys: missing}
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	report := o.newReport()
+	var applied []priorEnv
+	for _, k := range keys {
+		previous, exists := os.LookupEnv(k)
+		if exists && o.noOverride {
+			report.Skipped = append(report.Skipped, o.reportKey(k))
+			continue
+		}
 
-		for _, param := range output.Parameters {
-			k := strings.Replace(*param.Name, path, "", 1)
-			k = strings.ToUpper(k)
-			v := *param.Value
-			err := os.Setenv(k, v)
-			if err != nil {
-				errR := fmt.Errorf("problem copying ssm key (%s) to environment variable (%s) - %v", *param.Name, k, err)
-				return errR
+		err := o.setenv(k, params[k])
+		if err != nil {
+			errR := fmt.Errorf("problem copying ssm key to environment variable (%s) - %v", k, err)
+			if o.atomicApply {
+				if err := restoreEnv(applied); err != nil {
+					return nil, &applyError{err: errors.Join(errR, err)}
+				}
 			}
+			return nil, &applyError{err: errR}
 		}
-		nextToken = output.NextToken
-		if nextToken == nil {
-			break
+		applied = append(applied, priorEnv{key: k, value: previous, exists: exists})
+		report.Set = append(report.Set, o.reportKey(k))
+		if exists && previous != params[k] {
+			report.Overridden = append(report.Overridden, o.reportKey(k))
 		}
 	}
 
-	return nil
+	o.logReport(report)
+	return report, nil
+}
+
+// priorEnv is the state of an environment variable before it was set.
+type priorEnv struct {
+	key    string
+	value  string
+	exists bool
+}
+
+// restoreEnv restores the environment variables to their prior state, in reverse order.
+func restoreEnv(applied []priorEnv) error {
+	var errs []error
+	for i := len(applied) - 1; i >= 0; i-- {
+		prior := applied[i]
+
+		var err error
+		if prior.exists {
+			err = os.Setenv(prior.key, prior.value)
+		} else {
+			err = os.Unsetenv(prior.key)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore environment variable %s: %w", prior.key, err))
+		}
+	}
 
+	return errors.Join(errs...)
 }
@@ -0,0 +1,687 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/smithy-go"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+var _ ContextParamClient = (*ssm.Client)(nil)
+var _ ContextParamClient = (*stmocks.FakeSSM)(nil)
+
+// unsetEnv removes the environment variables set by the loader at the end of the test.
+func unsetEnv(t *testing.T, keys ...string) {
+	t.Cleanup(func() {
+		for _, key := range keys {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestInitEnvVarsWithClient(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_HOST", "SSMENV_TEST_PORT", "SSMENV_TEST_USER")
+
+	client := stmocks.NewFakeSSM(map[string]string{
+		"/myapp/ssmenv_test_host": "db.local",
+		"/myapp/ssmenv_test_port": "5432",
+		"/myapp/Ssmenv_Test_User": "admin",
+	})
+	client.PageSize = 1
+
+	if err := InitEnvVarsWithClient("/myapp/", client); err != nil {
+		t.Fatalf("InitEnvVarsWithClient() unexpected error = %v", err)
+	}
+
+	want := map[string]string{
+		"SSMENV_TEST_HOST": "db.local",
+		"SSMENV_TEST_PORT": "5432",
+		"SSMENV_TEST_USER": "admin",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("os.Getenv(%q) = %q, want %q", key, got, value)
+		}
+	}
+
+	inputs := client.Inputs()
+	if len(inputs) != 3 {
+		t.Fatalf("GetParametersByPath() calls = %d, want 3", len(inputs))
+	}
+	for i, input := range inputs {
+		if aws.ToString(input.Path) != "/myapp/" || !aws.ToBool(input.Recursive) || !aws.ToBool(input.WithDecryption) {
+			t.Errorf("GetParametersByPath() input %d = %v, want recursive decrypted path /myapp/", i, input)
+		}
+	}
+	if inputs[0].NextToken != nil || aws.ToString(inputs[2].NextToken) != "2" {
+		t.Errorf("GetParametersByPath() next tokens = %v, %v, want nil then the token of the last page", inputs[0].NextToken, inputs[2].NextToken)
+	}
+}
+
+func TestInitEnvVarsWithClientInvalid(t *testing.T) {
+	if err := InitEnvVarsWithClient("", stmocks.NewFakeSSM(nil)); err == nil {
+		t.Error("InitEnvVarsWithClient() expected error for empty path, got nil")
+	}
+	if err := InitEnvVarsWithClient("/myapp/", nil); err == nil {
+		t.Error("InitEnvVarsWithClient() expected error for nil client, got nil")
+	}
+}
+
+func TestInitEnvVarsWithOptionsNoOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		envNoOver   string
+		opts        []Option
+		want        string
+		wantSkipped bool
+	}{
+		{name: "override by default", want: "from-ssm"},
+		{name: "option", opts: []Option{WithNoOverride(true)}, want: "local", wantSkipped: true},
+		{name: "environment variable", envNoOver: "true", want: "local", wantSkipped: true},
+		{name: "option overrides environment variable", envNoOver: "true", opts: []Option{WithNoOverride(false)}, want: "from-ssm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SSMENV_TEST_LOCAL", "local")
+			t.Setenv("SSM_NO_OVERRIDE", tt.envNoOver)
+			unsetEnv(t, "SSMENV_TEST_NEW")
+
+			client := stmocks.NewFakeSSM(map[string]string{
+				"/myapp/ssmenv_test_local": "from-ssm",
+				"/myapp/ssmenv_test_new":   "new",
+			})
+
+			opts := append([]Option{WithPath("/myapp/"), WithClient(client)}, tt.opts...)
+			result, err := InitEnvVarsWithOptions(opts...)
+			if err != nil {
+				t.Fatalf("InitEnvVarsWithOptions() unexpected error = %v", err)
+			}
+
+			if got := os.Getenv("SSMENV_TEST_LOCAL"); got != tt.want {
+				t.Errorf("os.Getenv() = %q, want %q", got, tt.want)
+			}
+			if got := os.Getenv("SSMENV_TEST_NEW"); got != "new" {
+				t.Errorf("os.Getenv() = %q, want %q", got, "new")
+			}
+
+			wantSet, wantSkipped := []string{"SSMENV_TEST_LOCAL", "SSMENV_TEST_NEW"}, []string(nil)
+			if tt.wantSkipped {
+				wantSet, wantSkipped = []string{"SSMENV_TEST_NEW"}, []string{"SSMENV_TEST_LOCAL"}
+			}
+			if !slices.Equal(result.Set, wantSet) || !slices.Equal(result.Skipped, wantSkipped) {
+				t.Errorf("InitEnvVarsWithOptions() = %+v, want set %v and skipped %v", result, wantSet, wantSkipped)
+			}
+		})
+	}
+}
+
+// paramClientOnly hides the context support of a client.
+type paramClientOnly struct {
+	ParamClient
+}
+
+func TestInitEnvVarsContextRetry(t *testing.T) {
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+	unavailable := requestFailure("InternalServerError", "unavailable", 503)
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+	notFound := &smithy.GenericAPIError{Code: "ParameterNotFound", Message: "not found"}
+
+	tests := []struct {
+		name        string
+		errors      []error
+		opts        []Option
+		wantErr     error
+		wantCalls   int
+		contextLess bool
+	}{
+		{name: "throttled then success", errors: []error{throttled, throttled}, wantCalls: 3},
+		{name: "server error then success", errors: []error{unavailable}, wantCalls: 2},
+		{name: "without context support", errors: []error{throttled}, wantCalls: 2, contextLess: true},
+		{name: "access denied", errors: []error{denied}, wantErr: denied, wantCalls: 1},
+		{name: "parameter not found", errors: []error{notFound}, wantErr: notFound, wantCalls: 1},
+		{
+			name:      "max attempts",
+			errors:    []error{throttled, throttled, throttled},
+			opts:      []Option{WithMaxAttempts(3)},
+			wantErr:   throttled,
+			wantCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t, "SSMENV_TEST_RETRY")
+
+			client := stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_retry": "ok"})
+			for _, err := range tt.errors {
+				client.FailFirst(1, err)
+			}
+
+			var paramClient ParamClient = client
+			if tt.contextLess {
+				paramClient = paramClientOnly{client}
+			}
+
+			opts := append([]Option{WithPath("/myapp/"), WithClient(paramClient), WithBackoff(time.Millisecond, time.Millisecond)}, tt.opts...)
+			_, err := InitEnvVarsContext(context.Background(), opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("InitEnvVarsContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls := len(client.Inputs()); calls != tt.wantCalls {
+				t.Errorf("GetParametersByPath() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == nil && os.Getenv("SSMENV_TEST_RETRY") != "ok" {
+				t.Errorf("os.Getenv() = %q, want %q", os.Getenv("SSMENV_TEST_RETRY"), "ok")
+			}
+		})
+	}
+}
+
+func TestInitEnvVarsContextCancel(t *testing.T) {
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+	t.Run("cancelled before loading", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		client := stmocks.NewFakeSSM(nil)
+		_, err := InitEnvVarsContext(ctx, WithPath("/myapp/"), WithClient(client))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("InitEnvVarsContext() error = %v, want %v", err, context.Canceled)
+		}
+		if calls := len(client.Inputs()); calls != 0 {
+			t.Errorf("GetParametersByPath() calls = %d, want 0", calls)
+		}
+	})
+
+	t.Run("cancelled during backoff", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		client := stmocks.NewFakeSSM(nil)
+		client.FailFirst(1, throttled)
+
+		start := time.Now()
+		_, err := InitEnvVarsContext(ctx, WithPath("/myapp/"), WithClient(client), WithBackoff(time.Hour, time.Hour))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("InitEnvVarsContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("InitEnvVarsContext() returned after %v, want prompt return", elapsed)
+		}
+	})
+
+	t.Run("call timeout", func(t *testing.T) {
+		client := stmocks.NewFakeSSM(nil)
+		client.Delay = time.Hour
+
+		_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client),
+			WithCallTimeout(5*time.Millisecond), WithMaxAttempts(2), WithBackoff(0, 0))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("InitEnvVarsContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if calls := len(client.Inputs()); calls != 2 {
+			t.Errorf("GetParametersByPath() calls = %d, want 2 (timed out calls are retried)", calls)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	o := newOptions("/myapp/")
+	o.initialBackoff, o.maxBackoff = 100*time.Millisecond, 300*time.Millisecond
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if got := o.backoff(attempt); got < want/2 || got > want {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestInitEnvVarsContextClock(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_CLOCK")
+
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+	client := stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_clock": "ok"})
+	client.FailFirst(3, throttled)
+	clock := stmocks.NewClock(time.Unix(1700000000, 0))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client), WithClock(clock))
+		done <- err
+	}()
+
+	// the default backoff waits up to 5s before each retry
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(defaultMaxBackoff)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("InitEnvVarsContext() still waiting after the clock was advanced")
+	}
+	if calls := len(client.Inputs()); calls != 4 {
+		t.Errorf("GetParametersByPath() calls = %d, want 4", calls)
+	}
+	if os.Getenv("SSMENV_TEST_CLOCK") != "ok" {
+		t.Errorf("os.Getenv() = %q, want %q", os.Getenv("SSMENV_TEST_CLOCK"), "ok")
+	}
+}
+
+// keyTests are the key normalization cases shared by LoadParams and InitEnvVars.
+var keyTests = []struct {
+	name    string
+	param   string
+	wantKey string
+}{
+	{name: "lower case", param: "/myapp/ssmenv_test_key", wantKey: "SSMENV_TEST_KEY"},
+	{name: "mixed case", param: "/myapp/Ssmenv_Test_Mixed", wantKey: "SSMENV_TEST_MIXED"},
+	{name: "nested", param: "/myapp/ssmenv_test_db/password", wantKey: "SSMENV_TEST_DB_PASSWORD"},
+}
+
+func TestParamKey(t *testing.T) {
+	for _, tt := range keyTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newOptions("/myapp/").paramKey(tt.param); got != tt.wantKey {
+				t.Errorf("paramKey() = %q, want %q", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestParamKeyOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		opts    []Option
+		param   string
+		wantKey string
+	}{
+		{name: "nested", path: "/myapp/", param: "/myapp/db/password", wantKey: "DB_PASSWORD"},
+		{name: "legacy nested", path: "/myapp/", opts: []Option{WithLegacyNestedKeys()}, param: "/myapp/db/password", wantKey: "DB/PASSWORD"},
+		{name: "path without trailing slash", path: "/myapp", param: "/myapp/db/password", wantKey: "DB_PASSWORD"},
+		{name: "legacy path without trailing slash", path: "/myapp", opts: []Option{WithLegacyNestedKeys()}, param: "/myapp/db", wantKey: "/DB"},
+		{name: "prefix", path: "/myapp/", opts: []Option{WithPrefix("MYAPP_")}, param: "/myapp/db/password", wantKey: "MYAPP_DB_PASSWORD"},
+		{
+			name:    "transform",
+			path:    "/myapp/",
+			opts:    []Option{WithKeyTransform(func(name string) string { return strings.ToLower(path.Base(name)) })},
+			param:   "/myapp/db/password",
+			wantKey: "password",
+		},
+		{
+			name:    "transform and prefix",
+			path:    "/myapp/",
+			opts:    []Option{WithKeyTransform(path.Base), WithPrefix("APP_")},
+			param:   "/myapp/db/PASSWORD",
+			wantKey: "APP_PASSWORD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions(tt.path)
+			for _, opt := range tt.opts {
+				opt(o)
+			}
+			if got := o.paramKey(tt.param); got != tt.wantKey {
+				t.Errorf("paramKey() = %q, want %q", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLoadParamsMatchesInitEnvVars(t *testing.T) {
+	params := make(map[string]string)
+	for i, tt := range keyTests {
+		params[tt.param] = fmt.Sprintf("value %d", i)
+		unsetEnv(t, tt.wantKey)
+	}
+
+	// One parameter per page to cover the pagination
+	client := stmocks.NewFakeSSM(params)
+	client.PageSize = 1
+
+	loaded, err := LoadParamsContext(context.Background(), "/myapp/", WithClient(client))
+	if err != nil {
+		t.Fatalf("LoadParamsContext() unexpected error = %v", err)
+	}
+	if len(loaded) != len(keyTests) {
+		t.Errorf("LoadParamsContext() = %v, want %d parameters", loaded, len(keyTests))
+	}
+	for i, tt := range keyTests {
+		if want := fmt.Sprintf("value %d", i); loaded[tt.wantKey] != want {
+			t.Errorf("LoadParamsContext()[%q] = %q, want %q", tt.wantKey, loaded[tt.wantKey], want)
+		}
+		if _, exists := os.LookupEnv(tt.wantKey); exists {
+			t.Errorf("LoadParamsContext() set environment variable %s", tt.wantKey)
+		}
+	}
+
+	if err := InitEnvVarsWithClient("/myapp/", client); err != nil {
+		t.Fatalf("InitEnvVarsWithClient() unexpected error = %v", err)
+	}
+	for key, value := range loaded {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("os.Getenv(%q) = %q, want %q as loaded", key, got, value)
+		}
+	}
+}
+
+// pathsClient returns a fake client serving three pages of three parameters under each path, waiting delay per call.
+// The parameter ssmenv_test_level of each path holds the name of the path.
+func pathsClient(delay time.Duration, paths ...string) *stmocks.FakeSSM {
+	params := make(map[string]string)
+	for _, p := range paths {
+		name := strings.Trim(p, "/")
+		params[p+"ssmenv_test_level"] = name
+		for i := 0; i < 8; i++ {
+			params[fmt.Sprintf("%s%s_%d", p, name, i)] = strconv.Itoa(i)
+		}
+	}
+
+	client := stmocks.NewFakeSSM(params)
+	client.PageSize = 3
+	client.Delay = delay
+	return client
+}
+
+func TestInitEnvVarsContextPaths(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	paths := []string{"/shared/", "/team/", "/myapp/"}
+
+	var keys []string
+	for _, p := range paths {
+		for i := 0; i < 8; i++ {
+			keys = append(keys, fmt.Sprintf("%s_%d", strings.ToUpper(strings.Trim(p, "/")), i))
+		}
+	}
+	unsetEnv(t, append(keys, "SSMENV_TEST_LEVEL")...)
+
+	elapsed := make(map[int]time.Duration)
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			client := pathsClient(delay, paths...)
+
+			start := time.Now()
+			result, err := InitEnvVarsContext(context.Background(), WithPaths(paths...), WithClient(client), WithConcurrency(concurrency))
+			elapsed[concurrency] = time.Since(start)
+			if err != nil {
+				t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+			}
+
+			for _, key := range keys {
+				if got, want := os.Getenv(key), key[len(key)-1:]; got != want {
+					t.Errorf("os.Getenv(%q) = %q, want %q", key, got, want)
+				}
+			}
+			if got := os.Getenv("SSMENV_TEST_LEVEL"); got != "myapp" {
+				t.Errorf("os.Getenv() = %q, want the value of the last path %q", got, "myapp")
+			}
+			if len(result.Set) != len(keys)+1 {
+				t.Errorf("InitEnvVarsContext() set %d keys, want %d", len(result.Set), len(keys)+1)
+			}
+
+			if calls := len(client.Inputs()); calls != 9 {
+				t.Errorf("GetParametersByPath() calls = %d, want 9", calls)
+			}
+			if got := client.MaxInFlight(); got != concurrency {
+				t.Errorf("MaxInFlight() = %d, want %d", got, concurrency)
+			}
+		})
+	}
+
+	// Pages are sequential within a path, so the concurrent load takes about a third of the sequential one
+	if elapsed[1] < 9*delay || elapsed[3] > elapsed[1]*2/3 {
+		t.Errorf("InitEnvVarsContext() took %v sequentially and %v concurrently, want a speedup", elapsed[1], elapsed[3])
+	}
+}
+
+func TestLoadParamsContextPathsWarnings(t *testing.T) {
+	paths := []string{"/shared/", "/team/", "/myapp/"}
+	params := make(map[string]string)
+	for _, p := range paths {
+		for i := 0; i < 4; i++ {
+			params[fmt.Sprintf("%sconfig_%d", p, i)] = "{bad"
+		}
+	}
+	client := stmocks.NewFakeSSM(params)
+	client.PageSize = 2
+
+	// The handler is not safe for concurrent use, the race detector and the overlap check catch concurrent calls
+	var warnings []string
+	inHandler, overlaps := 0, 0
+	handle := func(msg string) {
+		inHandler++
+		if inHandler > 1 {
+			overlaps++
+		}
+		time.Sleep(time.Millisecond)
+		warnings = append(warnings, msg)
+		inHandler--
+	}
+
+	_, err := LoadParamsContext(context.Background(), "", WithPaths(paths...), WithClient(client), WithConcurrency(3),
+		ExpandJSON(), WithWarningHandler(handle))
+	if err != nil {
+		t.Fatalf("LoadParamsContext() unexpected error = %v", err)
+	}
+
+	if len(warnings) != 12 {
+		t.Errorf("LoadParamsContext() warned %d times, want 12", len(warnings))
+	}
+	if overlaps > 0 {
+		t.Errorf("warning handler called concurrently %d times, want serialized calls", overlaps)
+	}
+}
+
+func TestInitEnvVarsContextPathsErrors(t *testing.T) {
+	denied := &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "access denied"}
+	unsetEnv(t, "SSMENV_TEST_LEVEL", "SHARED_0")
+
+	client := pathsClient(0, "/shared/", "/team/", "/myapp/")
+	client.FailPath("/team/", denied)
+	client.FailPath("/myapp/", denied)
+
+	_, err := InitEnvVarsContext(context.Background(), WithPaths("/shared/", "/team/", "/myapp/"), WithClient(client))
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "AccessDeniedException" {
+		t.Fatalf("InitEnvVarsContext() error = %v, want AccessDeniedException", err)
+	}
+	for _, p := range []string{"/team/", "/myapp/"} {
+		if !strings.Contains(err.Error(), "path "+p) {
+			t.Errorf("InitEnvVarsContext() error = %v, want it to report path %s", err, p)
+		}
+	}
+	if strings.Contains(err.Error(), "/shared/") {
+		t.Errorf("InitEnvVarsContext() error = %v, want only the failed paths", err)
+	}
+	if _, exists := os.LookupEnv("SHARED_0"); exists {
+		t.Error("InitEnvVarsContext() set variables despite failed paths")
+	}
+}
+
+func TestInitEnvVarsContextPathList(t *testing.T) {
+	unsetEnv(t, "SSMENV_TEST_LEVEL", "SHARED_0", "MYAPP_0")
+	t.Setenv("SSM_PATH", "/shared/, /myapp/")
+
+	client := pathsClient(0, "/shared/", "/myapp/")
+	if _, err := InitEnvVarsContext(context.Background(), WithClient(client)); err != nil {
+		t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+	}
+
+	want := map[string]string{"SSMENV_TEST_LEVEL": "myapp", "SHARED_0": "0", "MYAPP_0": "0"}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("os.Getenv(%q) = %q, want %q", key, got, value)
+		}
+	}
+}
+
+// awsTestEnv isolates the AWS configuration of the test from the machine, with static credentials.
+func awsTestEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", path.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path.Join(dir, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+}
+
+func TestInitEnvVarsContextEndpoint(t *testing.T) {
+	var mu sync.Mutex
+	var targets, scopes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		targets = append(targets, r.Header.Get("X-Amz-Target"))
+		scopes = append(scopes, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		fmt.Fprint(w, `{"Parameters": [{"Name": "/myapp/ssmenv_test_endpoint", "Value": "localstack", "Type": "String"}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		opts       []Option
+		wantRegion string
+	}{
+		{name: "options", opts: []Option{WithEndpoint(server.URL), WithRegion("eu-west-3")}, wantRegion: "eu-west-3"},
+		{name: "environment variables", env: map[string]string{"SSM_ENDPOINT": server.URL, "SSM_REGION": "ap-south-1"}, wantRegion: "ap-south-1"},
+		{
+			name:       "options override environment variables",
+			env:        map[string]string{"SSM_ENDPOINT": "http://127.0.0.1:1", "SSM_REGION": "ap-south-1"},
+			opts:       []Option{WithEndpoint(server.URL), WithRegion("eu-west-3")},
+			wantRegion: "eu-west-3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			awsTestEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			unsetEnv(t, "SSMENV_TEST_ENDPOINT")
+			mu.Lock()
+			targets, scopes = nil, nil
+			mu.Unlock()
+
+			opts := append([]Option{WithPath("/myapp/"), WithMaxAttempts(1)}, tt.opts...)
+			if _, err := InitEnvVarsContext(context.Background(), opts...); err != nil {
+				t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+			}
+			if got := os.Getenv("SSMENV_TEST_ENDPOINT"); got != "localstack" {
+				t.Errorf("os.Getenv() = %q, want %q", got, "localstack")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(targets) != 1 || targets[0] != "AmazonSSM.GetParametersByPath" {
+				t.Fatalf("endpoint received %v, want a single GetParametersByPath call", targets)
+			}
+			if scope := "/" + tt.wantRegion + "/ssm/aws4_request"; !strings.Contains(scopes[0], scope) {
+				t.Errorf("Authorization = %q, want credential scope %q", scopes[0], scope)
+			}
+		})
+	}
+
+	t.Run("explicit client wins", func(t *testing.T) {
+		awsTestEnv(t)
+		unsetEnv(t, "SSMENV_TEST_ENDPOINT")
+		mu.Lock()
+		targets = nil
+		mu.Unlock()
+
+		client := stmocks.NewFakeSSM(map[string]string{"/myapp/ssmenv_test_endpoint": "fake"})
+		_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client), WithEndpoint(server.URL))
+		if err != nil {
+			t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if got := os.Getenv("SSMENV_TEST_ENDPOINT"); got != "fake" || len(targets) != 0 {
+			t.Errorf("os.Getenv() = %q with %d calls to the endpoint, want the value of the client", got, len(targets))
+		}
+	})
+}
+
+func TestInitEnvVarsContextAtomicApply(t *testing.T) {
+	failing := errors.New("setenv failed")
+
+	tests := []struct {
+		name   string
+		atomic bool
+		wantA  string
+		wantB  *string
+	}{
+		{name: "restored", atomic: true, wantA: "old"},
+		{name: "partial", atomic: false, wantA: "a", wantB: aws.String("b")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SSMENV_TEST_A", "old")
+			unsetEnv(t, "SSMENV_TEST_B", "SSMENV_TEST_C", "SSMENV_TEST_D")
+
+			client := stmocks.NewFakeSSM(map[string]string{
+				"/myapp/ssmenv_test_a": "a",
+				"/myapp/ssmenv_test_b": "b",
+				"/myapp/ssmenv_test_c": "c",
+				"/myapp/ssmenv_test_d": "d",
+			})
+			failOnC := func(o *options) {
+				o.setenv = func(key, value string) error {
+					if key == "SSMENV_TEST_C" {
+						return failing
+					}
+					return os.Setenv(key, value)
+				}
+			}
+
+			_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(client), AtomicApply(tt.atomic), failOnC)
+			if err == nil || !strings.Contains(err.Error(), "SSMENV_TEST_C") {
+				t.Fatalf("InitEnvVarsContext() error = %v, want the failure of SSMENV_TEST_C", err)
+			}
+
+			if got := os.Getenv("SSMENV_TEST_A"); got != tt.wantA {
+				t.Errorf("os.Getenv(%q) = %q, want %q", "SSMENV_TEST_A", got, tt.wantA)
+			}
+			if got, exists := os.LookupEnv("SSMENV_TEST_B"); exists != (tt.wantB != nil) || (exists && got != *tt.wantB) {
+				t.Errorf("os.LookupEnv(%q) = %q, %v, want %v", "SSMENV_TEST_B", got, exists, aws.ToString(tt.wantB))
+			}
+			for _, key := range []string{"SSMENV_TEST_C", "SSMENV_TEST_D"} {
+				if _, exists := os.LookupEnv(key); exists {
+					t.Errorf("os.LookupEnv(%q) found a value, want none", key)
+				}
+			}
+		})
+	}
+}
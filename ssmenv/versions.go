@@ -0,0 +1,135 @@
+package ssmenv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// VersionParamClient is a ParamClient fetching single parameters, required by WithVersions and GetParam,
+// implemented by *ssm.Client.
+type VersionParamClient interface {
+	ParamClient
+	GetParameter(ctx context.Context, input *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// WithLabel loads the versions of the parameters carrying the SSM label, i.e. "stable", instead of their
+// latest versions. Parameters without the label are not loaded.
+func WithLabel(label string) Option {
+	return func(o *options) {
+		o.label = label
+	}
+}
+
+// WithVersions pins parameters, identified by their full name such as /myapp/db/password, to a version.
+// Pinned parameters are fetched one by one after the parameters of their path, whose version they replace
+// regardless of WithLabel. Each pinned parameter must be under one of the paths, and the client must
+// implement VersionParamClient. Local files ignore the pinned versions.
+func WithVersions(versions map[string]int64) Option {
+	return func(o *options) {
+		o.versions = versions
+	}
+}
+
+// PinnedParamError is returned when a parameter pinned with WithVersions fails to be fetched.
+type PinnedParamError struct {
+	Name    string
+	Version int64
+	Err     error
+}
+
+func (e *PinnedParamError) Error() string {
+	return fmt.Sprintf("failed to fetch parameter %s version %d: %v", e.Name, e.Version, e.Err)
+}
+
+func (e *PinnedParamError) Unwrap() error {
+	return e.Err
+}
+
+// labelFilters returns the filters selecting the parameters carrying the label, nil without label.
+func (o *options) labelFilters() []types.ParameterStringFilter {
+	if o.label == "" {
+		return nil
+	}
+
+	return []types.ParameterStringFilter{{
+		Key:    aws.String("Label"),
+		Option: aws.String("Equals"),
+		Values: []string{o.label},
+	}}
+}
+
+// checkVersions checks that the client can fetch the pinned parameters and that each of them is under a path.
+func (o *options) checkVersions() error {
+	if len(o.versions) == 0 {
+		return nil
+	}
+
+	if _, ok := o.client.(VersionParamClient); !ok {
+		return fmt.Errorf("pinned versions require a client implementing VersionParamClient")
+	}
+
+	for name := range o.versions {
+		underPath := false
+		for _, path := range o.loadPaths() {
+			underPath = underPath || strings.HasPrefix(name, path)
+		}
+		if !underPath {
+			return fmt.Errorf("pinned parameter %s is not under the loaded paths", name)
+		}
+	}
+
+	return nil
+}
+
+// isPinned reports whether the parameter is pinned to a version.
+func (o *options) isPinned(name string) bool {
+	_, pinned := o.versions[name]
+	return pinned
+}
+
+// fetchPinned fetches the pinned parameters under the path, in name order, and adds them to params.
+func fetchPinned(ctx context.Context, o *options, params map[string]string) error {
+	var names []string
+	for name := range o.versions {
+		if strings.HasPrefix(name, o.path) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	client := o.client.(VersionParamClient)
+	for _, name := range names {
+		version := o.versions[name]
+		input := &ssm.GetParameterInput{
+			Name:           aws.String(name + ":" + strconv.FormatInt(version, 10)),
+			WithDecryption: aws.Bool(true),
+		}
+
+		var output *ssm.GetParameterOutput
+		err := retryCall(ctx, o, func(ctx context.Context) error {
+			var err error
+			output, err = client.GetParameter(ctx, input)
+			return err
+		})
+		if err != nil {
+			return &PinnedParamError{Name: name, Version: version, Err: err}
+		}
+
+		o.stats.addCount(o.path, 1)
+		param := *output.Parameter
+		param.Name = aws.String(name)
+		o.addParam(params, param)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package ssmenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/smithy-go"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+var _ VersionParamClient = (*ssm.Client)(nil)
+var _ VersionParamClient = (*stmocks.FakeSSM)(nil)
+
+// versionsClient returns a fake client serving three versions of each parameter, the second one labeled stable.
+func versionsClient() *stmocks.FakeSSM {
+	client := stmocks.NewFakeSSM(nil)
+	for _, value := range []string{"db-v1.local", "db-v2.local", "db-v3.local"} {
+		client.Put("/myapp/ssmenv_test_host", value)
+	}
+	for _, value := range []string{"first", "stable", "latest"} {
+		client.Put("/myapp/ssmenv_test_password", value)
+	}
+	client.LabelVersion("/myapp/ssmenv_test_host", 2, "stable")
+	client.LabelVersion("/myapp/ssmenv_test_password", 2, "stable")
+	return client
+}
+
+func TestInitEnvVarsContextVersions(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantLabel    string
+		wantHost     string
+		wantPassword string
+	}{
+		{name: "latest", wantHost: "db-v3.local", wantPassword: "latest"},
+		{name: "label", opts: []Option{WithLabel("stable")}, wantLabel: "stable", wantHost: "db-v2.local", wantPassword: "stable"},
+		{name: "unknown label", opts: []Option{WithLabel("unknown")}, wantLabel: "unknown"},
+		{
+			name:         "pinned version",
+			opts:         []Option{WithVersions(map[string]int64{"/myapp/ssmenv_test_password": 1})},
+			wantHost:     "db-v3.local",
+			wantPassword: "first",
+		},
+		{
+			name:         "pinned version and label",
+			opts:         []Option{WithLabel("stable"), WithVersions(map[string]int64{"/myapp/ssmenv_test_host": 1})},
+			wantLabel:    "stable",
+			wantHost:     "db-v1.local",
+			wantPassword: "stable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t, "SSMENV_TEST_HOST", "SSMENV_TEST_PASSWORD")
+
+			client := versionsClient()
+			opts := append([]Option{WithPath("/myapp/"), WithClient(client)}, tt.opts...)
+			if _, err := InitEnvVarsContext(context.Background(), opts...); err != nil {
+				t.Fatalf("InitEnvVarsContext() unexpected error = %v", err)
+			}
+
+			if got := os.Getenv("SSMENV_TEST_HOST"); got != tt.wantHost {
+				t.Errorf("os.Getenv(%q) = %q, want %q", "SSMENV_TEST_HOST", got, tt.wantHost)
+			}
+			if got := os.Getenv("SSMENV_TEST_PASSWORD"); got != tt.wantPassword {
+				t.Errorf("os.Getenv(%q) = %q, want %q", "SSMENV_TEST_PASSWORD", got, tt.wantPassword)
+			}
+
+			for _, input := range client.Inputs() {
+				label := ""
+				if len(input.ParameterFilters) > 0 {
+					label = input.ParameterFilters[0].Values[0]
+				}
+				if label != tt.wantLabel {
+					t.Errorf("GetParametersByPath() filters = %v, want label %q", input.ParameterFilters, tt.wantLabel)
+				}
+			}
+		})
+	}
+}
+
+func TestInitEnvVarsContextVersionsErrors(t *testing.T) {
+	t.Run("version not found", func(t *testing.T) {
+		_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(versionsClient()),
+			WithVersions(map[string]int64{"/myapp/ssmenv_test_password": 7}))
+
+		var pinnedErr *PinnedParamError
+		if !errors.As(err, &pinnedErr) || pinnedErr.Name != "/myapp/ssmenv_test_password" || pinnedErr.Version != 7 {
+			t.Fatalf("InitEnvVarsContext() error = %v, want PinnedParamError for version 7", err)
+		}
+		var apiErr smithy.APIError
+		if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ParameterVersionNotFound" {
+			t.Errorf("InitEnvVarsContext() error = %v, want %s", err, "ParameterVersionNotFound")
+		}
+	})
+
+	t.Run("not under path", func(t *testing.T) {
+		_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(versionsClient()),
+			WithVersions(map[string]int64{"/otherapp/key": 1}))
+		if err == nil || !strings.Contains(err.Error(), "/otherapp/key") {
+			t.Errorf("InitEnvVarsContext() error = %v, want pinned parameter outside the paths", err)
+		}
+	})
+
+	t.Run("unsupported client", func(t *testing.T) {
+		_, err := InitEnvVarsContext(context.Background(), WithPath("/myapp/"), WithClient(paramClientOnly{versionsClient()}),
+			WithVersions(map[string]int64{"/myapp/ssmenv_test_password": 1}))
+		if err == nil {
+			t.Error("InitEnvVarsContext() expected error for a client without GetParameter, got nil")
+		}
+	})
+}
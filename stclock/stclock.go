@@ -0,0 +1,89 @@
+// Package stclock defines the clock used by the packages of this module to read the time and wait,
+// so time-dependent code can be tested with the fake clock of stmocks instead of sleeping.
+package stclock
+
+import "time"
+
+// Clock reads the time and creates timers and tickers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a timer sending the time on its channel after at least d.
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a ticker sending the time on its channel every d. It panics if d is not positive.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event, like time.Timer.
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer already fired or was stopped.
+	Stop() bool
+	// Reset changes the timer to fire after d. It returns false if the timer already fired or was stopped.
+	Reset(d time.Duration) bool
+}
+
+// Ticker sends the time at intervals, like time.Ticker, dropping ticks for slow receivers.
+type Ticker interface {
+	// C returns the channel the ticks are sent on.
+	C() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are sent.
+	Stop()
+	// Reset stops the ticker and resets its period to d, the next tick arriving after d.
+	Reset(d time.Duration)
+}
+
+// System returns the clock of the system, using the time package.
+func System() Clock {
+	return systemClock{}
+}
+
+// systemClock is the Clock of the system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{timer: time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{ticker: time.NewTicker(d)}
+}
+
+// systemTimer is a Timer wrapping a time.Timer.
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+// systemTicker is a Ticker wrapping a time.Ticker.
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+func (t systemTicker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
@@ -0,0 +1,40 @@
+package stclock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystem(t *testing.T) {
+	clock := System()
+
+	if since := time.Since(clock.Now()); since < 0 || since > time.Second {
+		t.Errorf("Now() is %v away from the system time", since)
+	}
+
+	timer := clock.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+	if timer.Stop() {
+		t.Error("Stop() = true on a fired timer, want false")
+	}
+	if timer.Reset(time.Hour) {
+		t.Error("Reset() = true on a fired timer, want false")
+	}
+	if !timer.Stop() {
+		t.Error("Stop() = false on an active timer, want true")
+	}
+
+	ticker := clock.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ticker.C():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("tick %d not received", i)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package sterrors
+
+import "maps"
+
+// ErrorCode returns the code of the error. It is named after the type as the Code field takes the name Code.
+func (s *Error) ErrorCode() ErrorCode {
+	return s.Code
+}
+
+// HTTPCode returns the http code configured for the code of the error.
+func (s *Error) HTTPCode() int {
+	return s.Http_code
+}
+
+// Msg returns the message of the error, the configured message of its code possibly with a detail.
+func (s *Error) Msg() string {
+	return s.Message
+}
+
+// Cause returns the internal error wrapped by the error, nil if none.
+func (s *Error) Cause() error {
+	return s.Err
+}
+
+// Unwrap returns the internal error, so errors.Is and errors.As look through it, i.e. to find ErrUnknownCode
+// or ErrMissingParam.
+func (s *Error) Unwrap() error {
+	return s.Err
+}
+
+// Builder builds an error of a factory step by step:
+//
+//	return factory.Build(CodeSymbolNotFound).WithCause(err).WithDetail("symbol", symbol).Err()
+//
+// A Builder is immutable: each method returns a new Builder, so a partially built one can be reused.
+type Builder struct {
+	factory *ErrorFactory
+	code    ErrorCode
+	cause   error
+	details map[string]any
+}
+
+// Build starts building an error of the code, with the message and http code configured for it.
+func (e *ErrorFactory) Build(code ErrorCode) Builder {
+	return Builder{factory: e, code: code}
+}
+
+// WithCause returns a copy of the builder with the internal error of the error set.
+func (b Builder) WithCause(err error) Builder {
+	b.cause = err
+	return b
+}
+
+// WithDetail returns a copy of the builder with the detail added, as Error.WithDetail does.
+func (b Builder) WithDetail(key string, value any) Builder {
+	details := make(map[string]any, len(b.details)+1)
+	maps.Copy(details, b.details)
+	details[key] = value
+	b.details = details
+
+	return b
+}
+
+// Err creates the error, equivalent to the one created by NewError with the details added.
+func (b Builder) Err() error {
+	stErr := b.factory.newError(b.code, b.cause, b.factory.getMessage(b.code))
+	if len(b.details) > 0 {
+		stErr.Details = maps.Clone(b.details)
+	}
+
+	return stErr
+}
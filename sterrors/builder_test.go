@@ -0,0 +1,93 @@
+package sterrors
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	factory := NewFactory(testConfig, "internal error", 500, WithStackTraces(4))
+	cause := errors.New("no rows")
+
+	tests := []struct {
+		name  string
+		built error
+		want  *Error
+	}{
+		{
+			name:  "code only",
+			built: factory.Build(1001).Err(),
+			want:  factory.NewError(1001, nil).(*Error),
+		},
+		{
+			name:  "with cause",
+			built: factory.Build(1002).WithCause(cause).Err(),
+			want:  factory.NewError(1002, cause).(*Error),
+		},
+		{
+			name:  "with details",
+			built: factory.Build(1001).WithCause(cause).WithDetail("symbol", "AAPL").WithDetail("user_id", 42).Err(),
+			want:  factory.NewError(1001, cause).(*Error).WithDetail("symbol", "AAPL").WithDetail("user_id", 42),
+		},
+		{
+			name:  "unknown code",
+			built: factory.Build(4242).Err(),
+			want:  factory.NewError(4242, nil).(*Error),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Error
+			if !errors.As(tt.built, &got) {
+				t.Fatalf("Err() = %v, want *Error", tt.built)
+			}
+
+			// The stacks differ by the line of the call
+			if stack := got.StackTrace(); len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestBuilder") {
+				t.Errorf("StackTrace() = %v, want frames starting at the test function", stack)
+			}
+			got.stack, tt.want.stack = nil, nil
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Err() = %+v, want %+v", got, tt.want)
+			}
+			if got.ErrorCode() != tt.want.Code || got.HTTPCode() != tt.want.Http_code ||
+				got.Msg() != tt.want.Message || got.Cause() != tt.want.Err {
+				t.Errorf("accessors = %d %d %q %v, want %d %d %q %v", got.ErrorCode(), got.HTTPCode(), got.Msg(), got.Cause(),
+					tt.want.Code, tt.want.Http_code, tt.want.Message, tt.want.Err)
+			}
+		})
+	}
+}
+
+func TestBuilderImmutable(t *testing.T) {
+	base := newTestFactory().Build(1001).WithDetail("symbol", "AAPL")
+	first := base.WithDetail("user_id", 1).Err().(*Error)
+	second := base.WithCause(errors.New("no rows")).WithDetail("user_id", 2).Err().(*Error)
+
+	if first.Details["user_id"] != 1 || second.Details["user_id"] != 2 || first.Err != nil {
+		t.Errorf("builders share state: first = %+v, second = %+v", first, second)
+	}
+
+	first.WithDetail("exchange", "NASDAQ")
+	if _, ok := base.Err().(*Error).Details["exchange"]; ok {
+		t.Error("adding a detail to a built error changed the builder")
+	}
+}
+
+func TestBuilderJSON(t *testing.T) {
+	factory := newTestFactory()
+	factory.SetIncludeInternal(true)
+
+	body, err := json.Marshal(factory.Build(1001).WithCause(errors.New("no rows")).WithDetail("symbol", "AAPL").Err())
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error = %v", err)
+	}
+	if want := `{"code":1001,"message":"symbol not found","type":"NotFound","internal":"no rows"}`; string(body) != want {
+		t.Errorf("json.Marshal() = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,37 @@
+package sterrors
+
+import "errors"
+
+// ErrUnknownCode is joined to the internal error of the errors created with a code missing from the config
+// by a factory using StrictCodes, so errors.Is(err, ErrUnknownCode) finds it.
+var ErrUnknownCode = errors.New("unknown error code")
+
+// Observer is notified of every error created by a factory, i.e. to count them by code or to alert on
+// errors wrapping ErrUnknownCode.
+type Observer func(*Error)
+
+// StrictCodes makes the factory flag the errors created with a code missing from its config: they still get
+// the default message and http code, but their internal error wraps ErrUnknownCode.
+func StrictCodes(strict bool) FactoryOption {
+	return func(e *ErrorFactory) {
+		e.strictCodes = strict
+	}
+}
+
+// WithObserver sets the observer notified of every error created by the factory.
+func WithObserver(observer Observer) FactoryOption {
+	return func(e *ErrorFactory) {
+		e.observer = observer
+	}
+}
+
+// Codes returns the codes of the config of the factory, sorted.
+func (e *ErrorFactory) Codes() []ErrorCode {
+	return sortedCodes(e.config)
+}
+
+// Has returns whether the code is in the config of the factory.
+func (e *ErrorFactory) Has(code ErrorCode) bool {
+	_, ok := e.config[code]
+	return ok
+}
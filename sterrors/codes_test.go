@@ -0,0 +1,99 @@
+package sterrors
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStrictCodes(t *testing.T) {
+	cause := errors.New("no rows")
+
+	tests := []struct {
+		name        string
+		strict      bool
+		code        ErrorCode
+		wantUnknown bool
+		wantMessage string
+		wantHttp    int
+	}{
+		{
+			name:        "strict unknown code",
+			strict:      true,
+			code:        4242,
+			wantUnknown: true,
+			wantMessage: "internal error",
+			wantHttp:    500,
+		},
+		{
+			name:        "strict known code",
+			strict:      true,
+			code:        1001,
+			wantMessage: "symbol not found",
+			wantHttp:    404,
+		},
+		{
+			name:        "lenient unknown code",
+			code:        4242,
+			wantMessage: "internal error",
+			wantHttp:    500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var observed []*Error
+			factory := NewFactory(testConfig, "internal error", 500,
+				StrictCodes(tt.strict), WithObserver(func(e *Error) { observed = append(observed, e) }))
+
+			created := []*Error{
+				factory.NewError(tt.code, cause).(*Error),
+				factory.NewErrorf(tt.code, cause, ""),
+				factory.NewErrorParams(tt.code, cause, nil),
+				factory.NewErrorLocalized(tt.code, cause, "es"),
+			}
+
+			for _, err := range created {
+				if got := errors.Is(err, ErrUnknownCode); got != tt.wantUnknown {
+					t.Errorf("errors.Is(%v, ErrUnknownCode) = %v, want %v", err, got, tt.wantUnknown)
+				}
+				if !errors.Is(err, cause) {
+					t.Errorf("Err = %v, want it to wrap the cause", err.Err)
+				}
+				if err.Message != tt.wantMessage || err.Http_code != tt.wantHttp {
+					t.Errorf("error = %q %d, want %q %d", err.Message, err.Http_code, tt.wantMessage, tt.wantHttp)
+				}
+			}
+
+			if !reflect.DeepEqual(observed, created) {
+				t.Errorf("observer got %d errors, want the %d created", len(observed), len(created))
+			}
+		})
+	}
+}
+
+func TestStrictCodesNilError(t *testing.T) {
+	factory := NewFactory(testConfig, "internal error", 500, StrictCodes(true))
+	if created := factory.NewError(4242, nil); !errors.Is(created, ErrUnknownCode) {
+		t.Errorf("errors.Is(%v, ErrUnknownCode) = false, want true", created)
+	}
+
+	err := factory.NewErrorf(4242, nil, "")
+	if !errors.Is(err, ErrUnknownCode) {
+		t.Errorf("Err = %v, want ErrUnknownCode", err.Err)
+	}
+	if want := "http error: 500, with internal code: 4242, message: internal error, unknown error code 4242"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestFactoryCodes(t *testing.T) {
+	factory := newTestFactory()
+
+	if got := factory.Codes(); !reflect.DeepEqual(got, []ErrorCode{1001, 1002}) {
+		t.Errorf("Codes() = %v, want [1001 1002]", got)
+	}
+	if !factory.Has(1001) || factory.Has(4242) {
+		t.Errorf("Has() = %v, %v, want true for 1001 and false for 4242", factory.Has(1001), factory.Has(4242))
+	}
+}
@@ -0,0 +1,169 @@
+package sterrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats supported by LoadConfig.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// configEntry is an entry of an error catalog file. The code is decoded as any value so non-integer codes
+// can be reported.
+type configEntry struct {
+	Code      any               `json:"code" yaml:"code"`
+	Type      string            `json:"type" yaml:"type"`
+	Message   string            `json:"message" yaml:"message"`
+	Http_code int               `json:"http_code" yaml:"http_code"`
+	Retryable *bool             `json:"retryable" yaml:"retryable"`
+	Severity  string            `json:"severity" yaml:"severity"`
+	Messages  map[string]string `json:"messages" yaml:"messages"`
+}
+
+// LoadConfig reads an error catalog in the given format, FormatYAML ("yml" is accepted too) or FormatJSON.
+// The catalog is a list of entries with a code, type, message, http_code and optional retryable and severity:
+//
+//	# errors.yaml
+//	- code: 1001
+//	  type: NotFound
+//	  message: symbol not found
+//	  http_code: 404
+//	- code: 1002
+//	  type: RateLimited
+//	  message: too many requests
+//	  http_code: 429
+//	  retryable: true
+//	  severity: info
+//	  messages:
+//	    es: demasiadas solicitudes
+//	    pt-BR: muitas solicitações
+//
+// Entries with a non-integer or duplicate code, no message, an empty translation or an http_code outside 100-599 are rejected,
+// and the returned error reports all of them.
+func LoadConfig(r io.Reader, format string) (ErrorConfig, error) {
+	var entries []configEntry
+	switch strings.ToLower(format) {
+	case FormatYAML, "yml":
+		if err := yaml.NewDecoder(r).Decode(&entries); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode YAML error config: %w", err)
+		}
+	case FormatJSON:
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		if err := decoder.Decode(&entries); err != nil {
+			return nil, fmt.Errorf("failed to decode JSON error config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported error config format %q", format)
+	}
+
+	config := make(ErrorConfig, len(entries))
+	var errs []error
+	for i, entry := range entries {
+		code, ok := entryCode(entry.Code)
+		if !ok {
+			errs = append(errs, fmt.Errorf("entry %d: code %v is not an integer", i, entry.Code))
+			continue
+		}
+
+		if _, exists := config[code]; exists {
+			errs = append(errs, fmt.Errorf("entry %d: duplicate code %d", i, code))
+		}
+		if entry.Message == "" {
+			errs = append(errs, fmt.Errorf("entry %d: code %d has no message", i, code))
+		}
+		for _, locale := range sortedKeys(entry.Messages) {
+			if entry.Messages[locale] == "" {
+				errs = append(errs, fmt.Errorf("entry %d: code %d has no %s message", i, code, locale))
+			}
+		}
+		if entry.Http_code < 100 || entry.Http_code > 599 {
+			errs = append(errs, fmt.Errorf("entry %d: code %d has invalid http_code %d", i, code, entry.Http_code))
+		}
+
+		config[code] = ErrorData{
+			ErrorType: entry.Type,
+			Message:   entry.Message,
+			Http_code: entry.Http_code,
+			Retryable: entry.Retryable,
+			Severity:  entry.Severity,
+			Messages:  entry.Messages,
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid error config: %w", errors.Join(errs...))
+	}
+
+	return config, nil
+}
+
+// entryCode converts a decoded code into an ErrorCode. It returns false if the code is not an integer.
+func entryCode(value any) (ErrorCode, bool) {
+	switch v := value.(type) {
+	case int:
+		return ErrorCode(v), true
+	case json.Number:
+		code, err := v.Int64()
+		return ErrorCode(code), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Merge returns a new config with the entries of both configs. Codes defined in both with different data
+// are conflicts, reported all at once in the returned error.
+func (c ErrorConfig) Merge(other ErrorConfig) (ErrorConfig, error) {
+	merged := make(ErrorConfig, len(c)+len(other))
+	for code, data := range c {
+		merged[code] = data
+	}
+
+	var conflicts []ErrorCode
+	for code, data := range other {
+		if existing, ok := merged[code]; ok && !existing.equal(data) {
+			conflicts = append(conflicts, code)
+			continue
+		}
+		merged[code] = data
+	}
+
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool { return conflicts[i] < conflicts[j] })
+		return nil, fmt.Errorf("conflicting error codes: %v", conflicts)
+	}
+
+	return merged, nil
+}
+
+// equal checks that both data are the same, comparing the Retryable values rather than the pointers.
+func (d ErrorData) equal(other ErrorData) bool {
+	if (d.Retryable == nil) != (other.Retryable == nil) ||
+		(d.Retryable != nil && *d.Retryable != *other.Retryable) {
+		return false
+	}
+
+	return d.ErrorType == other.ErrorType && d.Message == other.Message && d.Http_code == other.Http_code &&
+		d.Severity == other.Severity && maps.Equal(d.Messages, other.Messages)
+}
+
+// sortedKeys returns the keys of the map in order, so problems are reported deterministically.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
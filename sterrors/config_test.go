@@ -0,0 +1,236 @@
+package sterrors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	want := ErrorConfig{
+		1001: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+		1002: {ErrorType: "BadRequest", Message: "invalid request", Http_code: 400},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			input: `
+- code: 1001
+  type: NotFound
+  message: symbol not found
+  http_code: 404
+- code: 1002
+  type: BadRequest
+  message: invalid request
+  http_code: 400
+`,
+		},
+		{
+			name:   "json",
+			format: "JSON",
+			input: `[
+				{"code": 1001, "type": "NotFound", "message": "symbol not found", "http_code": 404},
+				{"code": 1002, "type": "BadRequest", "message": "invalid request", "http_code": 400}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadConfig(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("LoadConfig() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadConfig() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		wantErrs []string
+	}{
+		{
+			name:   "yaml violations",
+			format: "yml",
+			input: `
+- code: 1001
+  message: symbol not found
+  http_code: 404
+- code: 1001
+  message: duplicate
+  http_code: 404
+- code: 1002
+  http_code: 400
+- code: 1003
+  message: bad status
+  http_code: 600
+- code: 1004
+  message: no status
+- code: 10.5
+  message: float code
+  http_code: 400
+- code: abc
+  message: string code
+  http_code: 400
+`,
+			wantErrs: []string{
+				"entry 1: duplicate code 1001",
+				"entry 2: code 1002 has no message",
+				"entry 3: code 1003 has invalid http_code 600",
+				"entry 4: code 1004 has invalid http_code 0",
+				"entry 5: code 10.5 is not an integer",
+				"entry 6: code abc is not an integer",
+			},
+		},
+		{
+			name:   "json violations",
+			format: FormatJSON,
+			input: `[
+				{"code": 1001, "message": "symbol not found", "http_code": 404},
+				{"code": 1001, "message": "duplicate", "http_code": 404},
+				{"code": 1.5, "message": "float code", "http_code": 400},
+				{"code": "1003", "message": "string code", "http_code": 400},
+				{"code": 1004, "message": "informational", "http_code": 99}
+			]`,
+			wantErrs: []string{
+				"entry 1: duplicate code 1001",
+				"entry 2: code 1.5 is not an integer",
+				"entry 3: code 1003 is not an integer",
+				"entry 4: code 1004 has invalid http_code 99",
+			},
+		},
+		{
+			name:     "malformed json",
+			format:   FormatJSON,
+			input:    `{"code": 1001}`,
+			wantErrs: []string{"failed to decode JSON error config"},
+		},
+		{
+			name:     "unsupported format",
+			format:   "toml",
+			wantErrs: []string{`unsupported error config format "toml"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(strings.NewReader(tt.input), tt.format)
+			if err == nil {
+				t.Fatalf("LoadConfig() = %v, want error", config)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfig() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorConfigMerge(t *testing.T) {
+	base := ErrorConfig{
+		1001: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+		1002: {ErrorType: "BadRequest", Message: "invalid request", Http_code: 400},
+	}
+
+	t.Run("no conflict", func(t *testing.T) {
+		got, err := base.Merge(ErrorConfig{
+			1002: {ErrorType: "BadRequest", Message: "invalid request", Http_code: 400},
+			2001: {ErrorType: "Unavailable", Message: "quotes unavailable", Http_code: 503},
+		})
+		if err != nil {
+			t.Fatalf("ErrorConfig.Merge() unexpected error = %v", err)
+		}
+		if len(got) != 3 || got[2001].Message != "quotes unavailable" {
+			t.Errorf("ErrorConfig.Merge() = %v, want 3 entries including 2001", got)
+		}
+		if len(base) != 2 {
+			t.Errorf("ErrorConfig.Merge() modified the receiver: %v", base)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		_, err := base.Merge(ErrorConfig{
+			1002: {ErrorType: "BadRequest", Message: "bad request", Http_code: 400},
+			1001: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 410},
+		})
+		if err == nil || err.Error() != "conflicting error codes: [1001 1002]" {
+			t.Errorf("ErrorConfig.Merge() error = %v, want conflicting error codes: [1001 1002]", err)
+		}
+	})
+}
+
+func TestLoadConfigMetadata(t *testing.T) {
+	input := `
+- code: 4001
+  type: RateLimited
+  message: too many requests
+  http_code: 429
+  retryable: false
+  severity: info
+- code: 4002
+  message: quotes unavailable
+  http_code: 503
+`
+	config, err := LoadConfig(strings.NewReader(input), FormatYAML)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error = %v", err)
+	}
+
+	if data := config[4001]; data.Retryable == nil || *data.Retryable || data.Severity != SeverityInfo {
+		t.Errorf("LoadConfig() 4001 = %+v, want retryable false and severity info", data)
+	}
+	if data := config[4002]; data.Retryable != nil || data.Severity != "" {
+		t.Errorf("LoadConfig() 4002 = %+v, want unset retryable and severity", data)
+	}
+
+	// Equal Retryable values held by different pointers are not a conflict
+	again, _ := LoadConfig(strings.NewReader(input), FormatYAML)
+	if _, err := config.Merge(again); err != nil {
+		t.Errorf("ErrorConfig.Merge() unexpected error = %v", err)
+	}
+}
+
+func TestLoadConfigMessages(t *testing.T) {
+	input := `[
+		{"code": 5001, "message": "symbol not found", "http_code": 404, "messages": {"es": "símbolo no encontrado"}},
+		{"code": 5002, "message": "invalid request", "http_code": 400, "messages": {"es": "", "fr": ""}}
+	]`
+
+	_, err := LoadConfig(strings.NewReader(input), FormatJSON)
+	if err == nil || !strings.Contains(err.Error(), "entry 1: code 5002 has no es message\nentry 1: code 5002 has no fr message") {
+		t.Fatalf("LoadConfig() error = %v, want missing es and fr messages", err)
+	}
+
+	config, err := LoadConfig(strings.NewReader(`
+- code: 5001
+  message: symbol not found
+  http_code: 404
+  messages:
+    es: símbolo no encontrado
+    pt-BR: símbolo não encontrado
+`), FormatYAML)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error = %v", err)
+	}
+	want := map[string]string{"es": "símbolo no encontrado", "pt-BR": "símbolo não encontrado"}
+	if !reflect.DeepEqual(config[5001].Messages, want) {
+		t.Errorf("LoadConfig() messages = %v, want %v", config[5001].Messages, want)
+	}
+
+	if _, err := config.Merge(ErrorConfig{5001: {Message: "symbol not found", Http_code: 404}}); err == nil {
+		t.Error("ErrorConfig.Merge() expected conflict on different messages, got nil")
+	}
+}
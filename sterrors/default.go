@@ -0,0 +1,70 @@
+package sterrors
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNoDefaultFactory is wrapped by the errors returned by the package-level constructors when
+// SetDefaultFactory was not called.
+var ErrNoDefaultFactory = errors.New("sterrors: no default factory")
+
+// defaultFactory is the factory used by the package-level constructors.
+var defaultFactory atomic.Pointer[ErrorFactory]
+
+// SetDefaultFactory sets the factory used by New, Newf and Wrap. It is safe for concurrent use and the last
+// call wins; a nil factory unsets it.
+func SetDefaultFactory(f *ErrorFactory) {
+	defaultFactory.Store(f)
+}
+
+// DefaultFactory returns the factory set using SetDefaultFactory, nil if none.
+func DefaultFactory() *ErrorFactory {
+	return defaultFactory.Load()
+}
+
+// New creates an error using the default factory, as ErrorFactory.NewError does. Without default factory,
+// it returns an error wrapping ErrNoDefaultFactory and err.
+func New(code ErrorCode, err error) error {
+	f := defaultFactory.Load()
+	if f == nil {
+		return noDefaultFactory(code, err)
+	}
+
+	return f.newError(code, err, f.getMessage(code))
+}
+
+// Newf creates an error using the default factory, as ErrorFactory.NewErrorf does. Without default factory,
+// it returns an error wrapping ErrNoDefaultFactory and err.
+func Newf(code ErrorCode, err error, format string, args ...any) error {
+	f := defaultFactory.Load()
+	if f == nil {
+		return noDefaultFactory(code, err)
+	}
+
+	return f.newError(code, err, f.formatMessage(code, format, args...))
+}
+
+// Wrap is like New but returns nil if err is nil, so it can wrap the result of a call directly.
+func Wrap(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	f := defaultFactory.Load()
+	if f == nil {
+		return noDefaultFactory(code, err)
+	}
+
+	return f.newError(code, err, f.getMessage(code))
+}
+
+// noDefaultFactory returns the error of the package-level constructors when no default factory is set.
+func noDefaultFactory(code ErrorCode, err error) error {
+	if err == nil {
+		return fmt.Errorf("%w, code %d", ErrNoDefaultFactory, code)
+	}
+
+	return fmt.Errorf("%w, code %d: %w", ErrNoDefaultFactory, code, err)
+}
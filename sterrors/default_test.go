@@ -0,0 +1,112 @@
+package sterrors
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDefaultFactoryUnset(t *testing.T) {
+	SetDefaultFactory(nil)
+	cause := errors.New("no rows")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"New", New(1001, cause)},
+		{"Newf", Newf(1001, cause, "%s", "AAPL")},
+		{"Wrap", Wrap(1001, cause)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, ErrNoDefaultFactory) {
+				t.Errorf("error = %v, want it to wrap ErrNoDefaultFactory", tt.err)
+			}
+			if !errors.Is(tt.err, cause) {
+				t.Errorf("error = %v, want it to wrap the cause", tt.err)
+			}
+			if want := "sterrors: no default factory, code 1001: no rows"; tt.err.Error() != want {
+				t.Errorf("error = %q, want %q", tt.err, want)
+			}
+		})
+	}
+
+	if err := New(1001, nil); !errors.Is(err, ErrNoDefaultFactory) {
+		t.Errorf("New() with nil error = %v, want it to wrap ErrNoDefaultFactory", err)
+	}
+}
+
+func TestDefaultFactory(t *testing.T) {
+	SetDefaultFactory(newTestFactory())
+	t.Cleanup(func() { SetDefaultFactory(nil) })
+
+	var stErr *Error
+	if err := New(1001, nil); !errors.As(err, &stErr) || stErr.Message != "symbol not found" {
+		t.Errorf("New() = %v, want the configured error", err)
+	}
+	if err := Newf(1001, nil, "%s", "AAPL"); !errors.As(err, &stErr) || stErr.Message != "symbol not found: AAPL" {
+		t.Errorf("Newf() = %v, want the formatted message", err)
+	}
+	if err := Wrap(1001, nil); err != nil {
+		t.Errorf("Wrap(nil) = %v, want nil", err)
+	}
+	if err := Wrap(1002, errors.New("bad limit")); !errors.As(err, &stErr) || stErr.Http_code != 400 {
+		t.Errorf("Wrap() = %v, want the configured error", err)
+	}
+
+	// Last write wins
+	SetDefaultFactory(NewFactory(ErrorConfig{1001: {Message: "replaced", Http_code: 404}}, "internal error", 500))
+	if err := New(1001, nil); !errors.As(err, &stErr) || stErr.Message != "replaced" {
+		t.Errorf("New() = %v, want the error of the last factory set", err)
+	}
+}
+
+func TestDefaultFactoryStackTrace(t *testing.T) {
+	SetDefaultFactory(NewFactory(testConfig, "internal error", 500, WithStackTraces(4)))
+	t.Cleanup(func() { SetDefaultFactory(nil) })
+
+	for _, err := range []error{New(1001, nil), Newf(1001, nil, ""), Wrap(1001, errors.New("no rows"))} {
+		var stErr *Error
+		if !errors.As(err, &stErr) {
+			t.Fatalf("error = %v, want *Error", err)
+		}
+		if stack := stErr.StackTrace(); len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestDefaultFactoryStackTrace") {
+			t.Errorf("StackTrace() = %v, want frames starting at the test function", stack)
+		}
+	}
+}
+
+func TestDefaultFactoryConcurrent(t *testing.T) {
+	t.Cleanup(func() { SetDefaultFactory(nil) })
+	factories := []*ErrorFactory{newTestFactory(), NewFactory(testConfig, "other default", 503)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetDefaultFactory(factories[(i+j)%len(factories)])
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := New(1001, nil)
+				var stErr *Error
+				if !errors.As(err, &stErr) && !errors.Is(err, ErrNoDefaultFactory) {
+					t.Errorf("New() = %v, want *Error or ErrNoDefaultFactory", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if f := DefaultFactory(); f != factories[0] && f != factories[1] {
+		t.Errorf("DefaultFactory() = %v, want one of the factories set", f)
+	}
+}
@@ -1,9 +1,14 @@
 package sterrors
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
 )
 
 func GetDocumentMd(w io.Writer, config ErrorConfig, appname string) error {
@@ -17,17 +22,40 @@ func GetDocumentMd(w io.Writer, config ErrorConfig, appname string) error {
 		return err
 	}
 
-	_, err = fmt.Fprintf(w, "|Error Code|Type|Message|HTTP Code|\n")
+	_, err = fmt.Fprintf(w, "|Error Code|Type|Message|HTTP Code|Retryable|Severity|\n")
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintf(w, "|:----------|:----------|:----------|:----------|\n")
+	_, err = fmt.Fprintf(w, "|:----------|:----------|:----------|:----------|:----------|:----------|\n")
 	if err != nil {
 		return err
 	}
 
-	codes := []ErrorCode{}
+	for _, code := range sortedCodes(config) {
+		info := config[code]
+		// Errors without http code are written as 500, see Error.status
+		status := httpStatus(info.Http_code)
+		retryable := retryableHttpCode(status)
+		if info.Retryable != nil {
+			retryable = *info.Retryable
+		}
+
+		_, err = fmt.Fprintf(w, "|%d|%s|%s|%d|%t|%s|\n", code, info.ErrorType, info.Message, info.Http_code,
+			retryable, severity(info.Severity, status))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+}
+
+// sortedCodes returns the codes of the config in order. All the document generators iterate through it,
+// so their ordering cannot diverge.
+func sortedCodes(config ErrorConfig) []ErrorCode {
+	codes := make([]ErrorCode, 0, len(config))
 	for code := range config {
 		codes = append(codes, code)
 	}
@@ -36,14 +64,115 @@ func GetDocumentMd(w io.Writer, config ErrorConfig, appname string) error {
 		return codes[i] < codes[j]
 	})
 
-	for _, code := range codes {
+	return codes
+}
+
+// documentEntry is an entry of the JSON document, also used as the example values of the OpenAPI document.
+type documentEntry struct {
+	Code      ErrorCode `json:"code" yaml:"code"`
+	Type      string    `json:"type" yaml:"type"`
+	Message   string    `json:"message" yaml:"message"`
+	Http_code int       `json:"http_code" yaml:"http_code,omitempty"`
+}
+
+// GetDocumentJSON writes the config as a JSON array of {code, type, message, http_code} sorted by code.
+func GetDocumentJSON(w io.Writer, config ErrorConfig) error {
+	entries := make([]documentEntry, 0, len(config))
+	for _, code := range sortedCodes(config) {
+		info := config[code]
+		entries = append(entries, documentEntry{
+			Code:      code,
+			Type:      info.ErrorType,
+			Message:   info.Message,
+			Http_code: info.Http_code,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// openAPISchema is the reusable Error schema of the OpenAPI document, matching the JSON body of the errors.
+const openAPISchema = `type: object
+required: [code, message, type]
+properties:
+  code:
+    type: integer
+    description: Internal error code.
+  message:
+    type: string
+    description: Human readable message.
+  type:
+    type: string
+    description: Error type.
+`
+
+// GetDocumentOpenAPI writes the config as an OpenAPI components YAML fragment, with a reusable Error schema
+// and one response per http code, named i.e. Error404, holding an example per error code.
+func GetDocumentOpenAPI(w io.Writer, config ErrorConfig, appname string) error {
+	var schema yaml.Node
+	if err := yaml.Unmarshal([]byte(openAPISchema), &schema); err != nil {
+		return err
+	}
+
+	// Responses are grouped by http code, examples sorted by error code
+	responses := &yaml.Node{Kind: yaml.MappingNode}
+	examples := map[int]*yaml.Node{}
+	var httpCodes []int
+	for _, code := range sortedCodes(config) {
 		info := config[code]
-		_, err = fmt.Fprintf(w, "|%d|%s|%s|%d|\n", code, info.ErrorType, info.Message, info.Http_code)
+		if _, ok := examples[info.Http_code]; !ok {
+			examples[info.Http_code] = &yaml.Node{Kind: yaml.MappingNode}
+			httpCodes = append(httpCodes, info.Http_code)
+		}
+
+		var example yaml.Node
+		err := example.Encode(map[string]any{
+			"summary": info.ErrorType,
+			"value":   documentEntry{Code: code, Type: info.ErrorType, Message: info.Message},
+		})
+		if err != nil {
+			return err
+		}
+		examples[info.Http_code].Content = append(examples[info.Http_code].Content,
+			scalarNode(strconv.Itoa(int(code))), &example)
+	}
+	sort.Ints(httpCodes)
+
+	for _, httpCode := range httpCodes {
+		var response yaml.Node
+		err := response.Encode(map[string]any{
+			"description": fmt.Sprintf("%s errors of %s.", http.StatusText(httpCode), appname),
+			"content": map[string]any{
+				"application/json": map[string]any{
+					"schema":   map[string]string{"$ref": "#/components/schemas/Error"},
+					"examples": examples[httpCode],
+				},
+			},
+		})
 		if err != nil {
 			return err
 		}
+		responses.Content = append(responses.Content, scalarNode("Error"+strconv.Itoa(httpCode)), &response)
+	}
 
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err := encoder.Encode(map[string]any{
+		"components": map[string]any{
+			"schemas":   map[string]any{"Error": schema.Content[0]},
+			"responses": responses,
+		},
+	})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return encoder.Close()
+}
+
+// scalarNode creates a YAML string node.
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
 }
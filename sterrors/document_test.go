@@ -0,0 +1,48 @@
+package sterrors
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// docConfig is the catalog of the golden document files.
+var docConfig = ErrorConfig{
+	1001: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+	999:  {ErrorType: "BadRequest", Message: "invalid: request", Http_code: 400},
+	1002: {ErrorType: "UserNotFound", Message: "user not found", Http_code: 404},
+	2001: {ErrorType: "RateLimited", Message: "too many requests", Http_code: 429, Severity: SeverityInfo},
+	3001: {ErrorType: "Internal", Message: "database error", Http_code: 500},
+}
+
+func TestGetDocument(t *testing.T) {
+	tests := []struct {
+		golden   string
+		generate func(w *bytes.Buffer) error
+	}{
+		{
+			golden:   "errors.md.golden",
+			generate: func(w *bytes.Buffer) error { return GetDocumentMd(w, docConfig, "quotes") },
+		},
+		{
+			golden:   "errors.json.golden",
+			generate: func(w *bytes.Buffer) error { return GetDocumentJSON(w, docConfig) },
+		},
+		{
+			golden:   "errors.openapi.yaml.golden",
+			generate: func(w *bytes.Buffer) error { return GetDocumentOpenAPI(w, docConfig, "quotes") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.generate(&buf); err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+
+			stmocks.Golden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
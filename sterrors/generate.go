@@ -0,0 +1,123 @@
+package sterrors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"text/template"
+)
+
+// generatedTemplate is the source written by GenerateConstants.
+var generatedTemplate = template.Must(template.New("constants").Parse(`// Code generated by sterrors.GenerateConstants; DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/stocktwits/go-infrastructure/v2/sterrors"
+
+const (
+{{- range .Constants}}
+	// {{.Name}} is the code of the error {{printf "%q" .Message}}.
+	{{.Name}} sterrors.ErrorCode = {{.Code}}
+{{- end}}
+)
+
+// errorCodes lists the generated constants.
+var errorCodes = []sterrors.ErrorCode{
+{{- range .Constants}}
+	{{.Name}},
+{{- end}}
+}
+
+// CheckErrorCodes returns an error listing the generated constants missing from config. Call it at init time
+// with the config the constants were generated from, so a mismatch fails at startup:
+//
+//	func init() {
+//		if err := CheckErrorCodes(config); err != nil {
+//			panic(err)
+//		}
+//	}
+func CheckErrorCodes(config sterrors.ErrorConfig) error {
+	return sterrors.CheckCodes(config, errorCodes...)
+}
+`))
+
+// generatedConstant is a constant of the source written by GenerateConstants.
+type generatedConstant struct {
+	Name    string
+	Code    ErrorCode
+	Message string
+}
+
+// GenerateConstants writes a gofmt'd Go file of the package declaring an ErrorCode constant for each entry
+// of names, sorted by code, and a CheckErrorCodes function asserting that every constant exists in a config.
+// It is meant to be called from a program run by go:generate.
+// Names must be distinct valid Go identifiers and their codes must exist in config; all the problems are
+// reported at once.
+func GenerateConstants(w io.Writer, config ErrorConfig, packageName string, names map[ErrorCode]string) error {
+	var errs []error
+	if !token.IsIdentifier(packageName) {
+		errs = append(errs, fmt.Errorf("invalid package name %q", packageName))
+	}
+
+	constants := make([]generatedConstant, 0, len(names))
+	codesByName := make(map[string]ErrorCode, len(names))
+	for code, name := range names {
+		constants = append(constants, generatedConstant{Name: name, Code: code, Message: config[code].Message})
+	}
+	sort.Slice(constants, func(i, j int) bool { return constants[i].Code < constants[j].Code })
+
+	for _, constant := range constants {
+		if !token.IsIdentifier(constant.Name) {
+			errs = append(errs, fmt.Errorf("code %d: invalid name %q", constant.Code, constant.Name))
+		}
+		if other, ok := codesByName[constant.Name]; ok {
+			errs = append(errs, fmt.Errorf("code %d: name %s already used by code %d", constant.Code, constant.Name, other))
+		}
+		codesByName[constant.Name] = constant.Code
+
+		if _, ok := config[constant.Code]; !ok {
+			errs = append(errs, fmt.Errorf("code %d: not in config", constant.Code))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid error code constants: %w", errors.Join(errs...))
+	}
+
+	var buf bytes.Buffer
+	err := generatedTemplate.Execute(&buf, struct {
+		Package   string
+		Constants []generatedConstant
+	}{packageName, constants})
+	if err != nil {
+		return fmt.Errorf("failed to generate constants: %w", err)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format constants: %w", err)
+	}
+
+	_, err = w.Write(source)
+	return err
+}
+
+// CheckCodes returns an error listing the codes missing from config. It is used by the code generated
+// by GenerateConstants.
+func CheckCodes(config ErrorConfig, codes ...ErrorCode) error {
+	var missing []ErrorCode
+	for _, code := range codes {
+		if _, ok := config[code]; !ok {
+			missing = append(missing, code)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("error codes %v missing from config", missing)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package sterrors
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestGenerateConstants(t *testing.T) {
+	names := map[ErrorCode]string{
+		1001: "ErrSymbolNotFound",
+		999:  "ErrBadRequest",
+		2001: "ErrRateLimited",
+	}
+
+	var buf bytes.Buffer
+	if err := GenerateConstants(&buf, docConfig, "quotes", names); err != nil {
+		t.Fatalf("GenerateConstants() unexpected error = %v", err)
+	}
+
+	stmocks.Golden(t, "constants.go.golden", buf.Bytes())
+
+	file, err := parser.ParseFile(token.NewFileSet(), "constants.go", buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		t.Fatalf("generated source does not parse: %v", err)
+	}
+	if file.Name.Name != "quotes" {
+		t.Errorf("generated package = %s, want quotes", file.Name.Name)
+	}
+
+	var constants []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if decl, ok := n.(*ast.GenDecl); ok && decl.Tok == token.CONST {
+			for _, spec := range decl.Specs {
+				constants = append(constants, spec.(*ast.ValueSpec).Names[0].Name)
+			}
+		}
+		return true
+	})
+	if got := strings.Join(constants, ","); got != "ErrBadRequest,ErrSymbolNotFound,ErrRateLimited" {
+		t.Errorf("generated constants = %s, want sorted by code", got)
+	}
+}
+
+func TestGenerateConstantsInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageName string
+		names       map[ErrorCode]string
+		wantErrs    []string
+	}{
+		{
+			name:        "duplicate name",
+			packageName: "quotes",
+			names:       map[ErrorCode]string{1001: "ErrNotFound", 1002: "ErrNotFound"},
+			wantErrs:    []string{"code 1002: name ErrNotFound already used by code 1001"},
+		},
+		{
+			name:        "invalid names",
+			packageName: "quotes",
+			names:       map[ErrorCode]string{999: "1Err", 1001: "Err-NotFound", 1002: "func", 2001: ""},
+			wantErrs: []string{
+				`code 999: invalid name "1Err"`,
+				`code 1001: invalid name "Err-NotFound"`,
+				`code 1002: invalid name "func"`,
+				`code 2001: invalid name ""`,
+			},
+		},
+		{
+			name:        "unknown code and package",
+			packageName: "my-quotes",
+			names:       map[ErrorCode]string{4242: "ErrUnknown"},
+			wantErrs:    []string{`invalid package name "my-quotes"`, "code 4242: not in config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := GenerateConstants(&buf, docConfig, tt.packageName, tt.names)
+			if err == nil {
+				t.Fatalf("GenerateConstants() expected error, wrote:\n%s", buf.String())
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("GenerateConstants() error = %q, want it to contain %q", err, want)
+				}
+			}
+			if buf.Len() > 0 {
+				t.Errorf("GenerateConstants() wrote %d bytes on error", buf.Len())
+			}
+		})
+	}
+}
+
+func TestCheckCodes(t *testing.T) {
+	if err := CheckCodes(docConfig, 999, 1001); err != nil {
+		t.Errorf("CheckCodes() unexpected error = %v", err)
+	}
+	if err := CheckCodes(docConfig, 999, 4242, 4343); err == nil || err.Error() != "error codes [4242 4343] missing from config" {
+		t.Errorf("CheckCodes() error = %v, want codes 4242 and 4343 missing", err)
+	}
+}
@@ -0,0 +1,139 @@
+package sterrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcDomain is the domain of the ErrorInfo details attached to the statuses.
+const grpcDomain = "sterrors"
+
+// Metadata keys of the ErrorInfo details.
+const (
+	grpcCodeKey     = "code"
+	grpcMessageKey  = "message"
+	grpcHttpCodeKey = "http_code"
+)
+
+// grpcCodes maps the http status codes to gRPC codes. Other 5xx codes map to codes.Internal.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:      codes.InvalidArgument,
+	http.StatusUnauthorized:    codes.Unauthenticated,
+	http.StatusForbidden:       codes.PermissionDenied,
+	http.StatusNotFound:        codes.NotFound,
+	http.StatusConflict:        codes.Aborted,
+	http.StatusTooManyRequests: codes.ResourceExhausted,
+}
+
+// ToGRPCStatus converts err into a gRPC status. An *Error in the chain of err gets the gRPC code matching its Http_code
+// and an errdetails.ErrorInfo detail holding its code, message and http code, the internal error is not included.
+// Other errors are converted using status.Convert. It returns nil if err is nil.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var stErr *Error
+	if !errors.As(err, &stErr) {
+		return status.Convert(err)
+	}
+
+	st := status.New(grpcCode(stErr.status()), stErr.Message)
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: stErr.Type,
+		Domain: grpcDomain,
+		Metadata: map[string]string{
+			grpcCodeKey:     strconv.Itoa(int(stErr.Code)),
+			grpcMessageKey:  stErr.Message,
+			grpcHttpCodeKey: strconv.Itoa(stErr.status()),
+		},
+	})
+	if detailsErr != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// FromGRPCStatus converts a gRPC status back into an *Error, using the ErrorInfo detail attached by ToGRPCStatus.
+// Statuses without the detail get the http code matching their gRPC code and their message.
+// It returns nil if st is nil or OK.
+func FromGRPCStatus(st *status.Status) *Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != grpcDomain {
+			continue
+		}
+
+		metadata := info.GetMetadata()
+		code, _ := strconv.Atoi(metadata[grpcCodeKey])
+		httpCode, err := strconv.Atoi(metadata[grpcHttpCodeKey])
+		if err != nil {
+			httpCode = httpCodeFromGRPC(st.Code())
+		}
+
+		return &Error{
+			Code:      ErrorCode(code),
+			Message:   metadata[grpcMessageKey],
+			Http_code: httpCode,
+			Type:      info.GetReason(),
+		}
+	}
+
+	return &Error{
+		Message:   st.Message(),
+		Http_code: httpCodeFromGRPC(st.Code()),
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor converting the errors returned by the handlers into gRPC statuses
+// using ToGRPCStatus. Errors that are neither an *Error nor a gRPC status are converted as the default error
+// of the factory.
+func UnaryServerInterceptor(factory *ErrorFactory) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		if _, ok := status.FromError(err); ok {
+			return resp, err
+		}
+
+		return resp, ToGRPCStatus(factory.asError(err)).Err()
+	}
+}
+
+// grpcCode returns the gRPC code matching an http status code.
+func grpcCode(httpCode int) codes.Code {
+	if code, ok := grpcCodes[httpCode]; ok {
+		return code
+	}
+	if httpCode >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+
+	return codes.Unknown
+}
+
+// httpCodeFromGRPC returns the http status code matching a gRPC code.
+func httpCodeFromGRPC(code codes.Code) int {
+	for httpCode, c := range grpcCodes {
+		if c == code {
+			return httpCode
+		}
+	}
+
+	return http.StatusInternalServerError
+}
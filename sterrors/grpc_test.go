@@ -0,0 +1,140 @@
+package sterrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	tests := []struct {
+		httpCode int
+		want     codes.Code
+	}{
+		{400, codes.InvalidArgument},
+		{401, codes.Unauthenticated},
+		{403, codes.PermissionDenied},
+		{404, codes.NotFound},
+		{409, codes.Aborted},
+		{429, codes.ResourceExhausted},
+		{500, codes.Internal},
+		{503, codes.Internal},
+		{418, codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.httpCode), func(t *testing.T) {
+			factory := NewFactory(ErrorConfig{
+				2001: {ErrorType: "TestError", Message: "test error", Http_code: tt.httpCode},
+			}, "internal error", 500)
+			err := fmt.Errorf("handler: %w", factory.NewError(2001, errors.New("internal cause")))
+
+			st := ToGRPCStatus(err)
+			if st.Code() != tt.want {
+				t.Errorf("ToGRPCStatus() code = %v, want %v", st.Code(), tt.want)
+			}
+			if st.Message() != "test error" {
+				t.Errorf("ToGRPCStatus() message = %q, want %q", st.Message(), "test error")
+			}
+
+			// The status goes through the wire format, as a client would receive it
+			got := FromGRPCStatus(status.FromProto(st.Proto()))
+			want := &Error{Code: 2001, Message: "test error", Http_code: tt.httpCode, Type: "TestError"}
+			if got.Code != want.Code || got.Message != want.Message || got.Http_code != want.Http_code ||
+				got.Type != want.Type || got.Err != nil {
+				t.Errorf("FromGRPCStatus() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestToGRPCStatusOtherErrors(t *testing.T) {
+	if st := ToGRPCStatus(nil); st != nil {
+		t.Errorf("ToGRPCStatus(nil) = %v, want nil", st)
+	}
+
+	st := ToGRPCStatus(status.Error(codes.Unavailable, "try later"))
+	if st.Code() != codes.Unavailable || st.Message() != "try later" {
+		t.Errorf("ToGRPCStatus() = %v, want the gRPC status unchanged", st)
+	}
+
+	got := FromGRPCStatus(st)
+	if got.Code != 0 || got.Http_code != 500 || got.Message != "try later" {
+		t.Errorf("FromGRPCStatus() = %+v, want code 0, http code 500 and the status message", got)
+	}
+
+	got = FromGRPCStatus(status.New(codes.NotFound, "missing"))
+	if got.Http_code != 404 {
+		t.Errorf("FromGRPCStatus() http code = %d, want 404", got.Http_code)
+	}
+
+	if got := FromGRPCStatus(status.New(codes.OK, "")); got != nil {
+		t.Errorf("FromGRPCStatus(OK) = %+v, want nil", got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	factory := newTestFactory()
+	interceptor := UnaryServerInterceptor(factory)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name: "nil error",
+		},
+		{
+			name:        "factory error",
+			err:         factory.NewErrorf(1001, nil, "%s", "AAPL"),
+			wantCode:    codes.NotFound,
+			wantMessage: "symbol not found: AAPL",
+		},
+		{
+			name:        "gRPC status",
+			err:         status.Error(codes.Unavailable, "try later"),
+			wantCode:    codes.Unavailable,
+			wantMessage: "try later",
+		},
+		{
+			name:        "unknown error",
+			err:         errors.New("connection refused"),
+			wantCode:    codes.Internal,
+			wantMessage: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(context.Context, any) (any, error) {
+				return "response", tt.err
+			}
+
+			resp, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{}, handler)
+			if resp != "response" {
+				t.Errorf("interceptor() response = %v, want the handler response", resp)
+			}
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("interceptor() unexpected error = %v", err)
+				}
+				return
+			}
+
+			st, ok := status.FromError(err)
+			if !ok {
+				t.Fatalf("interceptor() error = %v, want a gRPC status", err)
+			}
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMessage {
+				t.Errorf("interceptor() status = %v %q, want %v %q", st.Code(), st.Message(), tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package sterrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// errorBody is the JSON body of an API error response.
+type errorBody struct {
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Type      string    `json:"type"`
+	Internal  string    `json:"internal,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// MarshalJSON encodes the error as an API response body: {"code": 1001, "message": "...", "type": "..."}.
+// The message is the ClientMessage, localized for the locale of errors created using NewErrorLocalized.
+// The text of the internal error is only included as "internal" when IncludeInternal is set, redacted and
+// truncated as configured with RedactPatterns and TruncateCause, and the request id as "request_id" when
+// written by WriteHTTPContext or Handler.
+func (s *Error) MarshalJSON() ([]byte, error) {
+	body := errorBody{
+		Code:      s.ErrorCode(),
+		Message:   s.ClientMessage(),
+		Type:      s.Type,
+		RequestID: s.requestID,
+	}
+	if s.IncludeInternal && s.Cause() != nil {
+		body.Internal = s.causeText()
+	}
+
+	return json.Marshal(body)
+}
+
+// WriteHTTP writes err as a JSON response, with the status set from its Http_code.
+// A *ValidationError is written with all its entries. Errors that are not an *Error are written with
+// the default message and http code of the factory.
+func (e *ErrorFactory) WriteHTTP(w http.ResponseWriter, err error) {
+	e.WriteHTTPContext(context.Background(), w, err)
+}
+
+// WriteHTTPContext writes err like WriteHTTP, adding the transaction id of the stlogs logger info linked to ctx,
+// if any, as the "request_id" field of the body and the X-Request-ID header.
+func (e *ErrorFactory) WriteHTTPContext(ctx context.Context, w http.ResponseWriter, err error) {
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		writeValidation(ctx, w, vErr)
+		return
+	}
+
+	writeError(ctx, w, e.asError(err))
+}
+
+// Handler returns an http.Handler calling f and writing the error it returns using the factory, as WriteHTTPContext
+// does with the request context.
+// Errors with a 5xx status are logged with their code and wrapped cause, unless logger is nil. A panic in f is logged
+// with its stack and written as the default error of the factory. Errors returned or panics raised after f started
+// writing its response are still logged, but the error is not written over the partial response.
+func Handler(f func(http.ResponseWriter, *http.Request) error, factory *ErrorFactory, logger stlogs.Logger) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := &headerWriter{ResponseWriter: rw}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			stErr := factory.asError(fmt.Errorf("panic: %v", rec))
+			if logger != nil {
+				logger.WithData("code", stErr.Code).
+					WithData("stack", string(debug.Stack())).
+					WithError(stErr.Err).
+					Errorf("%s %s: %s", r.Method, r.URL.Path, stErr.Message)
+			}
+			if !w.wroteHeader {
+				writeError(r.Context(), w, stErr)
+			}
+		}()
+
+		err := f(w, r)
+		if err == nil {
+			return
+		}
+
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			if !w.wroteHeader {
+				writeValidation(r.Context(), w, vErr)
+			}
+			return
+		}
+
+		stErr := factory.asError(err)
+		if logger != nil && stErr.status() >= http.StatusInternalServerError {
+			entry := logger.WithData("code", stErr.Code).WithError(err)
+			if stErr.Err != nil {
+				entry.AddData("cause", stErr.Err.Error())
+			}
+			entry.Errorf("%s %s: %s", r.Method, r.URL.Path, stErr.Message)
+		}
+		if !w.wroteHeader {
+			writeError(r.Context(), w, stErr)
+		}
+	})
+}
+
+// headerWriter records whether the headers of a response were written.
+type headerWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *headerWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *headerWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches it.
+func (w *headerWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// asError returns the *Error in the chain of err, or a default error of the factory wrapping err.
+func (e *ErrorFactory) asError(err error) *Error {
+	var stErr *Error
+	if errors.As(err, &stErr) {
+		return stErr
+	}
+
+	return &Error{
+		Err:             err,
+		Message:         e.defaultMessage,
+		Http_code:       e.defaultHttpCode,
+		IncludeInternal: e.includeInternal,
+		redactPatterns:  e.redactPatterns,
+		maxCauseLength:  e.maxCauseLength,
+	}
+}
+
+// status returns the http status of the error, 500 if it is not set.
+func (s *Error) status() int {
+	return httpStatus(s.Http_code)
+}
+
+// httpStatus returns the http status written for an http code, 500 if it is not set.
+func httpStatus(httpCode int) int {
+	if httpCode == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return httpCode
+}
+
+// writeError writes the JSON body of the error with its status, its Retry-After header if it has a hint,
+// and the request id of ctx.
+func writeError(ctx context.Context, w http.ResponseWriter, stErr *Error) {
+	// The error is copied so the request id is not kept by an error that may be shared
+	withID := *stErr
+	withID.requestID = stlogs.TxID(ctx)
+	writeJSON(w, &withID, withID.status(), withID.retryAfter, withID.requestID)
+}
+
+// writeValidation writes the JSON body of the validation error with its status and the request id of ctx.
+func writeValidation(ctx context.Context, w http.ResponseWriter, vErr *ValidationError) {
+	withID := *vErr
+	withID.requestID = stlogs.TxID(ctx)
+	writeJSON(w, &withID, withID.status(), 0, withID.requestID)
+}
+
+// writeJSON writes the JSON body with the status, a Retry-After header if retryAfter is set and
+// an X-Request-ID header if requestID is set.
+func writeJSON(w http.ResponseWriter, v any, status int, retryAfter time.Duration, requestID string) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if requestID != "" {
+		w.Header().Set("X-Request-ID", requestID)
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
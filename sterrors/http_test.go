@@ -0,0 +1,322 @@
+package sterrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             func(f *ErrorFactory) error
+		includeInternal bool
+		wantStatus      int
+		wantBody        string
+	}{
+		{
+			name:       "factory error",
+			err:        func(f *ErrorFactory) error { return f.NewError(1001, errors.New("no rows")) },
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":1001,"message":"symbol not found","type":"NotFound"}`,
+		},
+		{
+			name: "wrapped factory error",
+			err: func(f *ErrorFactory) error {
+				return fmt.Errorf("get symbol: %w", f.NewErrorf(1002, nil, "limit %d", -1))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"code":1002,"message":"invalid request: limit -1","type":"BadRequest"}`,
+		},
+		{
+			name:       "plain error",
+			err:        func(*ErrorFactory) error { return errors.New("connection refused") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":0,"message":"internal error","type":""}`,
+		},
+		{
+			name:            "factory error with internal",
+			err:             func(f *ErrorFactory) error { return f.NewError(1001, errors.New("no rows")) },
+			includeInternal: true,
+			wantStatus:      http.StatusNotFound,
+			wantBody:        `{"code":1001,"message":"symbol not found","type":"NotFound","internal":"no rows"}`,
+		},
+		{
+			name:            "plain error with internal",
+			err:             func(*ErrorFactory) error { return errors.New("connection refused") },
+			includeInternal: true,
+			wantStatus:      http.StatusInternalServerError,
+			wantBody:        `{"code":0,"message":"internal error","type":"","internal":"connection refused"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := newTestFactory()
+			factory.SetIncludeInternal(tt.includeInternal)
+
+			rec := httptest.NewRecorder()
+			factory.WriteHTTP(rec, tt.err(factory))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("WriteHTTP() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("WriteHTTP() Content-Type = %q, want application/json", got)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("WriteHTTP() body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    func(f *ErrorFactory) func(http.ResponseWriter, *http.Request) error
+		wantStatus int
+		wantBody   string
+		wantLog    string
+		wantData   map[string]any
+		wantStack  bool
+	}{
+		{
+			name: "nil error",
+			handler: func(*ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(w http.ResponseWriter, _ *http.Request) error {
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte("created"))
+					return nil
+				}
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   "created",
+		},
+		{
+			name: "factory error",
+			handler: func(f *ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(http.ResponseWriter, *http.Request) error {
+					return f.NewErrorf(1001, nil, "%s", "AAPL")
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":1001,"message":"symbol not found: AAPL","type":"NotFound"}`,
+		},
+		{
+			name: "factory 5xx error",
+			handler: func(f *ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(http.ResponseWriter, *http.Request) error {
+					return fmt.Errorf("get quote: %w", f.NewError(1003, errors.New("timeout")))
+				}
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"code":1003,"message":"quotes unavailable","type":"Unavailable"}`,
+			wantLog:    "GET /quotes: quotes unavailable",
+			wantData:   map[string]any{"code": ErrorCode(1003), "cause": "timeout"},
+		},
+		{
+			name: "unknown error",
+			handler: func(*ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(http.ResponseWriter, *http.Request) error {
+					return errors.New("connection refused")
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":0,"message":"internal error","type":""}`,
+			wantLog:    "GET /quotes: internal error",
+			wantData:   map[string]any{"code": ErrorCode(0), "cause": "connection refused"},
+		},
+		{
+			name: "panic",
+			handler: func(*ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(http.ResponseWriter, *http.Request) error {
+					panic("nil map")
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":0,"message":"internal error","type":""}`,
+			wantLog:    "GET /quotes: internal error",
+			wantData:   map[string]any{"error": "panic: nil map"},
+			wantStack:  true,
+		},
+		{
+			name: "error after partial response",
+			handler: func(*ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(w http.ResponseWriter, _ *http.Request) error {
+					_, _ = w.Write([]byte(`{"quotes":[`))
+					return errors.New("connection reset")
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"quotes":[`,
+			wantLog:    "GET /quotes: internal error",
+			wantData:   map[string]any{"cause": "connection reset"},
+		},
+		{
+			name: "panic after partial response",
+			handler: func(*ErrorFactory) func(http.ResponseWriter, *http.Request) error {
+				return func(w http.ResponseWriter, _ *http.Request) error {
+					w.WriteHeader(http.StatusAccepted)
+					panic("nil map")
+				}
+			},
+			wantStatus: http.StatusAccepted,
+			wantLog:    "GET /quotes: internal error",
+			wantData:   map[string]any{"error": "panic: nil map"},
+			wantStack:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory(ErrorConfig{
+				1001: testConfig[1001],
+				1003: {ErrorType: "Unavailable", Message: "quotes unavailable", Http_code: 503},
+			}, "internal error", 500)
+			logger := stmocks.NewLogger()
+
+			rec := httptest.NewRecorder()
+			Handler(tt.handler(factory), factory, logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Handler() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("Handler() body = %s, want %s", got, tt.wantBody)
+			}
+
+			entries := logger.Entries()
+			if tt.wantLog == "" {
+				if len(entries) != 0 {
+					t.Errorf("Handler() logged %v, want nothing", entries)
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0].Level != stlogs.ERROR || entries[0].Msg != tt.wantLog {
+				t.Fatalf("Handler() logged %v, want an error %q", entries, tt.wantLog)
+			}
+			for key, want := range tt.wantData {
+				if got := entries[0].Data[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("Handler() logged data[%s] = %v, want %v", key, got, want)
+				}
+			}
+			if stack, _ := entries[0].Data["stack"].(string); tt.wantStack != strings.HasPrefix(stack, "goroutine") {
+				t.Errorf("Handler() logged stack %q, want stack %v", stack, tt.wantStack)
+			}
+		})
+	}
+}
+
+func TestHandlerNilLogger(t *testing.T) {
+	factory := newTestFactory()
+	handlers := map[string]func(http.ResponseWriter, *http.Request) error{
+		"error": func(http.ResponseWriter, *http.Request) error { return errors.New("connection refused") },
+		"panic": func(http.ResponseWriter, *http.Request) error { panic("nil map") },
+	}
+
+	for name, f := range handlers {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler(f, factory, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("Handler() status = %d, want %d", rec.Code, http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+func TestWriteHTTPRequestID(t *testing.T) {
+	factory := newTestFactory()
+	_, ctx := stlogs.NewLocal("sterrors-test").NewWithContext(context.Background())
+	txID := stlogs.TxID(ctx)
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		err            error
+		wantStatus     int
+		wantBody       string
+		wantRetryAfter string
+	}{
+		{
+			name:           "error with request id and retry hint",
+			ctx:            ctx,
+			err:            factory.NewErrorf(1002, nil, "").RetryAfter(30 * time.Second),
+			wantStatus:     http.StatusBadRequest,
+			wantBody:       `{"code":1002,"message":"invalid request","type":"BadRequest","request_id":"` + txID + `"}`,
+			wantRetryAfter: "30",
+		},
+		{
+			name:       "plain error with request id",
+			ctx:        ctx,
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":0,"message":"internal error","type":"","request_id":"` + txID + `"}`,
+		},
+		{
+			name:       "validation error with request id",
+			ctx:        ctx,
+			err:        factory.NewValidation(1002).Add("limit", 1002, nil),
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{"code":1002,"message":"invalid request","type":"BadRequest",` +
+				`"errors":[{"field":"limit","code":1002,"message":"invalid request"}],"request_id":"` + txID + `"}`,
+		},
+		{
+			name:       "context without logger info",
+			ctx:        context.Background(),
+			err:        factory.NewError(1001, nil),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":1001,"message":"symbol not found","type":"NotFound"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// WriteHTTPContext and Handler with the request context write the same response
+			handler := Handler(func(http.ResponseWriter, *http.Request) error { return tt.err }, factory, stmocks.NewLogger())
+			writers := map[string]func(w http.ResponseWriter){
+				"WriteHTTPContext": func(w http.ResponseWriter) { factory.WriteHTTPContext(tt.ctx, w, tt.err) },
+				"Handler": func(w http.ResponseWriter) {
+					handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes", nil).WithContext(tt.ctx))
+				},
+			}
+
+			for name, write := range writers {
+				rec := httptest.NewRecorder()
+				write(rec)
+
+				if rec.Code != tt.wantStatus {
+					t.Errorf("%s() status = %d, want %d", name, rec.Code, tt.wantStatus)
+				}
+				if got := rec.Body.String(); got != tt.wantBody {
+					t.Errorf("%s() body = %s, want %s", name, got, tt.wantBody)
+				}
+				if got, want := rec.Header().Get("X-Request-ID"), stlogs.TxID(tt.ctx); got != want {
+					t.Errorf("%s() X-Request-ID = %q, want %q", name, got, want)
+				}
+				if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+					t.Errorf("%s() Retry-After = %q, want %q", name, got, tt.wantRetryAfter)
+				}
+			}
+		})
+	}
+
+	// The request id is not kept by the written error
+	err := factory.NewError(1001, nil)
+	factory.WriteHTTPContext(ctx, httptest.NewRecorder(), err)
+	if body, _ := json.Marshal(err); strings.Contains(string(body), "request_id") {
+		t.Errorf("written error kept the request id: %s", body)
+	}
+}
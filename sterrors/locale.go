@@ -0,0 +1,49 @@
+package sterrors
+
+import "strings"
+
+// NewErrorLocalized creates an error like NewError, whose JSON body holds the message of the code
+// translated for the locale, as returned by LocalizedMessage.
+func (e *ErrorFactory) NewErrorLocalized(code ErrorCode, err error, locale string) *Error {
+	stErr := e.newError(code, err, e.getMessage(code))
+	stErr.locale = locale
+
+	return stErr
+}
+
+// LocalizedMessage returns the message of the error translated for the BCP-47 locale, i.e. "es-MX".
+// Without translation for the locale, it falls back to its base language ("es"), then to Message.
+// Tags are matched case-insensitively and "_" is accepted as separator.
+func (s *Error) LocalizedMessage(locale string) string {
+	if locale == "" || len(s.messages) == 0 {
+		return s.Message
+	}
+
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if message, ok := lookupMessage(s.messages, locale); ok {
+		return message
+	}
+
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if message, ok := lookupMessage(s.messages, base); ok {
+			return message
+		}
+	}
+
+	return s.Message
+}
+
+// lookupMessage returns the message of the locale, ignoring the case of the tags.
+func lookupMessage(messages map[string]string, locale string) (string, bool) {
+	if message, ok := messages[locale]; ok {
+		return message, true
+	}
+
+	for tag, message := range messages {
+		if strings.EqualFold(strings.ReplaceAll(tag, "_", "-"), locale) {
+			return message, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,60 @@
+package sterrors
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocalizedMessage(t *testing.T) {
+	factory := NewFactory(ErrorConfig{
+		5001: {
+			ErrorType: "NotFound",
+			Message:   "symbol not found",
+			Http_code: 404,
+			Messages: map[string]string{
+				"es":    "símbolo no encontrado",
+				"es-AR": "no encontramos el símbolo",
+				"pt-BR": "símbolo não encontrado",
+			},
+		},
+		5002: {ErrorType: "BadRequest", Message: "invalid request", Http_code: 400},
+	}, "internal error", 500)
+
+	tests := []struct {
+		name   string
+		code   ErrorCode
+		locale string
+		want   string
+	}{
+		{"exact match", 5001, "es-AR", "no encontramos el símbolo"},
+		{"case and separator", 5001, "PT_br", "símbolo não encontrado"},
+		{"language fallback", 5001, "es-MX", "símbolo no encontrado"},
+		{"language only", 5001, "es", "símbolo no encontrado"},
+		{"missing locale", 5001, "fr-FR", "symbol not found"},
+		{"missing base language", 5001, "pt", "symbol not found"},
+		{"no locale", 5001, "", "symbol not found"},
+		{"no translations", 5002, "es", "invalid request"},
+		{"unknown code", 9999, "es", "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := factory.NewErrorLocalized(tt.code, nil, tt.locale)
+			if got := err.LocalizedMessage(tt.locale); got != tt.want {
+				t.Errorf("LocalizedMessage() = %q, want %q", got, tt.want)
+			}
+
+			rec := httptest.NewRecorder()
+			factory.WriteHTTP(rec, err)
+			if !strings.Contains(rec.Body.String(), `"message":"`+tt.want+`"`) {
+				t.Errorf("WriteHTTP() body = %s, want message %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+
+	// Errors keep the default message in their logs
+	if got := factory.NewErrorLocalized(5001, nil, "es").Error(); !strings.Contains(got, "message: symbol not found") {
+		t.Errorf("Error() = %q, want the default message", got)
+	}
+}
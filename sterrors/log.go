@@ -0,0 +1,69 @@
+package sterrors
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// LogFields returns the structured fields describing err: error_code, error_type, http_code, message and
+// the cause wrapped by the *Error when err is or wraps an *Error, along with its namespace, details and stack
+// trace if set.
+// Other errors only get their text as cause.
+func LogFields(err error) map[string]interface{} {
+	if err == nil {
+		return nil
+	}
+
+	var stErr *Error
+	if !errors.As(err, &stErr) {
+		return map[string]interface{}{"cause": err.Error()}
+	}
+
+	fields := map[string]interface{}{
+		"error_code": stErr.Code,
+		"error_type": stErr.Type,
+		"http_code":  stErr.Http_code,
+		"message":    stErr.Message,
+	}
+	if stErr.Err != nil {
+		fields["cause"] = stErr.Err.Error()
+	}
+	if stErr.Namespace != "" {
+		fields["namespace"] = stErr.Namespace
+	}
+	if len(stErr.Details) > 0 {
+		fields["details"] = stErr.Details
+	}
+	if stack := stErr.StackTrace(); len(stack) > 0 {
+		fields["stack"] = stack
+	}
+
+	return fields
+}
+
+// Log logs err with the fields returned by LogFields, as a warning for 4xx errors and as an error otherwise,
+// including the errors that are not an *Error.
+func Log(logger stlogs.Logger, err error) {
+	if err == nil {
+		return
+	}
+
+	entry := logger.WithError(err)
+	for key, value := range LogFields(err) {
+		entry.AddData(key, value)
+	}
+
+	var stErr *Error
+	if errors.As(err, &stErr) && stErr.status() < http.StatusInternalServerError {
+		entry.Warnf("%s", stErr.Message)
+		return
+	}
+
+	message := err.Error()
+	if stErr != nil {
+		message = stErr.Message
+	}
+	entry.Errorf("%s", message)
+}
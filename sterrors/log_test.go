@@ -0,0 +1,89 @@
+package sterrors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+func TestLog(t *testing.T) {
+	factory := NewFactory(ErrorConfig{
+		1001: testConfig[1001],
+		1003: {ErrorType: "Unavailable", Message: "quotes unavailable", Http_code: 503},
+	}, "internal error", 500)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantLevel  stlogs.Level
+		wantFields map[string]any
+	}{
+		{
+			name:      "4xx error",
+			err:       factory.NewErrorf(1001, nil, "%s", "AAPL").WithDetail("user_id", 42),
+			wantLevel: stlogs.WARN,
+			wantFields: map[string]any{
+				"error_code": ErrorCode(1001),
+				"error_type": "NotFound",
+				"http_code":  404,
+				"message":    "symbol not found: AAPL",
+				"details":    map[string]any{"user_id": 42},
+			},
+		},
+		{
+			name:      "wrapped 5xx error",
+			err:       fmt.Errorf("get quote: %w", factory.NewError(1003, errors.New("timeout"))),
+			wantLevel: stlogs.ERROR,
+			wantFields: map[string]any{
+				"error_code": ErrorCode(1003),
+				"error_type": "Unavailable",
+				"http_code":  503,
+				"message":    "quotes unavailable",
+				"cause":      "timeout",
+			},
+		},
+		{
+			name:      "plain error",
+			err:       errors.New("connection refused"),
+			wantLevel: stlogs.ERROR,
+			wantFields: map[string]any{
+				"cause": "connection refused",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := stmocks.NewLogger()
+			Log(logger, tt.err)
+
+			entries := logger.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("Log() logged %v, want one entry", entries)
+			}
+			if entries[0].Level != tt.wantLevel {
+				t.Errorf("Log() level = %v, want %d", entries[0].Level, tt.wantLevel)
+			}
+
+			data := entries[0].Data
+			for key, want := range tt.wantFields {
+				if !reflect.DeepEqual(data[key], want) {
+					t.Errorf("Log() data[%s] = %v, want %v", key, data[key], want)
+				}
+			}
+			if data["error"] != tt.err.Error() {
+				t.Errorf("Log() data[error] = %v, want %q", data["error"], tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestLogFieldsNil(t *testing.T) {
+	if fields := LogFields(nil); fields != nil {
+		t.Errorf("LogFields(nil) = %v, want nil", fields)
+	}
+}
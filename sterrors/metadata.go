@@ -0,0 +1,84 @@
+package sterrors
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Common values of ErrorData.Severity.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// IsRetryable returns whether the error is safe to retry: the Retryable value configured for its code,
+// or when unset, true for 429 and 503 errors and errors with a RetryAfter hint.
+func (s *Error) IsRetryable() bool {
+	if s.retryable != nil {
+		return *s.retryable
+	}
+
+	return s.retryAfter > 0 || retryableHttpCode(s.status())
+}
+
+// Severity returns the severity configured for the code of the error, or when unset, SeverityError
+// for 5xx errors and SeverityWarning for others.
+func (s *Error) Severity() string {
+	return severity(s.severity, s.status())
+}
+
+// RetryAfter attaches a backoff hint to the error, written as the Retry-After header of its HTTP response,
+// and returns the error so calls can be chained.
+func (s *Error) RetryAfter(d time.Duration) *Error {
+	s.retryAfter = d
+	return s
+}
+
+// RetryAfterDelay returns the backoff hint attached using RetryAfter, zero if none.
+func (s *Error) RetryAfterDelay() time.Duration {
+	return s.retryAfter
+}
+
+// Timeout reports whether the wrapped error is a timeout, like a net.Error or context.DeadlineExceeded,
+// so generic retry code sees through the *Error. If no wrapped error implements Timeout, it returns the
+// Retryable value configured for the code, false if unset.
+func (s *Error) Timeout() bool {
+	var timeout interface{ Timeout() bool }
+	if errors.As(s.Err, &timeout) {
+		return timeout.Timeout()
+	}
+
+	return s.retryable != nil && *s.retryable
+}
+
+// Temporary reports whether the wrapped error is temporary, like a net.Error or context.DeadlineExceeded.
+// If no wrapped error implements Temporary, it returns the Retryable value configured for the code,
+// false if unset.
+func (s *Error) Temporary() bool {
+	var temporary interface{ Temporary() bool }
+	if errors.As(s.Err, &temporary) {
+		return temporary.Temporary()
+	}
+
+	return s.retryable != nil && *s.retryable
+}
+
+// retryableHttpCode returns whether errors with the http code are retryable when not configured.
+func retryableHttpCode(httpCode int) bool {
+	return httpCode == http.StatusTooManyRequests || httpCode == http.StatusServiceUnavailable
+}
+
+// severity returns the configured severity, or the severity matching the http code if empty.
+func severity(configured string, httpCode int) string {
+	if configured != "" {
+		return configured
+	}
+	if httpCode >= http.StatusInternalServerError {
+		return SeverityError
+	}
+
+	return SeverityWarning
+}
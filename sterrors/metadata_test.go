@@ -0,0 +1,201 @@
+package sterrors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorMetadata(t *testing.T) {
+	yes, no := true, false
+	factory := NewFactory(ErrorConfig{
+		4001: {ErrorType: "RateLimited", Message: "too many requests", Http_code: 429},
+		4002: {ErrorType: "Unavailable", Message: "quotes unavailable", Http_code: 503, Retryable: &no, Severity: SeverityCritical},
+		4003: {ErrorType: "Conflict", Message: "stale version", Http_code: 409, Retryable: &yes, Severity: SeverityInfo},
+		4004: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+		4005: {ErrorType: "Internal", Message: "database error", Http_code: 500},
+	}, "internal error", 500)
+
+	tests := []struct {
+		name          string
+		err           *Error
+		wantRetryable bool
+		wantSeverity  string
+	}{
+		{
+			name:          "heuristic 429",
+			err:           factory.NewErrorf(4001, nil, ""),
+			wantRetryable: true,
+			wantSeverity:  SeverityWarning,
+		},
+		{
+			name:         "explicit 503",
+			err:          factory.NewErrorf(4002, nil, ""),
+			wantSeverity: SeverityCritical,
+		},
+		{
+			name:          "explicit 409",
+			err:           factory.NewErrorParams(4003, nil, nil),
+			wantRetryable: true,
+			wantSeverity:  SeverityInfo,
+		},
+		{
+			name:         "heuristic 404",
+			err:          factory.NewErrorf(4004, nil, ""),
+			wantSeverity: SeverityWarning,
+		},
+		{
+			name:         "heuristic 500",
+			err:          factory.NewErrorf(4005, nil, ""),
+			wantSeverity: SeverityError,
+		},
+		{
+			name:          "retry after hint",
+			err:           factory.NewErrorf(4005, nil, "").RetryAfter(time.Second),
+			wantRetryable: true,
+			wantSeverity:  SeverityError,
+		},
+		{
+			name:         "unknown code",
+			err:          factory.NewErrorf(9999, nil, ""),
+			wantSeverity: SeverityError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.IsRetryable(); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := tt.err.Severity(); got != tt.wantSeverity {
+				t.Errorf("Severity() = %q, want %q", got, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	factory := newTestFactory()
+
+	rec := httptest.NewRecorder()
+	factory.WriteHTTP(rec, factory.NewErrorf(1002, nil, "").RetryAfter(1500*time.Millisecond))
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("WriteHTTP() Retry-After = %q, want %q", got, "2")
+	}
+
+	rec = httptest.NewRecorder()
+	factory.WriteHTTP(rec, factory.NewError(1002, nil))
+	if got := rec.Header().Get("Retry-After"); got != "" {
+		t.Errorf("WriteHTTP() Retry-After = %q, want none", got)
+	}
+}
+
+func TestGetDocumentMdMetadata(t *testing.T) {
+	no := false
+	config := ErrorConfig{
+		4001: {ErrorType: "RateLimited", Message: "too many requests", Http_code: 429},
+		4002: {ErrorType: "Unavailable", Message: "quotes unavailable", Http_code: 503, Retryable: &no, Severity: SeverityCritical},
+		4004: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+		5000: {ErrorType: "Internal", Message: "internal error"},
+	}
+
+	var buf bytes.Buffer
+	if err := GetDocumentMd(&buf, config, "quotes"); err != nil {
+		t.Fatalf("GetDocumentMd() unexpected error = %v", err)
+	}
+
+	want := "|Error Code|Type|Message|HTTP Code|Retryable|Severity|\n" +
+		"|:----------|:----------|:----------|:----------|:----------|:----------|\n" +
+		"|4001|RateLimited|too many requests|429|true|warning|\n" +
+		"|4002|Unavailable|quotes unavailable|503|false|critical|\n" +
+		"|4004|NotFound|symbol not found|404|false|warning|\n" +
+		"|5000|Internal|internal error|0|false|error|\n"
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("GetDocumentMd() = %q, want it to end with %q", buf.String(), want)
+	}
+}
+
+// fakeNetError is a net.Error reporting fixed timeout and temporary values.
+type fakeNetError struct {
+	timeout, temporary bool
+}
+
+func (e fakeNetError) Error() string   { return "i/o failure" }
+func (e fakeNetError) Timeout() bool   { return e.timeout }
+func (e fakeNetError) Temporary() bool { return e.temporary }
+
+var _ net.Error = fakeNetError{}
+
+func TestTimeoutTemporary(t *testing.T) {
+	yes, no := true, false
+	factory := NewFactory(ErrorConfig{
+		4001: {Message: "upstream failure", Http_code: 502, Retryable: &yes},
+		4002: {Message: "invalid upstream response", Http_code: 502, Retryable: &no},
+		4003: {Message: "quotes unavailable", Http_code: 503},
+	}, "internal error", 500)
+
+	tests := []struct {
+		name          string
+		err           *Error
+		wantTimeout   bool
+		wantTemporary bool
+	}{
+		{
+			name:          "net timeout",
+			err:           factory.NewErrorf(4002, fakeNetError{timeout: true, temporary: true}, ""),
+			wantTimeout:   true,
+			wantTemporary: true,
+		},
+		{
+			name: "net non temporary",
+			err:  factory.NewErrorf(4001, fakeNetError{}, ""),
+		},
+		{
+			name:          "wrapped deadline exceeded",
+			err:           factory.NewErrorf(4002, fmt.Errorf("get quote: %w", context.DeadlineExceeded), ""),
+			wantTimeout:   true,
+			wantTemporary: true,
+		},
+		{
+			name: "canceled",
+			err:  factory.NewErrorf(4002, context.Canceled, ""),
+		},
+		{
+			name:          "retryable metadata",
+			err:           factory.NewErrorf(4001, errors.New("connection reset"), ""),
+			wantTimeout:   true,
+			wantTemporary: true,
+		},
+		{
+			name: "not retryable metadata",
+			err:  factory.NewErrorf(4002, nil, ""),
+		},
+		{
+			name: "unset metadata",
+			err:  factory.NewErrorf(4003, nil, ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Timeout(); got != tt.wantTimeout {
+				t.Errorf("Timeout() = %v, want %v", got, tt.wantTimeout)
+			}
+			if got := tt.err.Temporary(); got != tt.wantTemporary {
+				t.Errorf("Temporary() = %v, want %v", got, tt.wantTemporary)
+			}
+
+			// Generic retry code checks the interfaces on wrapped errors
+			var timeout interface{ Timeout() bool }
+			if !errors.As(fmt.Errorf("handler: %w", tt.err), &timeout) || timeout.Timeout() != tt.wantTimeout {
+				t.Errorf("errors.As(Timeout) = %v, want %v", timeout, tt.wantTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package sterrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingParam is joined to the internal error of the errors created by NewErrorParams in strict mode
+// when the message has a placeholder without param.
+var ErrMissingParam = errors.New("missing message param")
+
+// SetStrictParams sets whether NewErrorParams reports the placeholders without param by joining
+// ErrMissingParam to the internal error. Placeholders without param are left intact in both modes.
+func (e *ErrorFactory) SetStrictParams(strict bool) {
+	e.strictParams = strict
+}
+
+// NewErrorParams creates an error like NewError, with the {name} placeholders of the configured message
+// replaced by the params, i.e. "symbol {symbol} not found" with the symbol param. Braces are escaped by
+// doubling them: "{{" and "}}" render as "{" and "}". The params are stored as the Details of the error.
+func (e *ErrorFactory) NewErrorParams(code ErrorCode, err error, params map[string]any) *Error {
+	message, missing := renderMessage(e.getMessage(code), params)
+	if len(missing) > 0 && e.strictParams {
+		err = errors.Join(err, fmt.Errorf("%w: %s", ErrMissingParam, strings.Join(missing, ", ")))
+	}
+
+	stErr := e.newError(code, err, message)
+	for key, value := range params {
+		stErr.WithDetail(key, value)
+	}
+
+	return stErr
+}
+
+// renderMessage replaces the placeholders of the template by the params. It returns the names of the
+// placeholders without param, which are left intact.
+func renderMessage(template string, params map[string]any) (string, []string) {
+	var b strings.Builder
+	var missing []string
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+
+		if c == '{' {
+			if end := strings.IndexByte(template[i+1:], '}'); end >= 0 {
+				name := template[i+1 : i+1+end]
+				if validParamName(name) {
+					if value, ok := params[name]; ok {
+						fmt.Fprint(&b, value)
+					} else {
+						b.WriteString(template[i : i+end+2])
+						missing = append(missing, name)
+					}
+					i += end + 1
+					continue
+				}
+			}
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String(), missing
+}
+
+// validParamName checks that a placeholder name is made of letters, digits and underscores,
+// and does not start with a digit.
+func validParamName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
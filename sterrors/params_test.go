@@ -0,0 +1,97 @@
+package sterrors
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewErrorParams(t *testing.T) {
+	config := ErrorConfig{
+		3001: {ErrorType: "NotFound", Message: "symbol {symbol} not found on {exchange}", Http_code: 404},
+		3002: {ErrorType: "BadRequest", Message: "use {{symbol}} or {{ {id} }}, not {} or {1x}", Http_code: 400},
+	}
+
+	tests := []struct {
+		name        string
+		code        ErrorCode
+		params      map[string]any
+		strict      bool
+		wantMessage string
+		wantMissing bool
+	}{
+		{
+			name:        "substitution",
+			code:        3001,
+			params:      map[string]any{"symbol": "AAPL", "exchange": "NASDAQ", "user_id": 42},
+			wantMessage: "symbol AAPL not found on NASDAQ",
+		},
+		{
+			name:        "missing param",
+			code:        3001,
+			params:      map[string]any{"symbol": "AAPL"},
+			wantMessage: "symbol AAPL not found on {exchange}",
+		},
+		{
+			name:        "missing param strict",
+			code:        3001,
+			params:      map[string]any{"symbol": "AAPL"},
+			strict:      true,
+			wantMessage: "symbol AAPL not found on {exchange}",
+			wantMissing: true,
+		},
+		{
+			name:        "escaped braces",
+			code:        3002,
+			params:      map[string]any{"symbol": "AAPL", "id": 7},
+			strict:      true,
+			wantMessage: "use {symbol} or { 7 }, not {} or {1x}",
+		},
+		{
+			name:        "unknown code",
+			code:        9999,
+			strict:      true,
+			wantMessage: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory(config, "internal error", 500)
+			factory.SetStrictParams(tt.strict)
+			cause := errors.New("no rows")
+
+			got := factory.NewErrorParams(tt.code, cause, tt.params)
+			if got.Message != tt.wantMessage {
+				t.Errorf("NewErrorParams() message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if !errors.Is(got.Err, cause) {
+				t.Errorf("NewErrorParams() Err = %v, want it to wrap %v", got.Err, cause)
+			}
+			if missing := errors.Is(got, ErrMissingParam); missing != tt.wantMissing {
+				t.Errorf("errors.Is(NewErrorParams(), ErrMissingParam) = %v, want %v", missing, tt.wantMissing)
+			}
+			if len(got.Details) != len(tt.params) {
+				t.Errorf("NewErrorParams() details = %v, want %v", got.Details, tt.params)
+			}
+			for key, value := range tt.params {
+				if got.Details[key] != value {
+					t.Errorf("NewErrorParams() details[%s] = %v, want %v", key, got.Details[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDocumentMdRawTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	config := ErrorConfig{3001: {ErrorType: "NotFound", Message: "symbol {symbol} not found", Http_code: 404}}
+	if err := GetDocumentMd(&buf, config, "quotes"); err != nil {
+		t.Fatalf("GetDocumentMd() unexpected error = %v", err)
+	}
+
+	if want := "|3001|NotFound|symbol {symbol} not found|404|"; !strings.Contains(buf.String(), want) {
+		t.Errorf("GetDocumentMd() = %q, want it to contain %q", buf.String(), want)
+	}
+}
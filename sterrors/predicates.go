@@ -0,0 +1,66 @@
+package sterrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTPStatus returns the http status written for err by WriteHTTP: the status of the *ValidationError or *Error
+// in its chain, or fallback if it holds neither.
+func HTTPStatus(err error, fallback int) int {
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		return vErr.status()
+	}
+
+	var stErr *Error
+	if errors.As(err, &stErr) {
+		return stErr.status()
+	}
+
+	return fallback
+}
+
+// IsClientError returns whether err holds an *Error or *ValidationError with a 4xx status.
+func IsClientError(err error) bool {
+	status := HTTPStatus(err, 0)
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError
+}
+
+// IsServerError returns whether err holds an *Error or *ValidationError with a 5xx status.
+func IsServerError(err error) bool {
+	status := HTTPStatus(err, 0)
+	return status >= http.StatusInternalServerError && status < 600
+}
+
+// IsCode returns whether err, or any error it wraps, is an *Error, *ValidationError or *FieldError with the code.
+// The entries of a ValidationError are checked too.
+func IsCode(err error, code ErrorCode) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case *Error:
+		return e.Code == code
+	case *ValidationError:
+		if e.Code == code {
+			return true
+		}
+	case *FieldError:
+		if e.Code == code {
+			return true
+		}
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return IsCode(u.Unwrap(), code)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range u.Unwrap() {
+			if IsCode(wrapped, code) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
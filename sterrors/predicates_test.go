@@ -0,0 +1,106 @@
+package sterrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPredicates(t *testing.T) {
+	factory := NewFactory(ErrorConfig{
+		1000: {ErrorType: "Validation", Message: "invalid request", Http_code: 422},
+		1001: testConfig[1001],
+		1003: {ErrorType: "Unavailable", Message: "quotes unavailable", Http_code: 503},
+		1004: {ErrorType: "Unset", Message: "no http code"},
+	}, "internal error", 500)
+
+	notFound := factory.NewError(1001, nil)
+	validation := factory.NewValidation(1000).
+		Add("symbol", 1004, nil).
+		AddError(factory.NewError(1003, nil))
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantClient  bool
+		wantServer  bool
+		wantCodes   []ErrorCode
+		wantNoCodes []ErrorCode
+	}{
+		{
+			name:        "unwrapped",
+			err:         notFound,
+			wantStatus:  http.StatusNotFound,
+			wantClient:  true,
+			wantCodes:   []ErrorCode{1001},
+			wantNoCodes: []ErrorCode{1003},
+		},
+		{
+			name:       "wrapped",
+			err:        fmt.Errorf("get quote: %w", fmt.Errorf("fetch: %w", factory.NewError(1003, errors.New("timeout")))),
+			wantStatus: http.StatusServiceUnavailable,
+			wantServer: true,
+			wantCodes:  []ErrorCode{1003},
+		},
+		{
+			name:       "no http code",
+			err:        factory.NewError(1004, nil),
+			wantStatus: http.StatusInternalServerError,
+			wantServer: true,
+			wantCodes:  []ErrorCode{1004},
+		},
+		{
+			name:        "validation",
+			err:         fmt.Errorf("create order: %w", validation),
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantClient:  true,
+			wantCodes:   []ErrorCode{1000, 1003, 1004},
+			wantNoCodes: []ErrorCode{1001},
+		},
+		{
+			name:        "joined",
+			err:         errors.Join(errors.New("first"), notFound),
+			wantStatus:  http.StatusNotFound,
+			wantClient:  true,
+			wantCodes:   []ErrorCode{1001},
+			wantNoCodes: []ErrorCode{0},
+		},
+		{
+			name:        "non-sterrors",
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusTeapot,
+			wantNoCodes: []ErrorCode{0, 1001},
+		},
+		{
+			name:        "nil",
+			wantStatus:  http.StatusTeapot,
+			wantNoCodes: []ErrorCode{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTTPStatus(tt.err, http.StatusTeapot); got != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.wantStatus)
+			}
+			if got := IsClientError(tt.err); got != tt.wantClient {
+				t.Errorf("IsClientError() = %v, want %v", got, tt.wantClient)
+			}
+			if got := IsServerError(tt.err); got != tt.wantServer {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.wantServer)
+			}
+			for _, code := range tt.wantCodes {
+				if !IsCode(tt.err, code) {
+					t.Errorf("IsCode(%d) = false, want true", code)
+				}
+			}
+			for _, code := range tt.wantNoCodes {
+				if IsCode(tt.err, code) {
+					t.Errorf("IsCode(%d) = true, want false", code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package sterrors
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// codeRange is the inclusive range of the codes of a factory.
+type codeRange struct {
+	min, max ErrorCode
+}
+
+func (r codeRange) contains(code ErrorCode) bool {
+	return code >= r.min && code <= r.max
+}
+
+func (r codeRange) overlaps(other codeRange) bool {
+	return r.min <= other.max && other.min <= r.max
+}
+
+func (r codeRange) String() string {
+	return fmt.Sprintf("[%d, %d]", r.min, r.max)
+}
+
+// NewFactoryWithRange creates a factory like NewFactory owning the codes from min to max included, so it
+// can be registered in a Registry. It returns an error listing the codes of the config outside the range.
+func NewFactoryWithRange(config ErrorConfig, defMsg string, defHttpCode int, min, max ErrorCode, opts ...FactoryOption) (*ErrorFactory, error) {
+	r := codeRange{min: min, max: max}
+	if min > max {
+		return nil, fmt.Errorf("invalid code range %s", r)
+	}
+
+	var outside []ErrorCode
+	for code := range config {
+		if !r.contains(code) {
+			outside = append(outside, code)
+		}
+	}
+	if len(outside) > 0 {
+		sort.Slice(outside, func(i, j int) bool { return outside[i] < outside[j] })
+		return nil, fmt.Errorf("error codes %v outside of range %s", outside, r)
+	}
+
+	factory := NewFactory(config, defMsg, defHttpCode, opts...)
+	factory.codeRange = &r
+
+	return factory, nil
+}
+
+// SetNamespace sets the namespace of the factory, prefixed to the codes of its errors in Error(),
+// i.e. "billing:1004".
+func (e *ErrorFactory) SetNamespace(namespace string) {
+	e.namespace = namespace
+}
+
+// Namespace returns the namespace of the factory.
+func (e *ErrorFactory) Namespace() string {
+	return e.namespace
+}
+
+// Registry holds factories owning distinct code ranges, so errors coming from several libraries
+// can be resolved to the factory that created them. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	factories []*ErrorFactory
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a factory created using NewFactoryWithRange. It returns an error if the factory has no range
+// or its range overlaps the range of a registered factory.
+func (r *Registry) Register(factory *ErrorFactory) error {
+	if factory.codeRange == nil {
+		return fmt.Errorf("factory %q has no code range", factory.namespace)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, registered := range r.factories {
+		if registered.codeRange.overlaps(*factory.codeRange) {
+			return fmt.Errorf("code range %s of factory %q overlaps range %s of factory %q",
+				factory.codeRange, factory.namespace, registered.codeRange, registered.namespace)
+		}
+	}
+	r.factories = append(r.factories, factory)
+
+	return nil
+}
+
+// FromError returns the registered factory owning the code of the *Error in the chain of err.
+// It returns false if err holds no *Error or its code belongs to no registered factory.
+func (r *Registry) FromError(err error) (*ErrorFactory, bool) {
+	var stErr *Error
+	if !errors.As(err, &stErr) {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, factory := range r.factories {
+		if factory.codeRange.contains(stErr.Code) {
+			return factory, true
+		}
+	}
+
+	return nil, false
+}
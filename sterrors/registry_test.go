@@ -0,0 +1,174 @@
+package sterrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewFactoryWithRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ErrorConfig
+		min, max ErrorCode
+		wantErr  string
+	}{
+		{
+			name:   "in range",
+			config: ErrorConfig{1000: {Message: "a"}, 1999: {Message: "b"}},
+			min:    1000,
+			max:    1999,
+		},
+		{
+			name:    "out of range",
+			config:  ErrorConfig{999: {Message: "a"}, 1500: {Message: "b"}, 2000: {Message: "c"}},
+			min:     1000,
+			max:     1999,
+			wantErr: "error codes [999 2000] outside of range [1000, 1999]",
+		},
+		{
+			name:    "invalid range",
+			min:     2000,
+			max:     1000,
+			wantErr: "invalid code range [2000, 1000]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory, err := NewFactoryWithRange(tt.config, "internal error", 500, tt.min, tt.max)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("NewFactoryWithRange() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || factory == nil {
+				t.Errorf("NewFactoryWithRange() = %v, %v, want a factory", factory, err)
+			}
+		})
+	}
+}
+
+func newRangeFactory(t *testing.T, namespace string, min, max ErrorCode) *ErrorFactory {
+	t.Helper()
+
+	factory, err := NewFactoryWithRange(ErrorConfig{
+		min: {ErrorType: "NotFound", Message: namespace + " not found", Http_code: 404},
+	}, "internal error", 500, min, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.SetNamespace(namespace)
+
+	return factory
+}
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(newRangeFactory(t, "billing", 1000, 1999)); err != nil {
+		t.Fatalf("Registry.Register() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		factory *ErrorFactory
+		wantErr string
+	}{
+		{
+			name:    "overlap",
+			factory: newRangeFactory(t, "quotes", 1500, 2499),
+			wantErr: `code range [1500, 2499] of factory "quotes" overlaps range [1000, 1999] of factory "billing"`,
+		},
+		{
+			name:    "same range",
+			factory: newRangeFactory(t, "auth", 1000, 1999),
+			wantErr: "overlaps",
+		},
+		{
+			name:    "no range",
+			factory: newTestFactory(),
+			wantErr: `factory "" has no code range`,
+		},
+		{
+			name:    "adjacent",
+			factory: newRangeFactory(t, "quotes", 2000, 2999),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Register(tt.factory)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Registry.Register() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Registry.Register() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryFromError(t *testing.T) {
+	billing := newRangeFactory(t, "billing", 1000, 1999)
+	quotes := newRangeFactory(t, "quotes", 2000, 2999)
+
+	registry := NewRegistry()
+	for _, factory := range []*ErrorFactory{billing, quotes} {
+		if err := registry.Register(factory); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantNamespace string
+		wantOK        bool
+	}{
+		{
+			name:          "billing",
+			err:           billing.NewError(1000, nil),
+			wantNamespace: "billing",
+			wantOK:        true,
+		},
+		{
+			name:          "wrapped quotes",
+			err:           fmt.Errorf("get quote: %w", quotes.NewError(2000, nil)),
+			wantNamespace: "quotes",
+			wantOK:        true,
+		},
+		{
+			name: "unregistered code",
+			err:  newTestFactory().NewError(6001, nil),
+		},
+		{
+			name: "plain error",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory, ok := registry.FromError(tt.err)
+			if ok != tt.wantOK {
+				t.Fatalf("Registry.FromError() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && factory.Namespace() != tt.wantNamespace {
+				t.Errorf("Registry.FromError() namespace = %q, want %q", factory.Namespace(), tt.wantNamespace)
+			}
+		})
+	}
+}
+
+func TestErrorNamespace(t *testing.T) {
+	err := newRangeFactory(t, "billing", 1004, 1999).NewError(1004, errors.New("card declined"))
+	want := "http error: 404, with internal code: billing:1004, message: billing not found, card declined"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
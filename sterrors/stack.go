@@ -0,0 +1,53 @@
+package sterrors
+
+import "runtime"
+
+// stackSkip is the number of frames skipped when capturing a stack: runtime.Callers, captureStack,
+// newError and the exported factory method.
+const stackSkip = 4
+
+// Frame is a frame of the stack trace of an error.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// WithStackTraces captures the stack, up to depth frames, where the errors of the factory are created,
+// starting at the caller of the factory method. Only the program counters are captured, so it is cheap
+// enough for production; they are resolved when StackTrace is called.
+func WithStackTraces(depth int) FactoryOption {
+	return func(e *ErrorFactory) {
+		e.stackDepth = depth
+	}
+}
+
+// captureStack returns the program counters of the caller of the factory method, nil if stack traces are disabled.
+func (e *ErrorFactory) captureStack() []uintptr {
+	if e.stackDepth <= 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, e.stackDepth)
+	return pcs[:runtime.Callers(stackSkip, pcs)]
+}
+
+// StackTrace returns the stack captured when the error was created by a factory using WithStackTraces,
+// the caller of the factory first. It is logged by Log but never included in the JSON body.
+func (s *Error) StackTrace() []Frame {
+	if len(s.stack) == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(s.stack)
+	stack := make([]Frame, 0, len(s.stack))
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
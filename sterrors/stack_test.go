@@ -0,0 +1,88 @@
+package sterrors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	factory := NewFactory(testConfig, "internal error", 500, WithStackTraces(8))
+
+	tests := []struct {
+		name string
+		new  func() *Error
+	}{
+		{"NewError", func() *Error { return factory.NewError(1001, nil).(*Error) }},
+		{"NewErrorf", func() *Error { return factory.NewErrorf(1001, nil, "%s", "AAPL") }},
+		{"NewErrorParams", func() *Error { return factory.NewErrorParams(1001, nil, nil) }},
+		{"NewErrorLocalized", func() *Error { return factory.NewErrorLocalized(1001, nil, "es") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := tt.new().StackTrace()
+			if len(stack) == 0 || len(stack) > 8 {
+				t.Fatalf("StackTrace() has %d frames, want 1 to 8", len(stack))
+			}
+
+			top := stack[0]
+			if !strings.HasPrefix(top.Function, "github.com/stocktwits/go-infrastructure/v2/sterrors.TestStackTrace.func") {
+				t.Errorf("StackTrace() top function = %s, want the test function", top.Function)
+			}
+			if !strings.HasSuffix(top.File, "stack_test.go") || top.Line == 0 {
+				t.Errorf("StackTrace() top frame = %s:%d, want stack_test.go", top.File, top.Line)
+			}
+		})
+	}
+}
+
+func TestStackTraceDisabled(t *testing.T) {
+	err := newTestFactory().NewErrorf(1001, nil, "")
+	if stack := err.StackTrace(); stack != nil {
+		t.Errorf("StackTrace() = %v, want nil without WithStackTraces", stack)
+	}
+	if _, ok := LogFields(err)["stack"]; ok {
+		t.Error("LogFields() has a stack without WithStackTraces")
+	}
+}
+
+func TestStackTraceOutput(t *testing.T) {
+	factory := NewFactory(testConfig, "internal error", 500, WithStackTraces(4))
+	err := factory.NewError(1001, errors.New("no rows"))
+
+	body, marshalErr := err.(*Error).MarshalJSON()
+	if marshalErr != nil {
+		t.Fatalf("MarshalJSON() unexpected error = %v", marshalErr)
+	}
+	if strings.Contains(string(body), "stack") || strings.Contains(string(body), "stack_test.go") {
+		t.Errorf("MarshalJSON() = %s, want no stack trace", body)
+	}
+
+	stack, ok := LogFields(err)["stack"].([]Frame)
+	if !ok || len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestStackTraceOutput") {
+		t.Errorf("LogFields() stack = %v, want frames starting at the test function", stack)
+	}
+}
+
+func BenchmarkNewError(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		opts []FactoryOption
+	}{
+		{"without stack", nil},
+		{"with stack", []FactoryOption{WithStackTraces(16)}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			factory := NewFactory(testConfig, "internal error", 500, bm.opts...)
+			cause := errors.New("no rows")
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = factory.NewError(1001, cause)
+			}
+		})
+	}
+}
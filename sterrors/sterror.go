@@ -1,6 +1,14 @@
 package sterrors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type ErrorCode int
 
@@ -8,46 +16,173 @@ type ErrorData struct {
 	ErrorType string
 	Message   string
 	Http_code int
+	// Retryable marks the error as safe to retry. When nil, 429 and 503 errors are retryable.
+	Retryable *bool
+	// Severity is used for alerting, i.e. SeverityWarning. When empty, 5xx errors are SeverityError
+	// and other errors SeverityWarning.
+	Severity string
+	// Messages holds the translations of Message keyed by BCP-47 language tag, i.e. "es" or "pt-BR".
+	Messages map[string]string
 }
 
 type ErrorConfig map[ErrorCode]ErrorData
 
 type Error struct {
-	Err       error
-	Code      ErrorCode
-	Message   string
+	Err  error
+	Code ErrorCode
+	// Deprecated: the message comes from the config of the code and should not be changed, use Msg.
+	Message string
+	// Deprecated: the http code comes from the config of the code and should not be changed, use HTTPCode.
 	Http_code int
+	Details   map[string]any
+	// Type is the ErrorType configured for the code.
+	Type string
+	// IncludeInternal adds the text of Err to the JSON body, for non-production environments.
+	IncludeInternal bool
+	// Namespace is the namespace of the factory, prefixed to the code in Error().
+	Namespace string
+
+	retryable  *bool
+	severity   string
+	retryAfter time.Duration
+	messages   map[string]string
+	locale     string
+	stack      []uintptr
+	requestID  string
+
+	redactPatterns []*regexp.Regexp
+	maxCauseLength int
 }
 
 type ErrorFactory struct {
 	config          ErrorConfig
 	defaultMessage  string
 	defaultHttpCode int
+	includeInternal bool
+	strictParams    bool
+	namespace       string
+	codeRange       *codeRange
+	stackDepth      int
+	strictCodes     bool
+	observer        Observer
+	redactPatterns  []*regexp.Regexp
+	maxCauseLength  int
 }
 
-func NewFactory(config ErrorConfig, defMsg string, defHttpCode int) *ErrorFactory {
-	return &ErrorFactory{
+// FactoryOption configures an ErrorFactory.
+type FactoryOption func(*ErrorFactory)
+
+func NewFactory(config ErrorConfig, defMsg string, defHttpCode int, opts ...FactoryOption) *ErrorFactory {
+	factory := &ErrorFactory{
 		config:          config,
 		defaultMessage:  defMsg,
 		defaultHttpCode: defHttpCode,
 	}
+	for _, opt := range opts {
+		opt(factory)
+	}
+
+	return factory
 }
 
 func (e *ErrorFactory) NewError(code ErrorCode, err error) error {
-	return &Error{
-		Err:       err,
-		Code:      code,
-		Message:   e.getMessage(code),
-		Http_code: e.getHttpCode(code),
+	return e.newError(code, err, e.getMessage(code))
+}
+
+// SetIncludeInternal sets whether the errors created by the factory include the text of the internal
+// error in their JSON body. It should only be enabled in non-production environments.
+func (e *ErrorFactory) SetIncludeInternal(include bool) {
+	e.includeInternal = include
+}
+
+// NewErrorf creates an error like NewError, with the formatted detail appended to the configured message,
+// i.e. "symbol not found: AAPL".
+func (e *ErrorFactory) NewErrorf(code ErrorCode, err error, format string, args ...any) *Error {
+	return e.newError(code, err, e.formatMessage(code, format, args...))
+}
+
+// formatMessage returns the configured message of the code with the formatted detail appended.
+func (e *ErrorFactory) formatMessage(code ErrorCode, format string, args ...any) string {
+	message := e.getMessage(code)
+	if detail := fmt.Sprintf(format, args...); detail != "" {
+		message += ": " + detail
+	}
+
+	return message
+}
+
+// WithDetail adds a structured detail to the error, such as a user_id or a symbol, and returns the error
+// so calls can be chained.
+func (s *Error) WithDetail(key string, value any) *Error {
+	if s.Details == nil {
+		s.Details = make(map[string]any)
 	}
+	s.Details[key] = value
+
+	return s
 }
 
 func (s *Error) Error() string {
+	code := strconv.Itoa(int(s.Code))
+	if s.Namespace != "" {
+		code = s.Namespace + ":" + code
+	}
+
+	msg := fmt.Sprintf("http error: %d, with internal code: %s, message: %s", s.Http_code, code, s.Message)
 	if s.Err != nil {
-		return fmt.Sprintf("http error: %d, with internal code: %d, message: %s, %s", s.Http_code, s.Code, s.Message, s.Err.Error())
+		msg += ", " + s.causeText()
+	}
+
+	if len(s.Details) > 0 {
+		msg += ", details: " + s.formatDetails()
+	}
+
+	return msg
+}
+
+// formatDetails formats the details as key=value pairs sorted by key.
+func (s *Error) formatDetails() string {
+	keys := make([]string, 0, len(s.Details))
+	for key := range s.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, s.Details[key])
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// newError creates an error with the configured data of the code and the given message.
+func (e *ErrorFactory) newError(code ErrorCode, err error, message string) *Error {
+	data, known := e.config[code]
+	if !known && e.strictCodes {
+		err = errors.Join(fmt.Errorf("%w %d", ErrUnknownCode, code), err)
+	}
+
+	stErr := &Error{
+		Err:             err,
+		Code:            code,
+		Message:         message,
+		Http_code:       e.getHttpCode(code),
+		Type:            data.ErrorType,
+		IncludeInternal: e.includeInternal,
+		Namespace:       e.namespace,
+		retryable:       data.Retryable,
+		severity:        data.Severity,
+		messages:        data.Messages,
+		stack:           e.captureStack(),
+		redactPatterns:  e.redactPatterns,
+		maxCauseLength:  e.maxCauseLength,
+	}
+	if e.observer != nil {
+		e.observer(stErr)
 	}
 
-	return fmt.Sprintf("http error: %d, with internal code: %d, message: %s", s.Http_code, s.Code, s.Message)
+	return stErr
 }
 
 func (e *ErrorFactory) getMessage(code ErrorCode) string {
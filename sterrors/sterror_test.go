@@ -0,0 +1,100 @@
+package sterrors
+
+import (
+	"errors"
+	"testing"
+)
+
+var testConfig = ErrorConfig{
+	1001: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+	1002: {ErrorType: "BadRequest", Message: "invalid request", Http_code: 400},
+}
+
+func newTestFactory() *ErrorFactory {
+	return NewFactory(testConfig, "internal error", 500)
+}
+
+func TestNewError(t *testing.T) {
+	err := newTestFactory().NewError(1001, errors.New("no rows"))
+	want := "http error: 404, with internal code: 1001, message: symbol not found, no rows"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestNewErrorf(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   ErrorCode
+		err    error
+		format string
+		args   []any
+		want   string
+	}{
+		{
+			name:   "formatted detail",
+			code:   1001,
+			err:    errors.New("no rows"),
+			format: "%s on %s",
+			args:   []any{"AAPL", "NASDAQ"},
+			want:   "http error: 404, with internal code: 1001, message: symbol not found: AAPL on NASDAQ, no rows",
+		},
+		{
+			name:   "nil error",
+			code:   1002,
+			format: "limit %d",
+			args:   []any{-1},
+			want:   "http error: 400, with internal code: 1002, message: invalid request: limit -1",
+		},
+		{
+			name: "empty format",
+			code: 1002,
+			want: "http error: 400, with internal code: 1002, message: invalid request",
+		},
+		{
+			name:   "unknown code",
+			code:   9999,
+			format: "id %d",
+			args:   []any{42},
+			want:   "http error: 500, with internal code: 9999, message: internal error: id 42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestFactory().NewErrorf(tt.code, tt.err, tt.format, tt.args...)
+			if err.Error() != tt.want {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.want)
+			}
+			if err.Err != tt.err {
+				t.Errorf("Err = %v, want %v", err.Err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWithDetail(t *testing.T) {
+	err := newTestFactory().NewErrorf(1001, nil, "%s", "AAPL").
+		WithDetail("user_id", 42).
+		WithDetail("symbol", "AAPL").
+		WithDetail("exchange", "NASDAQ")
+
+	want := "http error: 404, with internal code: 1001, message: symbol not found: AAPL, details: exchange=NASDAQ symbol=AAPL user_id=42"
+	for i := 0; i < 10; i++ {
+		if err.Error() != want {
+			t.Fatalf("Error() = %q, want %q", err.Error(), want)
+		}
+	}
+
+	if err.Details["user_id"] != 42 {
+		t.Errorf("Details[user_id] = %v, want 42", err.Details["user_id"])
+	}
+
+	var stErr *Error
+	if wrapped := newTestFactory().NewError(1002, nil); errors.As(wrapped, &stErr) {
+		stErr.WithDetail("field", "limit")
+		if want := "http error: 400, with internal code: 1002, message: invalid request, details: field=limit"; wrapped.Error() != want {
+			t.Errorf("Error() = %q, want %q", wrapped.Error(), want)
+		}
+	}
+}
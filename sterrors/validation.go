@@ -0,0 +1,160 @@
+package sterrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FieldError is an entry of a ValidationError, describing the problem of a field.
+type FieldError struct {
+	Field   string
+	Code    ErrorCode
+	Message string
+	// Err is the cause of the entry, not included in the JSON body.
+	Err error
+}
+
+func (f *FieldError) Error() string {
+	msg := f.Message
+	if f.Field != "" {
+		msg = f.Field + ": " + msg
+	}
+	if f.Err != nil {
+		msg += ": " + f.Err.Error()
+	}
+
+	return msg
+}
+
+func (f *FieldError) Unwrap() error {
+	return f.Err
+}
+
+// MarshalJSON encodes the entry as {"field": "...", "code": 1001, "message": "..."}, the field being omitted if empty.
+func (f *FieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field   string    `json:"field,omitempty"`
+		Code    ErrorCode `json:"code"`
+		Message string    `json:"message"`
+	}{f.Field, f.Code, f.Message})
+}
+
+// ValidationError aggregates several problems, typically the invalid fields of a request, into a single error
+// written as one response by WriteHTTP:
+//
+//	{"code": 1000, "message": "invalid request", "type": "Validation", "errors": [{"field": "symbol", "code": 1001, "message": "symbol required"}]}
+type ValidationError struct {
+	Code      ErrorCode
+	Message   string
+	Http_code int
+	Type      string
+	Errors    []*FieldError
+
+	factory   *ErrorFactory
+	requestID string
+}
+
+// NewValidation creates an empty validation error with the data configured for the code.
+// Entries are added using Add and AddError.
+func (e *ErrorFactory) NewValidation(code ErrorCode) *ValidationError {
+	return &ValidationError{
+		Code:      code,
+		Message:   e.getMessage(code),
+		Http_code: e.getHttpCode(code),
+		Type:      e.config[code].ErrorType,
+		factory:   e,
+	}
+}
+
+// Add adds an entry for the field with the message configured for the code, and returns the validation error
+// so calls can be chained. err is the optional cause of the entry.
+func (v *ValidationError) Add(field string, code ErrorCode, err error) *ValidationError {
+	v.Errors = append(v.Errors, &FieldError{
+		Field:   field,
+		Code:    code,
+		Message: v.factory.getMessage(code),
+		Err:     err,
+	})
+
+	return v
+}
+
+// AddError adds an entry without field for err, with the code and message of the *Error it holds, or the
+// default message of the factory. It returns the validation error so calls can be chained.
+func (v *ValidationError) AddError(err error) *ValidationError {
+	stErr := v.factory.asError(err)
+	v.Errors = append(v.Errors, &FieldError{
+		Code:    stErr.Code,
+		Message: stErr.Message,
+		Err:     err,
+	})
+
+	return v
+}
+
+// Len returns the number of entries.
+func (v *ValidationError) Len() int {
+	return len(v.Errors)
+}
+
+// ErrOrNil returns the validation error, or nil if it has no entry, so a handler can end with
+// return validation.ErrOrNil().
+func (v *ValidationError) ErrOrNil() error {
+	if len(v.Errors) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+func (v *ValidationError) Error() string {
+	msg := fmt.Sprintf("http error: %d, with internal code: %d, message: %s", v.Http_code, v.Code, v.Message)
+	if len(v.Errors) == 0 {
+		return msg
+	}
+
+	entries := make([]string, len(v.Errors))
+	for i, entry := range v.Errors {
+		entries[i] = entry.Error()
+	}
+
+	return msg + ", errors: " + strings.Join(entries, "; ")
+}
+
+// Unwrap returns the entries, so errors.Is and errors.As look through them.
+func (v *ValidationError) Unwrap() []error {
+	errs := make([]error, len(v.Errors))
+	for i, entry := range v.Errors {
+		errs[i] = entry
+	}
+
+	return errs
+}
+
+// MarshalJSON encodes the validation error as {"code": ..., "message": ..., "type": ..., "errors": [...]},
+// with the "request_id" when written by WriteHTTPContext or Handler.
+func (v *ValidationError) MarshalJSON() ([]byte, error) {
+	entries := v.Errors
+	if entries == nil {
+		entries = []*FieldError{}
+	}
+
+	return json.Marshal(struct {
+		Code      ErrorCode     `json:"code"`
+		Message   string        `json:"message"`
+		Type      string        `json:"type"`
+		Errors    []*FieldError `json:"errors"`
+		RequestID string        `json:"request_id,omitempty"`
+	}{v.Code, v.Message, v.Type, entries, v.requestID})
+}
+
+// status returns the http status of the validation error, 422 if it is not set.
+func (v *ValidationError) status() int {
+	if v.Http_code == 0 {
+		return http.StatusUnprocessableEntity
+	}
+
+	return v.Http_code
+}
@@ -0,0 +1,112 @@
+package sterrors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+var validationConfig = ErrorConfig{
+	1000: {ErrorType: "Validation", Message: "invalid request", Http_code: 422},
+	1001: {ErrorType: "Required", Message: "field required", Http_code: 400},
+	1002: {ErrorType: "Range", Message: "must be positive", Http_code: 400},
+	1003: {ErrorType: "NotFound", Message: "symbol not found", Http_code: 404},
+}
+
+func TestValidationError(t *testing.T) {
+	factory := NewFactory(validationConfig, "internal error", 500)
+	cause := errors.New("parse error")
+	notFound := factory.NewErrorf(1003, nil, "%s", "XYZ")
+
+	v := factory.NewValidation(1000).
+		Add("symbol", 1001, nil).
+		Add("price", 1002, cause).
+		AddError(notFound).
+		AddError(errors.New("unexpected"))
+
+	if v.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", v.Len())
+	}
+
+	want := "http error: 422, with internal code: 1000, message: invalid request, errors: " +
+		"symbol: field required; price: must be positive: parse error; " +
+		"symbol not found: XYZ: http error: 404, with internal code: 1003, message: symbol not found: XYZ; " +
+		"internal error: unexpected"
+	if v.Error() != want {
+		t.Errorf("Error() = %q, want %q", v.Error(), want)
+	}
+
+	err := v.ErrOrNil()
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(%v, cause) = false, want true", err)
+	}
+
+	var stErr *Error
+	if !errors.As(err, &stErr) || stErr != notFound {
+		t.Errorf("errors.As(*Error) = %v, want the added error", stErr)
+	}
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "symbol" {
+		t.Errorf("errors.As(*FieldError) = %v, want the symbol entry", fieldErr)
+	}
+}
+
+func TestValidationErrorEmpty(t *testing.T) {
+	v := NewFactory(validationConfig, "internal error", 500).NewValidation(1000)
+
+	if err := v.ErrOrNil(); err != nil {
+		t.Errorf("ErrOrNil() = %v, want nil", err)
+	}
+	if len(v.Unwrap()) != 0 {
+		t.Errorf("Unwrap() = %v, want no errors", v.Unwrap())
+	}
+
+	want := "http error: 422, with internal code: 1000, message: invalid request"
+	if v.Error() != want {
+		t.Errorf("Error() = %q, want %q", v.Error(), want)
+	}
+
+	body, err := v.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() unexpected error = %v", err)
+	}
+	if want := `{"code":1000,"message":"invalid request","type":"Validation","errors":[]}`; string(body) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", body, want)
+	}
+}
+
+func TestValidationErrorHTTP(t *testing.T) {
+	factory := NewFactory(validationConfig, "internal error", 500)
+	v := factory.NewValidation(1000).
+		Add("symbol", 1001, nil).
+		Add("price", 1002, errors.New("parse error"))
+
+	wantBody := `{"code":1000,"message":"invalid request","type":"Validation","errors":[` +
+		`{"field":"symbol","code":1001,"message":"field required"},` +
+		`{"field":"price","code":1002,"message":"must be positive"}]}`
+
+	rec := httptest.NewRecorder()
+	factory.WriteHTTP(rec, v)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("WriteHTTP() status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if rec.Body.String() != wantBody {
+		t.Errorf("WriteHTTP() body = %s, want %s", rec.Body.String(), wantBody)
+	}
+
+	logger := stmocks.NewLogger()
+	rec = httptest.NewRecorder()
+	Handler(func(http.ResponseWriter, *http.Request) error {
+		return v.ErrOrNil()
+	}, factory, logger).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if rec.Code != http.StatusUnprocessableEntity || rec.Body.String() != wantBody {
+		t.Errorf("Handler() = %d %s, want %d %s", rec.Code, rec.Body.String(), http.StatusUnprocessableEntity, wantBody)
+	}
+	if entries := logger.Entries(); len(entries) != 0 {
+		t.Errorf("Handler() logged %v, want nothing", entries)
+	}
+}
@@ -3,17 +3,22 @@ package stlogs
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
 	"regexp"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/oklog/ulid"
 	"github.com/sirupsen/logrus"
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
 )
 
 const SchemaVersion = 1
@@ -45,6 +50,25 @@ var singleLogger *AuditLogger
 //Defines if logs will be printed pretty
 var prettyPrint bool
 
+//Clock used to timestamp the logs and generate their IDs
+var logClock stclock.Clock = stclock.System()
+var clockLock sync.RWMutex
+
+//Generator of the log IDs, nil to generate ULIDs
+var idGenerator func() string
+var idLock sync.RWMutex
+
+//Maximum number of data keys of an entry, 0 for no limit
+var maxDataKeys int
+var maxDataKeysLock sync.RWMutex
+
+//Data key set to true when keys were dropped because of the maximum number of data keys
+const DataTruncatedKey = "data_truncated"
+
+//Build metadata added to every entry, nil when not set
+var buildInfo *BuildInfo
+var buildLock sync.RWMutex
+
 //Local loggers
 var localLoggers map[string]*AuditLogger = make(map[string]*AuditLogger)
 
@@ -73,9 +97,165 @@ func SetPretty(f bool) {
 	prettyPrint = f
 }
 
-//Generates a new log ID
+//Set the clock used to timestamp the logs and generate their IDs, i.e. a fake clock in tests
+func SetClock(clock stclock.Clock) {
+	clockLock.Lock()
+	defer clockLock.Unlock()
+
+	logClock = clock
+}
+
+//Returns the time of the log clock
+func now() time.Time {
+	clockLock.RLock()
+	defer clockLock.RUnlock()
+
+	return logClock.Now()
+}
+
+//Set the generator of the log and transaction IDs, i.e. a sequence in tests, nil restoring the ULIDs
+//Returns a function restoring the previous generator
+func SetIDGenerator(generate func() string) func() {
+	idLock.Lock()
+	defer idLock.Unlock()
+
+	previous := idGenerator
+	idGenerator = generate
+
+	return func() {
+		idLock.Lock()
+		defer idLock.Unlock()
+
+		idGenerator = previous
+	}
+}
+
+//Set the maximum number of data keys of an entry, 0 or less for no limit
+//The keys added over the limit are dropped and DataTruncatedKey is set to true, it is not counted in the limit
+//Returns a function restoring the previous limit
+func SetMaxDataKeys(max int) func() {
+	maxDataKeysLock.Lock()
+	defer maxDataKeysLock.Unlock()
+
+	previous := maxDataKeys
+	maxDataKeys = max
+
+	return func() {
+		maxDataKeysLock.Lock()
+		defer maxDataKeysLock.Unlock()
+
+		maxDataKeys = previous
+	}
+}
+
+//Returns the maximum number of data keys of an entry
+func getMaxDataKeys() int {
+	maxDataKeysLock.RLock()
+	defer maxDataKeysLock.RUnlock()
+
+	return maxDataKeys
+}
+
+//Returns the value if it can be encoded to JSON, a placeholder with its type otherwise
+//so a func, a channel or a complex number doesn't break the whole entry
+func serializable(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return value
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return unserializable(value)
+		}
+		return value
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return unserializable(value)
+		}
+		return value
+	}
+
+	if _, err := json.Marshal(value); err != nil {
+		return unserializable(value)
+	}
+
+	return value
+}
+
+//Placeholder of a value that can't be encoded to JSON
+func unserializable(value interface{}) string {
+	return fmt.Sprintf("<unserializable %T>", value)
+}
+
+//Build metadata added to every entry as the "build" field, see SetBuildInfo
+type BuildInfo struct {
+	Version string `json:"ver,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	Date    string `json:"date,omitempty"`
+}
+
+//Set the build metadata added to the entries of the global and local loggers as the "build" field
+//It applies to the loggers already created too, empty values are left out
+//Setting all the values empty removes the field
+func SetBuildInfo(version, commit, buildDate string) {
+	buildLock.Lock()
+	defer buildLock.Unlock()
+
+	if version == "" && commit == "" && buildDate == "" {
+		buildInfo = nil
+		return
+	}
+
+	buildInfo = &BuildInfo{Version: version, Commit: commit, Date: buildDate}
+}
+
+//Set the build metadata from the information embedded in the binary by the Go toolchain:
+//the main module version, and the VCS revision and time
+//Returns false if the binary has no build information
+func SetBuildInfoFromBinary() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+
+	var commit, buildDate string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+		case "vcs.time":
+			buildDate = setting.Value
+		}
+	}
+
+	SetBuildInfo(info.Main.Version, commit, buildDate)
+
+	return true
+}
+
+//Returns the build metadata, nil if not set
+func getBuildInfo() *BuildInfo {
+	buildLock.RLock()
+	defer buildLock.RUnlock()
+
+	return buildInfo
+}
+
+//Generates a new log ID with the generator if set, a ULID otherwise
 func getID() string {
-	t := time.Now()
+	idLock.RLock()
+	generate := idGenerator
+	idLock.RUnlock()
+
+	if generate != nil {
+		return generate()
+	}
+
+	return newULID()
+}
+
+//Generates a new ULID at the time of the log clock
+func newULID() string {
+	t := now()
 	entropy := ulid.Monotonic(rand.New(rand.NewSource(t.UnixNano())), 0)
 
 	return ulid.MustNew(ulid.Timestamp(t), entropy).String()
@@ -220,12 +400,16 @@ func (ae *AuditEntry) copyInfo() *InfoCtx {
 func (ae *AuditEntry) getEntry() *logrus.Entry {
 	al := ae.auditLogger
 
-	entry := al.logger.WithField("src", al.app)
+	entry := al.logger.WithField("src", al.app).WithTime(now())
 
 	entry = entry.WithField("host", al.hostname)
 
 	entry = entry.WithField("sv", SchemaVersion)
 
+	if build := getBuildInfo(); build != nil {
+		entry = entry.WithField("build", *build)
+	}
+
 	if len(ae.info.auditData) > 0 {
 		entry = entry.WithField("data", ae.info.auditData)
 	}
@@ -285,6 +469,18 @@ func (ae *AuditEntry) NewWithContext(ctx context.Context) (Logger, context.Conte
 	}
 }
 
+//TxID returns the transaction id of the logger info linked to the context by NewWithContext, "" if there is none
+func TxID(ctx context.Context) string {
+	infCtx, ok := ctx.Value(InfoCtxKey).(*InfoCtx)
+	if !ok {
+		return ""
+	}
+
+	txID, _ := infCtx.auditData["txId"].(string)
+
+	return txID
+}
+
 //Adds a key to be recognized as sensitive data. This will use for maps keys and structures field names
 func (ae *AuditEntry) AddSensitive(s ...string) {
 	ae.auditLogger.sensitive = append(ae.auditLogger.sensitive, s...)
@@ -297,12 +493,28 @@ func (ae *AuditEntry) AddData(key string, value interface{}) Logger {
 	ae.Lock()
 	defer ae.Unlock()
 
-	ae.info.auditData[key] = value
+	if _, exists := ae.info.auditData[key]; !exists && key != DataTruncatedKey {
+		if max := getMaxDataKeys(); max > 0 && ae.dataKeys() >= max {
+			ae.info.auditData[DataTruncatedKey] = true
+			return ae
+		}
+	}
+
+	ae.info.auditData[key] = serializable(value)
 
 	return ae
 
 }
 
+//Number of data keys of the entry, without DataTruncatedKey
+func (ae *AuditEntry) dataKeys() int {
+	if _, truncated := ae.info.auditData[DataTruncatedKey]; truncated {
+		return len(ae.info.auditData) - 1
+	}
+
+	return len(ae.info.auditData)
+}
+
 //Adds a new tag to the tags array
 //This value will be printed in all the logs that uses the same entry object, or that uses the same context
 func (ae *AuditEntry) AddTag(tag string) Logger {
@@ -470,6 +682,15 @@ func NewLocalWithLevel(module string, level string) Logger {
 
 }
 
+//Creates a local logger like NewLocalWithLevel writing its entries to w instead of the standard error,
+//i.e. a buffer in tests
+func NewLocalWithOutput(module string, level string, w io.Writer) Logger {
+	entry := NewLocalWithLevel(module, level).(*AuditEntry)
+	entry.auditLogger.logger.SetOutput(w)
+
+	return entry
+}
+
 type PrintHook struct{}
 
 func (ph *PrintHook) Levels() []logrus.Level {
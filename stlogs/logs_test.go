@@ -1,12 +1,18 @@
 package stlogs
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"math"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/oklog/ulid"
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
 )
 
 type Log struct {
@@ -207,6 +213,21 @@ func TestLocalLoggerWithLevel(t *testing.T) {
 	}
 }
 
+func TestLocalLoggerWithOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logs := NewLocalWithOutput("with_output", "info", &buf)
+
+	logs.WithData("symbol", "AAPL").Info("test with output")
+
+	logSt := Log{}
+	if err := json.Unmarshal(buf.Bytes(), &logSt); err != nil {
+		t.Fatalf("invalid log entry %q: %v", buf.String(), err)
+	}
+	if logSt.Msg != "test with output" || logSt.Data["symbol"] != "AAPL" {
+		t.Errorf("wrong entry, want the entry with its data, have: %s", buf.String())
+	}
+}
+
 func OtherPlace(ctx context.Context) {
 	log := NewLocal("module")
 	log, _ = log.NewWithContext(ctx)
@@ -454,3 +475,208 @@ func TestHideData(t *testing.T) {
 	}
 
 }
+
+func TestTxID(t *testing.T) {
+	if txID := TxID(context.Background()); txID != "" {
+		t.Errorf("TxID() without logger info = %q, want empty", txID)
+	}
+
+	log, ctx := NewLocal("test-txid").NewWithContext(context.Background())
+
+	data, err := log.testLevel("info", "test txid")
+	if err != nil {
+		t.Fatalf("error will running log: %v", err)
+	}
+
+	logSt := Log{}
+	_ = json.Unmarshal(data, &logSt)
+
+	txID := TxID(ctx)
+	if txID == "" || txID != logSt.Data["txId"] {
+		t.Errorf("TxID() = %q, want the logged txId %v", txID, logSt.Data["txId"])
+	}
+}
+
+// fixedClock is a clock stopped at a time
+type fixedClock struct {
+	stclock.Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestSetClock(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	SetClock(fixedClock{now: at})
+	defer SetClock(stclock.System())
+
+	log, _ := NewLocal("test-clock").NewWithContext(context.Background())
+
+	data, err := log.testLevel("info", "test clock")
+	if err != nil {
+		t.Fatalf("error will running log: %v", err)
+	}
+
+	logSt := Log{}
+	_ = json.Unmarshal(data, &logSt)
+
+	if !logSt.Ts.Equal(at) {
+		t.Errorf("ts = %v, want the clock time %v", logSt.Ts, at)
+	}
+
+	txID, err := ulid.Parse(logSt.Data["txId"].(string))
+	if err != nil || txID.Time() != ulid.Timestamp(at) {
+		t.Errorf("txId = %v, want an id generated at the clock time", logSt.Data["txId"])
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	logger := NewLocal("test-ids")
+
+	ids := []string{"first", "second"}
+	restore := SetIDGenerator(func() string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	})
+
+	for _, want := range []string{"first", "second"} {
+		data, err := logger.testLevel("info", "test id")
+		if err != nil {
+			t.Fatalf("error will running log: %v", err)
+		}
+
+		logSt := Log{}
+		_ = json.Unmarshal(data, &logSt)
+		if logSt.Id != want {
+			t.Errorf("id = %q, want %q", logSt.Id, want)
+		}
+	}
+
+	restore()
+
+	data, err := logger.testLevel("info", "test id")
+	if err != nil {
+		t.Fatalf("error will running log: %v", err)
+	}
+
+	logSt := Log{}
+	_ = json.Unmarshal(data, &logSt)
+	if _, err := ulid.Parse(logSt.Id); err != nil || len(logSt.Id) != 26 {
+		t.Errorf("id = %q after restore, want a ULID", logSt.Id)
+	}
+}
+
+func TestUnserializableData(t *testing.T) {
+	log := NewLocal("test-unserializable").NewEntry().
+		AddData("user", "john").
+		AddData("count", 3).
+		AddData("callback", func() {}).
+		AddData("updates", make(chan int)).
+		AddData("ratio", complex(1, 2)).
+		AddData("nan", math.NaN()).
+		AddData("nested", map[string]interface{}{"fn": func() {}})
+
+	data, err := log.testLevel("info", "test unserializable")
+	if err != nil {
+		t.Fatalf("error will running log: %v", err)
+	}
+
+	logSt := Log{}
+	if err := json.Unmarshal(data, &logSt); err != nil {
+		t.Fatalf("log is not valid JSON: %v: %s", err, data)
+	}
+
+	want := map[string]interface{}{
+		"user":     "john",
+		"count":    float64(3),
+		"callback": "<unserializable func()>",
+		"updates":  "<unserializable chan int>",
+		"ratio":    "<unserializable complex128>",
+		"nan":      "<unserializable float64>",
+		"nested":   "<unserializable map[string]interface {}>",
+	}
+	for key, value := range want {
+		if logSt.Data[key] != value {
+			t.Errorf("data %s = %#v, want %#v", key, logSt.Data[key], value)
+		}
+	}
+}
+
+func TestSetMaxDataKeys(t *testing.T) {
+	restore := SetMaxDataKeys(2)
+
+	log := NewLocal("test-max-keys").NewEntry().
+		AddData("first", 1).
+		AddData("second", 2).
+		AddData("third", 3).
+		AddData("first", "updated")
+
+	data, err := log.WithData("fourth", 4).testLevel("info", "test max keys")
+	if err != nil {
+		t.Fatalf("error will running log: %v", err)
+	}
+
+	logSt := Log{}
+	_ = json.Unmarshal(data, &logSt)
+
+	want := map[string]interface{}{"first": "updated", "second": float64(2), DataTruncatedKey: true}
+	if !reflect.DeepEqual(logSt.Data, want) {
+		t.Errorf("data = %v, want %v", logSt.Data, want)
+	}
+
+	restore()
+
+	data, err = log.WithData("fourth", 4).testLevel("info", "test max keys")
+	if err != nil {
+		t.Fatalf("error will running log: %v", err)
+	}
+
+	logSt = Log{}
+	_ = json.Unmarshal(data, &logSt)
+	if logSt.Data["fourth"] != float64(4) {
+		t.Errorf("data = %v after restore, want the fourth key", logSt.Data)
+	}
+}
+
+func TestSetBuildInfo(t *testing.T) {
+	logger := NewLocal("test-build")
+	defer SetBuildInfo("", "", "")
+
+	build := func() (map[string]interface{}, bool) {
+		data, err := logger.testLevel("info", "test build")
+		if err != nil {
+			t.Fatalf("error will running log: %v", err)
+		}
+
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Fatalf("log is not valid JSON: %v: %s", err, data)
+		}
+		value, ok := entry["build"].(map[string]interface{})
+		return value, ok
+	}
+
+	if got, ok := build(); ok {
+		t.Errorf("build = %v without build info, want no field", got)
+	}
+
+	SetBuildInfo("v1.2.3", "abc123", "2024-01-02T03:04:05Z")
+	want := map[string]interface{}{"ver": "v1.2.3", "commit": "abc123", "date": "2024-01-02T03:04:05Z"}
+	if got, _ := build(); !reflect.DeepEqual(got, want) {
+		t.Errorf("build = %v, want %v", got, want)
+	}
+
+	SetBuildInfo("v1.2.4", "", "")
+	want = map[string]interface{}{"ver": "v1.2.4"}
+	if got, _ := build(); !reflect.DeepEqual(got, want) {
+		t.Errorf("build = %v, want %v", got, want)
+	}
+
+	SetBuildInfo("", "", "")
+	if got, ok := build(); ok {
+		t.Errorf("build = %v after removing the build info, want no field", got)
+	}
+}
@@ -0,0 +1,245 @@
+package stmocks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
+)
+
+// ClockMock is an stclock.Clock whose time only moves with Advance, firing the timers and tickers whose
+// deadlines are crossed. It is safe for concurrent use, concurrent Advance calls being applied one after the other.
+type ClockMock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	seq     uint64
+	// waiters are the active timers and tickers
+	waiters []*clockWaiter
+}
+
+var _ stclock.Clock = (*ClockMock)(nil)
+
+// clockWaiter is a timer, or a ticker if its period is set.
+type clockWaiter struct {
+	clock    *ClockMock
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+	// seq orders the waiters of the same deadline by scheduling order
+	seq uint64
+}
+
+// NewClock creates a clock set to start.
+func NewClock(start time.Time) *ClockMock {
+	c := &ClockMock{now: start}
+	c.changed = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now returns the time of the clock.
+func (c *ClockMock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers in deadline order, the clock being set to the
+// deadline of each one as it fires. A timer fires once. A ticker fires once, its ticks for the other periods crossed
+// being dropped like time.Ticker drops the ticks of slow receivers, and its next tick stays aligned to its period.
+// A tick is also dropped if the previous one was not received.
+func (c *ClockMock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fire(c.now.Add(d))
+}
+
+// NewTimer creates a timer firing once the clock is advanced by d. It fires at once if d is not positive.
+func (c *ClockMock) NewTimer(d time.Duration) stclock.Timer {
+	w := &clockWaiter{clock: c, c: make(chan time.Time, 1)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.schedule(w, d)
+
+	return &timerMock{w}
+}
+
+// NewTicker creates a ticker firing every time the clock is advanced by d. It panics if d is not positive.
+func (c *ClockMock) NewTicker(d time.Duration) stclock.Ticker {
+	if d <= 0 {
+		panic("stmocks: non-positive interval for NewTicker")
+	}
+	w := &clockWaiter{clock: c, c: make(chan time.Time, 1), period: d}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.schedule(w, d)
+
+	return &tickerMock{w}
+}
+
+// Waiters returns the number of active timers and tickers.
+func (c *ClockMock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers and tickers are active, so a test can advance the clock once the code
+// under test is waiting on it.
+func (c *ClockMock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+// schedule activates the waiter to fire after d, firing it at once if d is not positive. c.mu must be held.
+func (c *ClockMock) schedule(w *clockWaiter, d time.Duration) {
+	c.unschedule(w)
+
+	c.seq++
+	w.seq = c.seq
+	w.deadline = c.now.Add(d)
+	c.waiters = append(c.waiters, w)
+	c.changed.Broadcast()
+
+	c.fire(c.now)
+}
+
+// unschedule deactivates the waiter, and returns whether it was active. c.mu must be held.
+func (c *ClockMock) unschedule(w *clockWaiter) bool {
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// fire fires the waiters up to the time in deadline order, then sets the clock to the time. c.mu must be held.
+func (c *ClockMock) fire(until time.Time) {
+	for {
+		w := c.next(until)
+		if w == nil {
+			break
+		}
+
+		if w.deadline.After(c.now) {
+			c.now = w.deadline
+		}
+
+		select {
+		case w.c <- w.deadline:
+		default:
+		}
+
+		if w.period == 0 {
+			c.unschedule(w)
+			continue
+		}
+
+		w.deadline = w.deadline.Add(w.period)
+		if behind := until.Sub(w.deadline); behind >= 0 {
+			w.deadline = w.deadline.Add((behind/w.period + 1) * w.period)
+		}
+		c.seq++
+		w.seq = c.seq
+	}
+
+	if until.After(c.now) {
+		c.now = until
+	}
+}
+
+// next returns the active waiter of the earliest deadline up to the time, nil if there is none. c.mu must be held.
+func (c *ClockMock) next(until time.Time) *clockWaiter {
+	var next *clockWaiter
+	for _, w := range c.waiters {
+		if w.deadline.After(until) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) || (w.deadline.Equal(next.deadline) && w.seq < next.seq) {
+			next = w
+		}
+	}
+
+	return next
+}
+
+// drain removes the time left in the channel, so no stale time is received after Stop or Reset.
+func (w *clockWaiter) drain() {
+	select {
+	case <-w.c:
+	default:
+	}
+}
+
+// timerMock is a timer of a ClockMock.
+type timerMock struct {
+	*clockWaiter
+}
+
+func (t *timerMock) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timerMock) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	return t.clock.unschedule(t.clockWaiter)
+}
+
+func (t *timerMock) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	active := t.clock.unschedule(t.clockWaiter)
+	t.clock.schedule(t.clockWaiter, d)
+
+	return active
+}
+
+// tickerMock is a ticker of a ClockMock.
+type tickerMock struct {
+	*clockWaiter
+}
+
+func (t *tickerMock) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *tickerMock) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	t.clock.unschedule(t.clockWaiter)
+}
+
+func (t *tickerMock) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("stmocks: non-positive interval for Ticker.Reset")
+	}
+
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.drain()
+	t.period = d
+	t.clock.schedule(t.clockWaiter, d)
+}
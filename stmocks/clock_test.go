@@ -0,0 +1,174 @@
+package stmocks
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var clockStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// received returns the time received from the channel, false if none is ready.
+func received(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestClockTimers(t *testing.T) {
+	clock := NewClock(clockStart)
+	third := clock.NewTimer(3 * time.Second)
+	first := clock.NewTimer(time.Second)
+	second := clock.NewTimer(2 * time.Second)
+	stopped := clock.NewTimer(2 * time.Second)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Stop() = false on an active timer or true on a stopped one")
+	}
+
+	steps := []struct {
+		advance time.Duration
+		want    []bool
+	}{
+		{500 * time.Millisecond, []bool{false, false, false}},
+		{500 * time.Millisecond, []bool{true, false, false}},
+		{1500 * time.Millisecond, []bool{false, true, false}},
+		{time.Hour, []bool{false, false, true}},
+		{time.Hour, []bool{false, false, false}},
+	}
+
+	timers := []interface{ C() <-chan time.Time }{first, second, third}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		for j, timer := range timers {
+			at, ok := received(timer.C())
+			if ok != step.want[j] {
+				t.Fatalf("step %d: timer %d fired = %v, want %v", i, j, ok, step.want[j])
+			}
+			if want := clockStart.Add(time.Duration(j+1) * time.Second); ok && !at.Equal(want) {
+				t.Errorf("step %d: timer %d fired at %v, want its deadline %v", i, j, at, want)
+			}
+		}
+	}
+
+	if _, ok := received(stopped.C()); ok {
+		t.Error("stopped timer fired")
+	}
+	if want := clockStart.Add(2*time.Hour + 2500*time.Millisecond); !clock.Now().Equal(want) {
+		t.Errorf("Now() = %v, want %v", clock.Now(), want)
+	}
+	if clock.Waiters() != 0 {
+		t.Errorf("Waiters() = %d, want 0 once all timers fired", clock.Waiters())
+	}
+}
+
+func TestClockTimerReset(t *testing.T) {
+	clock := NewClock(clockStart)
+	timer := clock.NewTimer(time.Second)
+
+	clock.Advance(time.Second)
+	if timer.Reset(time.Second) {
+		t.Error("Reset() = true on a fired timer, want false")
+	}
+	if _, ok := received(timer.C()); ok {
+		t.Error("stale time received after Reset()")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if !timer.Reset(time.Second) {
+		t.Error("Reset() = false on an active timer, want true")
+	}
+	clock.Advance(900 * time.Millisecond)
+	if _, ok := received(timer.C()); ok {
+		t.Error("timer fired before its reset deadline")
+	}
+	clock.Advance(100 * time.Millisecond)
+	if at, ok := received(timer.C()); !ok || !at.Equal(clockStart.Add(2500*time.Millisecond)) {
+		t.Errorf("timer fired = %v at %v, want fired at its reset deadline", ok, at)
+	}
+
+	immediate := clock.NewTimer(0)
+	if _, ok := received(immediate.C()); !ok {
+		t.Error("timer of zero duration did not fire at once")
+	}
+}
+
+func TestClockTicker(t *testing.T) {
+	clock := NewClock(clockStart)
+	ticker := clock.NewTicker(time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		clock.Advance(time.Minute)
+		if at, ok := received(ticker.C()); !ok || !at.Equal(clockStart.Add(time.Duration(i)*time.Minute)) {
+			t.Fatalf("tick %d = %v at %v, want a tick at %d minutes", i, ok, at, i)
+		}
+	}
+
+	// a large advance delivers a single tick, the next one staying aligned to the period
+	clock.Advance(10*time.Minute + 30*time.Second)
+	if at, ok := received(ticker.C()); !ok || !at.Equal(clockStart.Add(4*time.Minute)) {
+		t.Errorf("catch-up tick = %v at %v, want the first tick crossed", ok, at)
+	}
+	if _, ok := received(ticker.C()); ok {
+		t.Error("more than one tick received after a large advance")
+	}
+	clock.Advance(30 * time.Second)
+	if at, ok := received(ticker.C()); !ok || !at.Equal(clockStart.Add(14*time.Minute)) {
+		t.Errorf("tick after catch-up = %v at %v, want a tick at 14 minutes", ok, at)
+	}
+
+	ticker.Reset(time.Hour)
+	clock.Advance(time.Minute)
+	if _, ok := received(ticker.C()); ok {
+		t.Error("tick received before the reset period")
+	}
+	ticker.Stop()
+	clock.Advance(2 * time.Hour)
+	if _, ok := received(ticker.C()); ok {
+		t.Error("tick received after Stop()")
+	}
+}
+
+func TestClockConcurrent(t *testing.T) {
+	clock := NewClock(clockStart)
+
+	const waiters = 20
+	var released sync.WaitGroup
+	fired := make(chan time.Time, waiters)
+	for i := 1; i <= waiters; i++ {
+		released.Add(1)
+		go func(d time.Duration) {
+			defer released.Done()
+			fired <- <-clock.NewTimer(d).C()
+		}(time.Duration(i) * time.Second)
+	}
+	clock.BlockUntil(waiters)
+
+	var advances sync.WaitGroup
+	for i := 0; i < waiters; i++ {
+		advances.Add(1)
+		go func() {
+			defer advances.Done()
+			clock.Advance(time.Second)
+		}()
+	}
+	advances.Wait()
+	released.Wait()
+	close(fired)
+
+	seen := map[time.Time]bool{}
+	for at := range fired {
+		if seen[at] {
+			t.Errorf("waiter released twice at %v", at)
+		}
+		seen[at] = true
+	}
+	if len(seen) != waiters {
+		t.Errorf("%d waiters released, want %d", len(seen), waiters)
+	}
+	if want := clockStart.Add(waiters * time.Second); !clock.Now().Equal(want) {
+		t.Errorf("Now() = %v, want %v", clock.Now(), want)
+	}
+}
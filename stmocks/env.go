@@ -0,0 +1,118 @@
+package stmocks
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Env is a sandbox of the process environment restored at the end of a test, see EnvSandbox.
+// The environment is shared by the process, so the tests using it must not run in parallel.
+type Env struct {
+	t        testing.TB
+	snapshot map[string]string
+
+	mu     sync.Mutex
+	denied map[string]bool
+}
+
+// EnvSandbox snapshots the environment and restores it at the end of the test, including the variables set or
+// unset directly with os.Setenv and os.Unsetenv by the code under test.
+func EnvSandbox(t testing.TB) *Env {
+	t.Helper()
+
+	e := &Env{t: t, snapshot: environ(), denied: map[string]bool{}}
+	t.Cleanup(e.restore)
+
+	return e
+}
+
+// environ returns the variables of the environment keyed by name.
+func environ() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return env
+}
+
+// restore sets the variables back to their snapshot, unsetting the ones added since.
+func (e *Env) restore() {
+	for key, value := range environ() {
+		want, existed := e.snapshot[key]
+		switch {
+		case !existed:
+			os.Unsetenv(key)
+		case want != value:
+			os.Setenv(key, want)
+		}
+	}
+
+	for key, value := range e.snapshot {
+		if _, ok := os.LookupEnv(key); !ok {
+			os.Setenv(key, value)
+		}
+	}
+}
+
+// Set sets the variable, failing the test if it cannot be set.
+func (e *Env) Set(key, value string) *Env {
+	e.t.Helper()
+
+	if err := os.Setenv(key, value); err != nil {
+		e.t.Fatalf("failed to set %s: %v", key, err)
+	}
+
+	return e
+}
+
+// Unset removes the variable, failing the test if it cannot be removed.
+func (e *Env) Unset(key string) *Env {
+	e.t.Helper()
+
+	if err := os.Unsetenv(key); err != nil {
+		e.t.Fatalf("failed to unset %s: %v", key, err)
+	}
+
+	return e
+}
+
+// Deny fails the test when the variables are read through Getenv or LookupEnv.
+func (e *Env) Deny(keys ...string) *Env {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, key := range keys {
+		e.denied[key] = true
+	}
+
+	return e
+}
+
+// Getenv returns the value of the variable like os.Getenv, failing the test if it is denied.
+func (e *Env) Getenv(key string) string {
+	e.t.Helper()
+
+	value, _ := e.LookupEnv(key)
+	return value
+}
+
+// LookupEnv returns the value of the variable like os.LookupEnv, failing the test if it is denied.
+// It can be passed as the lookup function of the packages accepting one.
+func (e *Env) LookupEnv(key string) (string, bool) {
+	e.t.Helper()
+
+	e.mu.Lock()
+	denied := e.denied[key]
+	e.mu.Unlock()
+
+	if denied {
+		e.t.Errorf("denied environment variable %s was read", key)
+	}
+
+	return os.LookupEnv(key)
+}
@@ -0,0 +1,68 @@
+package stmocks
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEnvSandbox(t *testing.T) {
+	os.Setenv("STMOCKS_ENV_KEPT", "original")
+	os.Setenv("STMOCKS_ENV_UNSET", "original")
+	os.Unsetenv("STMOCKS_ENV_ADDED")
+	os.Unsetenv("STMOCKS_ENV_DIRECT")
+	t.Cleanup(func() {
+		os.Unsetenv("STMOCKS_ENV_KEPT")
+		os.Unsetenv("STMOCKS_ENV_UNSET")
+	})
+
+	tb := &fakeTB{}
+	env := EnvSandbox(tb)
+	env.Set("STMOCKS_ENV_KEPT", "changed").Set("STMOCKS_ENV_ADDED", "added").Unset("STMOCKS_ENV_UNSET")
+
+	// direct mutations of the code under test
+	os.Setenv("STMOCKS_ENV_DIRECT", "direct")
+	os.Setenv("STMOCKS_ENV_KEPT", "changed directly")
+
+	if env.Getenv("STMOCKS_ENV_KEPT") != "changed directly" || env.Getenv("STMOCKS_ENV_ADDED") != "added" {
+		t.Errorf("Getenv() does not return the sandbox values")
+	}
+	if _, ok := env.LookupEnv("STMOCKS_ENV_UNSET"); ok {
+		t.Error("LookupEnv() found an unset variable")
+	}
+
+	tb.end()
+
+	want := map[string]string{"STMOCKS_ENV_KEPT": "original", "STMOCKS_ENV_UNSET": "original"}
+	for key, value := range want {
+		if got, ok := os.LookupEnv(key); !ok || got != value {
+			t.Errorf("%s = %q, %v after cleanup, want %q", key, got, ok, value)
+		}
+	}
+	for _, key := range []string{"STMOCKS_ENV_ADDED", "STMOCKS_ENV_DIRECT"} {
+		if value, ok := os.LookupEnv(key); ok {
+			t.Errorf("%s = %q after cleanup, want unset", key, value)
+		}
+	}
+	if len(tb.errors) > 0 {
+		t.Errorf("reported %q, want no errors", tb.errors)
+	}
+}
+
+func TestEnvSandboxDeny(t *testing.T) {
+	tb := &fakeTB{}
+	env := EnvSandbox(tb).Deny("AWS_SECRET_ACCESS_KEY")
+	defer tb.end()
+
+	lookup := env.LookupEnv
+	lookup("HOME")
+	if len(tb.errors) != 0 {
+		t.Errorf("reported %q for an allowed variable, want no errors", tb.errors)
+	}
+
+	lookup("AWS_SECRET_ACCESS_KEY")
+	env.Getenv("AWS_SECRET_ACCESS_KEY")
+	if len(tb.errors) != 2 || !strings.Contains(tb.errors[0], "denied environment variable AWS_SECRET_ACCESS_KEY was read") {
+		t.Errorf("reported %q, want an error for each read of the denied variable", tb.errors)
+	}
+}
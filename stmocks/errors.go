@@ -0,0 +1,105 @@
+package stmocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// injectionKey is the context key of the error injections.
+type injectionKey struct{}
+
+// injection is an error injected for a target, linked to the injections of the parent context.
+type injection struct {
+	parent    *injection
+	target    string
+	err       error
+	once      bool
+	fired     atomic.Bool
+	consulted *consulted
+}
+
+// consulted records the targets queried through the contexts sharing the same root injection.
+type consulted struct {
+	mu      sync.Mutex
+	targets map[string]struct{}
+}
+
+func (c *consulted) add(target string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.targets[target] = struct{}{}
+}
+
+func (c *consulted) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	targets := make([]string, 0, len(c.targets))
+	for target := range c.targets {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	return targets
+}
+
+// WithError returns a copy of ctx making ErrorFor return err for the target, so production code can fail on demand
+// in tests. An error injected again for the same target overrides the previous one.
+func WithError(ctx context.Context, target string, err error) context.Context {
+	return withInjection(ctx, target, err, false)
+}
+
+// WithErrorOnce is like WithError but the error is only returned the first time ErrorFor is called for the target,
+// i.e. to test that an operation succeeds when retried.
+func WithErrorOnce(ctx context.Context, target string, err error) context.Context {
+	return withInjection(ctx, target, err, true)
+}
+
+func withInjection(ctx context.Context, target string, err error, once bool) context.Context {
+	inj := &injection{target: target, err: err, once: once}
+	if parent, ok := ctx.Value(injectionKey{}).(*injection); ok {
+		inj.parent = parent
+		inj.consulted = parent.consulted
+	} else {
+		inj.consulted = &consulted{targets: map[string]struct{}{}}
+	}
+
+	return context.WithValue(ctx, injectionKey{}, inj)
+}
+
+// ErrorFor returns the error injected for the target by WithError or WithErrorOnce, nil if none.
+// It is safe for concurrent use, a WithErrorOnce error being returned by a single call.
+func ErrorFor(ctx context.Context, target string) error {
+	inj, ok := ctx.Value(injectionKey{}).(*injection)
+	if !ok {
+		return nil
+	}
+	inj.consulted.add(target)
+
+	for ; inj != nil; inj = inj.parent {
+		if inj.target != target {
+			continue
+		}
+		if inj.once && !inj.fired.CompareAndSwap(false, true) {
+			return nil
+		}
+
+		return inj.err
+	}
+
+	return nil
+}
+
+// Consulted returns the sorted targets passed to ErrorFor with ctx or any context sharing its injections,
+// whether an error was injected for them or not, so tests can assert the failure paths were reached.
+func Consulted(ctx context.Context) []string {
+	inj, ok := ctx.Value(injectionKey{}).(*injection)
+	if !ok {
+		return nil
+	}
+
+	return inj.consulted.list()
+}
@@ -0,0 +1,118 @@
+package stmocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestErrorFor(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	errSave := errors.New("save failed")
+	errSaveAgain := errors.New("save failed again")
+
+	ctx := WithError(context.Background(), "fetch", errFetch)
+	ctx = WithError(ctx, "save", errSave)
+	child := WithError(ctx, "save", errSaveAgain)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		target string
+		want   error
+	}{
+		{"injected", ctx, "fetch", errFetch},
+		{"other target", ctx, "save", errSave},
+		{"not injected", ctx, "delete", nil},
+		{"overridden", child, "save", errSaveAgain},
+		{"inherited", child, "fetch", errFetch},
+		{"no injection", context.Background(), "fetch", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				if err := ErrorFor(tt.ctx, tt.target); err != tt.want {
+					t.Errorf("ErrorFor(%s) call %d = %v, want %v", tt.target, i, err, tt.want)
+				}
+			}
+		})
+	}
+
+	if got, want := Consulted(ctx), []string{"delete", "fetch", "save"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Consulted() = %v, want %v", got, want)
+	}
+	if got := Consulted(context.Background()); got != nil {
+		t.Errorf("Consulted() without injection = %v, want nil", got)
+	}
+}
+
+func TestErrorOnce(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	ctx := WithErrorOnce(context.Background(), "fetch", errFetch)
+
+	// A retry loop gets the error once, then succeeds
+	var attempts []error
+	for i := 0; i < 3; i++ {
+		err := ErrorFor(ctx, "fetch")
+		attempts = append(attempts, err)
+		if err == nil {
+			break
+		}
+	}
+	if want := []error{errFetch, nil}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("attempts = %v, want %v", attempts, want)
+	}
+
+	// An error injected once again fires once more
+	ctx = WithErrorOnce(ctx, "fetch", errFetch)
+	if err := ErrorFor(ctx, "fetch"); err != errFetch {
+		t.Errorf("ErrorFor() after a new injection = %v, want %v", err, errFetch)
+	}
+	if err := ErrorFor(ctx, "fetch"); err != nil {
+		t.Errorf("ErrorFor() second call = %v, want nil", err)
+	}
+}
+
+func TestErrorForConcurrent(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	parent := WithErrorOnce(context.Background(), "fetch", errFetch)
+	parent = WithError(parent, "save", errors.New("save failed"))
+
+	var fired atomic.Int32
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 16; i++ {
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+
+				ctx := WithError(parent, fmt.Sprintf("target%d", i), errors.New("failed"))
+				var wg sync.WaitGroup
+				for j := 0; j < 10; j++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if ErrorFor(ctx, "fetch") != nil {
+							fired.Add(1)
+						}
+						if ErrorFor(ctx, "save") == nil {
+							t.Error("ErrorFor(save) = nil, want the injected error")
+						}
+						_ = ErrorFor(ctx, fmt.Sprintf("target%d", i))
+					}()
+				}
+				wg.Wait()
+			})
+		}
+	})
+
+	if fired.Load() != 1 {
+		t.Errorf("once error fired %d times, want 1", fired.Load())
+	}
+	if got := len(Consulted(parent)); got != 18 {
+		t.Errorf("Consulted() has %d targets, want 18", got)
+	}
+}
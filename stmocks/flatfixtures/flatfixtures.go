@@ -0,0 +1,138 @@
+// Package flatfixtures provides JSON documents to test the exports of the flat package, kept out of stmocks
+// so that the mocks do not depend on flat.
+package flatfixtures
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/flat"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// OrdersWithItems returns the canonical orders fixture: an array of orders with an array of items each,
+// one order without items and one item with a null field.
+func OrdersWithItems() *flat.DynamicValue {
+	return embeddedFixture("orders-with-items.json")
+}
+
+// UsersWithNestedAddress returns the canonical users fixture: an array of users with an address object holding
+// a geo object, one user with a null address and email.
+func UsersWithNestedAddress() *flat.DynamicValue {
+	return embeddedFixture("users-with-nested-address.json")
+}
+
+// embeddedFixture returns the embedded fixture file.
+func embeddedFixture(name string) *flat.DynamicValue {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("flatfixtures: missing fixture %s: %v", name, err))
+	}
+
+	return flat.ReadJSONFromReader(bytes.NewReader(data))
+}
+
+// LoadJSONFixture returns the JSON document of the file testdata/<path>, failing t with the line and column of
+// the error if the file is not valid JSON.
+func LoadJSONFixture(t testing.TB, path string) *flat.DynamicValue {
+	t.Helper()
+
+	path, data := readFixture(t, path)
+	if err := checkJSON(data); err != nil {
+		t.Fatalf("invalid JSON fixture %s:%v", path, err)
+	}
+
+	return flat.ReadJSONFromReader(bytes.NewReader(data))
+}
+
+// LoadJSONLFixture returns the stream of JSON objects of the file testdata/<path>, one object per line,
+// failing t with the line of the first invalid object.
+func LoadJSONLFixture(t testing.TB, path string) *flat.DynamicValue {
+	t.Helper()
+
+	path, data := readFixture(t, path)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var object map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			t.Fatalf("invalid JSONL fixture %s:%d: %v", path, line, err)
+		}
+	}
+
+	return flat.StreamJSONFromReader(bytes.NewReader(data))
+}
+
+// readFixture returns the path and the content of the fixture file under testdata, failing t if it cannot be read.
+func readFixture(t testing.TB, path string) (string, []byte) {
+	t.Helper()
+
+	path = filepath.Join("testdata", path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	return path, data
+}
+
+// checkJSON returns an error locating the first syntax error of data as "line:column: message".
+func checkJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v any
+	err := decoder.Decode(&v)
+	if err == nil && decoder.More() {
+		err = errors.New("unexpected data after the JSON document")
+	}
+	if err == nil {
+		return nil
+	}
+
+	offset := decoder.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// the offset is past the invalid character
+		offset = max(syntaxErr.Offset-1, 0)
+	}
+	line, column := position(data, offset)
+
+	return fmt.Errorf("%d:%d: %w", line, column, err)
+}
+
+// position returns the line and column of the byte at offset in data, starting at 1.
+func position(data []byte, offset int64) (int, int) {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
+
+// MustDynamic returns v as a DynamicValue, like it would be decoded from its JSON encoding.
+// It panics if v cannot be encoded to JSON, i.e. holds a channel or a function, so fixture typos fail at once.
+func MustDynamic(v any) *flat.DynamicValue {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("flatfixtures: unsupported fixture value %T: %v", v, err))
+	}
+
+	return flat.ReadJSONFromReader(bytes.NewReader(data))
+}
@@ -0,0 +1,163 @@
+package flatfixtures
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/flat"
+)
+
+// fakeTB records the errors reported through testing.TB.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// fatalTB records the failures of a test, stopping at the fatal ones like testing.T.
+type fatalTB struct {
+	fakeTB
+	fatal bool
+}
+
+func (f *fatalTB) Fatalf(format string, args ...any) {
+	f.fatal = true
+	f.Errorf(format, args...)
+	panic(f)
+}
+
+// run calls fn with the recorder, recovering from the fatal failures.
+func (f *fatalTB) run(fn func(tb *fatalTB)) {
+	defer func() {
+		if r := recover(); r != nil && r != f {
+			panic(r)
+		}
+	}()
+
+	fn(f)
+}
+
+func TestLoadJSONFixture(t *testing.T) {
+	tests := []struct {
+		name    string
+		load    func(t testing.TB, path string) *flat.DynamicValue
+		path    string
+		want    [][]string
+		wantErr string
+	}{
+		{
+			name: "json",
+			load: LoadJSONFixture,
+			path: "fixture.json",
+			want: [][]string{{"name", "age"}, {"John", "30"}},
+		},
+		{
+			name: "jsonl",
+			load: LoadJSONLFixture,
+			path: "fixture.jsonl",
+			want: [][]string{{"name", "age"}, {"John", "30"}, {"Jane", "25"}},
+		},
+		{
+			name:    "broken json",
+			load:    LoadJSONFixture,
+			path:    "broken.json",
+			wantErr: "invalid JSON fixture testdata/broken.json:4:1: invalid character '}'",
+		},
+		{
+			name:    "broken jsonl",
+			load:    LoadJSONLFixture,
+			path:    "broken.jsonl",
+			wantErr: "invalid JSONL fixture testdata/broken.jsonl:3: invalid character '}'",
+		},
+		{
+			name:    "missing",
+			load:    LoadJSONFixture,
+			path:    "missing.json",
+			wantErr: "failed to read fixture",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fatalTB{}
+			var data *flat.DynamicValue
+			tb.run(func(tb *fatalTB) { data = tt.load(tb, tt.path) })
+
+			if tt.wantErr != "" {
+				if !tb.fatal || len(tb.errors) != 1 || !strings.Contains(tb.errors[0], tt.wantErr) {
+					t.Errorf("load() reported %q, want a fatal error containing %q", tb.errors, tt.wantErr)
+				}
+				return
+			}
+			if len(tb.errors) > 0 {
+				t.Fatalf("load() reported %q, want no errors", tb.errors)
+			}
+
+			got, err := data.GetCSV(func(s flat.Source, d flat.Dest) {
+				d.Col("name", s.Key("name"))
+				d.Col("age", s.Key("age"))
+			}).ExportRecords()
+			if err != nil {
+				t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CSV.ExportRecords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalFixtures(t *testing.T) {
+	orders, err := OrdersWithItems().GetCSV(func(s flat.Source, d flat.Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("first_symbol", s.Path("items", 0, "symbol"))
+		d.Col("last_price", s.Path("items", 1, "price"))
+	}).ExportRecords()
+	if err != nil {
+		t.Fatalf("OrdersWithItems() export error = %v", err)
+	}
+	wantOrders := [][]string{{"id", "first_symbol", "last_price"}, {"1001", "AAPL", "304.5"}, {"1002", "", ""}, {"1003", "SHIB", ""}}
+	if !reflect.DeepEqual(orders, wantOrders) {
+		t.Errorf("OrdersWithItems() records = %q, want %q", orders, wantOrders)
+	}
+
+	users, err := UsersWithNestedAddress().GetCSV(func(s flat.Source, d flat.Dest) {
+		d.Col("name", s.Key("name"))
+		d.Col("city", s.Path("address", "city"))
+		d.Col("lat", s.Path("address", "geo", "lat"))
+	}).ExportRecords()
+	if err != nil {
+		t.Fatalf("UsersWithNestedAddress() export error = %v", err)
+	}
+	wantUsers := [][]string{{"name", "city", "lat"}, {"John Doe", "New York", "40.7128"}, {"Jane Roe", "London", "51.5237"}, {"Bob Smith", "", ""}}
+	if !reflect.DeepEqual(users, wantUsers) {
+		t.Errorf("UsersWithNestedAddress() records = %q, want %q", users, wantUsers)
+	}
+}
+
+func TestMustDynamic(t *testing.T) {
+	got, err := MustDynamic([]map[string]any{{"id": 1, "tags": []string{"a"}}}).GetCSV(func(s flat.Source, d flat.Dest) {
+		d.Col("id", s.Key("id"))
+		d.Col("tag", s.Path("tags", 0))
+	}).ExportRecords()
+	if err != nil {
+		t.Fatalf("CSV.ExportRecords() unexpected error = %v", err)
+	}
+	if want := [][]string{{"id", "tag"}, {"1", "a"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CSV.ExportRecords() = %q, want %q", got, want)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("MustDynamic() expected panic for a channel value")
+		}
+	}()
+	MustDynamic(map[string]any{"ch": make(chan int)})
+}
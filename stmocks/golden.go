@@ -0,0 +1,148 @@
+package stmocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// UpdateGolden rewrites the golden files compared by Golden instead of comparing them when set, i.e. by a test
+// package from its own flag.
+var UpdateGolden bool
+
+// goldenUpdateEnv is the environment variable rewriting the golden files when set to 1, like UpdateGolden.
+const goldenUpdateEnv = "GOLDEN_UPDATE"
+
+// goldenUpdateFlag is the name of the boolean flag rewriting the golden files if the test package defines it.
+// The package does not register it, so test packages can define their own -update flag.
+const goldenUpdateFlag = "update"
+
+// Golden compares got with the golden file testdata/<name>, failing t with a unified diff on mismatch.
+// The file is rewritten with got instead when UpdateGolden is set, when the tests run with GOLDEN_UPDATE=1,
+// or with -update if the test package defines that boolean flag.
+// Line endings are normalized, so the tests pass on checkouts converting them to CRLF.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	golden(t, filepath.Join("testdata", name), got, updateGolden())
+}
+
+// updateGolden reports whether the golden files are rewritten, see Golden.
+func updateGolden() bool {
+	if UpdateGolden || os.Getenv(goldenUpdateEnv) == "1" {
+		return true
+	}
+
+	f := flag.Lookup(goldenUpdateFlag)
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	update, ok := getter.Get().(bool)
+	return ok && update
+}
+
+// GoldenJSON compares the indented JSON encoding of v with the golden file testdata/<name> like Golden.
+// The keys of objects are sorted, including the keys of v if it holds raw JSON, i.e. a json.RawMessage,
+// so the file does not change with the encoding order.
+func GoldenJSON(t testing.TB, name string, v any) {
+	t.Helper()
+
+	got, err := stableJSON(v)
+	if err != nil {
+		t.Fatalf("failed to encode %s: %v", name, err)
+	}
+
+	Golden(t, name, got)
+}
+
+// stableJSON returns the indented JSON encoding of v with the keys of objects sorted.
+func stableJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	data, err = json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// golden compares got with the file at path, or rewrites the file if update is set.
+func golden(t testing.TB, path string, got []byte, update bool) {
+	t.Helper()
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create the golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update the golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("golden file %s does not exist, run the tests with GOLDEN_UPDATE=1 to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("failed to read the golden file: %v", err)
+	}
+
+	wantText, gotText := normalizeLineEndings(want), normalizeLineEndings(got)
+	if wantText == gotText {
+		return
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(wantText),
+		B:        splitLines(gotText),
+		FromFile: path,
+		ToFile:   "got",
+		Context:  3,
+	})
+	if err != nil {
+		t.Fatalf("failed to diff with the golden file: %v", err)
+	}
+
+	t.Errorf("mismatch with the golden file, run the tests with GOLDEN_UPDATE=1 to rewrite it:\n%s", diff)
+}
+
+// splitLines splits the text into lines keeping their line ending, adding one to the last line if it has none.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+
+	return lines
+}
+
+// normalizeLineEndings returns data with the CRLF line endings replaced by LF.
+func normalizeLineEndings(data []byte) string {
+	return string(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")))
+}
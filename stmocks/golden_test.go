@@ -0,0 +1,162 @@
+package stmocks
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update is the -update flag of the tests of the package, honored by Golden and by TestPriceFixtures.
+var update = flag.Bool("update", false, "rewrite the golden files and the price fixtures")
+
+// goldenTB records the failures of a test, stopping at the fatal ones like testing.T.
+type goldenTB struct {
+	fakeTB
+	fatal bool
+}
+
+func (g *goldenTB) Fatalf(format string, args ...any) {
+	g.fatal = true
+	g.Errorf(format, args...)
+	panic(g)
+}
+
+// run calls f with the recorder, recovering from the fatal failures.
+func (g *goldenTB) run(f func(tb *goldenTB)) {
+	defer func() {
+		if r := recover(); r != nil && r != g {
+			panic(r)
+		}
+	}()
+
+	f(g)
+}
+
+func TestGolden(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "out.csv")
+
+	tests := []struct {
+		name      string
+		content   string
+		got       string
+		update    bool
+		wantErr   string
+		wantFatal bool
+		wantFile  string
+	}{
+		{name: "missing", got: "a\n", wantErr: "does not exist, run the tests with GOLDEN_UPDATE=1", wantFatal: true},
+		{name: "update creates", got: "name,age\nJohn,30\n", update: true, wantFile: "name,age\nJohn,30\n"},
+		{name: "equal", content: "name,age\nJohn,30\n", got: "name,age\nJohn,30\n"},
+		{name: "CRLF checkout", content: "name,age\r\nJohn,30\r\n", got: "name,age\nJohn,30\n"},
+		{
+			name:    "mismatch",
+			content: "name,age\nJohn,30\nJane,25\n",
+			got:     "name,age\nJohn,31\nJane,25\n",
+			wantErr: "--- " + path + "\n+++ got\n@@ -1,3 +1,3 @@\n name,age\n-John,30\n+John,31\n Jane,25\n",
+		},
+		{name: "update rewrites", content: "old\n", got: "new\n", update: true, wantFile: "new\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.RemoveAll(filepath.Dir(path))
+			if tt.content != "" {
+				os.MkdirAll(filepath.Dir(path), 0o755)
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tb := &goldenTB{}
+			tb.run(func(tb *goldenTB) { golden(tb, path, []byte(tt.got), tt.update) })
+
+			if tt.wantErr == "" && len(tb.errors) > 0 {
+				t.Errorf("golden() reported %q, want no errors", tb.errors)
+			}
+			if tt.wantErr != "" && (len(tb.errors) != 1 || !strings.Contains(tb.errors[0], tt.wantErr)) {
+				t.Errorf("golden() reported %q, want it to contain %q", tb.errors, tt.wantErr)
+			}
+			if tb.fatal != tt.wantFatal {
+				t.Errorf("golden() fatal = %v, want %v", tb.fatal, tt.wantFatal)
+			}
+
+			if tt.wantFile != "" {
+				data, err := os.ReadFile(path)
+				if err != nil || string(data) != tt.wantFile {
+					t.Errorf("golden file = %q, %v, want %q", data, err, tt.wantFile)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateGolden(t *testing.T) {
+	t.Setenv(goldenUpdateEnv, "")
+	defer func(updateFlag, updateVar bool) { *update, UpdateGolden = updateFlag, updateVar }(*update, UpdateGolden)
+
+	tests := []struct {
+		name       string
+		updateFlag bool
+		updateVar  bool
+		env        string
+		want       bool
+	}{
+		{name: "default"},
+		{name: "flag of the test package", updateFlag: true, want: true},
+		{name: "variable", updateVar: true, want: true},
+		{name: "environment", env: "1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*update, UpdateGolden = tt.updateFlag, tt.updateVar
+			t.Setenv(goldenUpdateEnv, tt.env)
+
+			if got := updateGolden(); got != tt.want {
+				t.Errorf("updateGolden() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStableJSON(t *testing.T) {
+	want := "{\n  \"a\": 1,\n  \"b\": {\n    \"c\": 12345678901234567890,\n    \"d\": [\n      true\n    ]\n  }\n}\n"
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"map", map[string]any{"b": map[string]any{"d": []bool{true}, "c": json.Number("12345678901234567890")}, "a": 1}},
+		{"raw JSON", json.RawMessage(`{"b":{"d":[true],"c":12345678901234567890},"a":1}`)},
+		{"struct", struct {
+			B struct {
+				D []bool      `json:"d"`
+				C json.Number `json:"c"`
+			} `json:"b"`
+			A int `json:"a"`
+		}{B: struct {
+			D []bool      `json:"d"`
+			C json.Number `json:"c"`
+		}{D: []bool{true}, C: "12345678901234567890"}, A: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stableJSON(tt.v)
+			if err != nil {
+				t.Fatalf("stableJSON() unexpected error = %v", err)
+			}
+			if string(got) != want {
+				t.Errorf("stableJSON() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGoldenJSON(t *testing.T) {
+	GoldenJSON(t, "golden.json", map[string]any{"name": "stmocks", "tags": []string{"golden", "json"}})
+}
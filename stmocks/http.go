@@ -0,0 +1,403 @@
+package stmocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// HTTPMock is an http.RoundTripper answering the requests with scripted responses, for the tests of the clients
+// of HTTP APIs. Requests are answered by the first stub matching them, in registration order, or by the next stub
+// if Ordered is used. It records every request, and reports the requests matching no stub and the stubs not
+// matched as often as expected. It is safe for concurrent use.
+type HTTPMock struct {
+	mu         sync.Mutex
+	t          testing.TB
+	ordered    bool
+	stubs      []*HTTPStub
+	requests   []RecordedRequest
+	unexpected []string
+}
+
+// HTTPMockOption customizes an HTTPMock.
+type HTTPMockOption func(*HTTPMock)
+
+// ReportTo fails t for each unexpected request as it arrives, and for each unmet stub at the end of the test.
+func ReportTo(t testing.TB) HTTPMockOption {
+	return func(m *HTTPMock) {
+		m.t = t
+	}
+}
+
+// Ordered expects the requests to match the stubs in registration order, each stub answering one request
+// unless Times is used.
+func Ordered() HTTPMockOption {
+	return func(m *HTTPMock) {
+		m.ordered = true
+	}
+}
+
+// NewHTTPMock creates an HTTP mock without stubs.
+func NewHTTPMock(opts ...HTTPMockOption) *HTTPMock {
+	m := &HTTPMock{}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.t != nil {
+		m.t.Cleanup(func() {
+			if err := m.unmet(); err != nil {
+				m.t.Error(err)
+			}
+		})
+	}
+
+	return m
+}
+
+// Transport returns the round tripper to install on the http.Client under test.
+func (m *HTTPMock) Transport() http.RoundTripper {
+	return m
+}
+
+// Client returns an http.Client using the mock as transport.
+func (m *HTTPMock) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// RecordedRequest is a request received by an HTTPMock.
+type RecordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the body of the request into v.
+func (r RecordedRequest) JSON(v any) error {
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		return fmt.Errorf("failed to decode the body of %s %s: %w", r.Method, r.URL.Path, err)
+	}
+
+	return nil
+}
+
+// Requests returns the received requests, in order, matched or not.
+func (m *HTTPMock) Requests() []RecordedRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]RecordedRequest(nil), m.requests...)
+}
+
+// Verify returns an error describing the unexpected requests and the stubs not matched as often as expected,
+// nil if there are none.
+func (m *HTTPMock) Verify() error {
+	m.mu.Lock()
+	var errs []error
+	for _, request := range m.unexpected {
+		errs = append(errs, fmt.Errorf("unexpected request %s", request))
+	}
+	m.mu.Unlock()
+
+	return errors.Join(append(errs, m.unmet())...)
+}
+
+// unmet returns an error describing the stubs not matched as often as expected, nil if there are none.
+func (m *HTTPMock) unmet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, stub := range m.stubs {
+		if err := stub.unmet(m.ordered); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// On adds a stub answering the requests of the method, "" matching any method, and of the URL path matching
+// pattern, with the syntax of path.Match, i.e. "/users/*". By default the stub answers 200 without body and is
+// expected to match at least one request, exactly one if Ordered is used.
+func (m *HTTPMock) On(method, pathPattern string) *HTTPStub {
+	if _, err := path.Match(pathPattern, ""); err != nil {
+		panic(fmt.Sprintf("stmocks: invalid path pattern %q: %v", pathPattern, err))
+	}
+
+	stub := &HTTPStub{
+		mock:    m,
+		method:  strings.ToUpper(method),
+		pattern: pathPattern,
+		status:  http.StatusOK,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stubs = append(m.stubs, stub)
+
+	return stub
+}
+
+// RoundTrip records the request and answers it with the matching stub, or fails if no stub matches it.
+func (m *HTTPMock) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the request body: %w", err)
+		}
+	}
+
+	request := RecordedRequest{Method: req.Method, URL: req.URL, Header: req.Header.Clone(), Body: body}
+	description := req.Method + " " + req.URL.String()
+
+	m.mu.Lock()
+	m.requests = append(m.requests, request)
+	stub := m.match(request)
+	if stub == nil {
+		m.unexpected = append(m.unexpected, description)
+	}
+	m.mu.Unlock()
+
+	if stub == nil {
+		if m.t != nil {
+			m.t.Errorf("stmocks: unexpected request %s", description)
+		}
+		return nil, fmt.Errorf("stmocks: no stub matching %s", description)
+	}
+
+	return stub.respond(req)
+}
+
+// match returns the stub answering the request and counts the match, nil if there is none. m.mu must be held.
+func (m *HTTPMock) match(request RecordedRequest) *HTTPStub {
+	for _, stub := range m.stubs {
+		if stub.exhausted(m.ordered) {
+			continue
+		}
+
+		if !stub.matches(request) {
+			if m.ordered {
+				return nil
+			}
+			continue
+		}
+
+		stub.calls++
+		return stub
+	}
+
+	return nil
+}
+
+// HTTPStub is a scripted response of an HTTPMock, configured by its chainable methods.
+type HTTPStub struct {
+	mock     *HTTPMock
+	method   string
+	pattern  string
+	headers  http.Header
+	bodies   []func(body []byte) bool
+	times    int
+	calls    int
+	status   int
+	response []byte
+	header   http.Header
+	err      error
+}
+
+// WithHeader only matches the requests with the header set to value.
+func (s *HTTPStub) WithHeader(key, value string) *HTTPStub {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	if s.headers == nil {
+		s.headers = http.Header{}
+	}
+	s.headers.Add(key, value)
+
+	return s
+}
+
+// WithBody only matches the requests whose body satisfies match.
+func (s *HTTPStub) WithBody(match func(body []byte) bool) *HTTPStub {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	s.bodies = append(s.bodies, match)
+
+	return s
+}
+
+// WithJSONBody only matches the requests whose JSON body is equal to v once both are decoded,
+// so the formatting and the order of the keys do not matter.
+func (s *HTTPStub) WithJSONBody(v any) *HTTPStub {
+	want, err := normalizeJSON(v)
+	if err != nil {
+		panic(fmt.Sprintf("stmocks: invalid JSON body matcher: %v", err))
+	}
+
+	return s.WithBody(func(body []byte) bool {
+		var got any
+		return json.Unmarshal(body, &got) == nil && reflect.DeepEqual(got, want)
+	})
+}
+
+// normalizeJSON returns v as decoded from its JSON encoding.
+func normalizeJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+// Times expects the stub to match exactly n requests, the following ones being answered by the next stubs.
+func (s *HTTPStub) Times(n int) *HTTPStub {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	s.times = n
+
+	return s
+}
+
+// Return answers the matched requests with the status and body.
+func (s *HTTPStub) Return(status int, body string) *HTTPStub {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	s.status, s.response, s.err = status, []byte(body), nil
+
+	return s
+}
+
+// ReturnJSON answers the matched requests with the status and the JSON encoding of body. It panics if body
+// cannot be encoded.
+func (s *HTTPStub) ReturnJSON(status int, body any) *HTTPStub {
+	data, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("stmocks: invalid JSON response: %v", err))
+	}
+
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	s.status, s.response, s.err = status, data, nil
+	s.header = http.Header{"Content-Type": []string{"application/json"}}
+
+	return s
+}
+
+// ReturnError fails the matched requests with err, i.e. a network error.
+func (s *HTTPStub) ReturnError(err error) *HTTPStub {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	s.err = err
+
+	return s
+}
+
+// String describes the stub.
+func (s *HTTPStub) String() string {
+	method := s.method
+	if method == "" {
+		method = "*"
+	}
+
+	return method + " " + s.pattern
+}
+
+// matches reports whether the request matches the stub. s.mock.mu must be held.
+func (s *HTTPStub) matches(request RecordedRequest) bool {
+	if s.method != "" && s.method != request.Method {
+		return false
+	}
+	if ok, _ := path.Match(s.pattern, request.URL.Path); !ok {
+		return false
+	}
+
+	for key, values := range s.headers {
+		for _, value := range values {
+			if !contains(request.Header.Values(key), value) {
+				return false
+			}
+		}
+	}
+
+	for _, match := range s.bodies {
+		if !match(request.Body) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// exhausted reports whether the stub matched all the requests it expects. s.mock.mu must be held.
+func (s *HTTPStub) exhausted(ordered bool) bool {
+	times := s.times
+	if times == 0 && ordered {
+		times = 1
+	}
+
+	return times > 0 && s.calls >= times
+}
+
+// unmet returns an error if the stub was not matched as often as expected. s.mock.mu must be held.
+func (s *HTTPStub) unmet(ordered bool) error {
+	switch {
+	case s.times > 0 && s.calls != s.times:
+		return fmt.Errorf("stub %s matched %d times, want %d", s, s.calls, s.times)
+	case s.times == 0 && ordered && s.calls != 1:
+		return fmt.Errorf("stub %s matched %d times, want 1", s, s.calls)
+	case s.calls == 0:
+		return fmt.Errorf("stub %s never matched", s)
+	default:
+		return nil
+	}
+}
+
+// respond returns the response of the stub to the request.
+func (s *HTTPStub) respond(req *http.Request) (*http.Response, error) {
+	s.mock.mu.Lock()
+	defer s.mock.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	header := s.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.status, http.StatusText(s.status)),
+		StatusCode:    s.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(s.response)),
+		ContentLength: int64(len(s.response)),
+		Request:       req,
+	}, nil
+}
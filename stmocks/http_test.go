@@ -0,0 +1,236 @@
+package stmocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTB records the failures and cleanups of a test.
+type fakeTB struct {
+	testing.TB
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Error(args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.Error(fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(cleanup func()) {
+	f.cleanups = append(f.cleanups, cleanup)
+}
+
+// end runs the cleanups like the end of the test.
+func (f *fakeTB) end() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+// send makes a request through the client and returns the status and body of the response.
+func send(t *testing.T, client *http.Client, method, url, body string) (int, string, error) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("http.NewRequest() unexpected error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer token")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), err
+}
+
+func TestHTTPMockPrecedence(t *testing.T) {
+	mock := NewHTTPMock()
+	mock.On("GET", "/users/1").Times(1).ReturnJSON(http.StatusOK, map[string]string{"name": "first"})
+	mock.On("GET", "/users/*").WithHeader("Authorization", "Bearer token").ReturnJSON(http.StatusOK, map[string]string{"name": "any"})
+	mock.On("GET", "/users/*").ReturnJSON(http.StatusUnauthorized, nil)
+	mock.On("", "/health").Return(http.StatusNoContent, "")
+	client := mock.Client()
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{"first registered", "GET", "http://api/users/1", http.StatusOK, `{"name":"first"}`},
+		{"next once exhausted", "GET", "http://api/users/1", http.StatusOK, `{"name":"any"}`},
+		{"pattern", "GET", "http://api/users/2?full=1", http.StatusOK, `{"name":"any"}`},
+		{"any method", "HEAD", "http://api/health", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body, err := send(t, client, tt.method, tt.url, "")
+			if err != nil {
+				t.Fatalf("client.Do() unexpected error = %v", err)
+			}
+			if status != tt.wantStatus || body != tt.wantBody {
+				t.Errorf("client.Do() = %d %q, want %d %q", status, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	if _, _, err := send(t, client, "POST", "http://api/users/1", ""); err == nil {
+		t.Error("client.Do() expected error for an unexpected request, got nil")
+	}
+
+	err := mock.Verify()
+	if err == nil || !strings.Contains(err.Error(), "unexpected request POST http://api/users/1") {
+		t.Errorf("Verify() = %v, want the unexpected request", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "stub GET /users/* never matched") {
+		t.Errorf("Verify() = %v, want the stub without header never matched", err)
+	}
+}
+
+func TestHTTPMockOrdered(t *testing.T) {
+	mock := NewHTTPMock(Ordered())
+	mock.On("POST", "/login").Return(http.StatusOK, "token")
+	mock.On("GET", "/items").Times(2).Return(http.StatusOK, "items")
+	mock.On("DELETE", "/session").Return(http.StatusOK, "bye")
+	client := mock.Client()
+
+	for _, req := range []struct{ method, path, want string }{
+		{"POST", "/login", "token"},
+		{"GET", "/items", "items"},
+		{"GET", "/items", "items"},
+	} {
+		if _, body, err := send(t, client, req.method, "http://api"+req.path, ""); err != nil || body != req.want {
+			t.Fatalf("%s %s = %q, %v, want %q", req.method, req.path, body, err, req.want)
+		}
+	}
+
+	// out of order
+	if _, _, err := send(t, client, "POST", "http://api/login", ""); err == nil {
+		t.Error("client.Do() expected error for a request out of order, got nil")
+	}
+
+	err := mock.Verify()
+	if err == nil || !strings.Contains(err.Error(), "stub DELETE /session matched 0 times, want 1") {
+		t.Errorf("Verify() = %v, want the unmet DELETE stub", err)
+	}
+}
+
+func TestHTTPMockReportTo(t *testing.T) {
+	tb := &fakeTB{}
+	mock := NewHTTPMock(ReportTo(tb))
+	mock.On("GET", "/users/*").Times(2).ReturnJSON(http.StatusOK, []int{1})
+	mock.On("GET", "/unused")
+	networkErr := errors.New("connection reset")
+	mock.On("GET", "/down").ReturnError(networkErr)
+
+	client := mock.Client()
+	send(t, client, "GET", "http://api/users/1", "")
+	send(t, client, "GET", "http://api/missing", "")
+	if _, _, err := send(t, client, "GET", "http://api/down", ""); !errors.Is(err, networkErr) {
+		t.Errorf("client.Do() error = %v, want %v", err, networkErr)
+	}
+
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "unexpected request GET http://api/missing") {
+		t.Errorf("reported %q, want the unexpected request as it arrives", tb.errors)
+	}
+
+	tb.end()
+	if len(tb.errors) != 2 {
+		t.Fatalf("reported %q, want the unmet stubs at the end of the test", tb.errors)
+	}
+	for _, want := range []string{"stub GET /users/* matched 1 times, want 2", "stub GET /unused never matched"} {
+		if !strings.Contains(tb.errors[1], want) {
+			t.Errorf("reported %q, want it to contain %q", tb.errors[1], want)
+		}
+	}
+}
+
+func TestHTTPMockJSONBody(t *testing.T) {
+	mock := NewHTTPMock()
+	mock.On("POST", "/orders").WithJSONBody(map[string]any{"symbol": "AAPL", "qty": 10}).ReturnJSON(http.StatusCreated, map[string]int{"id": 1})
+	mock.On("POST", "/orders").ReturnJSON(http.StatusBadRequest, map[string]string{"error": "invalid order"})
+	client := mock.Client()
+
+	status, body, err := send(t, client, "POST", "http://api/orders", `{ "qty": 10, "symbol": "AAPL" }`)
+	if err != nil || status != http.StatusCreated || body != `{"id":1}` {
+		t.Errorf("matching body = %d %q, %v, want 201 {\"id\":1}", status, body, err)
+	}
+	if status, _, _ := send(t, client, "POST", "http://api/orders", `{"qty": 5, "symbol": "AAPL"}`); status != http.StatusBadRequest {
+		t.Errorf("other body status = %d, want 400", status)
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Requests() = %d requests, want 2", len(requests))
+	}
+	var order struct {
+		Symbol string `json:"symbol"`
+		Qty    int    `json:"qty"`
+	}
+	if err := requests[1].JSON(&order); err != nil || order.Symbol != "AAPL" || order.Qty != 5 {
+		t.Errorf("JSON() = %+v, %v, want the captured order", order, err)
+	}
+	if requests[0].Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("captured header = %v, want the Authorization header", requests[0].Header)
+	}
+	if err := mock.Verify(); err != nil {
+		t.Errorf("Verify() unexpected error = %v", err)
+	}
+}
+
+func TestHTTPMockConcurrent(t *testing.T) {
+	mock := NewHTTPMock()
+	mock.On("PUT", "/counters/*").Times(50).Return(http.StatusOK, "ok")
+	client := mock.Client()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest("PUT", fmt.Sprintf("http://api/counters/%d", i), bytes.NewReader([]byte(`{}`)))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Errorf("client.Do() unexpected error = %v", err)
+				return
+			}
+			resp.Body.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	if err := mock.Verify(); err != nil {
+		t.Errorf("Verify() unexpected error = %v", err)
+	}
+	if len(mock.Requests()) != 50 {
+		t.Errorf("Requests() = %d requests, want 50", len(mock.Requests()))
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(mock.Requests()[0].Body, &body); err != nil {
+		t.Errorf("captured body unexpected error = %v", err)
+	}
+}
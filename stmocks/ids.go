@@ -0,0 +1,23 @@
+package stmocks
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// NewSequentialIDs returns an ID generator returning "prefix-000001", "prefix-000002"... for
+// stlogs.SetIDGenerator, so the ids of the logs can be asserted. It is safe for concurrent use.
+func NewSequentialIDs(prefix string) func() string {
+	var last atomic.Int64
+
+	return func() string {
+		return fmt.Sprintf("%s-%06d", prefix, last.Add(1))
+	}
+}
+
+// FixedID returns an ID generator always returning id, for stlogs.SetIDGenerator.
+func FixedID(id string) func() string {
+	return func() string {
+		return id
+	}
+}
@@ -0,0 +1,65 @@
+package stmocks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// captureStlogs returns an stlogs logger writing to a buffer, and a function returning the logged entries.
+func captureStlogs(t *testing.T) (stlogs.Logger, func() []map[string]any) {
+	var buf bytes.Buffer
+	logger := stlogs.NewLocalWithOutput("stmocks-test", "info", &buf)
+
+	return logger, func() []map[string]any {
+		var entries []map[string]any
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var entry map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("invalid log entry %q: %v", scanner.Text(), err)
+			}
+			entries = append(entries, entry)
+		}
+
+		return entries
+	}
+}
+
+func TestSequentialIDs(t *testing.T) {
+	logger, entries := captureStlogs(t)
+
+	restore := stlogs.SetIDGenerator(NewSequentialIDs("log"))
+	logger.Info("first")
+	logger.Warn("second")
+	restore()
+	logger.Info("third")
+
+	logged := entries()
+	if len(logged) != 3 {
+		t.Fatalf("logged %d entries, want 3", len(logged))
+	}
+	for i, want := range []string{"log-000001", "log-000002"} {
+		if logged[i]["id"] != want {
+			t.Errorf("entry %d id = %v, want %q", i, logged[i]["id"], want)
+		}
+	}
+	if id, _ := logged[2]["id"].(string); len(id) != 26 {
+		t.Errorf("id = %q after restore, want a 26 characters ULID", id)
+	}
+}
+
+func TestFixedID(t *testing.T) {
+	generate := FixedID("req-1")
+	if generate() != "req-1" || generate() != "req-1" {
+		t.Error("FixedID() generator does not return the fixed id")
+	}
+
+	sequence := NewSequentialIDs("tx")
+	if first, second := sequence(), sequence(); first != "tx-000001" || second != "tx-000002" {
+		t.Errorf("NewSequentialIDs() = %q, %q, want tx-000001, tx-000002", first, second)
+	}
+}
@@ -0,0 +1,317 @@
+package stmocks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// LogEntry is an entry recorded by a LoggerMock, with the data and tags of the logger at the time it was emitted.
+type LogEntry struct {
+	Level stlogs.Level
+	Msg   string
+	Data  map[string]any
+	Tags  []string
+}
+
+// LoggerMock is an stlogs.Logger recording the emitted entries instead of printing them, so tests can assert on
+// what was logged. The loggers derived from it by WithData, NewEntry, NewWithContext... record to the same entries.
+// Fatal entries are recorded without exiting. It is safe for concurrent use.
+type LoggerMock struct {
+	// Logger is nil, only embedded to implement the unexported method of stlogs.Logger.
+	stlogs.Logger
+
+	recorder *logRecorder
+	info     *logInfo
+}
+
+var _ stlogs.Logger = (*LoggerMock)(nil)
+
+// logRecorder holds the entries recorded by a LoggerMock and the loggers derived from it.
+type logRecorder struct {
+	mu        sync.Mutex
+	entries   []LogEntry
+	sensitive []string
+	txIDs     atomic.Int64
+}
+
+// logInfo holds the data and tags of a logger, shared by the loggers linked to the same context.
+type logInfo struct {
+	mu   sync.Mutex
+	data map[string]any
+	tags []string
+}
+
+// logInfoKey is the context key of the logInfo linked by NewWithContext.
+type logInfoKey struct{}
+
+// NewLogger creates a logger recording its entries.
+func NewLogger() *LoggerMock {
+	return &LoggerMock{
+		recorder: &logRecorder{},
+		info:     &logInfo{data: map[string]any{}},
+	}
+}
+
+// Entries returns the recorded entries, in order.
+func (l *LoggerMock) Entries() []LogEntry {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	return append([]LogEntry(nil), l.recorder.entries...)
+}
+
+// EntriesAt returns the recorded entries of the level, in order. Trace entries are recorded at stlogs.DEBUG.
+func (l *LoggerMock) EntriesAt(level stlogs.Level) []LogEntry {
+	var entries []LogEntry
+	for _, entry := range l.Entries() {
+		if entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Contains reports whether an entry was recorded with a message containing msg.
+func (l *LoggerMock) Contains(msg string) bool {
+	for _, entry := range l.Entries() {
+		if strings.Contains(entry.Msg, msg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DataEquals reports whether an entry was recorded with the data key set to value, compared with reflect.DeepEqual.
+func (l *LoggerMock) DataEquals(key string, value any) bool {
+	for _, entry := range l.Entries() {
+		if v, ok := entry.Data[key]; ok && reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sensitive returns the keys added by AddSensitive, in order. Their values are recorded as is.
+func (l *LoggerMock) Sensitive() []string {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	return append([]string(nil), l.recorder.sensitive...)
+}
+
+// Reset removes the recorded entries.
+func (l *LoggerMock) Reset() {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	l.recorder.entries = nil
+}
+
+// record records an entry with a copy of the data and tags of the logger.
+func (l *LoggerMock) record(level stlogs.Level, msg string) {
+	data, tags := l.info.copy()
+
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	l.recorder.entries = append(l.recorder.entries, LogEntry{Level: level, Msg: msg, Data: data, Tags: tags})
+}
+
+// copy returns a copy of the data and tags.
+func (i *logInfo) copy() (map[string]any, []string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	data := make(map[string]any, len(i.data))
+	for k, v := range i.data {
+		data[k] = v
+	}
+
+	return data, append([]string(nil), i.tags...)
+}
+
+// derive returns a logger recording to the same entries with the info.
+func (l *LoggerMock) derive(info *logInfo) *LoggerMock {
+	return &LoggerMock{recorder: l.recorder, info: info}
+}
+
+// AddData adds the key to the data of the logger and of the loggers linked to the same context.
+func (l *LoggerMock) AddData(key string, value interface{}) stlogs.Logger {
+	l.info.mu.Lock()
+	defer l.info.mu.Unlock()
+
+	l.info.data[key] = value
+
+	return l
+}
+
+// AddTag adds the tag to the tags of the logger and of the loggers linked to the same context.
+func (l *LoggerMock) AddTag(tag string) stlogs.Logger {
+	return l.AddTags(tag)
+}
+
+// AddTags adds the tags to the tags of the logger and of the loggers linked to the same context,
+// ignoring the tags already present.
+func (l *LoggerMock) AddTags(tags ...string) stlogs.Logger {
+	l.info.mu.Lock()
+	defer l.info.mu.Unlock()
+
+	for _, tag := range tags {
+		if !contains(l.info.tags, tag) {
+			l.info.tags = append(l.info.tags, tag)
+		}
+	}
+
+	return l
+}
+
+// contains reports whether the tags hold the tag.
+func contains(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewEntry returns a logger with a copy of the data and tags, so its modifications are not seen by l.
+func (l *LoggerMock) NewEntry() stlogs.Logger {
+	data, tags := l.info.copy()
+
+	return l.derive(&logInfo{data: data, tags: tags})
+}
+
+// NewWithContext returns a logger sharing the data and tags linked to ctx. If there are none, it returns a logger
+// with a new "txId" data, and a copy of ctx linked to its data and tags.
+func (l *LoggerMock) NewWithContext(ctx context.Context) (stlogs.Logger, context.Context) {
+	if info, ok := ctx.Value(logInfoKey{}).(*logInfo); ok {
+		return l.derive(info), ctx
+	}
+
+	txID := "tx-" + strconv.FormatInt(l.recorder.txIDs.Add(1), 10)
+	nl := l.derive(&logInfo{data: map[string]any{"txId": txID}})
+
+	return nl, context.WithValue(ctx, logInfoKey{}, nl.info)
+}
+
+// WithData returns a new entry with the key added to its data.
+func (l *LoggerMock) WithData(key string, value interface{}) stlogs.Logger {
+	return l.NewEntry().AddData(key, value)
+}
+
+// WithTag returns a new entry with the tag added to its tags.
+func (l *LoggerMock) WithTag(tag string) stlogs.Logger {
+	return l.NewEntry().AddTag(tag)
+}
+
+// WithTags returns a new entry with the tags added to its tags.
+func (l *LoggerMock) WithTags(tags ...string) stlogs.Logger {
+	return l.NewEntry().AddTags(tags...)
+}
+
+// WithError returns a new entry with the message of the error as "error" data.
+func (l *LoggerMock) WithError(err error) stlogs.Logger {
+	if err == nil {
+		err = fmt.Errorf("nil error was logged")
+	}
+
+	return l.WithData("error", err.Error())
+}
+
+// AddSensitive records the sensitive keys, returned by Sensitive.
+func (l *LoggerMock) AddSensitive(keys ...string) {
+	l.recorder.mu.Lock()
+	defer l.recorder.mu.Unlock()
+
+	l.recorder.sensitive = append(l.recorder.sensitive, keys...)
+}
+
+// sprintln formats the args like fmt.Sprintln without the trailing newline, as logrus does.
+func sprintln(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
+
+func (l *LoggerMock) Tracef(format string, args ...interface{}) {
+	l.record(stlogs.DEBUG, fmt.Sprintf(format, args...))
+}
+
+func (l *LoggerMock) Debugf(format string, args ...interface{}) {
+	l.record(stlogs.DEBUG, fmt.Sprintf(format, args...))
+}
+
+func (l *LoggerMock) Infof(format string, args ...interface{}) {
+	l.record(stlogs.INFO, fmt.Sprintf(format, args...))
+}
+
+func (l *LoggerMock) Warnf(format string, args ...interface{}) {
+	l.record(stlogs.WARN, fmt.Sprintf(format, args...))
+}
+
+func (l *LoggerMock) Errorf(format string, args ...interface{}) {
+	l.record(stlogs.ERROR, fmt.Sprintf(format, args...))
+}
+
+func (l *LoggerMock) Fatalf(format string, args ...interface{}) {
+	l.record(stlogs.FATAL, fmt.Sprintf(format, args...))
+}
+
+func (l *LoggerMock) Trace(args ...interface{}) {
+	l.record(stlogs.DEBUG, fmt.Sprint(args...))
+}
+
+func (l *LoggerMock) Debug(args ...interface{}) {
+	l.record(stlogs.DEBUG, fmt.Sprint(args...))
+}
+
+func (l *LoggerMock) Info(args ...interface{}) {
+	l.record(stlogs.INFO, fmt.Sprint(args...))
+}
+
+func (l *LoggerMock) Warn(args ...interface{}) {
+	l.record(stlogs.WARN, fmt.Sprint(args...))
+}
+
+func (l *LoggerMock) Error(args ...interface{}) {
+	l.record(stlogs.ERROR, fmt.Sprint(args...))
+}
+
+func (l *LoggerMock) Fatal(args ...interface{}) {
+	l.record(stlogs.FATAL, fmt.Sprint(args...))
+}
+
+func (l *LoggerMock) Traceln(args ...interface{}) {
+	l.record(stlogs.DEBUG, sprintln(args...))
+}
+
+func (l *LoggerMock) Debugln(args ...interface{}) {
+	l.record(stlogs.DEBUG, sprintln(args...))
+}
+
+func (l *LoggerMock) Infoln(args ...interface{}) {
+	l.record(stlogs.INFO, sprintln(args...))
+}
+
+func (l *LoggerMock) Warnln(args ...interface{}) {
+	l.record(stlogs.WARN, sprintln(args...))
+}
+
+func (l *LoggerMock) Errorln(args ...interface{}) {
+	l.record(stlogs.ERROR, sprintln(args...))
+}
+
+func (l *LoggerMock) Fatalln(args ...interface{}) {
+	l.record(stlogs.FATAL, sprintln(args...))
+}
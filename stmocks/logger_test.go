@@ -0,0 +1,144 @@
+package stmocks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/sterrors"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+func TestLoggerLevels(t *testing.T) {
+	logger := NewLogger()
+
+	tests := []struct {
+		name      string
+		log       func(l stlogs.Logger)
+		wantLevel stlogs.Level
+		wantMsg   string
+	}{
+		{"trace", func(l stlogs.Logger) { l.Tracef("a %d", 1) }, stlogs.DEBUG, "a 1"},
+		{"debug", func(l stlogs.Logger) { l.Debug("a", 1) }, stlogs.DEBUG, "a1"},
+		{"info", func(l stlogs.Logger) { l.Infoln("a", 1) }, stlogs.INFO, "a 1"},
+		{"warn", func(l stlogs.Logger) { l.Warnf("a %s", "b") }, stlogs.WARN, "a b"},
+		{"error", func(l stlogs.Logger) { l.Error("failed") }, stlogs.ERROR, "failed"},
+		{"fatal", func(l stlogs.Logger) { l.Fatalln("exit") }, stlogs.FATAL, "exit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.Reset()
+			tt.log(logger)
+
+			entries := logger.EntriesAt(tt.wantLevel)
+			if len(entries) != 1 || entries[0].Msg != tt.wantMsg {
+				t.Errorf("EntriesAt(%d) = %+v, want one entry %q", tt.wantLevel, entries, tt.wantMsg)
+			}
+			if len(logger.Entries()) != 1 {
+				t.Errorf("Entries() = %+v, want one entry", logger.Entries())
+			}
+		})
+	}
+}
+
+func TestLoggerChainedData(t *testing.T) {
+	logger := NewLogger()
+	logger.AddData("app", "api").AddTag("http")
+
+	request := logger.WithData("user", 42).WithTags("auth", "http")
+	request.WithError(errors.New("denied")).Warn("login failed")
+	request.Info("login attempt")
+	logger.Info("done")
+
+	want := []LogEntry{
+		{Level: stlogs.WARN, Msg: "login failed", Data: map[string]any{"app": "api", "user": 42, "error": "denied"}, Tags: []string{"http", "auth"}},
+		{Level: stlogs.INFO, Msg: "login attempt", Data: map[string]any{"app": "api", "user": 42}, Tags: []string{"http", "auth"}},
+		{Level: stlogs.INFO, Msg: "done", Data: map[string]any{"app": "api"}, Tags: []string{"http"}},
+	}
+	if got := logger.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %+v, want %+v", got, want)
+	}
+
+	if !logger.Contains("login") || logger.Contains("logout") {
+		t.Error("Contains() does not match the recorded messages")
+	}
+	if !logger.DataEquals("user", 42) || logger.DataEquals("user", "42") {
+		t.Error("DataEquals() does not match the recorded data")
+	}
+}
+
+func TestLoggerContext(t *testing.T) {
+	logger := NewLogger()
+
+	first, ctx := logger.NewWithContext(context.Background())
+	first.AddData("request", "r1")
+
+	// a logger linked to the same context shares the data
+	second, sameCtx := logger.NewWithContext(ctx)
+	if sameCtx != ctx {
+		t.Error("NewWithContext() returned a new context, want the linked one")
+	}
+	second.AddTag("handler")
+	second.Info("handled")
+
+	// an unlinked context gets its own transaction
+	other, _ := logger.NewWithContext(context.Background())
+	other.Info("other")
+
+	entries := logger.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() = %+v, want 2 entries", entries)
+	}
+	txID := entries[0].Data["txId"]
+	if txID == nil || entries[0].Data["request"] != "r1" || !reflect.DeepEqual(entries[0].Tags, []string{"handler"}) {
+		t.Errorf("entry = %+v, want the txId, data and tags of the context", entries[0])
+	}
+	if entries[1].Data["txId"] == txID || entries[1].Data["request"] != nil {
+		t.Errorf("entry = %+v, want a distinct txId without the data of the other context", entries[1])
+	}
+}
+
+func TestLoggerSterrors(t *testing.T) {
+	logger := NewLogger()
+	factory := sterrors.NewFactory(sterrors.ErrorConfig{
+		404: {ErrorType: "NotFound", Message: "not found", Http_code: 404},
+	}, "internal error", 500)
+
+	sterrors.Log(logger, factory.NewErrorf(404, errors.New("no row"), ""))
+	sterrors.Log(logger, errors.New("boom"))
+
+	if warns := logger.EntriesAt(stlogs.WARN); len(warns) != 1 || warns[0].Msg != "not found" {
+		t.Errorf("EntriesAt(WARN) = %+v, want the not found error", warns)
+	}
+	if !logger.DataEquals("error_code", sterrors.ErrorCode(404)) {
+		t.Errorf("Entries() = %+v, want error_code 404", logger.Entries())
+	}
+	if errs := logger.EntriesAt(stlogs.ERROR); len(errs) != 1 || errs[0].Data["error"] != "boom" {
+		t.Errorf("EntriesAt(ERROR) = %+v, want the boom error", errs)
+	}
+}
+
+func TestLoggerConcurrent(t *testing.T) {
+	logger := NewLogger()
+	l, ctx := logger.NewWithContext(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cl, _ := logger.NewWithContext(ctx)
+			cl.AddData("worker", i)
+			cl.WithTag("worker").Info("work")
+		}(i)
+	}
+	wg.Wait()
+	l.Info("done")
+
+	if len(logger.EntriesAt(stlogs.INFO)) != 11 {
+		t.Errorf("EntriesAt(INFO) = %d entries, want 11", len(logger.EntriesAt(stlogs.INFO)))
+	}
+}
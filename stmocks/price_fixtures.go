@@ -0,0 +1,213 @@
+// Code generated by go generate from pricefmt; DO NOT EDIT.
+
+package stmocks
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stocktwits/go-infrastructure/v2/pricefmt"
+)
+
+// priceFixtures returns new fixtures, so callers can change them.
+func priceFixtures() []PriceFixture {
+	return []PriceFixture{
+		{
+			Name:  "zero",
+			Value: decimal.RequireFromString("0"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "0",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     false,
+			},
+		},
+		{
+			Name:  "one",
+			Value: decimal.RequireFromString("1"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "1",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     false,
+			},
+		},
+		{
+			Name:  "minus one",
+			Value: decimal.RequireFromString("-1"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "-1",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     true,
+			},
+		},
+		{
+			Name:  "half",
+			Value: decimal.RequireFromString("0.5"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "0.5",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     false,
+			},
+		},
+		{
+			Name:  "below one",
+			Value: decimal.RequireFromString("0.999999"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "0.999999",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     false,
+			},
+		},
+		{
+			Name:  "three leading zeros",
+			Value: decimal.RequireFromString("0.0001"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      false,
+				IsSmallDecimal:    true,
+				RawValue:          "0.0001",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: ptr(3),
+				AfterZerosValue:   ptr[int64](1),
+			},
+		},
+		{
+			Name:  "below subscript boundary",
+			Value: decimal.RequireFromString("0.00001"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      false,
+				IsSmallDecimal:    true,
+				RawValue:          "0.00001",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: ptr(4),
+				AfterZerosValue:   ptr[int64](1),
+			},
+		},
+		{
+			Name:  "four leading zeros",
+			Value: decimal.RequireFromString("0.0000456"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      false,
+				IsSmallDecimal:    true,
+				RawValue:          "0.0000456",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: ptr(4),
+				AfterZerosValue:   ptr[int64](456),
+			},
+		},
+		{
+			Name:  "subscript boundary",
+			Value: decimal.RequireFromString("0.000001"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      true,
+				IsSmallDecimal:    true,
+				RawValue:          "0.000001",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: ptr(5),
+				AfterZerosValue:   ptr[int64](1),
+			},
+		},
+		{
+			Name:  "negative subscript",
+			Value: decimal.RequireFromString("-0.000001234"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      true,
+				IsSmallDecimal:    true,
+				RawValue:          "-0.000001234",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        true,
+				ZerosAfterDecimal: ptr(5),
+				AfterZerosValue:   ptr[int64](1234),
+			},
+		},
+		{
+			Name:  "truncated subscript value",
+			Value: decimal.RequireFromString("0.00000123456"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      true,
+				IsSmallDecimal:    true,
+				RawValue:          "0.00000123456",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: ptr(5),
+				AfterZerosValue:   ptr[int64](1234),
+			},
+		},
+		{
+			Name:  "tiny",
+			Value: decimal.RequireFromString("1e-10"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:      true,
+				IsSmallDecimal:    true,
+				RawValue:          "0.0000000001",
+				CurrencyCode:      "USD",
+				CurrencyString:    "$",
+				IsNegative:        false,
+				ZerosAfterDecimal: ptr(9),
+				AfterZerosValue:   ptr[int64](1),
+			},
+		},
+		{
+			Name:  "large",
+			Value: decimal.RequireFromString("12345678.9"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "12345678.9",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     false,
+			},
+		},
+		{
+			Name:  "negative large",
+			Value: decimal.RequireFromString("-98765432.1"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "-98765432.1",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     true,
+			},
+		},
+		{
+			Name:  "beyond int64 precision",
+			Value: decimal.RequireFromString("123456789012345678901234567890.123456789"),
+			Formatted: pricefmt.PriceFormatted{
+				UseSubscript:   false,
+				IsSmallDecimal: false,
+				RawValue:       "123456789012345678901234567890.123456789",
+				CurrencyCode:   "USD",
+				CurrencyString: "$",
+				IsNegative:     false,
+			},
+		},
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
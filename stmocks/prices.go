@@ -0,0 +1,77 @@
+package stmocks
+
+import (
+	"sync"
+
+	"github.com/shopspring/decimal"
+	"github.com/stocktwits/go-infrastructure/v2/pricefmt"
+)
+
+//go:generate go test -run TestPriceFixtures -update
+
+// PriceFixture is a price exercising an edge case of pricefmt, with its expected formatting in USD.
+type PriceFixture struct {
+	// Name describes the edge case, i.e. "subscript boundary".
+	Name      string
+	Value     decimal.Decimal
+	Formatted pricefmt.PriceFormatted
+}
+
+// priceFixtureInputs are the prices of the fixtures. The expected formatting of each one is generated from
+// pricefmt into price_fixtures.go by go generate, and checked by the tests of the package.
+var priceFixtureInputs = []struct {
+	name  string
+	value string
+}{
+	{"zero", "0"},
+	{"one", "1"},
+	{"minus one", "-1"},
+	{"half", "0.5"},
+	{"below one", "0.999999"},
+	{"three leading zeros", "0.0001"},
+	{"below subscript boundary", "0.00001"},
+	{"four leading zeros", "0.0000456"},
+	{"subscript boundary", "0.000001"},
+	{"negative subscript", "-0.000001234"},
+	{"truncated subscript value", "0.00000123456"},
+	{"tiny", "1e-10"},
+	{"large", "12345678.9"},
+	{"negative large", "-98765432.1"},
+	{"beyond int64 precision", "123456789012345678901234567890.123456789"},
+}
+
+// PriceFixtures returns prices covering the edge cases of pricefmt, with the formatting pricefmt.Format returns
+// for them: zero, negatives, small decimals on each side of the subscript boundary, values truncated after
+// the leading zeros, large values and values beyond the precision of int64.
+func PriceFixtures() []PriceFixture {
+	return priceFixtures()
+}
+
+// PriceFeed returns prices in sequence, starting over after the last one. It is safe for concurrent use.
+type PriceFeed struct {
+	mu     sync.Mutex
+	values []decimal.Decimal
+	next   int
+}
+
+// NewPriceFeed creates a feed of the values, or of the values of PriceFixtures if there are none.
+func NewPriceFeed(values ...decimal.Decimal) *PriceFeed {
+	if len(values) == 0 {
+		for _, fixture := range PriceFixtures() {
+			values = append(values, fixture.Value)
+		}
+	}
+
+	return &PriceFeed{values: values}
+}
+
+// Next returns the next price, the first one after the last.
+func (f *PriceFeed) Next() decimal.Decimal {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value := f.values[f.next]
+	f.next = (f.next + 1) % len(f.values)
+
+	return value
+}
@@ -0,0 +1,124 @@
+package stmocks
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stocktwits/go-infrastructure/v2/pricefmt"
+)
+
+const priceFixturesFile = "price_fixtures.go"
+
+// generatePriceFixtures returns the source of price_fixtures.go, with the formatting of the fixture inputs by pricefmt.
+func generatePriceFixtures() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go generate from pricefmt; DO NOT EDIT.\n\n")
+	buf.WriteString("package stmocks\n\n")
+	buf.WriteString("import (\n\"github.com/shopspring/decimal\"\n\"github.com/stocktwits/go-infrastructure/v2/pricefmt\"\n)\n\n")
+	buf.WriteString("// priceFixtures returns new fixtures, so callers can change them.\n")
+	buf.WriteString("func priceFixtures() []PriceFixture {\nreturn []PriceFixture{\n")
+
+	for _, input := range priceFixtureInputs {
+		formatted, err := pricefmt.Format(input.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", input.name, err)
+		}
+
+		fmt.Fprintf(&buf, "{\nName: %q,\nValue: decimal.RequireFromString(%q),\nFormatted: pricefmt.PriceFormatted{\n", input.name, input.value)
+		fmt.Fprintf(&buf, "UseSubscript: %t,\nIsSmallDecimal: %t,\nRawValue: %q,\nCurrencyCode: %q,\nCurrencyString: %q,\nIsNegative: %t,\n",
+			formatted.UseSubscript, formatted.IsSmallDecimal, formatted.RawValue, formatted.CurrencyCode, formatted.CurrencyString, formatted.IsNegative)
+		if formatted.ZerosAfterDecimal != nil {
+			fmt.Fprintf(&buf, "ZerosAfterDecimal: ptr(%d),\n", *formatted.ZerosAfterDecimal)
+		}
+		if formatted.AfterZerosValue != nil {
+			fmt.Fprintf(&buf, "AfterZerosValue: ptr[int64](%d),\n", *formatted.AfterZerosValue)
+		}
+		buf.WriteString("},\n},\n")
+	}
+	buf.WriteString("}\n}\n\n")
+	buf.WriteString("// ptr returns a pointer to v.\nfunc ptr[T any](v T) *T {\nreturn &v\n}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+func TestPriceFixtures(t *testing.T) {
+	want, err := generatePriceFixtures()
+	if err != nil {
+		t.Fatalf("generatePriceFixtures() unexpected error = %v", err)
+	}
+
+	if updateGolden() {
+		if err := os.WriteFile(priceFixturesFile, want, 0644); err != nil {
+			t.Fatalf("failed to update %s: %v", priceFixturesFile, err)
+		}
+	}
+
+	got, err := os.ReadFile(priceFixturesFile)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", priceFixturesFile, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of sync with pricefmt, run go generate ./stmocks", priceFixturesFile)
+	}
+
+	for _, fixture := range PriceFixtures() {
+		formatted, err := pricefmt.Format(fixture.Value)
+		if err != nil {
+			t.Errorf("pricefmt.Format(%s) unexpected error = %v", fixture.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*formatted, fixture.Formatted) {
+			t.Errorf("pricefmt.Format(%s) = %+v, want %+v", fixture.Name, *formatted, fixture.Formatted)
+		}
+	}
+}
+
+func TestPriceFixturesCoverage(t *testing.T) {
+	values := map[string]bool{}
+	subscripts := 0
+	for _, fixture := range PriceFixtures() {
+		values[fixture.Value.String()] = true
+		if fixture.Formatted.UseSubscript {
+			subscripts++
+		}
+	}
+
+	for _, want := range []string{"0", "1", "-1", "0.0001", "0.0000456", "0.0000000001", "12345678.9"} {
+		if !values[decimal.RequireFromString(want).String()] {
+			t.Errorf("PriceFixtures() has no %s", want)
+		}
+	}
+	if subscripts == 0 {
+		t.Error("PriceFixtures() has no subscript price")
+	}
+
+	fixtures := PriceFixtures()
+	fixtures[0].Formatted.RawValue = "changed"
+	if PriceFixtures()[0].Formatted.RawValue == "changed" {
+		t.Error("PriceFixtures() returned shared fixtures, want new ones")
+	}
+}
+
+func TestPriceFeed(t *testing.T) {
+	feed := NewPriceFeed(decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3))
+
+	var got []string
+	for i := 0; i < 7; i++ {
+		got = append(got, feed.Next().String())
+	}
+	if want := []string{"1", "2", "3", "1", "2", "3", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+
+	fixtures := NewPriceFeed()
+	for i, fixture := range PriceFixtures() {
+		if next := fixtures.Next(); !next.Equal(fixture.Value) {
+			t.Errorf("Next() %d = %v, want fixture %s", i, next, fixture.Name)
+		}
+	}
+}
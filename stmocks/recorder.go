@@ -0,0 +1,127 @@
+package stmocks
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Call is a call recorded by a Recorder.
+type Call struct {
+	Name string
+	Args []any
+}
+
+// Recorder records the calls made to a fake, so tests can assert on them afterwards.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecorder creates a recorder without calls.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Record records a call of the method name with its arguments.
+func (r *Recorder) Record(name string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Name: name, Args: args})
+}
+
+// Calls returns the recorded calls, in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// CallCount returns the number of recorded calls of the method name.
+func (r *Recorder) CallCount(name string) int {
+	count := 0
+	for _, call := range r.Calls() {
+		if call.Name == name {
+			count++
+		}
+	}
+
+	return count
+}
+
+// CalledWith reports whether a call of the method name was recorded with as many arguments as matchers,
+// each one matched by the matcher at the same position.
+func (r *Recorder) CalledWith(name string, matchers ...Matcher) bool {
+	for _, call := range r.Calls() {
+		if call.Name == name && matchArgs(call.Args, matchers) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchArgs reports whether each argument is matched by the matcher at the same position.
+func matchArgs(args []any, matchers []Matcher) bool {
+	if len(args) != len(matchers) {
+		return false
+	}
+
+	for i, matcher := range matchers {
+		if !matcher.Match(args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CallsInOrder reports whether calls of the method names were recorded in this order, other calls being
+// allowed in between.
+func (r *Recorder) CallsInOrder(names ...string) bool {
+	next := 0
+	for _, call := range r.Calls() {
+		if next < len(names) && call.Name == names[next] {
+			next++
+		}
+	}
+
+	return next == len(names)
+}
+
+// Reset removes the recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
+
+// Matcher matches an argument of a recorded call.
+type Matcher interface {
+	Match(arg any) bool
+}
+
+// MatcherFunc is a Matcher calling the function.
+type MatcherFunc func(arg any) bool
+
+// Match returns f(arg).
+func (f MatcherFunc) Match(arg any) bool {
+	return f(arg)
+}
+
+// Eq matches the arguments equal to v, compared with reflect.DeepEqual.
+func Eq(v any) Matcher {
+	return MatcherFunc(func(arg any) bool {
+		return reflect.DeepEqual(arg, v)
+	})
+}
+
+// Any matches any argument.
+func Any() Matcher {
+	return MatcherFunc(func(any) bool {
+		return true
+	})
+}
@@ -0,0 +1,93 @@
+package stmocks
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMailer is a fake built on a Recorder.
+type fakeMailer struct {
+	*Recorder
+}
+
+func (m fakeMailer) Connect(host string) {
+	m.Record("Connect", host)
+}
+
+func (m fakeMailer) SendEmail(to, subject string, attachments []string) {
+	m.Record("SendEmail", to, subject, attachments)
+}
+
+func (m fakeMailer) Close() {
+	m.Record("Close")
+}
+
+func TestRecorder(t *testing.T) {
+	mailer := fakeMailer{NewRecorder()}
+	mailer.Connect("smtp.local")
+	mailer.SendEmail("john@example.com", "Welcome", nil)
+	mailer.SendEmail("jane@example.com", "Your report", []string{"report.pdf"})
+	mailer.Close()
+
+	hasPrefix := func(prefix string) Matcher {
+		return MatcherFunc(func(arg any) bool {
+			s, ok := arg.(string)
+			return ok && strings.HasPrefix(s, prefix)
+		})
+	}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"equal arguments", mailer.CalledWith("SendEmail", Eq("jane@example.com"), Eq("Your report"), Eq([]string{"report.pdf"})), true},
+		{"any arguments", mailer.CalledWith("SendEmail", Any(), Eq("Welcome"), Any()), true},
+		{"func matcher", mailer.CalledWith("SendEmail", hasPrefix("jane"), Any(), Any()), true},
+		{"different argument", mailer.CalledWith("SendEmail", Eq("bob@example.com"), Any(), Any()), false},
+		{"wrong argument count", mailer.CalledWith("SendEmail", Any()), false},
+		{"no arguments", mailer.CalledWith("Close"), true},
+		{"not called", mailer.CalledWith("Open"), false},
+		{"in order", mailer.CallsInOrder("Connect", "SendEmail", "Close"), true},
+		{"in order with calls in between", mailer.CallsInOrder("Connect", "Close"), true},
+		{"repeated calls in order", mailer.CallsInOrder("SendEmail", "SendEmail", "Close"), true},
+		{"out of order", mailer.CallsInOrder("Close", "Connect"), false},
+		{"more calls than recorded", mailer.CallsInOrder("Connect", "Connect"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if count := mailer.CallCount("SendEmail"); count != 2 {
+		t.Errorf("CallCount(SendEmail) = %d, want 2", count)
+	}
+
+	mailer.Reset()
+	if calls := mailer.Calls(); len(calls) != 0 {
+		t.Errorf("Calls() after Reset() = %v, want none", calls)
+	}
+}
+
+func TestRecorderConcurrent(t *testing.T) {
+	mailer := fakeMailer{NewRecorder()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mailer.SendEmail("john@example.com", "Alert", nil)
+		}()
+	}
+	wg.Wait()
+
+	if count := mailer.CallCount("SendEmail"); count != 20 {
+		t.Errorf("CallCount(SendEmail) = %d, want 20", count)
+	}
+}
@@ -0,0 +1,129 @@
+package stmocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// scenarios are the scenarios defined by DefineScenario, keyed by name.
+var (
+	scenariosMu sync.RWMutex
+	scenarios   = map[string]*scenario{}
+)
+
+// scenario is a named bundle of mock values, error injections and clock offset.
+type scenario struct {
+	apply       []func(ctx context.Context) context.Context
+	clockOffset time.Duration
+}
+
+// ScenarioBuilder configures a scenario in the setup function of DefineScenario.
+type ScenarioBuilder struct {
+	scenario *scenario
+}
+
+// DefineScenario defines the scenario name, i.e. "payment declines", configured by setup, so integration tests
+// can apply it with WithScenario instead of scattering its values across packages. It returns name, so scenarios
+// can be defined by package variables. It panics if the scenario is already defined.
+func DefineScenario(name string, setup func(b *ScenarioBuilder)) string {
+	b := &ScenarioBuilder{scenario: &scenario{}}
+	setup(b)
+
+	scenariosMu.Lock()
+	defer scenariosMu.Unlock()
+
+	if _, exists := scenarios[name]; exists {
+		panic(fmt.Sprintf("stmocks: scenario %q already defined", name))
+	}
+	scenarios[name] = b.scenario
+
+	return name
+}
+
+// ScenarioValue sets the mock value v under the name when the scenario is applied, as WithValue does.
+func ScenarioValue[T any](b *ScenarioBuilder, name string, v T) *ScenarioBuilder {
+	b.scenario.apply = append(b.scenario.apply, func(ctx context.Context) context.Context {
+		return WithValue(ctx, name, v)
+	})
+
+	return b
+}
+
+// Error injects err for the target when the scenario is applied, as WithError does.
+func (b *ScenarioBuilder) Error(target string, err error) *ScenarioBuilder {
+	b.scenario.apply = append(b.scenario.apply, func(ctx context.Context) context.Context {
+		return WithError(ctx, target, err)
+	})
+
+	return b
+}
+
+// ErrorOnce injects err once for the target when the scenario is applied, as WithErrorOnce does.
+// Each context created by WithScenario returns the error once.
+func (b *ScenarioBuilder) ErrorOnce(target string, err error) *ScenarioBuilder {
+	b.scenario.apply = append(b.scenario.apply, func(ctx context.Context) context.Context {
+		return WithErrorOnce(ctx, target, err)
+	})
+
+	return b
+}
+
+// ClockOffset moves the clocks created by Scenarios.NewClock forward by d, i.e. to make cached data stale.
+// Offsets add up.
+func (b *ScenarioBuilder) ClockOffset(d time.Duration) *ScenarioBuilder {
+	b.scenario.clockOffset += d
+
+	return b
+}
+
+// Scenarios describes the scenarios applied to a context.
+type Scenarios struct {
+	// Names are the names of the scenarios, in the order they were applied.
+	Names []string
+	// ClockOffset is the sum of the clock offsets of the scenarios.
+	ClockOffset time.Duration
+}
+
+// Active reports whether the scenario name is applied.
+func (s Scenarios) Active(name string) bool {
+	return contains(s.Names, name)
+}
+
+// NewClock creates a fake clock set to start moved forward by the clock offset of the scenarios.
+func (s Scenarios) NewClock(start time.Time) *ClockMock {
+	return NewClock(start.Add(s.ClockOffset))
+}
+
+// scenariosKey is the context key of the applied Scenarios.
+type scenariosKey struct{}
+
+// WithScenario returns a copy of ctx with the values and error injections of the scenario name. Scenarios compose,
+// the values and errors of the last scenario applied overriding the previous ones. It panics if the scenario is not
+// defined, so a mistyped name fails when the test context is created rather than being silently ignored.
+func WithScenario(ctx context.Context, name string) context.Context {
+	scenariosMu.RLock()
+	s, ok := scenarios[name]
+	scenariosMu.RUnlock()
+
+	if !ok {
+		panic(fmt.Sprintf("stmocks: unknown scenario %q", name))
+	}
+
+	for _, apply := range s.apply {
+		ctx = apply(ctx)
+	}
+
+	active := FromScenario(ctx)
+	active.Names = append(append([]string(nil), active.Names...), name)
+	active.ClockOffset += s.clockOffset
+
+	return context.WithValue(ctx, scenariosKey{}, active)
+}
+
+// FromScenario returns the scenarios applied to ctx by WithScenario, without names if there are none.
+func FromScenario(ctx context.Context) Scenarios {
+	s, _ := ctx.Value(scenariosKey{}).(Scenarios)
+	return s
+}
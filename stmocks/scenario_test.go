@@ -0,0 +1,100 @@
+package stmocks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	errDeclined = errors.New("payment declined")
+	errTimeout  = errors.New("gateway timeout")
+
+	paymentDeclines = DefineScenario("payment declines", func(b *ScenarioBuilder) {
+		b.Error("charge", errDeclined)
+		ScenarioValue(b, "card", "4000-0000-0000-0002")
+	})
+	quoteFeedStale = DefineScenario("quote feed stale", func(b *ScenarioBuilder) {
+		ScenarioValue(b, "quote age", 15*time.Minute)
+		b.ClockOffset(10 * time.Minute).ClockOffset(5 * time.Minute)
+		b.ErrorOnce("quotes", errTimeout)
+	})
+)
+
+func TestWithScenario(t *testing.T) {
+	ctx := WithScenario(context.Background(), paymentDeclines)
+	ctx = WithScenario(ctx, quoteFeedStale)
+
+	if err := ErrorFor(ctx, "charge"); err != errDeclined {
+		t.Errorf("ErrorFor(charge) = %v, want %v", err, errDeclined)
+	}
+	if err := ErrorFor(ctx, "quotes"); err != errTimeout {
+		t.Errorf("ErrorFor(quotes) = %v, want %v", err, errTimeout)
+	}
+	if err := ErrorFor(ctx, "quotes"); err != nil {
+		t.Errorf("ErrorFor(quotes) second call = %v, want nil", err)
+	}
+
+	if card, ok := Value[string](ctx, "card"); !ok || card != "4000-0000-0000-0002" {
+		t.Errorf("Value(card) = %q, %v, want the declined card", card, ok)
+	}
+	if age, ok := Value[time.Duration](ctx, "quote age"); !ok || age != 15*time.Minute {
+		t.Errorf("Value(quote age) = %v, %v, want 15m", age, ok)
+	}
+
+	active := FromScenario(ctx)
+	if want := []string{paymentDeclines, quoteFeedStale}; !reflect.DeepEqual(active.Names, want) {
+		t.Errorf("FromScenario().Names = %v, want %v", active.Names, want)
+	}
+	if !active.Active(paymentDeclines) || active.Active("unknown") {
+		t.Errorf("FromScenario().Active() does not match the names %v", active.Names)
+	}
+
+	start := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	if now := active.NewClock(start).Now(); !now.Equal(start.Add(15 * time.Minute)) {
+		t.Errorf("NewClock().Now() = %v, want the start moved by the 15m offset", now)
+	}
+
+	// the first context is not changed by the composition
+	if first := FromScenario(WithScenario(context.Background(), paymentDeclines)); len(first.Names) != 1 || first.ClockOffset != 0 {
+		t.Errorf("FromScenario() = %+v, want only the payment scenario", first)
+	}
+	if none := FromScenario(context.Background()); len(none.Names) != 0 {
+		t.Errorf("FromScenario() without scenario = %+v, want none", none)
+	}
+}
+
+func TestWithScenarioErrorOncePerContext(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		ctx := WithScenario(context.Background(), quoteFeedStale)
+		if err := ErrorFor(ctx, "quotes"); err != errTimeout {
+			t.Errorf("context %d: ErrorFor(quotes) = %v, want %v", i, err, errTimeout)
+		}
+	}
+}
+
+func TestScenarioPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       func()
+		wantMsg string
+	}{
+		{"unknown", func() { WithScenario(context.Background(), "payment declined") }, `unknown scenario "payment declined"`},
+		{"duplicate", func() { DefineScenario(paymentDeclines, func(*ScenarioBuilder) {}) }, `scenario "payment declines" already defined`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, tt.wantMsg) {
+					t.Errorf("panic = %q, want it to contain %q", msg, tt.wantMsg)
+				}
+			}()
+			tt.f()
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package stmocks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// secretARNPrefix prefixes the name of a secret to build its fake ARN.
+const secretARNPrefix = "arn:aws:secretsmanager:us-east-1:000000000000:secret:"
+
+// FakeSecretsManager is an in-memory Secrets Manager client serving secret strings, implementing
+// ssmenv.SecretsClient. Secrets can be fetched by name or by the ARN returned by SecretARN, and are
+// listed sorted by name, PageSize secrets per page. It is safe for concurrent use.
+type FakeSecretsManager struct {
+	// Secrets are the secret strings keyed by secret name.
+	Secrets map[string]string
+	// PageSize is the number of secrets listed per page, all of them if zero.
+	PageSize int
+
+	mu         sync.Mutex
+	listCalls  int
+	valueCalls int
+}
+
+// NewFakeSecretsManager creates a fake Secrets Manager client serving the secrets, keyed by name.
+func NewFakeSecretsManager(secrets map[string]string) *FakeSecretsManager {
+	return &FakeSecretsManager{Secrets: secrets}
+}
+
+// SecretARN returns the ARN FakeSecretsManager gives to the secret name.
+func SecretARN(name string) string {
+	return secretARNPrefix + name
+}
+
+// GetSecretValue returns the secret identified by the name or ARN of the input SecretId.
+// It returns a ResourceNotFoundException error if the secret does not exist.
+func (f *FakeSecretsManager) GetSecretValue(_ context.Context, input *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.valueCalls++
+
+	name := strings.TrimPrefix(aws.ToString(input.SecretId), secretARNPrefix)
+	value, ok := f.Secrets[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("secret %s not found", name))}
+	}
+
+	return &secretsmanager.GetSecretValueOutput{
+		ARN:          aws.String(SecretARN(name)),
+		Name:         aws.String(name),
+		SecretString: aws.String(value),
+	}, nil
+}
+
+// ListSecrets returns the page selected by the NextToken of the input of the secrets whose name starts
+// with one of the values of the name filters, all secrets without filters.
+func (f *FakeSecretsManager) ListSecrets(_ context.Context, input *secretsmanager.ListSecretsInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.ListSecretsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listCalls++
+
+	var prefixes []string
+	for _, filter := range input.Filters {
+		if filter.Key == types.FilterNameStringTypeName {
+			prefixes = append(prefixes, filter.Values...)
+		}
+	}
+
+	var names []string
+	for name := range f.Secrets {
+		if len(prefixes) == 0 || hasAnyPrefix(name, prefixes) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start := 0
+	if input.NextToken != nil {
+		var err error
+		if start, err = strconv.Atoi(*input.NextToken); err != nil || start <= 0 || start >= len(names) {
+			return nil, fmt.Errorf("invalid next token %q", *input.NextToken)
+		}
+	}
+
+	end := len(names)
+	if f.PageSize > 0 && start+f.PageSize < end {
+		end = start + f.PageSize
+	}
+
+	output := &secretsmanager.ListSecretsOutput{}
+	for _, name := range names[start:end] {
+		output.SecretList = append(output.SecretList, types.SecretListEntry{
+			ARN:  aws.String(SecretARN(name)),
+			Name: aws.String(name),
+		})
+	}
+	if end < len(names) {
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+
+	return output, nil
+}
+
+// Calls returns the number of ListSecrets and GetSecretValue calls.
+func (f *FakeSecretsManager) Calls() (list, value int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.listCalls, f.valueCalls
+}
+
+// hasAnyPrefix reports whether s starts with one of the prefixes.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package stmocks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+func TestFakeSecretsManager(t *testing.T) {
+	fake := NewFakeSecretsManager(map[string]string{
+		"app/db":    "s3cret",
+		"app/key":   "abc",
+		"app/token": "tok",
+		"other/key": "other",
+	})
+	fake.PageSize = 2
+
+	for _, id := range []string{"app/db", SecretARN("app/db")} {
+		output, err := fake.GetSecretValue(context.Background(), &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+		if err != nil {
+			t.Fatalf("GetSecretValue(%s) unexpected error = %v", id, err)
+		}
+		if aws.ToString(output.SecretString) != "s3cret" || aws.ToString(output.ARN) != SecretARN("app/db") {
+			t.Errorf("GetSecretValue(%s) = %q %s, want %q %s", id, aws.ToString(output.SecretString), aws.ToString(output.ARN), "s3cret", SecretARN("app/db"))
+		}
+	}
+
+	var notFound *types.ResourceNotFoundException
+	if _, err := fake.GetSecretValue(context.Background(), &secretsmanager.GetSecretValueInput{SecretId: aws.String("app/none")}); !errors.As(err, &notFound) {
+		t.Errorf("GetSecretValue() error = %v, want ResourceNotFoundException", err)
+	}
+
+	var names []string
+	var nextToken *string
+	for {
+		output, err := fake.ListSecrets(context.Background(), &secretsmanager.ListSecretsInput{
+			Filters:   []types.Filter{{Key: types.FilterNameStringTypeName, Values: []string{"app/"}}},
+			NextToken: nextToken,
+		})
+		if err != nil {
+			t.Fatalf("ListSecrets() unexpected error = %v", err)
+		}
+		for _, entry := range output.SecretList {
+			names = append(names, aws.ToString(entry.Name))
+		}
+		if nextToken = output.NextToken; nextToken == nil {
+			break
+		}
+	}
+
+	if want := []string{"app/db", "app/key", "app/token"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListSecrets() = %v, want %v", names, want)
+	}
+	if list, value := fake.Calls(); list != 2 || value != 3 {
+		t.Errorf("Calls() = %d, %d, want 2 list and 3 value calls", list, value)
+	}
+}
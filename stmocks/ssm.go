@@ -0,0 +1,414 @@
+package stmocks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/smithy-go"
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
+)
+
+// defaultSSMPageSize is the maximum number of parameters SSM returns by page.
+const defaultSSMPageSize = 10
+
+// maxSSMBatchSize is the maximum number of names SSM accepts by GetParameters call.
+const maxSSMBatchSize = 10
+
+// SSMThrottlingError returns the error SSM fails with when a call is throttled, retried by ssmenv.
+func SSMThrottlingError() error {
+	return &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+}
+
+// SSMAccessDeniedError returns the error SSM fails with when the caller lacks permissions, never retried by ssmenv.
+func SSMAccessDeniedError() error {
+	return &smithy.GenericAPIError{
+		Code:    "AccessDeniedException",
+		Message: "User is not authorized to perform: ssm:GetParametersByPath",
+	}
+}
+
+// FakeSSM is an in-memory SSM client serving parameters by path, name, version and label, implementing
+// ssmenv.ParamClient, ssmenv.VersionParamClient and ssmenv.BatchParamClient.
+// It is safe for concurrent use.
+type FakeSSM struct {
+	// PageSize is the maximum number of parameters returned by call, 10 by default like SSM.
+	PageSize int
+	// Delay is waited by the GetParametersByPath calls before responding, unless their context is done first.
+	Delay time.Duration
+
+	mu           sync.Mutex
+	clock        stclock.Clock
+	params       map[string]*fakeParameter
+	failures     []error
+	pathFailures map[string]error
+	inputs       []*ssm.GetParametersByPathInput
+	paramInputs  []*ssm.GetParameterInput
+	batchInputs  []*ssm.GetParametersInput
+	inFlight     int
+	maxInFlight  int
+}
+
+// fakeParameter holds the versions of a parameter, the first one being version 1, and its labels.
+type fakeParameter struct {
+	paramType types.ParameterType
+	versions  []fakeVersion
+	labels    map[string]int64
+}
+
+// fakeVersion is a version of a parameter with the time it was stored.
+type fakeVersion struct {
+	value    string
+	modified time.Time
+}
+
+// NewFakeSSM creates a fake SSM client serving the parameters, keyed by full name, i.e. /myapp/db/host.
+func NewFakeSSM(params map[string]string) *FakeSSM {
+	f := &FakeSSM{
+		PageSize: defaultSSMPageSize,
+		clock:    stclock.System(),
+		params:   make(map[string]*fakeParameter, len(params)),
+	}
+	for name, value := range params {
+		f.put(name, value)
+	}
+
+	return f
+}
+
+// WithClock stamps the versions stored from now on and waits the Delay with clock, i.e. a ClockMock,
+// instead of the system clock.
+func (f *FakeSSM) WithClock(clock stclock.Clock) *FakeSSM {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.clock = clock
+
+	return f
+}
+
+// Put stores a new version of the parameter holding value, like PutParameter with overwrite, and returns
+// its version number. The parameter is created as a String if it does not exist.
+func (f *FakeSSM) Put(name, value string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.put(name, value)
+}
+
+// put stores a new version of the parameter and returns its version number.
+func (f *FakeSSM) put(name, value string) int64 {
+	param, ok := f.params[name]
+	if !ok {
+		param = &fakeParameter{paramType: types.ParameterTypeString, labels: map[string]int64{}}
+		f.params[name] = param
+	}
+	param.versions = append(param.versions, fakeVersion{value: value, modified: f.clock.Now()})
+
+	return int64(len(param.versions))
+}
+
+// LabelVersion attaches the labels to the version of the parameter, moving them from any other version,
+// like LabelParameterVersion.
+func (f *FakeSSM) LabelVersion(name string, version int64, labels ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if param, ok := f.params[name]; ok {
+		for _, label := range labels {
+			param.labels[label] = version
+		}
+	}
+}
+
+// SetSecure flags the parameters as SecureString. Their value is only returned in clear to the calls
+// requesting decryption.
+func (f *FakeSSM) SetSecure(names ...string) {
+	f.setType(types.ParameterTypeSecureString, names)
+}
+
+// SetStringList flags the parameters as StringList, holding comma-separated values.
+func (f *FakeSSM) SetStringList(names ...string) {
+	f.setType(types.ParameterTypeStringList, names)
+}
+
+// setType sets the type of the parameters.
+func (f *FakeSSM) setType(paramType types.ParameterType, names []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, name := range names {
+		if param, ok := f.params[name]; ok {
+			param.paramType = paramType
+		}
+	}
+}
+
+// FailFirst makes the next n GetParametersByPath calls fail with err, i.e. SSMThrottlingError or
+// SSMAccessDeniedError. Calling it again queues more failures after the previous ones.
+func (f *FakeSSM) FailFirst(n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		f.failures = append(f.failures, err)
+	}
+}
+
+// FailPath makes every GetParametersByPath call for the path fail with err, once the failures queued by
+// FailFirst are consumed.
+func (f *FakeSSM) FailPath(path string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.pathFailures == nil {
+		f.pathFailures = make(map[string]error)
+	}
+	f.pathFailures[path] = err
+}
+
+// Calls returns the number of GetParametersByPath calls made, failed ones included.
+func (f *FakeSSM) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.inputs)
+}
+
+// Inputs returns the inputs of the GetParametersByPath calls made, failed ones included, in order.
+func (f *FakeSSM) Inputs() []*ssm.GetParametersByPathInput {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*ssm.GetParametersByPathInput(nil), f.inputs...)
+}
+
+// ParamInputs returns the inputs of the GetParameter calls made, failed ones included, in order.
+func (f *FakeSSM) ParamInputs() []*ssm.GetParameterInput {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*ssm.GetParameterInput(nil), f.paramInputs...)
+}
+
+// BatchInputs returns the inputs of the GetParameters calls made, failed ones included, in order.
+func (f *FakeSSM) BatchInputs() []*ssm.GetParametersInput {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*ssm.GetParametersInput(nil), f.batchInputs...)
+}
+
+// MaxInFlight returns the highest number of GetParametersByPath calls that were in flight at the same time.
+func (f *FakeSSM) MaxInFlight() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.maxInFlight
+}
+
+// GetParametersByPath waits for the Delay, then returns the next injected failure if any, otherwise the page of
+// parameters under the path of the input selected by its NextToken, sorted by name. Only the parameters directly
+// under the path are returned unless the input is recursive, and only the labeled versions if the input filters
+// them by label. It returns the context error if ctx is done first.
+func (f *FakeSSM) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.mu.Lock()
+	f.inputs = append(f.inputs, input)
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	delay, clock := f.Delay, f.clock
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if delay > 0 {
+		timer := clock.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-timer.C():
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		return nil, err
+	}
+	if err, ok := f.pathFailures[aws.ToString(input.Path)]; ok {
+		return nil, err
+	}
+
+	label := ""
+	for _, filter := range input.ParameterFilters {
+		if aws.ToString(filter.Key) == "Label" && len(filter.Values) > 0 {
+			label = filter.Values[0]
+		}
+	}
+	names := f.namesUnder(aws.ToString(input.Path), aws.ToBool(input.Recursive), label)
+
+	start := 0
+	if input.NextToken != nil {
+		var err error
+		start, err = strconv.Atoi(aws.ToString(input.NextToken))
+		if err != nil || start < 0 || start > len(names) {
+			return nil, &types.InvalidNextToken{Message: aws.String(fmt.Sprintf("invalid NextToken %q", aws.ToString(input.NextToken)))}
+		}
+	}
+
+	pageSize := f.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultSSMPageSize
+	}
+	if maxResults := int(aws.ToInt32(input.MaxResults)); maxResults > 0 && maxResults < pageSize {
+		pageSize = maxResults
+	}
+	end := min(start+pageSize, len(names))
+
+	output := &ssm.GetParametersByPathOutput{}
+	for _, name := range names[start:end] {
+		version := int64(len(f.params[name].versions))
+		if label != "" {
+			version = f.params[name].labels[label]
+		}
+		output.Parameters = append(output.Parameters, f.parameter(name, version, aws.ToBool(input.WithDecryption)))
+	}
+	if end < len(names) {
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+
+	return output, nil
+}
+
+// namesUnder returns the sorted names of the parameters under the path, only those with a version labeled
+// with label if set.
+func (f *FakeSSM) namesUnder(path string, recursive bool, label string) []string {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+
+	var names []string
+	for name, param := range f.params {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" || (!recursive && strings.Contains(rest, "/")) {
+			continue
+		}
+		if _, labeled := param.labels[label]; label != "" && !labeled {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GetParameter returns the parameter selected by the name of the input, optionally followed by a version or
+// label selector, i.e. /myapp/key:3 or /myapp/key:stable. It returns a ParameterNotFound error if the parameter
+// does not exist, and a ParameterVersionNotFound error if the version or label does not.
+func (f *FakeSSM) GetParameter(_ context.Context, input *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.paramInputs = append(f.paramInputs, input)
+
+	param, err := f.selectParameter(aws.ToString(input.Name), aws.ToBool(input.WithDecryption))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssm.GetParameterOutput{Parameter: param}, nil
+}
+
+// GetParameters returns the parameters selected by the names of the input like GetParameter, and the
+// names that do not select a parameter as invalid parameters. It fails if more than 10 names are
+// requested, like SSM.
+func (f *FakeSSM) GetParameters(_ context.Context, input *ssm.GetParametersInput, _ ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batchInputs = append(f.batchInputs, input)
+
+	if len(input.Names) > maxSSMBatchSize {
+		return nil, &smithy.GenericAPIError{
+			Code:    "ValidationException",
+			Message: fmt.Sprintf("%d names requested, at most %d are allowed", len(input.Names), maxSSMBatchSize),
+		}
+	}
+
+	output := &ssm.GetParametersOutput{}
+	for _, name := range input.Names {
+		if param, err := f.selectParameter(name, aws.ToBool(input.WithDecryption)); err == nil {
+			output.Parameters = append(output.Parameters, *param)
+		} else {
+			output.InvalidParameters = append(output.InvalidParameters, name)
+		}
+	}
+
+	return output, nil
+}
+
+// selectParameter returns the parameter selected by a name optionally followed by a version or label
+// selector. Like SSM, the name of the parameter returned excludes the selector.
+func (f *FakeSSM) selectParameter(nameSelector string, decrypt bool) (*types.Parameter, error) {
+	name, selector, hasSelector := strings.Cut(nameSelector, ":")
+	param, ok := f.params[name]
+	if !ok {
+		return nil, &types.ParameterNotFound{Message: aws.String(fmt.Sprintf("parameter %s not found", name))}
+	}
+
+	version := int64(len(param.versions))
+	if hasSelector {
+		if number, err := strconv.ParseInt(selector, 10, 64); err == nil {
+			version = number
+		} else {
+			version = param.labels[selector]
+		}
+	}
+	if version < 1 || version > int64(len(param.versions)) {
+		return nil, &types.ParameterVersionNotFound{Message: aws.String(fmt.Sprintf("version %s of parameter %s not found", selector, name))}
+	}
+
+	selected := f.parameter(name, version, decrypt)
+	if hasSelector {
+		selected.Selector = aws.String(":" + selector)
+	}
+
+	return &selected, nil
+}
+
+// parameter returns the version of the parameter of the name, with its value masked if it is secure and not
+// decrypted.
+func (f *FakeSSM) parameter(name string, version int64, decrypt bool) types.Parameter {
+	param := f.params[name]
+	stored := param.versions[version-1]
+
+	selected := types.Parameter{
+		Name:             aws.String(name),
+		Value:            aws.String(stored.value),
+		Type:             param.paramType,
+		Version:          version,
+		LastModifiedDate: aws.Time(stored.modified),
+	}
+	if param.paramType == types.ParameterTypeSecureString && !decrypt {
+		selected.Value = aws.String("encrypted:" + name)
+	}
+
+	return selected
+}
@@ -0,0 +1,376 @@
+package stmocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/stocktwits/go-infrastructure/v2/ssmenv"
+)
+
+// unsetEnv removes the environment variables at the end of the test.
+func unsetEnv(t *testing.T, keys ...string) {
+	t.Cleanup(func() {
+		for _, key := range keys {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestFakeSSMPagination(t *testing.T) {
+	params := map[string]string{}
+	for i := 0; i < 25; i++ {
+		params[fmt.Sprintf("/app/key%02d", i)] = fmt.Sprint(i)
+	}
+
+	tests := []struct {
+		name       string
+		pageSize   int
+		maxResults int32
+		wantPages  []int
+	}{
+		{"default page size", 0, 0, []int{10, 10, 5}},
+		{"custom page size", 4, 10, []int{4, 4, 4, 4, 4, 4, 1}},
+		{"capped by max results", 20, 10, []int{10, 10, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFakeSSM(params)
+			if tt.pageSize > 0 {
+				fake.PageSize = tt.pageSize
+			}
+
+			var pages []int
+			var names []string
+			var nextToken *string
+			for {
+				input := &ssm.GetParametersByPathInput{Path: aws.String("/app"), NextToken: nextToken}
+				if tt.maxResults > 0 {
+					input.MaxResults = aws.Int32(tt.maxResults)
+				}
+				output, err := fake.GetParametersByPath(context.Background(), input)
+				if err != nil {
+					t.Fatalf("GetParametersByPath() unexpected error = %v", err)
+				}
+
+				pages = append(pages, len(output.Parameters))
+				for _, param := range output.Parameters {
+					names = append(names, aws.ToString(param.Name))
+				}
+				if nextToken = output.NextToken; nextToken == nil {
+					break
+				}
+			}
+
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("page sizes = %v, want %v", pages, tt.wantPages)
+			}
+			if len(names) != 25 || names[0] != "/app/key00" || names[24] != "/app/key24" {
+				t.Errorf("names = %v, want /app/key00 to /app/key24 in order", names)
+			}
+			if fake.Calls() != len(tt.wantPages) {
+				t.Errorf("Calls() = %d, want %d", fake.Calls(), len(tt.wantPages))
+			}
+		})
+	}
+}
+
+func TestFakeSSMPath(t *testing.T) {
+	fake := NewFakeSSM(map[string]string{
+		"/app/host":        "localhost",
+		"/app/db/user":     "admin",
+		"/application/key": "other",
+	})
+
+	tests := []struct {
+		name      string
+		path      string
+		recursive bool
+		want      []string
+	}{
+		{"recursive", "/app", true, []string{"/app/db/user", "/app/host"}},
+		{"one level", "/app", false, []string{"/app/host"}},
+		{"trailing slash", "/app/", true, []string{"/app/db/user", "/app/host"}},
+		{"unknown path", "/none", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := fake.GetParametersByPath(context.Background(), &ssm.GetParametersByPathInput{
+				Path:      aws.String(tt.path),
+				Recursive: aws.Bool(tt.recursive),
+			})
+			if err != nil {
+				t.Fatalf("GetParametersByPath() unexpected error = %v", err)
+			}
+
+			var names []string
+			for _, param := range output.Parameters {
+				names = append(names, aws.ToString(param.Name))
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("GetParametersByPath(%s) = %v, want %v", tt.path, names, tt.want)
+			}
+		})
+	}
+
+	if _, err := fake.GetParametersByPath(context.Background(), &ssm.GetParametersByPathInput{Path: aws.String("/app"), NextToken: aws.String("x")}); err == nil {
+		t.Error("GetParametersByPath() expected error for invalid NextToken, got nil")
+	}
+}
+
+func TestFakeSSMDecryption(t *testing.T) {
+	fake := NewFakeSSM(map[string]string{"/app/password": "s3cret", "/app/host": "localhost"})
+	fake.SetSecure("/app/password")
+
+	tests := []struct {
+		name         string
+		decrypt      bool
+		wantPassword string
+	}{
+		{"decrypted", true, "s3cret"},
+		{"encrypted", false, "encrypted:/app/password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := fake.GetParametersByPath(context.Background(), &ssm.GetParametersByPathInput{
+				Path:           aws.String("/app"),
+				WithDecryption: aws.Bool(tt.decrypt),
+			})
+			if err != nil {
+				t.Fatalf("GetParametersByPath() unexpected error = %v", err)
+			}
+
+			host, password := output.Parameters[0], output.Parameters[1]
+			if password.Type != types.ParameterTypeSecureString || aws.ToString(password.Value) != tt.wantPassword {
+				t.Errorf("password = %s %q, want SecureString %q", password.Type, aws.ToString(password.Value), tt.wantPassword)
+			}
+			if host.Type != types.ParameterTypeString || aws.ToString(host.Value) != "localhost" {
+				t.Errorf("host = %s %q, want String \"localhost\"", host.Type, aws.ToString(host.Value))
+			}
+		})
+	}
+}
+
+func TestFakeSSMInitEnvVars(t *testing.T) {
+	params := map[string]string{"/fakessm/password": "s3cret"}
+	for i := 0; i < 12; i++ {
+		params[fmt.Sprintf("/fakessm/nested/key%02d", i)] = fmt.Sprint(i)
+	}
+	fake := NewFakeSSM(params)
+	fake.SetSecure("/fakessm/password")
+	fake.FailFirst(1, SSMThrottlingError())
+
+	keys := []string{"PASSWORD"}
+	for i := 0; i < 12; i++ {
+		keys = append(keys, fmt.Sprintf("NESTED_KEY%02d", i))
+	}
+	unsetEnv(t, keys...)
+
+	if err := ssmenv.InitEnvVarsWithClient("/fakessm", fake); err != nil {
+		t.Fatalf("InitEnvVarsWithClient() unexpected error = %v", err)
+	}
+
+	if got := os.Getenv("PASSWORD"); got != "s3cret" {
+		t.Errorf("PASSWORD = %q, want decrypted value %q", got, "s3cret")
+	}
+	if got := os.Getenv("NESTED_KEY11"); got != "11" {
+		t.Errorf("NESTED_KEY11 = %q, want %q", got, "11")
+	}
+
+	// one throttled call, then the two pages
+	if fake.Calls() != 3 {
+		t.Errorf("Calls() = %d, want 3", fake.Calls())
+	}
+	for i, input := range fake.Inputs() {
+		if !aws.ToBool(input.WithDecryption) || !aws.ToBool(input.Recursive) {
+			t.Errorf("input %d = %v, want recursive with decryption", i, input)
+		}
+	}
+}
+
+func TestFakeSSMPermanentFailure(t *testing.T) {
+	fake := NewFakeSSM(map[string]string{"/fakessm/denied": "value"})
+	fake.FailFirst(1, SSMAccessDeniedError())
+	unsetEnv(t, "DENIED")
+
+	err := ssmenv.InitEnvVarsWithClient("/fakessm", fake)
+	var callErr *ssmenv.CallError
+	if !errors.As(err, &callErr) || !strings.Contains(err.Error(), "AccessDeniedException") {
+		t.Fatalf("InitEnvVarsWithClient() error = %v, want AccessDeniedException CallError", err)
+	}
+	if fake.Calls() != 1 {
+		t.Errorf("Calls() = %d, want 1, permission errors are not retried", fake.Calls())
+	}
+	if _, ok := os.LookupEnv("DENIED"); ok {
+		t.Error("DENIED set, want nothing applied on failure")
+	}
+}
+
+func TestFakeSSMRetriesExhausted(t *testing.T) {
+	fake := NewFakeSSM(map[string]string{"/fakessm/throttled": "value"})
+	fake.FailFirst(3, SSMThrottlingError())
+	unsetEnv(t, "THROTTLED")
+
+	report, err := ssmenv.InitEnvVarsWithOptions(
+		ssmenv.WithClient(fake),
+		ssmenv.WithPath("/fakessm"),
+		ssmenv.WithMaxAttempts(3),
+		ssmenv.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	if err == nil {
+		t.Fatalf("InitEnvVarsWithOptions() = %+v, want throttling error", report)
+	}
+	if fake.Calls() != 3 {
+		t.Errorf("Calls() = %d, want 3 attempts", fake.Calls())
+	}
+
+	if _, err := ssmenv.InitEnvVarsWithOptions(ssmenv.WithClient(fake), ssmenv.WithPath("/fakessm")); err != nil {
+		t.Fatalf("InitEnvVarsWithOptions() unexpected error once failures are consumed = %v", err)
+	}
+	if got := os.Getenv("THROTTLED"); got != "value" {
+		t.Errorf("THROTTLED = %q, want %q", got, "value")
+	}
+}
+
+func TestFakeSSMVersions(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	clock := NewClock(start)
+	fake := NewFakeSSM(nil).WithClock(clock)
+	for _, value := range []string{"v1", "v2", "v3"} {
+		fake.Put("/app/key", value)
+		clock.Advance(time.Hour)
+	}
+	fake.LabelVersion("/app/key", 2, "stable")
+
+	tests := []struct {
+		name        string
+		selector    string
+		wantValue   string
+		wantVersion int64
+		wantCode    string
+	}{
+		{name: "latest", selector: "/app/key", wantValue: "v3", wantVersion: 3},
+		{name: "version", selector: "/app/key:1", wantValue: "v1", wantVersion: 1},
+		{name: "label", selector: "/app/key:stable", wantValue: "v2", wantVersion: 2},
+		{name: "unknown version", selector: "/app/key:9", wantCode: "ParameterVersionNotFound"},
+		{name: "unknown label", selector: "/app/key:beta", wantCode: "ParameterVersionNotFound"},
+		{name: "unknown parameter", selector: "/app/none", wantCode: "ParameterNotFound"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := fake.GetParameter(context.Background(), &ssm.GetParameterInput{Name: aws.String(tt.selector)})
+			if tt.wantCode != "" {
+				var apiErr smithy.APIError
+				if !errors.As(err, &apiErr) || apiErr.ErrorCode() != tt.wantCode {
+					t.Fatalf("GetParameter(%s) error = %v, want %s", tt.selector, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetParameter(%s) unexpected error = %v", tt.selector, err)
+			}
+
+			param := output.Parameter
+			if aws.ToString(param.Name) != "/app/key" || aws.ToString(param.Value) != tt.wantValue || param.Version != tt.wantVersion {
+				t.Errorf("GetParameter(%s) = %s %q version %d, want /app/key %q version %d", tt.selector,
+					aws.ToString(param.Name), aws.ToString(param.Value), param.Version, tt.wantValue, tt.wantVersion)
+			}
+			if want := start.Add(time.Duration(tt.wantVersion-1) * time.Hour); !aws.ToTime(param.LastModifiedDate).Equal(want) {
+				t.Errorf("GetParameter(%s) LastModifiedDate = %v, want %v", tt.selector, param.LastModifiedDate, want)
+			}
+		})
+	}
+
+	if calls := len(fake.ParamInputs()); calls != len(tests) {
+		t.Errorf("ParamInputs() = %d inputs, want %d", calls, len(tests))
+	}
+
+	// Filtering by label serves the labeled versions of the labeled parameters only
+	fake.Put("/app/unlabeled", "value")
+	output, err := fake.GetParametersByPath(context.Background(), &ssm.GetParametersByPathInput{
+		Path:             aws.String("/app"),
+		ParameterFilters: []types.ParameterStringFilter{{Key: aws.String("Label"), Values: []string{"stable"}}},
+	})
+	if err != nil {
+		t.Fatalf("GetParametersByPath() unexpected error = %v", err)
+	}
+	if len(output.Parameters) != 1 || aws.ToString(output.Parameters[0].Value) != "v2" {
+		t.Errorf("GetParametersByPath() = %v, want the version labeled stable of /app/key", output.Parameters)
+	}
+}
+
+func TestFakeSSMGetParameters(t *testing.T) {
+	fake := NewFakeSSM(map[string]string{"/app/host": "localhost", "/app/list": "a,b"})
+	fake.SetStringList("/app/list")
+
+	output, err := fake.GetParameters(context.Background(), &ssm.GetParametersInput{
+		Names: []string{"/app/host", "/app/list", "/app/none", "/app/host:2"},
+	})
+	if err != nil {
+		t.Fatalf("GetParameters() unexpected error = %v", err)
+	}
+	if len(output.Parameters) != 2 || output.Parameters[1].Type != types.ParameterTypeStringList {
+		t.Errorf("GetParameters() = %v, want /app/host and the StringList /app/list", output.Parameters)
+	}
+	if want := []string{"/app/none", "/app/host:2"}; !reflect.DeepEqual(output.InvalidParameters, want) {
+		t.Errorf("GetParameters() invalid parameters = %v, want %v", output.InvalidParameters, want)
+	}
+
+	names := make([]string, 11)
+	for i := range names {
+		names[i] = fmt.Sprintf("/app/key%02d", i)
+	}
+	if _, err := fake.GetParameters(context.Background(), &ssm.GetParametersInput{Names: names}); err == nil {
+		t.Error("GetParameters() expected error for 11 names, got nil")
+	}
+	if calls := len(fake.BatchInputs()); calls != 2 {
+		t.Errorf("BatchInputs() = %d inputs, want 2", calls)
+	}
+}
+
+func TestFakeSSMFailures(t *testing.T) {
+	fake := NewFakeSSM(map[string]string{"/app/host": "localhost", "/denied/key": "value"})
+	fake.FailPath("/denied", SSMAccessDeniedError())
+	fake.FailFirst(1, SSMThrottlingError())
+
+	get := func(ctx context.Context, path string) error {
+		_, err := fake.GetParametersByPath(ctx, &ssm.GetParametersByPathInput{Path: aws.String(path)})
+		return err
+	}
+
+	if err := get(context.Background(), "/denied"); !strings.Contains(fmt.Sprint(err), "ThrottlingException") {
+		t.Errorf("GetParametersByPath() error = %v, want the queued ThrottlingException first", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := get(context.Background(), "/denied"); !strings.Contains(fmt.Sprint(err), "AccessDeniedException") {
+			t.Errorf("GetParametersByPath() error = %v, want AccessDeniedException for every call", err)
+		}
+	}
+	if err := get(context.Background(), "/app"); err != nil {
+		t.Errorf("GetParametersByPath() unexpected error = %v for another path", err)
+	}
+
+	fake.Delay = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := get(ctx, "/app"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetParametersByPath() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if fake.Calls() != 5 || fake.MaxInFlight() != 1 {
+		t.Errorf("Calls() = %d and MaxInFlight() = %d, want 5 calls with the timed out one, one at a time", fake.Calls(), fake.MaxInFlight())
+	}
+}
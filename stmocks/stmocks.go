@@ -2,15 +2,33 @@ package stmocks
 
 import "context"
 
-type key int
+// mockKey is the context key of a mock value, distinct for each name and type of value.
+type mockKey[T any] struct {
+	name string
+}
+
+// defaultName is the name of the value set by NewMockContext.
+const defaultName = ""
 
-var mockKey key
+// WithValue returns a copy of ctx holding the mock value v under the name. Values are keyed by name and type,
+// so values of different names or types coexist, and setting a value again overwrites it.
+func WithValue[T any](ctx context.Context, name string, v T) context.Context {
+	return context.WithValue(ctx, mockKey[T]{name: name}, v)
+}
+
+// Value returns the mock value of type T set under the name by WithValue. It returns false if there is none,
+// including when the value was set with another type.
+func Value[T any](ctx context.Context, name string) (T, bool) {
+	v, ok := ctx.Value(mockKey[T]{name: name}).(T)
+	return v, ok
+}
 
+// NewMockContext returns a copy of ctx holding the mock string value, as WithValue does with the default name.
 func NewMockContext(ctx context.Context, value string) context.Context {
-	return context.WithValue(ctx, mockKey, value)
+	return WithValue(ctx, defaultName, value)
 }
 
+// FromMockContext returns the mock string value set by NewMockContext.
 func FromMockContext(ctx context.Context) (string, bool) {
-	err, ok := ctx.Value(mockKey).(string)
-	return err, ok
+	return Value[string](ctx, defaultName)
 }
@@ -0,0 +1,86 @@
+package stmocks
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type quote struct {
+	Symbol string
+	Price  float64
+}
+
+func TestValue(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+
+	ctx := context.Background()
+	ctx = WithValue(ctx, "quote", quote{Symbol: "AAPL", Price: 190.5})
+	ctx = WithValue(ctx, "fetch", errFetch)
+	ctx = WithValue(ctx, "limit", 10)
+	ctx = WithValue(ctx, "offset", 20)
+	ctx = NewMockContext(ctx, "legacy")
+
+	if got, ok := Value[quote](ctx, "quote"); !ok || got != (quote{Symbol: "AAPL", Price: 190.5}) {
+		t.Errorf("Value[quote]() = %v, %v, want the quote", got, ok)
+	}
+	if got, ok := Value[error](ctx, "fetch"); !ok || got != errFetch {
+		t.Errorf("Value[error]() = %v, %v, want %v", got, ok, errFetch)
+	}
+	if got, ok := Value[int](ctx, "limit"); !ok || got != 10 {
+		t.Errorf("Value[int](limit) = %v, %v, want 10", got, ok)
+	}
+	if got, ok := Value[int](ctx, "offset"); !ok || got != 20 {
+		t.Errorf("Value[int](offset) = %v, %v, want 20", got, ok)
+	}
+	if got, ok := FromMockContext(ctx); !ok || got != "legacy" {
+		t.Errorf("FromMockContext() = %q, %v, want legacy", got, ok)
+	}
+}
+
+func TestValueMissing(t *testing.T) {
+	ctx := WithValue(context.Background(), "limit", 10)
+
+	tests := []struct {
+		name string
+		get  func() (any, bool)
+	}{
+		{"type mismatch", func() (any, bool) { return Value[string](ctx, "limit") }},
+		{"wider type", func() (any, bool) { return Value[int64](ctx, "limit") }},
+		{"interface type", func() (any, bool) { return Value[any](ctx, "limit") }},
+		{"unknown name", func() (any, bool) { return Value[int](ctx, "offset") }},
+		{"no mock string", func() (any, bool) { return FromMockContext(ctx) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := tt.get(); ok {
+				t.Errorf("got %v, true, want false", got)
+			}
+		})
+	}
+
+	if got, ok := Value[error](context.Background(), "fetch"); ok || got != nil {
+		t.Errorf("Value[error]() = %v, %v, want nil, false", got, ok)
+	}
+}
+
+func TestValueOverwrite(t *testing.T) {
+	parent := WithValue(context.Background(), "limit", 10)
+	child := WithValue(parent, "limit", 50)
+
+	if got, _ := Value[int](child, "limit"); got != 50 {
+		t.Errorf("Value[int]() = %d, want the overwritten 50", got)
+	}
+	if got, _ := Value[int](parent, "limit"); got != 10 {
+		t.Errorf("Value[int]() on parent = %d, want 10", got)
+	}
+
+	ctx := NewMockContext(NewMockContext(context.Background(), "first"), "second")
+	if got, _ := FromMockContext(ctx); got != "second" {
+		t.Errorf("FromMockContext() = %q, want second", got)
+	}
+	if got, ok := Value[string](ctx, ""); !ok || got != "second" {
+		t.Errorf("Value[string](\"\") = %q, %v, want the NewMockContext value", got, ok)
+	}
+}
@@ -0,0 +1,104 @@
+package stmocks
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/stclock"
+)
+
+// WriterMock is an io.Writer counting the bytes written to an underlying writer, optionally failing from a given
+// write or waiting before each write, to test the error paths and the throughput of the code writing to it.
+// It is safe for concurrent use, the writes to the underlying writer being serialized.
+type WriterMock struct {
+	mu         sync.Mutex
+	underlying io.Writer
+	failOn     int
+	err        error
+	delay      time.Duration
+	clock      stclock.Clock
+	writes     int
+	bytes      int64
+}
+
+// FailingWriter creates a writer failing with err from the failOn-th write, 1 for the first one, and for every write
+// after it, like a closed connection or a full disk. The previous writes are kept in a buffer returned by String.
+func FailingWriter(failOn int, err error) *WriterMock {
+	return &WriterMock{underlying: &bytes.Buffer{}, failOn: failOn, err: err, clock: stclock.System()}
+}
+
+// SlowWriter creates a writer waiting for the delay before each write to underlying. The delay is waited on the
+// system clock unless WithClock is used.
+func SlowWriter(delay time.Duration, underlying io.Writer) *WriterMock {
+	return &WriterMock{underlying: underlying, delay: delay, clock: stclock.System()}
+}
+
+// CountingWriter creates a writer counting the writes and bytes written to underlying.
+func CountingWriter(underlying io.Writer) *WriterMock {
+	return &WriterMock{underlying: underlying, clock: stclock.System()}
+}
+
+// WithClock waits the delay of the writer on clock, i.e. a ClockMock, so the tests do not sleep.
+func (w *WriterMock) WithClock(clock stclock.Clock) *WriterMock {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.clock = clock
+
+	return w
+}
+
+// Write waits for the delay of the writer, then writes p to the underlying writer unless the writer fails.
+func (w *WriterMock) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.writes++
+	write, delay, clock := w.writes, w.delay, w.clock
+	w.mu.Unlock()
+
+	if delay > 0 {
+		<-clock.NewTimer(delay).C()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failOn > 0 && write >= w.failOn {
+		return 0, w.err
+	}
+
+	n, err := w.underlying.Write(p)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// Writes returns the number of calls to Write, failed ones included.
+func (w *WriterMock) Writes() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.writes
+}
+
+// Bytes returns the number of bytes written to the underlying writer.
+func (w *WriterMock) Bytes() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.bytes
+}
+
+// String returns the data written before the failure by a FailingWriter, or the data of an underlying writer
+// implementing fmt.Stringer, i.e. a bytes.Buffer.
+func (w *WriterMock) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if s, ok := w.underlying.(interface{ String() string }); ok {
+		return s.String()
+	}
+
+	return ""
+}
@@ -0,0 +1,118 @@
+package stmocks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFailingWriter(t *testing.T) {
+	errDiskFull := errors.New("disk full")
+
+	tests := []struct {
+		name       string
+		failOn     int
+		wantErrs   []bool
+		wantString string
+	}{
+		{"first write", 1, []bool{true, true, true}, ""},
+		{"third write", 3, []bool{false, false, true, true}, "ab"},
+		{"never", 0, []bool{false, false, false}, "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := FailingWriter(tt.failOn, errDiskFull)
+			for i, wantErr := range tt.wantErrs {
+				n, err := w.Write([]byte{byte('a' + i)})
+				if wantErr && (!errors.Is(err, errDiskFull) || n != 0) {
+					t.Errorf("Write() %d = %d, %v, want 0, %v", i+1, n, err, errDiskFull)
+				}
+				if !wantErr && (err != nil || n != 1) {
+					t.Errorf("Write() %d = %d, %v, want 1, nil", i+1, n, err)
+				}
+			}
+
+			if w.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", w.String(), tt.wantString)
+			}
+			if w.Writes() != len(tt.wantErrs) || w.Bytes() != int64(len(tt.wantString)) {
+				t.Errorf("Writes(), Bytes() = %d, %d, want %d, %d", w.Writes(), w.Bytes(), len(tt.wantErrs), len(tt.wantString))
+			}
+		})
+	}
+}
+
+func TestSlowWriter(t *testing.T) {
+	clock := NewClock(clockStart)
+	var buf bytes.Buffer
+	w := SlowWriter(time.Second, &buf).WithClock(clock)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(w, "slow")
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("Write() returned before the delay")
+	default:
+	}
+	if buf.Len() != 0 {
+		t.Errorf("written %q before the delay, want nothing", buf.String())
+	}
+
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("Write() unexpected error = %v", err)
+	}
+	if buf.String() != "slow" || w.Bytes() != 4 {
+		t.Errorf("written %q, Bytes() = %d, want \"slow\", 4", buf.String(), w.Bytes())
+	}
+}
+
+func TestSlowWriterSystemClock(t *testing.T) {
+	var buf bytes.Buffer
+	w := SlowWriter(10*time.Millisecond, &buf)
+
+	start := time.Now()
+	fmt.Fprint(w, "a")
+	fmt.Fprint(w, "b")
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("two writes took %v, want at least 20ms", elapsed)
+	}
+	if buf.String() != "ab" {
+		t.Errorf("written %q, want \"ab\"", buf.String())
+	}
+}
+
+func TestCountingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := CountingWriter(&buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			io.WriteString(w, "12345")
+		}()
+	}
+	wg.Wait()
+
+	if w.Writes() != 20 || w.Bytes() != 100 || buf.Len() != 100 {
+		t.Errorf("Writes(), Bytes() = %d, %d with %d bytes written, want 20, 100", w.Writes(), w.Bytes(), buf.Len())
+	}
+	if w.String() != buf.String() {
+		t.Error("String() does not return the data of the underlying buffer")
+	}
+	if CountingWriter(io.Discard).String() != "" {
+		t.Error("String() of a writer without String method is not empty")
+	}
+}
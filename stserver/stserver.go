@@ -0,0 +1,138 @@
+// Package stserver composes the request logger of stlogs, the error responses of sterrors and an optional
+// error injection, i.e. by stmocks in tests, into the middleware stack shared by the HTTP services.
+// Each piece remains usable on its own: RequestLogger, sterrors.Handler and ErrorInjection.
+package stserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/stocktwits/go-infrastructure/v2/sterrors"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+)
+
+// RequestIDHeader is the header holding the request id, read from the requests and written to the responses.
+const RequestIDHeader = "X-Request-ID"
+
+// HandlerFunc is an HTTP handler returning an error, written as the response by the stack.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Option customizes the stack created by NewHTTPStack.
+type Option func(*options)
+
+// options holds the settings of a stack.
+type options struct {
+	injectionTarget string
+	injector        ErrorInjector
+}
+
+// ErrorInjector returns the error injected for target in the request context, nil if none, i.e. stmocks.ErrorFor.
+type ErrorInjector func(ctx context.Context, target string) error
+
+// WithErrorInjection makes the handlers fail with the error returned by injector for target, i.e. stmocks.ErrorFor
+// with the errors set by stmocks.WithError or a stmocks scenario, so test environments can exercise the error
+// responses. It should not be used in production.
+func WithErrorInjection(target string, injector ErrorInjector) Option {
+	return func(o *options) {
+		o.injectionTarget = target
+		o.injector = injector
+	}
+}
+
+// NewHTTPStack returns a middleware turning a HandlerFunc into an http.Handler that:
+//   - links a request logger to the request context using RequestLogger, with the request id as txId,
+//   - writes the error returned by the handler using factory, as sterrors.Handler does,
+//     with the request id in the body and the X-Request-ID header,
+//   - recovers from the panics of the handler, logged with their stack and written as the default error of factory.
+//
+// Options add an error injection, see WithErrorInjection.
+func NewHTTPStack(logger stlogs.Logger, factory *sterrors.ErrorFactory, opts ...Option) func(HandlerFunc) http.Handler {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(h HandlerFunc) http.Handler {
+		if o.injector != nil {
+			h = ErrorInjection(o.injectionTarget, o.injector, h)
+		}
+
+		return RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestLogger, _ := logger.NewWithContext(r.Context())
+			sterrors.Handler(h, factory, requestLogger).ServeHTTP(w, r)
+		}))
+	}
+}
+
+// RequestLogger returns a middleware linking a logger to the context of each request with logger.NewWithContext,
+// so the entries logged with the request context share its txId. The txId is the X-Request-ID header of the
+// request if set, and is written to the X-Request-ID header of the response.
+// Each request is logged once served, with its method, path, status and duration.
+func RequestLogger(logger stlogs.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestLogger, ctx := logger.NewWithContext(r.Context())
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID != "" {
+				requestLogger.AddData("txId", requestID)
+			} else {
+				requestID = stlogs.TxID(ctx)
+			}
+			if requestID != "" {
+				w.Header().Set(RequestIDHeader, requestID)
+			}
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				requestLogger.NewEntry().
+					AddData("method", r.Method).
+					AddData("path", r.URL.Path).
+					AddData("status", sw.status).
+					AddData("duration_ms", time.Since(start).Milliseconds()).
+					Infof("%s %s %d", r.Method, r.URL.Path, sw.status)
+			}()
+
+			next.ServeHTTP(sw, r.WithContext(ctx))
+		})
+	}
+}
+
+// ErrorInjection returns a HandlerFunc returning the error returned by injector for target and the request
+// context, if any, instead of calling h.
+func ErrorInjection(target string, injector ErrorInjector, h HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := injector(r.Context(), target); err != nil {
+			return err
+		}
+
+		return h(w, r)
+	}
+}
+
+// statusWriter records the status written to a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches it.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
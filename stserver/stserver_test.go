@@ -0,0 +1,179 @@
+package stserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stocktwits/go-infrastructure/v2/sterrors"
+	"github.com/stocktwits/go-infrastructure/v2/stlogs"
+	"github.com/stocktwits/go-infrastructure/v2/stmocks"
+)
+
+// captureLogs returns an stlogs logger writing to a buffer, and a function returning its entries.
+// The writes are serialized by the stmocks writer since the server logs from its own goroutines.
+func captureLogs(t *testing.T) (stlogs.Logger, func() []map[string]any) {
+	w := stmocks.CountingWriter(&bytes.Buffer{})
+	logger := stlogs.NewLocalWithOutput("stserver-test", "info", w)
+
+	return logger, func() []map[string]any {
+		var entries []map[string]any
+		scanner := bufio.NewScanner(strings.NewReader(w.String()))
+		for scanner.Scan() {
+			var entry map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("invalid log entry %q: %v", scanner.Text(), err)
+			}
+			entries = append(entries, entry)
+		}
+
+		return entries
+	}
+}
+
+const codeNotFound sterrors.ErrorCode = 1001
+
+func TestNewHTTPStack(t *testing.T) {
+	factory := sterrors.NewFactory(sterrors.ErrorConfig{
+		codeNotFound: {ErrorType: "NotFound", Message: "symbol not found", Http_code: http.StatusNotFound},
+	}, "internal error", http.StatusInternalServerError)
+
+	tests := []struct {
+		name       string
+		handler    HandlerFunc
+		requestID  string
+		wantStatus int
+		wantBody   string
+		wantLogs   int
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte("ok"))
+				return err
+			},
+			requestID:  "req-1",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok",
+			wantLogs:   1,
+		},
+		{
+			name: "handler error",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return factory.NewError(codeNotFound, errors.New("AAPL"))
+			},
+			requestID:  "req-2",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"code":1001,"message":"symbol not found","type":"NotFound","request_id":"req-2"}`,
+			wantLogs:   1,
+		},
+		{
+			name: "panic",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				panic("boom")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"code":0,"message":"internal error","type":"","request_id":"%s"}`,
+			wantLogs:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, entries := captureLogs(t)
+			server := httptest.NewServer(NewHTTPStack(logger, factory)(tt.handler))
+			defer server.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/symbols/AAPL", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("GET unexpected error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("io.ReadAll() unexpected error = %v", err)
+			}
+
+			requestID := resp.Header.Get(RequestIDHeader)
+			if tt.requestID != "" && requestID != tt.requestID {
+				t.Errorf("%s header = %q, want %q", RequestIDHeader, requestID, tt.requestID)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if want := strings.ReplaceAll(tt.wantBody, "%s", requestID); string(body) != want {
+				t.Errorf("body = %s, want %s", body, want)
+			}
+
+			logged := entries()
+			if len(logged) != tt.wantLogs {
+				t.Fatalf("logged %d entries, want %d", len(logged), tt.wantLogs)
+			}
+			for _, entry := range logged {
+				if data, _ := entry["data"].(map[string]any); data["txId"] != requestID {
+					t.Errorf("logged %v, want txId %q", entry, requestID)
+				}
+			}
+
+			request, _ := logged[len(logged)-1]["data"].(map[string]any)
+			if request["status"] != float64(tt.wantStatus) || request["path"] != "/symbols/AAPL" {
+				t.Errorf("request entry = %v, want status %d and path /symbols/AAPL", request, tt.wantStatus)
+			}
+			if tt.wantLogs > 1 {
+				if panicked, _ := logged[0]["data"].(map[string]any); panicked["error"] != "panic: boom" || panicked["stack"] == nil {
+					t.Errorf("panic entry = %v, want the panic with its stack", panicked)
+				}
+			}
+		})
+	}
+}
+
+func TestNewHTTPStackErrorInjection(t *testing.T) {
+	factory := sterrors.NewFactory(sterrors.ErrorConfig{
+		codeNotFound: {ErrorType: "NotFound", Message: "symbol not found", Http_code: http.StatusNotFound},
+	}, "internal error", http.StatusInternalServerError)
+	logger := stmocks.NewLogger()
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantStatus int
+	}{
+		{name: "honored", opts: []Option{WithErrorInjection("symbols", stmocks.ErrorFor)}, wantStatus: http.StatusNotFound},
+		{name: "ignored without the option", wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := stmocks.WithError(context.Background(), "symbols", factory.NewError(codeNotFound, nil))
+			req := httptest.NewRequest(http.MethodGet, "/symbols/AAPL", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			NewHTTPStack(logger, factory, tt.opts...)(ok).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	if !logger.DataEquals("status", http.StatusNotFound) || !logger.DataEquals("status", http.StatusNoContent) {
+		t.Errorf("logged %v, want the status of each request", logger.Entries())
+	}
+}